@@ -4,6 +4,8 @@ go 1.13
 
 require (
 	github.com/Jeffail/gabs v1.4.0
+	github.com/davecgh/go-spew v1.1.1
+	github.com/evanphx/json-patch v4.5.0+incompatible
 	github.com/go-logr/logr v0.1.0
 	github.com/go-openapi/spec v0.19.4
 	github.com/google/uuid v1.1.1
@@ -12,11 +14,13 @@ require (
 	github.com/onsi/gomega v1.8.1
 	github.com/operator-framework/operator-sdk v0.17.0
 	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.5.1
 	github.com/spf13/pflag v1.0.5
 	github.com/stretchr/testify v1.4.0
 	golang.org/x/crypto v0.0.0-20200220183623-bac4c82f6975
 	gopkg.in/yaml.v2 v2.2.8
 	k8s.io/api v0.17.4
+	k8s.io/apiextensions-apiserver v0.17.4
 	k8s.io/apimachinery v0.17.4
 	k8s.io/client-go v12.0.0+incompatible
 	k8s.io/code-generator v0.17.4
@@ -25,6 +29,7 @@ require (
 	k8s.io/kubernetes v1.17.4
 	sigs.k8s.io/controller-runtime v0.5.2
 	sigs.k8s.io/controller-tools v0.2.8
+	sigs.k8s.io/yaml v1.1.0
 )
 
 replace (
@@ -8,6 +8,11 @@ set -x
 opDeploy="operator/deploy"
 chartTmpl="charts/cass-operator-chart/templates"
 crdFilename="cassandra.datastax.com_cassandradatacenters_crd.yaml"
+backupCrdFilename="cassandra.datastax.com_cassandrabackups_crd.yaml"
+restoreCrdFilename="cassandra.datastax.com_cassandrarestores_crd.yaml"
+scheduleCrdFilename="cassandra.datastax.com_cassandrasnapshotschedules_crd.yaml"
+keyspaceCrdFilename="cassandra.datastax.com_cassandrakeyspaces_crd.yaml"
+clusterCrdFilename="cassandra.datastax.com_cassandraclusters_crd.yaml"
 
 diff -u $opDeploy/role.yaml                   $chartTmpl/role.yaml | diff-so-fancy || true
 diff -u $opDeploy/role_binding.yaml           $chartTmpl/rolebinding.yaml | diff-so-fancy || true
@@ -15,7 +20,13 @@ diff -u $opDeploy/cluster_role.yaml           $chartTmpl/clusterrole.yaml | diff
 diff -u $opDeploy/cluster_role_binding.yaml   $chartTmpl/clusterrolebinding.yaml | diff-so-fancy || true
 diff -u $opDeploy/service_account.yaml        $chartTmpl/serviceaccount.yaml | diff-so-fancy || true
 diff -u $opDeploy/webhook_configuration.yaml  $chartTmpl/validatingwebhookconfiguration.yaml | diff-so-fancy || true
+diff -u $opDeploy/mutating_webhook_configuration.yaml $chartTmpl/mutatingwebhookconfiguration.yaml | diff-so-fancy || true
 diff -u $opDeploy/operator.yaml               $chartTmpl/deployment.yaml | diff-so-fancy || true
 diff -u $opDeploy/webhook_service.yaml        $chartTmpl/service.yaml | diff-so-fancy || true
 diff -u $opDeploy/webhook_secret.yaml         $chartTmpl/secret.yaml | diff-so-fancy || true
 diff -u $opDeploy/crds/$crdFilename           $chartTmpl/customresourcedefinition.yaml | diff-so-fancy || true
+diff -u $opDeploy/crds/$backupCrdFilename     $chartTmpl/customresourcedefinition-cassandrabackups.yaml | diff-so-fancy || true
+diff -u $opDeploy/crds/$restoreCrdFilename    $chartTmpl/customresourcedefinition-cassandrarestores.yaml | diff-so-fancy || true
+diff -u $opDeploy/crds/$scheduleCrdFilename   $chartTmpl/customresourcedefinition-cassandrasnapshotschedules.yaml | diff-so-fancy || true
+diff -u $opDeploy/crds/$keyspaceCrdFilename   $chartTmpl/customresourcedefinition-cassandrakeyspaces.yaml | diff-so-fancy || true
+diff -u $opDeploy/crds/$clusterCrdFilename    $chartTmpl/customresourcedefinition-cassandraclusters.yaml | diff-so-fancy || true
@@ -73,6 +73,7 @@ func cleanupLingeringHelmResources() {
 	_ = kubectl.DeleteByTypeAndName("clusterrole", "cass-operator-cluster-role").ExecV()
 	_ = kubectl.DeleteByTypeAndName("clusterrolebinding", "cass-operator").ExecV()
 	_ = kubectl.DeleteByTypeAndName("validatingwebhookconfiguration", "cassandradatacenter-webhook-registration").ExecV()
+	_ = kubectl.DeleteByTypeAndName("mutatingwebhookconfiguration", "cassandradatacenter-mutating-webhook-registration").ExecV()
 	_ = kubectl.DeleteByTypeAndName("crd", "cassandradatacenters.cassandra.datastax.com").ExecV()
 }
 
@@ -34,6 +34,16 @@ const (
 	mermaidJsImage             = "operator-mermaid-js"
 	generatedDseDataCentersCrd = "operator/deploy/crds/cassandra.datastax.com_cassandradatacenters_crd.yaml"
 	helmChartCrd               = "charts/cass-operator-chart/templates/customresourcedefinition.yaml"
+	generatedBackupsCrd        = "operator/deploy/crds/cassandra.datastax.com_cassandrabackups_crd.yaml"
+	helmChartBackupsCrd        = "charts/cass-operator-chart/templates/customresourcedefinition-cassandrabackups.yaml"
+	generatedRestoresCrd       = "operator/deploy/crds/cassandra.datastax.com_cassandrarestores_crd.yaml"
+	helmChartRestoresCrd       = "charts/cass-operator-chart/templates/customresourcedefinition-cassandrarestores.yaml"
+	generatedSchedulesCrd      = "operator/deploy/crds/cassandra.datastax.com_cassandrasnapshotschedules_crd.yaml"
+	helmChartSchedulesCrd      = "charts/cass-operator-chart/templates/customresourcedefinition-cassandrasnapshotschedules.yaml"
+	generatedKeyspacesCrd      = "operator/deploy/crds/cassandra.datastax.com_cassandrakeyspaces_crd.yaml"
+	helmChartKeyspacesCrd      = "charts/cass-operator-chart/templates/customresourcedefinition-cassandrakeyspaces.yaml"
+	generatedClustersCrd       = "operator/deploy/crds/cassandra.datastax.com_cassandraclusters_crd.yaml"
+	helmChartClustersCrd       = "charts/cass-operator-chart/templates/customresourcedefinition-cassandraclusters.yaml"
 	packagePath                = "github.com/datastax/cass-operator/operator"
 	envGitBranch               = "MO_BRANCH"
 	envVersionString           = "MO_VERSION"
@@ -237,6 +247,7 @@ func doSdkGenerate() {
 	generateK8sAndOpenApi()
 	postProcessCrd()
 	patchCrdToTemplate()
+	cpAdditionalCrdsToChart()
 }
 
 func cpCrdToChart() {
@@ -247,6 +258,25 @@ func cpCrdToChart() {
 	mageutil.PanicOnError(err)
 }
 
+// The CRDs other than the CassandraDatacenter one do not need any patching
+// to be used as chart templates
+func cpAdditionalCrdsToChart() {
+	crds := map[string]string{
+		generatedBackupsCrd:   helmChartBackupsCrd,
+		generatedRestoresCrd:  helmChartRestoresCrd,
+		generatedSchedulesCrd: helmChartSchedulesCrd,
+		generatedKeyspacesCrd: helmChartKeyspacesCrd,
+		generatedClustersCrd:  helmChartClustersCrd,
+	}
+	for generated, chart := range crds {
+		crd, err := ioutil.ReadFile(generated)
+		mageutil.PanicOnError(err)
+
+		err = ioutil.WriteFile(chart, crd, os.ModePerm)
+		mageutil.PanicOnError(err)
+	}
+}
+
 func patchCrdToTemplate() {
 	shutil.RunVPanic("patch", generatedDseDataCentersCrd, "mage/operator/crd.patch", "-o", helmChartCrd)
 }
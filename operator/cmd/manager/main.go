@@ -21,6 +21,7 @@ import (
 
 	"github.com/datastax/cass-operator/operator/pkg/apis"
 	"github.com/datastax/cass-operator/operator/pkg/controller"
+	"github.com/datastax/cass-operator/operator/pkg/images"
 	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
 	kubemetrics "github.com/operator-framework/operator-sdk/pkg/kube-metrics"
 	"github.com/operator-framework/operator-sdk/pkg/leader"
@@ -138,6 +139,11 @@ func main() {
 		log.Error(err, "Failed to read base OS into env")
 	}
 
+	if err = loadVersionCatalog(); err != nil {
+		log.Error(err, "Failed to load the version catalog")
+		os.Exit(1)
+	}
+
 	// Set default manager options
 	options := manager.Options{
 		Namespace:          namespace,
@@ -189,7 +195,7 @@ func main() {
 	if !skipWebhook {
 		err = controllerRuntime.NewWebhookManagedBy(mgr).For(&api.CassandraDatacenter{}).Complete()
 		if err != nil {
-			log.Error(err, "unable to create validating webhook for CassandraDatacenter")
+			log.Error(err, "unable to create validating, defaulting and conversion webhooks for CassandraDatacenter")
 			os.Exit(1)
 		}
 	}
@@ -236,6 +242,30 @@ func readBaseOsIntoEnv() error {
 	return nil
 }
 
+// loadVersionCatalog replaces the default version catalog with the file named
+// by the VERSION_CATALOG_FILE env variable, if any. A missing file, e.g. from an
+// optional ConfigMap that does not exist, leaves the default catalog in place.
+func loadVersionCatalog() error {
+	path := os.Getenv(images.EnvVersionCatalogFile)
+	if path == "" {
+		return nil
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		log.Info(fmt.Sprintf("No version catalog at %s, using the default one", path))
+		return nil
+	}
+
+	catalog, err := images.LoadCatalog(path)
+	if err != nil {
+		return err
+	}
+	images.SetCatalog(catalog)
+	log.Info(fmt.Sprintf("Loaded the version catalog at %s", path))
+
+	return nil
+}
+
 // addMetrics will create the Services and Service Monitors to allow the operator export the metrics by using
 // the Prometheus operator
 func addMetrics(ctx context.Context, cfg *rest.Config) {
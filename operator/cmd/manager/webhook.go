@@ -16,6 +16,7 @@ import (
 
 	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -23,33 +24,30 @@ import (
 
 func ensureWebhookCertificate(cfg *rest.Config, namespace string) (err error) {
 	var contents []byte
-	var webhook map[string]interface{}
 	var bundled string
 	var client crclient.Client
 	var certpool *x509.CertPool
 	if contents, err = ioutil.ReadFile(serverCertFile); err == nil && len(contents) > 0 {
 		if client, err = crclient.New(cfg, crclient.Options{}); err == nil {
-			if err, _, webhook, _ = fetchWebhookForNamespace(client, namespace); err == nil {
-				if bundled, _, err = unstructured.NestedString(webhook, "clientConfig", "caBundle"); err == nil {
-					if base64.StdEncoding.EncodeToString([]byte(contents)) == bundled {
-						certpool, err = x509.SystemCertPool()
-						if err != nil {
-							certpool = x509.NewCertPool()
-						}
-						var block *pem.Block
-						if block, _ = pem.Decode(contents); err == nil && block != nil {
-							var cert *x509.Certificate
-							if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
-								certpool.AddCert(cert)
-								log.Info("Attempting to validate operator CA")
-								verify_opts := x509.VerifyOptions{
-									DNSName: fmt.Sprintf("cassandradatacenter-webhook-service.%s.svc", namespace),
-									Roots:   certpool,
-								}
-								if _, err = cert.Verify(verify_opts); err == nil {
-									log.Info("Found valid certificate for webhook")
-									return nil
-								}
+			if bundled, err = fetchWebhookCABundle(client, namespace); err == nil {
+				if base64.StdEncoding.EncodeToString([]byte(contents)) == bundled {
+					certpool, err = x509.SystemCertPool()
+					if err != nil {
+						certpool = x509.NewCertPool()
+					}
+					var block *pem.Block
+					if block, _ = pem.Decode(contents); err == nil && block != nil {
+						var cert *x509.Certificate
+						if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+							certpool.AddCert(cert)
+							log.Info("Attempting to validate operator CA")
+							verify_opts := x509.VerifyOptions{
+								DNSName: fmt.Sprintf("cassandradatacenter-webhook-service.%s.svc", namespace),
+								Roots:   certpool,
+							}
+							if _, err = cert.Verify(verify_opts); err == nil {
+								log.Info("Found valid certificate for webhook")
+								return nil
 							}
 						}
 					}
@@ -92,16 +90,89 @@ func updateSecretAndWebhook(cfg *rest.Config, namespace string) (err error) {
 	return err
 }
 
-func fetchWebhookForNamespace(client crclient.Client, namespace string) (err error, webhook_config *unstructured.Unstructured, webhook map[string]interface{}, unstructured_index int) {
+// webhookConfiguration is a webhook configuration that the operator keeps the
+// CA bundle of up to date
+type webhookConfiguration struct {
+	kind string
+	name string
+}
+
+var webhookConfigurations = []webhookConfiguration{
+	{kind: "ValidatingWebhookConfiguration", name: "cassandradatacenter-webhook-registration"},
+	{kind: "MutatingWebhookConfiguration", name: "cassandradatacenter-mutating-webhook-registration"},
+}
+
+// fetchWebhookCABundle returns the CA bundle of the webhooks for the namespace,
+// or an empty string when the webhook configurations and the conversion
+// webhook of the CRD do not agree on it. A webhook that is not installed is
+// skipped.
+func fetchWebhookCABundle(client crclient.Client, namespace string) (bundled string, err error) {
+	found := false
+	for _, configuration := range webhookConfigurations {
+		var webhook map[string]interface{}
+		var webhookBundle string
+		if err, _, webhook, _ = fetchWebhookForNamespace(client, namespace, configuration); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return "", err
+		}
+		if webhookBundle, _, err = unstructured.NestedString(webhook, "clientConfig", "caBundle"); err != nil {
+			return "", err
+		}
+		if found && webhookBundle != bundled {
+			return "", nil
+		}
+		bundled = webhookBundle
+		found = true
+	}
+
+	_, clientConfig, present, crdErr := fetchConversionWebhook(client)
+	if crdErr != nil && !apierrors.IsNotFound(crdErr) {
+		return "", crdErr
+	}
+	if crdErr == nil && present {
+		crdNamespace, _, _ := unstructured.NestedString(clientConfig, "service", "namespace")
+		crdBundle, _, _ := unstructured.NestedString(clientConfig, "caBundle")
+		if crdNamespace != namespace || (found && crdBundle != bundled) {
+			return "", nil
+		}
+		bundled = crdBundle
+	}
+	return bundled, nil
+}
+
+// The CRD whose conversion webhook the operator serves
+const conversionWebhookCRD = "cassandradatacenters.cassandra.datastax.com"
+
+// fetchConversionWebhook returns the CassandraDatacenter CRD and the client
+// config of its conversion webhook. present is false when the CRD does not
+// convert between versions through a webhook, e.g. on Kubernetes older than
+// 1.15.
+func fetchConversionWebhook(client crclient.Client) (crd *unstructured.Unstructured, clientConfig map[string]interface{}, present bool, err error) {
+	crd = &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "apiextensions.k8s.io",
+		Kind:    "CustomResourceDefinition",
+		Version: "v1beta1",
+	})
+	if err = client.Get(context.Background(), crclient.ObjectKey{Name: conversionWebhookCRD}, crd); err != nil {
+		return crd, nil, false, err
+	}
+	clientConfig, present, err = unstructured.NestedMap(crd.Object, "spec", "conversion", "webhookClientConfig")
+	return crd, clientConfig, present, err
+}
+
+func fetchWebhookForNamespace(client crclient.Client, namespace string, configuration webhookConfiguration) (err error, webhook_config *unstructured.Unstructured, webhook map[string]interface{}, unstructured_index int) {
 
 	webhook_config = &unstructured.Unstructured{}
 	webhook_config.SetGroupVersionKind(schema.GroupVersionKind{
 		Group:   "admissionregistration.k8s.io",
-		Kind:    "ValidatingWebhookConfiguration",
+		Kind:    configuration.kind,
 		Version: "v1beta1",
 	})
 	err = client.Get(context.Background(), crclient.ObjectKey{
-		Name: "cassandradatacenter-webhook-registration",
+		Name: configuration.name,
 	}, webhook_config)
 	if err != nil {
 		return err, webhook_config, webhook, 0
@@ -126,12 +197,47 @@ func fetchWebhookForNamespace(client crclient.Client, namespace string) (err err
 }
 
 func updateWebhook(client crclient.Client, cert, namespace string) (err error) {
+	for _, configuration := range webhookConfigurations {
+		err = updateWebhookConfiguration(client, cert, namespace, configuration)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err = updateConversionWebhook(client, cert, namespace); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// updateConversionWebhook points the conversion webhook of the CRD at the
+// operator in the namespace, trusting the CA of cert
+func updateConversionWebhook(client crclient.Client, cert, namespace string) error {
+	crd, clientConfig, present, err := fetchConversionWebhook(client)
+	if err != nil || !present {
+		return err
+	}
+	if err = unstructured.SetNestedField(clientConfig, namespace, "service", "namespace"); err != nil {
+		return err
+	}
+	if err = unstructured.SetNestedField(clientConfig, base64.StdEncoding.EncodeToString([]byte(cert)), "caBundle"); err != nil {
+		return err
+	}
+	if err = unstructured.SetNestedMap(crd.Object, clientConfig, "spec", "conversion", "webhookClientConfig"); err != nil {
+		return err
+	}
+	return client.Update(context.Background(), crd)
+}
+
+func updateWebhookConfiguration(client crclient.Client, cert, namespace string, configuration webhookConfiguration) (err error) {
 	var webhook_slice []interface{}
 	var webhook map[string]interface{}
 	var present bool
 	var webhook_index int
 	var webhook_config *unstructured.Unstructured
-	err, webhook_config, webhook, webhook_index = fetchWebhookForNamespace(client, namespace)
+	err, webhook_config, webhook, webhook_index = fetchWebhookForNamespace(client, namespace, configuration)
 	if err == nil {
 		if err = unstructured.SetNestedField(webhook, namespace, "clientConfig", "service", "namespace"); err == nil {
 			if err = unstructured.SetNestedField(webhook, base64.StdEncoding.EncodeToString([]byte(cert)), "clientConfig", "caBundle"); err == nil {
@@ -0,0 +1,80 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseTime(t *testing.T, value string) time.Time {
+	parsed, err := time.Parse(time.RFC3339, value)
+	assert.NoError(t, err)
+	return parsed
+}
+
+func TestSchedule_Next(t *testing.T) {
+	tests := []struct {
+		spec string
+		from string
+		want string
+	}{
+		{"0 2 * * *", "2020-07-01T01:59:00Z", "2020-07-01T02:00:00Z"},
+		{"0 2 * * *", "2020-07-01T02:00:00Z", "2020-07-02T02:00:00Z"},
+		{"*/15 * * * *", "2020-07-01T10:07:30Z", "2020-07-01T10:15:00Z"},
+		{"30 1 1 * *", "2020-12-15T00:00:00Z", "2021-01-01T01:30:00Z"},
+		{"0 0 * * sun", "2020-07-01T00:00:00Z", "2020-07-05T00:00:00Z"},
+		{"0 0 * * 7", "2020-07-01T00:00:00Z", "2020-07-05T00:00:00Z"},
+		{"0 0 * * 1-5", "2020-07-03T12:00:00Z", "2020-07-06T00:00:00Z"},
+		{"0 0 13 * 5", "2020-07-01T00:00:00Z", "2020-07-03T00:00:00Z"},
+		{"0 0 29 feb *", "2021-01-01T00:00:00Z", "2024-02-29T00:00:00Z"},
+		{"@hourly", "2020-07-01T10:07:00Z", "2020-07-01T11:00:00Z"},
+		{"@weekly", "2020-07-01T10:07:00Z", "2020-07-05T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := Parse(tt.spec)
+			assert.NoError(t, err)
+			assert.Equal(t, parseTime(t, tt.want), schedule.Next(parseTime(t, tt.from)))
+		})
+	}
+}
+
+func TestSchedule_NextNever(t *testing.T) {
+	schedule, err := Parse("0 0 30 2 *")
+	assert.NoError(t, err)
+	assert.True(t, schedule.Next(parseTime(t, "2020-07-01T00:00:00Z")).IsZero())
+}
+
+func TestSchedule_MostRecent(t *testing.T) {
+	schedule, err := Parse("0 2 * * *")
+	assert.NoError(t, err)
+
+	last := parseTime(t, "2020-07-01T02:00:00Z")
+	assert.True(t, schedule.MostRecent(last, parseTime(t, "2020-07-02T01:59:00Z")).IsZero())
+	assert.Equal(t, parseTime(t, "2020-07-02T02:00:00Z"),
+		schedule.MostRecent(last, parseTime(t, "2020-07-02T02:00:00Z")))
+	assert.Equal(t, parseTime(t, "2020-07-04T02:00:00Z"),
+		schedule.MostRecent(last, parseTime(t, "2020-07-04T12:00:00Z")), "should skip the missed times")
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"@reboot",
+	} {
+		_, err := Parse(spec)
+		assert.Error(t, err, "Should reject %q", spec)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package dccontext
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/events"
+	"github.com/datastax/cass-operator/operator/pkg/httphelper"
+)
+
+const (
+	// Values of the api.CassNodeState label, as set by the datacenter
+	// reconciler
+	NodeStateReadyToStart = "Ready-to-Start"
+	NodeStateStarted      = "Started"
+)
+
+// Context contains what the controllers of resources that act on the nodes
+// of a CassandraDatacenter need to reach them through the management API
+type Context struct {
+	Client         runtimeClient.Client
+	Scheme         *runtime.Scheme
+	Datacenter     *api.CassandraDatacenter
+	NodeMgmtClient httphelper.NodeMgmtClient
+	Recorder       record.EventRecorder
+	ReqLogger      logr.Logger
+
+	// See the reconciliation package for why the context is kept here
+	Ctx context.Context
+
+	// The pods of the datacenter, as of the last call to ListPods
+	Pods []*corev1.Pod
+}
+
+// New fetches the datacenter with the given name and its pods, and sets up a
+// client for the management API of those pods
+func New(
+	namespace string,
+	dcName string,
+	cli runtimeClient.Client,
+	scheme *runtime.Scheme,
+	rec record.EventRecorder,
+	reqLogger logr.Logger) (*Context, error) {
+
+	c := &Context{}
+	c.Client = cli
+	c.Scheme = scheme
+	c.Recorder = &events.LoggingEventRecorder{EventRecorder: rec, ReqLogger: reqLogger}
+	c.Ctx = context.Background()
+	c.ReqLogger = reqLogger.
+		WithValues("datacenterName", dcName)
+
+	dc := &api.CassandraDatacenter{}
+	err := c.Client.Get(c.Ctx, types.NamespacedName{Namespace: namespace, Name: dcName}, dc)
+	if err != nil {
+		return nil, err
+	}
+	c.Datacenter = dc
+
+	httpClient, err := httphelper.BuildManagementApiHttpClient(dc, cli, c.Ctx)
+	if err != nil {
+		c.ReqLogger.Error(err, "error in BuildManagementApiHttpClient")
+		return nil, err
+	}
+
+	protocol, err := httphelper.GetManagementApiProtocol(dc)
+	if err != nil {
+		c.ReqLogger.Error(err, "error in GetManagementApiProtocol")
+		return nil, err
+	}
+
+	c.NodeMgmtClient = httphelper.NodeMgmtClient{
+		Client:   httpClient,
+		Log:      c.ReqLogger,
+		Protocol: protocol,
+	}
+
+	if err := c.ListPods(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// ListPods refreshes the pods of the datacenter
+func (c *Context) ListPods() error {
+	podList := &corev1.PodList{}
+	listOptions := &runtimeClient.ListOptions{
+		Namespace:     c.Datacenter.Namespace,
+		LabelSelector: labels.SelectorFromSet(c.Datacenter.GetDatacenterLabels()),
+	}
+	if err := c.Client.List(c.Ctx, podList, listOptions); err != nil {
+		c.ReqLogger.Error(err, "error listing pods of datacenter")
+		return err
+	}
+
+	c.Pods = nil
+	for idx := range podList.Items {
+		c.Pods = append(c.Pods, &podList.Items[idx])
+	}
+	return nil
+}
+
+// StartedPods returns the pods of the datacenter whose Cassandra node is
+// started
+func (c *Context) StartedPods() []*corev1.Pod {
+	pods := []*corev1.Pod{}
+	for _, pod := range c.Pods {
+		if IsServerStarted(pod) {
+			pods = append(pods, pod)
+		}
+	}
+	return pods
+}
+
+// FindPodByName returns the pod with the given name, or nil if there is none
+func FindPodByName(pods []*corev1.Pod, name string) *corev1.Pod {
+	for _, pod := range pods {
+		if pod.Name == name {
+			return pod
+		}
+	}
+	return nil
+}
+
+func IsServerStarted(pod *corev1.Pod) bool {
+	return pod.Labels[api.CassNodeState] == NodeStateStarted
+}
+
+func IsServerReadyToStart(pod *corev1.Pod) bool {
+	return pod.Labels[api.CassNodeState] == NodeStateReadyToStart
+}
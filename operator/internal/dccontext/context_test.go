@@ -0,0 +1,45 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package dccontext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+)
+
+func newPod(name string, nodeState string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{api.CassNodeState: nodeState},
+		},
+	}
+}
+
+func TestContext_StartedPods(t *testing.T) {
+	c := &Context{Pods: []*corev1.Pod{
+		newPod("pod-0", NodeStateStarted),
+		newPod("pod-1", NodeStateReadyToStart),
+		newPod("pod-2", NodeStateStarted),
+	}}
+
+	started := c.StartedPods()
+	if assert.Equal(t, 2, len(started)) {
+		assert.Equal(t, "pod-0", started[0].Name)
+		assert.Equal(t, "pod-2", started[1].Name)
+	}
+	assert.True(t, IsServerReadyToStart(c.Pods[1]))
+}
+
+func TestFindPodByName(t *testing.T) {
+	pods := []*corev1.Pod{newPod("pod-0", NodeStateStarted), newPod("pod-1", NodeStateStarted)}
+
+	assert.Equal(t, pods[1], FindPodByName(pods, "pod-1"))
+	assert.Nil(t, FindPodByName(pods, "pod-2"))
+}
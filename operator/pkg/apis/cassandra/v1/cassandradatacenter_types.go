@@ -0,0 +1,745 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package v1
+
+import (
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SuperUserUpsertedAnnotation keeps the deprecated superUserUpserted
+	// status field of v1beta1, which v1 does not have, so that converting
+	// back to v1beta1 does not lose it
+	SuperUserUpsertedAnnotation = "cassandra.datastax.com/v1beta1-superuser-upserted"
+
+	// Progress states for status
+	ProgressUpdating ProgressState = "Updating"
+	ProgressReady    ProgressState = "Ready"
+)
+
+// This type exists so there's no chance of pushing random strings to our progress status
+type ProgressState string
+
+// RestartStrategy is how a rolling restart restarts each Cassandra node
+type RestartStrategy string
+
+const (
+	// RestartStrategyPodDelete drains the node and deletes its pod, so the
+	// pod is recreated from the StatefulSet
+	RestartStrategyPodDelete RestartStrategy = "podDelete"
+
+	// RestartStrategyProcessRestart drains the node and stops Cassandra
+	// through the management API, then starts it again in the same pod
+	RestartStrategyProcessRestart RestartStrategy = "processRestart"
+)
+
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the resources of the datacenter without
+	// removing its nodes from the cluster
+	DeletionPolicyDelete DeletionPolicy = "delete"
+
+	// DeletionPolicyDecommission removes the nodes of the datacenter from
+	// the cluster before its resources are deleted
+	DeletionPolicyDecommission DeletionPolicy = "decommission"
+)
+
+type CassandraUser struct {
+	SecretName string `json:"secretName"`
+	Superuser  bool   `json:"superuser"`
+}
+
+// CassandraDatacenterSpec defines the desired state of a CassandraDatacenter
+type CassandraDatacenterSpec struct {
+	// Desired number of Cassandra server nodes
+	// +kubebuilder:validation:Minimum=1
+	Size int32 `json:"size"`
+
+	// Version string for config builder,
+	// used to generate Cassandra server configuration. The supported
+	// versions are those of the version catalog of the operator.
+	ServerVersion string `json:"serverVersion"`
+
+	// Cassandra server image name.
+	// More info: https://kubernetes.io/docs/concepts/containers/images
+	ServerImage string `json:"serverImage,omitempty"`
+
+	// The registry to pull the images that the operator picks by default from, e.g.
+	// "registry.example.com:5000". It replaces the registry of the server, config builder,
+	// system logger, Reaper and Reaper schema init images, and takes precedence over the
+	// registry of the version catalog. Images set explicitly in the spec are used as is.
+	// +optional
+	ImageRegistry string `json:"imageRegistry,omitempty"`
+
+	// Secrets to pull the images of every pod that the operator creates for the datacenter,
+	// including the Reaper Deployment and the Reaper schema init Job. They are added to the
+	// image pull secrets of the PodTemplateSpec.
+	// More info: https://kubernetes.io/docs/concepts/containers/images#specifying-imagepullsecrets-on-a-pod
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// Server type: "cassandra" or "dse"
+	// +kubebuilder:validation:Enum=cassandra;dse
+	ServerType string `json:"serverType"`
+
+	// Config for the server, by configuration file
+	// +optional
+	Config *ServerConfig `json:"config,omitempty"`
+
+	// Config for the Management API certificates
+	ManagementApiAuth ManagementApiAuthConfig `json:"managementApiAuth,omitempty"`
+
+	// Encryption of the traffic between nodes and of CQL client connections
+	// +optional
+	TLS *TLSConfig `json:"tls,omitempty"`
+
+	// Kubernetes resource requests and limits, per pod
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// A list of the named racks in the datacenter, representing independent failure domains. The
+	// number of racks should match the replication factor in the keyspaces you plan to create, and
+	// the number of racks cannot easily be changed once a datacenter is deployed.
+	Racks []Rack `json:"racks,omitempty"`
+
+	// Describes the persistent storage of each server node
+	StorageConfig StorageConfig `json:"storageConfig"`
+
+	// A list of pod names that need to be replaced.
+	ReplaceNodes []string `json:"replaceNodes,omitempty"`
+
+	// The name by which CQL clients and instances will know the cluster. If the same
+	// cluster name is shared by multiple Datacenters in the same Kubernetes namespace,
+	// they will join together in a multi-datacenter cluster.
+	// +kubebuilder:validation:MinLength=2
+	ClusterName string `json:"clusterName"`
+
+	// A stopped CassandraDatacenter will have no running server pods, like using "stop" with
+	// traditional System V init scripts. Other Kubernetes resources will be left intact, and volumes
+	// will re-attach when the CassandraDatacenter workload is resumed.
+	Stopped bool `json:"stopped,omitempty"`
+
+	// What happens to the nodes of the datacenter when the CassandraDatacenter is deleted:
+	// "delete" deletes its resources, leaving the nodes listed as down in the other
+	// datacenters of the cluster. "decommission" first removes the datacenter from the
+	// system_auth, system_distributed and system_traces keyspaces, waits until no other
+	// keyspace is replicated to it, and removes each of its nodes from the cluster. Defaults
+	// to "delete".
+	// +kubebuilder:validation:Enum=delete;decommission
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// Container image for the config builder init container.
+	ConfigBuilderImage string `json:"configBuilderImage,omitempty"`
+
+	// Indicates that configuration and container image changes should only be pushed to
+	// the first rack of the datacenter
+	CanaryUpgrade bool `json:"canaryUpgrade,omitempty"`
+
+	// Turning this option on allows multiple server pods to be created on a k8s worker node.
+	// By default the operator creates just one server pod per k8s worker node using k8s
+	// podAntiAffinity and requiredDuringSchedulingIgnoredDuringExecution.
+	AllowMultipleNodesPerWorker bool `json:"allowMultipleNodesPerWorker,omitempty"`
+
+	// This secret defines the username and password for the Cassandra server superuser.
+	// If it is omitted, we will generate a secret instead.
+	SuperuserSecretName string `json:"superuserSecretName,omitempty"`
+
+	// The k8s service account to use for the server pods
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+
+	// Whether to do a rolling restart at the next opportunity. The operator will set this back
+	// to false once the restart is in progress.
+	RollingRestartRequested bool `json:"rollingRestartRequested,omitempty"`
+
+	// How nodes are restarted during a rolling restart: "podDelete" deletes each pod,
+	// "processRestart" stops and starts Cassandra in place through the management API,
+	// which skips rescheduling the pod and rerunning its init containers. Defaults to
+	// "podDelete".
+	// +kubebuilder:validation:Enum=podDelete;processRestart
+	// +optional
+	RestartStrategy RestartStrategy `json:"restartStrategy,omitempty"`
+
+	// The number of pods of a rack that may be restarted at the same time during rolling
+	// restarts and upgrades. Racks are still restarted one at a time, and only once every
+	// node of the other racks is started and the cluster is healthy. Defaults to 1, which
+	// restarts one pod of the datacenter at a time.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxUnavailablePerRack int32 `json:"maxUnavailablePerRack,omitempty"`
+
+	// A map of label keys and values to restrict Cassandra node scheduling to k8s workers
+	// with matchiing labels.
+	// More info: https://kubernetes.io/docs/concepts/configuration/assign-pod-node/#nodeselector
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Rack names in this list are set to the latest StatefulSet configuration
+	// even if Cassandra nodes are down. Use this to recover from an upgrade that couldn't
+	// roll out.
+	ForceUpgradeRacks []string `json:"forceUpgradeRacks,omitempty"`
+
+	DseWorkloads *DseWorkloads `json:"dseWorkloads,omitempty"`
+
+	// PodTemplate provides customisation options (labels, annotations, affinity rules, resource requests, and so on) for the cassandra pods
+	PodTemplateSpec *corev1.PodTemplateSpec `json:"podTemplateSpec,omitempty"`
+
+	// Cassandra users to bootstrap
+	Users []CassandraUser `json:"users,omitempty"`
+
+	AdditionalSeeds []string `json:"additionalSeeds,omitempty"`
+
+	Reaper *ReaperConfig `json:"reaper,omitempty"`
+
+	// Repairs that the operator runs on a schedule through the management API,
+	// one node or token range at a time. Unlike the Reaper sidecar this works
+	// for every server type.
+	// +optional
+	Repair *RepairConfig `json:"repair,omitempty"`
+}
+
+// ServerConfig holds the settings of the server by configuration file. The
+// settings of each file are passed on to the config builder unchanged.
+type ServerConfig struct {
+	// Settings of cassandra.yaml
+	// +optional
+	CassandraYaml json.RawMessage `json:"cassandraYaml,omitempty"`
+
+	// Settings of dse.yaml, with DSE
+	// +optional
+	DseYaml json.RawMessage `json:"dseYaml,omitempty"`
+
+	// Settings of jvm.options, with Cassandra 3.11 and DSE
+	// +optional
+	JvmOptions json.RawMessage `json:"jvmOptions,omitempty"`
+
+	// Settings of jvm-server.options, with Cassandra 4.0
+	// +optional
+	JvmServerOptions json.RawMessage `json:"jvmServerOptions,omitempty"`
+
+	// Settings of jvm8-server.options, with Cassandra 4.0 on Java 8
+	// +optional
+	Jvm8ServerOptions json.RawMessage `json:"jvm8ServerOptions,omitempty"`
+
+	// Settings of jvm11-server.options, with Cassandra 4.0 on Java 11
+	// +optional
+	Jvm11ServerOptions json.RawMessage `json:"jvm11ServerOptions,omitempty"`
+
+	// Settings of cassandra-env.sh
+	// +optional
+	CassandraEnvSh json.RawMessage `json:"cassandraEnvSh,omitempty"`
+
+	// Settings of the other files that the config builder generates, keyed by
+	// the config builder name of the file, e.g. "10-write-prom-conf"
+	// +optional
+	AdditionalSections map[string]json.RawMessage `json:"additionalSections,omitempty"`
+}
+
+type DseWorkloads struct {
+	AnalyticsEnabled bool `json:"analyticsEnabled,omitempty"`
+	GraphEnabled     bool `json:"graphEnabled,omitempty"`
+	SearchEnabled    bool `json:"searchEnabled,omitempty"`
+}
+
+type StorageConfig struct {
+	// The persistent volumes of each server node
+	Volumes StorageVolumes `json:"volumes"`
+
+	// What happens to the persistent volumes when the CassandraDatacenter is
+	// deleted
+	// +optional
+	Retention StorageRetention `json:"retention,omitempty"`
+}
+
+type StorageVolumes struct {
+	// The volume of the Cassandra data directory
+	Data *corev1.PersistentVolumeClaimSpec `json:"data,omitempty"`
+
+	// Optional volume for the commit log. The commit log is kept on the data
+	// volume when it is not set.
+	// +optional
+	CommitLog *corev1.PersistentVolumeClaimSpec `json:"commitLog,omitempty"`
+
+	// Optional volume for hints. Hints are kept on the data volume when it is
+	// not set.
+	// +optional
+	Hints *corev1.PersistentVolumeClaimSpec `json:"hints,omitempty"`
+
+	// Optional volume for saved caches. Saved caches are kept on the data
+	// volume when it is not set.
+	// +optional
+	SavedCaches *corev1.PersistentVolumeClaimSpec `json:"savedCaches,omitempty"`
+}
+
+type StorageRetention struct {
+	// "Delete" deletes the PersistentVolumeClaims, "Retain" removes the
+	// operator's labels from them and leaves them in place, and "Snapshot"
+	// takes a VolumeSnapshot of each one before deleting it. Defaults to
+	// "Delete".
+	// +kubebuilder:validation:Enum=Delete;Retain;Snapshot
+	// +optional
+	Policy StorageRetentionPolicy `json:"policy,omitempty"`
+
+	// The VolumeSnapshotClass of the snapshots taken with the "Snapshot"
+	// policy. The default class of the CSI driver is used when it is not set.
+	// +optional
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
+}
+
+type StorageRetentionPolicy string
+
+const (
+	StorageRetentionPolicyDelete   StorageRetentionPolicy = "Delete"
+	StorageRetentionPolicyRetain   StorageRetentionPolicy = "Retain"
+	StorageRetentionPolicySnapshot StorageRetentionPolicy = "Snapshot"
+)
+
+// Rack ...
+type Rack struct {
+	// The rack name
+	// +kubebuilder:validation:MinLength=2
+	Name string `json:"name"`
+	// Zone name to pin the rack, using node affinity
+	Zone string `json:"zone,omitempty"`
+}
+
+type CassandraNodeStatus struct {
+	HostID string `json:"hostID,omitempty"`
+
+	// When the node was last asked to be removed from the ring, while the
+	// datacenter is decommissioned
+	RemoveNodeRequested *metav1.Time `json:"removeNodeRequested,omitempty"`
+}
+
+type CassandraStatusMap map[string]CassandraNodeStatus
+
+type RackState string
+
+const (
+	RackRemoving RackState = "Removing"
+)
+
+type RackStatus struct {
+	// The rack name
+	Name string `json:"name"`
+	// The number of pods of the rack
+	// +optional
+	Nodes int32 `json:"nodes,omitempty"`
+	// The number of pods of the rack whose Cassandra node is ready
+	// +optional
+	ReadyNodes int32 `json:"readyNodes,omitempty"`
+	// What the operator is currently doing with the rack, empty when it is
+	// not working on the rack
+	// +optional
+	State RackState `json:"state,omitempty"`
+	// The number of nodes still in the rack while it is being removed
+	// +optional
+	RemainingNodes int32 `json:"remainingNodes,omitempty"`
+	// The pod that is currently being decommissioned
+	// +optional
+	DecommissioningPod string `json:"decommissioningPod,omitempty"`
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+type VolumeExpansionState string
+
+const (
+	VolumeExpansionResizing                VolumeExpansionState = "Resizing"
+	VolumeExpansionFileSystemResizePending VolumeExpansionState = "FileSystemResizePending"
+)
+
+type VolumeExpansionStatus struct {
+	// The pod whose server-data volume is being expanded
+	PodName string `json:"podName"`
+	// The size the volume is being expanded to
+	Size resource.Quantity `json:"size"`
+	// Whether the volume itself or its file system is being resized
+	State VolumeExpansionState `json:"state"`
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+type DatacenterConditionType string
+
+const (
+	DatacenterReady          DatacenterConditionType = "Ready"
+	DatacenterInitialized    DatacenterConditionType = "Initialized"
+	DatacenterReplacingNodes DatacenterConditionType = "ReplacingNodes"
+	DatacenterScalingUp      DatacenterConditionType = "ScalingUp"
+	DatacenterScalingDown    DatacenterConditionType = "ScalingDown"
+	DatacenterUpdating       DatacenterConditionType = "Updating"
+	DatacenterStopped        DatacenterConditionType = "Stopped"
+	DatacenterResuming       DatacenterConditionType = "Resuming"
+	DatacenterRollingRestart DatacenterConditionType = "RollingRestart"
+	// The volumes of a rack cannot be expanded to the storage request of
+	// cassandraDataVolumeClaimSpec, as their StorageClass does not allow it
+	DatacenterVolumeExpansionBlocked DatacenterConditionType = "VolumeExpansionBlocked"
+)
+
+type DatacenterCondition struct {
+	Type               DatacenterConditionType `json:"type"`
+	Status             corev1.ConditionStatus  `json:"status"`
+	LastTransitionTime metav1.Time             `json:"lastTransitionTime,omitempty"`
+}
+
+// CassandraDatacenterStatus defines the observed state of CassandraDatacenter
+type CassandraDatacenterStatus struct {
+	Conditions []DatacenterCondition `json:"conditions,omitempty"`
+
+	// The timestamp at which managed cassandra users' credentials
+	// were last upserted to the management API
+	// +optional
+	UsersUpserted metav1.Time `json:"usersUpserted,omitempty"`
+
+	// The timestamp when the operator last started a Server node
+	// with the management API
+	// +optional
+	LastServerNodeStarted metav1.Time `json:"lastServerNodeStarted,omitempty"`
+
+	// Last known progress state of the Cassandra Operator
+	// +optional
+	CassandraOperatorProgress ProgressState `json:"cassandraOperatorProgress,omitempty"`
+
+	// +optional
+	LastRollingRestart metav1.Time `json:"lastRollingRestart,omitempty"`
+
+	// +optional
+	NodeStatuses CassandraStatusMap `json:"nodeStatuses,omitempty"`
+
+	// +optional
+	NodeReplacements []string `json:"nodeReplacements,omitempty"`
+
+	// The nodes of each rack, and what the operator is doing with the racks
+	// it is working on, such as racks that are being removed from the
+	// datacenter
+	// +optional
+	Racks []RackStatus `json:"racks,omitempty"`
+
+	// The replication factor in this datacenter that the system_auth,
+	// system_distributed and system_traces keyspaces were last altered to
+	// +optional
+	SystemKeyspacesReplicationFactor int32 `json:"systemKeyspacesReplicationFactor,omitempty"`
+
+	// The progress of the repair of system_auth that follows altering the
+	// replication of the system keyspaces. The replication factor above is
+	// only recorded once it is done.
+	// +optional
+	SystemKeyspacesRepair *SystemKeyspacesRepairStatus `json:"systemKeyspacesRepair,omitempty"`
+
+	// The volumes that are being expanded after the storage request of the
+	// data volume was increased
+	// +optional
+	VolumeExpansions []VolumeExpansionStatus `json:"volumeExpansions,omitempty"`
+
+	// The progress of the scheduled repairs of spec.repair
+	// +optional
+	Repair *RepairStatus `json:"repair,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CassandraDatacenter is the Schema for the cassandradatacenters API
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=cassandradatacenters,scope=Namespaced,shortName=cassdc;cassdcs
+type CassandraDatacenter struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CassandraDatacenterSpec   `json:"spec,omitempty"`
+	Status CassandraDatacenterStatus `json:"status,omitempty"`
+}
+
+type ManagementApiAuthManualConfig struct {
+	ClientSecretName string `json:"clientSecretName"`
+	ServerSecretName string `json:"serverSecretName"`
+	// +optional
+	SkipSecretValidation bool `json:"skipSecretValidation,omitempty"`
+}
+
+type ManagementApiAuthInsecureConfig struct {
+}
+
+// CertManagerIssuerReference points to a cert-manager Issuer or ClusterIssuer
+type CertManagerIssuerReference struct {
+	Name string `json:"name"`
+	// Defaults to Issuer
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	// +optional
+	Kind string `json:"kind,omitempty"`
+}
+
+// ManagementApiAuthCertManagerConfig has cert-manager issue the server and
+// client certificates of the Management API
+type ManagementApiAuthCertManagerConfig struct {
+	// The issuer of both certificates. It must put its CA certificate in
+	// the issued secrets, like the CA and Vault issuers do, as each side
+	// verifies the other with it. When empty, the operator creates a
+	// self-signed CA for the datacenter.
+	// +optional
+	IssuerRef *CertManagerIssuerReference `json:"issuerRef,omitempty"`
+	// How long the certificates are valid for, e.g. "2160h"
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// How long before they expire the certificates are renewed, e.g. "360h"
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
+// ManagementApiAuthAutoConfig has the operator generate a CA and the server
+// and client certificates of the Management API, and renew them
+type ManagementApiAuthAutoConfig struct {
+	// How long the server and client certificates are valid for. Defaults to
+	// "8760h", one year.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// How long before they expire the certificates are renewed. Defaults to
+	// "720h", 30 days.
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
+type ManagementApiAuthConfig struct {
+	Insecure    *ManagementApiAuthInsecureConfig    `json:"insecure,omitempty"`
+	Manual      *ManagementApiAuthManualConfig      `json:"manual,omitempty"`
+	CertManager *ManagementApiAuthCertManagerConfig `json:"certManager,omitempty"`
+	Auto        *ManagementApiAuthAutoConfig        `json:"auto,omitempty"`
+	// other strategy configs go here
+}
+
+// TLSConfig encrypts the traffic between nodes, and optionally CQL client
+// connections, with the keystore and truststore of a secret
+type TLSConfig struct {
+	// Name of the secret that holds the keystore and the truststore, in the
+	// keys "keystore.jks" and "truststore.jks", like the JKS keystores that
+	// cert-manager creates, and their passwords, in the keys
+	// "keystore-password" and "truststore-password". The passwords default
+	// to "cassandra" when their key is missing. The secret must exist in the
+	// namespace of the datacenter.
+	KeystoreSecretName string `json:"keystoreSecretName"`
+
+	// Which traffic between nodes is encrypted: "all", "dc" for traffic
+	// between datacenters, "rack" for traffic between racks, or "none".
+	// Defaults to "all".
+	// +kubebuilder:validation:Enum=all;dc;rack;none
+	// +optional
+	InternodeEncryption string `json:"internodeEncryption,omitempty"`
+
+	// Whether nodes must present a certificate trusted by the truststore to
+	// other nodes
+	// +optional
+	RequireInternodeClientAuth bool `json:"requireInternodeClientAuth,omitempty"`
+
+	// Encryption of CQL client connections
+	// +optional
+	ClientEncryption *TLSClientEncryptionConfig `json:"clientEncryption,omitempty"`
+}
+
+type TLSClientEncryptionConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Accept unencrypted connections as well, e.g. while clients are moved
+	// to TLS
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+
+	// Whether clients must present a certificate trusted by the truststore
+	// +optional
+	RequireClientAuth bool `json:"requireClientAuth,omitempty"`
+}
+
+// ReaperDeploymentMode is how Reaper is run for a datacenter
+type ReaperDeploymentMode string
+
+const (
+	// ReaperDeploymentModeSidecar runs Reaper as a sidecar container in each
+	// Cassandra pod
+	ReaperDeploymentModeSidecar ReaperDeploymentMode = "Sidecar"
+
+	// ReaperDeploymentModeDeployment runs a single Reaper instance per
+	// datacenter in its own Deployment, connecting to the nodes through
+	// remote JMX
+	ReaperDeploymentModeDeployment ReaperDeploymentMode = "Deployment"
+)
+
+type ReaperConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+
+	Image string `json:"image,omitempty"`
+
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// Whether Reaper runs as a sidecar in each Cassandra pod or as a single
+	// Deployment for the datacenter. Defaults to Sidecar.
+	// +kubebuilder:validation:Enum=Sidecar;Deployment
+	// +optional
+	DeploymentMode ReaperDeploymentMode `json:"deploymentMode,omitempty"`
+
+	// The name of a Secret in the namespace of the datacenter with "username"
+	// and "password" keys, used to authenticate to the Reaper UI and REST API.
+	// Authentication is disabled when this is not set.
+	// +optional
+	UISecretName string `json:"uiSecretName,omitempty"`
+
+	// The name of a Secret in the namespace of the datacenter with "username"
+	// and "password" keys, used by Reaper to authenticate to JMX. Required
+	// when deploymentMode is Deployment, as the nodes then open JMX to the
+	// network.
+	// +optional
+	JmxSecretName string `json:"jmxSecretName,omitempty"`
+
+	// Repair schedules that the operator creates in Reaper once the cluster is
+	// registered with it. Schedules created by the operator that are no longer
+	// listed here are deleted, while the ones created through the Reaper UI
+	// are left alone.
+	// +optional
+	Schedules []ReaperRepairSchedule `json:"schedules,omitempty"`
+}
+
+type ReaperRepairSchedule struct {
+	// The keyspace to repair
+	// +kubebuilder:validation:MinLength=1
+	Keyspace string `json:"keyspace"`
+
+	// The tables of the keyspace to repair. Every table is repaired when it
+	// is empty.
+	// +optional
+	Tables []string `json:"tables,omitempty"`
+
+	// The share of the time that Reaper spends repairing, between "0.0"
+	// exclusive and "1.0". The Reaper default is used when it is not set.
+	// +kubebuilder:validation:Pattern=`^(0?\.[0-9]*[1-9][0-9]*|1(\.0*)?)$`
+	// +optional
+	Intensity string `json:"intensity,omitempty"`
+
+	// How the replicas of a token range are repaired. The Reaper default is
+	// used when it is not set.
+	// +kubebuilder:validation:Enum=Sequential;Parallel;DatacenterAware
+	// +optional
+	Parallelism RepairParallelism `json:"parallelism,omitempty"`
+
+	// The number of days between two repairs of the keyspace. Defaults to 7.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	IntervalDays int32 `json:"intervalDays,omitempty"`
+}
+
+// RepairType is how much of a node's token ranges a repair session covers
+type RepairType string
+
+const (
+	// RepairTypeFull repairs all of the token ranges of a node in one session
+	RepairTypeFull RepairType = "Full"
+
+	// RepairTypeSubrange repairs each token range of a node in a session of
+	// its own, which keeps the sessions short
+	RepairTypeSubrange RepairType = "Subrange"
+)
+
+// RepairParallelism is how the replicas of a token range are repaired
+type RepairParallelism string
+
+const (
+	RepairParallelismSequential      RepairParallelism = "Sequential"
+	RepairParallelismParallel        RepairParallelism = "Parallel"
+	RepairParallelismDatacenterAware RepairParallelism = "DatacenterAware"
+)
+
+type RepairConfig struct {
+	// The keyspaces to repair. Every keyspace except the node local system
+	// keyspaces is repaired when it is empty.
+	// +optional
+	Keyspaces []string `json:"keyspaces,omitempty"`
+
+	// When to repair, in cron format, e.g. "0 2 * * 0". Times are in UTC.
+	Schedule string `json:"schedule"`
+
+	// Whether a node repairs all of its token ranges at once ("Full") or one
+	// token range at a time ("Subrange"). Defaults to "Full".
+	// +kubebuilder:validation:Enum=Full;Subrange
+	// +optional
+	Type RepairType `json:"type,omitempty"`
+
+	// How the replicas of a token range are repaired. The server default is
+	// used when it is not set.
+	// +kubebuilder:validation:Enum=Sequential;Parallel;DatacenterAware
+	// +optional
+	Parallelism RepairParallelism `json:"parallelism,omitempty"`
+}
+
+// SystemKeyspacesRepairStatus is the progress of the repair of system_auth
+// on every node, after the replication of the system keyspaces was altered
+type SystemKeyspacesRepairStatus struct {
+	// The replication factor the system keyspaces were altered to
+	ReplicationFactor int32 `json:"replicationFactor"`
+
+	// The pods whose node has been repaired
+	// +optional
+	RepairedPods []string `json:"repairedPods,omitempty"`
+
+	// The pod whose node is being repaired
+	// +optional
+	PodName string `json:"podName,omitempty"`
+
+	// The management API job of the repair in progress
+	// +optional
+	JobID string `json:"jobId,omitempty"`
+}
+
+type KeyspaceRepairStatus struct {
+	// The keyspace name
+	Name string `json:"name"`
+	// When the keyspace was last repaired on every node of the datacenter
+	// without a failed session
+	LastCompleted metav1.Time `json:"lastCompleted"`
+}
+
+type RepairStatus struct {
+	// The scheduled time of the repair in progress, or of the last one
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// The keyspaces left to repair, starting with the one being repaired. It is
+	// empty when no repair is in progress.
+	// +optional
+	PendingKeyspaces []string `json:"pendingKeyspaces,omitempty"`
+
+	// The pod whose token ranges are being repaired
+	// +optional
+	PodName string `json:"podName,omitempty"`
+
+	// The position of the token range being repaired among the token ranges of
+	// the pod, with the "Subrange" type
+	// +optional
+	RangeIndex int32 `json:"rangeIndex,omitempty"`
+
+	// The management API job of the repair session in progress
+	// +optional
+	JobID string `json:"jobId,omitempty"`
+
+	// The sessions that failed while repairing the current keyspace
+	// +optional
+	FailedSessions int32 `json:"failedSessions,omitempty"`
+
+	// The last completed repair of each keyspace
+	// +optional
+	Keyspaces []KeyspaceRepairStatus `json:"keyspaces,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CassandraDatacenterList contains a list of CassandraDatacenter
+type CassandraDatacenterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CassandraDatacenter `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CassandraDatacenter{}, &CassandraDatacenterList{})
+}
@@ -0,0 +1,511 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+)
+
+// The config builder sections of the v1beta1 config that have a field of
+// their own in ServerConfig
+const (
+	cassandraYamlSection      = "cassandra-yaml"
+	dseYamlSection            = "dse-yaml"
+	jvmOptionsSection         = "jvm-options"
+	jvmServerOptionsSection   = "jvm-server-options"
+	jvm8ServerOptionsSection  = "jvm8-server-options"
+	jvm11ServerOptionsSection = "jvm11-server-options"
+	cassandraEnvShSection     = "cassandra-env-sh"
+)
+
+var _ conversion.Convertible = &CassandraDatacenter{}
+
+// ConvertTo converts this CassandraDatacenter to the v1beta1 hub version
+func (dc *CassandraDatacenter) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1beta1.CassandraDatacenter)
+	if !ok {
+		return fmt.Errorf("cannot convert CassandraDatacenter to %T", dstRaw)
+	}
+
+	dst.ObjectMeta = *dc.ObjectMeta.DeepCopy()
+	if err := convertSpecTo(&dc.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	convertStatusTo(&dc.Status, &dst.Status)
+
+	if value, found := dst.Annotations[SuperUserUpsertedAnnotation]; found {
+		if err := dst.Status.SuperUserUpserted.UnmarshalQueryParameter(value); err != nil {
+			return fmt.Errorf("invalid %s annotation: %s", SuperUserUpsertedAnnotation, err.Error())
+		}
+		delete(dst.Annotations, SuperUserUpsertedAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+
+	return nil
+}
+
+// ConvertFrom converts the v1beta1 hub version to this CassandraDatacenter
+func (dc *CassandraDatacenter) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1beta1.CassandraDatacenter)
+	if !ok {
+		return fmt.Errorf("cannot convert %T to CassandraDatacenter", srcRaw)
+	}
+
+	dc.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	if err := convertSpecFrom(&src.Spec, &dc.Spec); err != nil {
+		return err
+	}
+	convertStatusFrom(&src.Status, &dc.Status)
+
+	if !src.Status.SuperUserUpserted.IsZero() {
+		value, err := src.Status.SuperUserUpserted.MarshalQueryParameter()
+		if err != nil {
+			return err
+		}
+		if dc.Annotations == nil {
+			dc.Annotations = map[string]string{}
+		}
+		dc.Annotations[SuperUserUpsertedAnnotation] = value
+	}
+
+	return nil
+}
+
+func convertSpecTo(src *CassandraDatacenterSpec, dst *v1beta1.CassandraDatacenterSpec) error {
+	config, err := convertConfigTo(src.Config)
+	if err != nil {
+		return err
+	}
+
+	dst.Size = src.Size
+	dst.ServerVersion = src.ServerVersion
+	dst.ServerImage = src.ServerImage
+	dst.ImageRegistry = src.ImageRegistry
+	dst.ImagePullSecrets = src.ImagePullSecrets
+	dst.ServerType = src.ServerType
+	dst.Config = config
+	dst.ManagementApiAuth = convertManagementApiAuthTo(src.ManagementApiAuth)
+	dst.TLS = convertTLSTo(src.TLS)
+	dst.Resources = src.Resources
+	dst.Racks = nil
+	for _, rack := range src.Racks {
+		dst.Racks = append(dst.Racks, v1beta1.Rack(rack))
+	}
+	dst.StorageConfig = v1beta1.StorageConfig{
+		CassandraDataVolumeClaimSpec: src.StorageConfig.Volumes.Data,
+		CommitLogVolumeClaimSpec:     src.StorageConfig.Volumes.CommitLog,
+		HintsVolumeClaimSpec:         src.StorageConfig.Volumes.Hints,
+		SavedCachesVolumeClaimSpec:   src.StorageConfig.Volumes.SavedCaches,
+		RetentionPolicy:              v1beta1.StorageRetentionPolicy(src.StorageConfig.Retention.Policy),
+		VolumeSnapshotClassName:      src.StorageConfig.Retention.VolumeSnapshotClassName,
+	}
+	dst.ReplaceNodes = src.ReplaceNodes
+	dst.ClusterName = src.ClusterName
+	dst.Stopped = src.Stopped
+	dst.DeletionPolicy = v1beta1.DeletionPolicy(src.DeletionPolicy)
+	dst.ConfigBuilderImage = src.ConfigBuilderImage
+	dst.CanaryUpgrade = src.CanaryUpgrade
+	dst.AllowMultipleNodesPerWorker = src.AllowMultipleNodesPerWorker
+	dst.SuperuserSecretName = src.SuperuserSecretName
+	dst.ServiceAccount = src.ServiceAccount
+	dst.RollingRestartRequested = src.RollingRestartRequested
+	dst.RestartStrategy = v1beta1.RestartStrategy(src.RestartStrategy)
+	dst.MaxUnavailablePerRack = src.MaxUnavailablePerRack
+	dst.NodeSelector = src.NodeSelector
+	dst.ForceUpgradeRacks = src.ForceUpgradeRacks
+	dst.DseWorkloads = (*v1beta1.DseWorkloads)(src.DseWorkloads)
+	dst.PodTemplateSpec = src.PodTemplateSpec
+	dst.Users = nil
+	for _, user := range src.Users {
+		dst.Users = append(dst.Users, v1beta1.CassandraUser(user))
+	}
+	dst.AdditionalSeeds = src.AdditionalSeeds
+	dst.Reaper = convertReaperTo(src.Reaper)
+	if src.Repair != nil {
+		dst.Repair = &v1beta1.RepairConfig{
+			Keyspaces:   src.Repair.Keyspaces,
+			Schedule:    src.Repair.Schedule,
+			Type:        v1beta1.RepairType(src.Repair.Type),
+			Parallelism: v1beta1.RepairParallelism(src.Repair.Parallelism),
+		}
+	} else {
+		dst.Repair = nil
+	}
+
+	return nil
+}
+
+func convertSpecFrom(src *v1beta1.CassandraDatacenterSpec, dst *CassandraDatacenterSpec) error {
+	config, err := convertConfigFrom(src.Config)
+	if err != nil {
+		return err
+	}
+
+	dst.Size = src.Size
+	dst.ServerVersion = src.ServerVersion
+	dst.ServerImage = src.ServerImage
+	dst.ImageRegistry = src.ImageRegistry
+	dst.ImagePullSecrets = src.ImagePullSecrets
+	dst.ServerType = src.ServerType
+	dst.Config = config
+	dst.ManagementApiAuth = convertManagementApiAuthFrom(src.ManagementApiAuth)
+	dst.TLS = convertTLSFrom(src.TLS)
+	dst.Resources = src.Resources
+	dst.Racks = nil
+	for _, rack := range src.Racks {
+		dst.Racks = append(dst.Racks, Rack(rack))
+	}
+	dst.StorageConfig = StorageConfig{
+		Volumes: StorageVolumes{
+			Data:        src.StorageConfig.CassandraDataVolumeClaimSpec,
+			CommitLog:   src.StorageConfig.CommitLogVolumeClaimSpec,
+			Hints:       src.StorageConfig.HintsVolumeClaimSpec,
+			SavedCaches: src.StorageConfig.SavedCachesVolumeClaimSpec,
+		},
+		Retention: StorageRetention{
+			Policy:                  StorageRetentionPolicy(src.StorageConfig.RetentionPolicy),
+			VolumeSnapshotClassName: src.StorageConfig.VolumeSnapshotClassName,
+		},
+	}
+	dst.ReplaceNodes = src.ReplaceNodes
+	dst.ClusterName = src.ClusterName
+	dst.Stopped = src.Stopped
+	dst.DeletionPolicy = DeletionPolicy(src.DeletionPolicy)
+	dst.ConfigBuilderImage = src.ConfigBuilderImage
+	dst.CanaryUpgrade = src.CanaryUpgrade
+	dst.AllowMultipleNodesPerWorker = src.AllowMultipleNodesPerWorker
+	dst.SuperuserSecretName = src.SuperuserSecretName
+	dst.ServiceAccount = src.ServiceAccount
+	dst.RollingRestartRequested = src.RollingRestartRequested
+	dst.RestartStrategy = RestartStrategy(src.RestartStrategy)
+	dst.MaxUnavailablePerRack = src.MaxUnavailablePerRack
+	dst.NodeSelector = src.NodeSelector
+	dst.ForceUpgradeRacks = src.ForceUpgradeRacks
+	dst.DseWorkloads = (*DseWorkloads)(src.DseWorkloads)
+	dst.PodTemplateSpec = src.PodTemplateSpec
+	dst.Users = nil
+	for _, user := range src.Users {
+		dst.Users = append(dst.Users, CassandraUser(user))
+	}
+	dst.AdditionalSeeds = src.AdditionalSeeds
+	dst.Reaper = convertReaperFrom(src.Reaper)
+	if src.Repair != nil {
+		dst.Repair = &RepairConfig{
+			Keyspaces:   src.Repair.Keyspaces,
+			Schedule:    src.Repair.Schedule,
+			Type:        RepairType(src.Repair.Type),
+			Parallelism: RepairParallelism(src.Repair.Parallelism),
+		}
+	} else {
+		dst.Repair = nil
+	}
+
+	return nil
+}
+
+// configSections returns the fields of the config that hold a config builder
+// section, keyed by the name of the section
+func (config *ServerConfig) configSections() map[string]*json.RawMessage {
+	return map[string]*json.RawMessage{
+		cassandraYamlSection:      &config.CassandraYaml,
+		dseYamlSection:            &config.DseYaml,
+		jvmOptionsSection:         &config.JvmOptions,
+		jvmServerOptionsSection:   &config.JvmServerOptions,
+		jvm8ServerOptionsSection:  &config.Jvm8ServerOptions,
+		jvm11ServerOptionsSection: &config.Jvm11ServerOptions,
+		cassandraEnvShSection:     &config.CassandraEnvSh,
+	}
+}
+
+// convertConfigTo merges the sections of the config into the single JSON
+// object of the v1beta1 config
+func convertConfigTo(config *ServerConfig) (json.RawMessage, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	sections := map[string]json.RawMessage{}
+	for name, value := range config.configSections() {
+		if len(*value) > 0 {
+			sections[name] = *value
+		}
+	}
+	for name, value := range config.AdditionalSections {
+		if _, found := sections[name]; found {
+			return nil, fmt.Errorf("spec.config.additionalSections.%s is already set by another field of spec.config", name)
+		}
+		sections[name] = value
+	}
+
+	return json.Marshal(sections)
+}
+
+// convertConfigFrom splits the JSON object of the v1beta1 config into its
+// sections
+func convertConfigFrom(config json.RawMessage) (*ServerConfig, error) {
+	if len(config) == 0 {
+		return nil, nil
+	}
+
+	sections := map[string]json.RawMessage{}
+	if err := json.Unmarshal(config, &sections); err != nil {
+		return nil, fmt.Errorf("spec.config must be a JSON object: %s", err.Error())
+	}
+
+	serverConfig := &ServerConfig{}
+	fields := serverConfig.configSections()
+	for name, value := range sections {
+		if field, found := fields[name]; found {
+			*field = value
+			continue
+		}
+		if serverConfig.AdditionalSections == nil {
+			serverConfig.AdditionalSections = map[string]json.RawMessage{}
+		}
+		serverConfig.AdditionalSections[name] = value
+	}
+
+	return serverConfig, nil
+}
+
+func convertManagementApiAuthTo(src ManagementApiAuthConfig) v1beta1.ManagementApiAuthConfig {
+	dst := v1beta1.ManagementApiAuthConfig{
+		Insecure: (*v1beta1.ManagementApiAuthInsecureConfig)(src.Insecure),
+		Manual:   (*v1beta1.ManagementApiAuthManualConfig)(src.Manual),
+		Auto:     (*v1beta1.ManagementApiAuthAutoConfig)(src.Auto),
+	}
+	if src.CertManager != nil {
+		dst.CertManager = &v1beta1.ManagementApiAuthCertManagerConfig{
+			IssuerRef:   (*v1beta1.CertManagerIssuerReference)(src.CertManager.IssuerRef),
+			Duration:    src.CertManager.Duration,
+			RenewBefore: src.CertManager.RenewBefore,
+		}
+	}
+	return dst
+}
+
+func convertManagementApiAuthFrom(src v1beta1.ManagementApiAuthConfig) ManagementApiAuthConfig {
+	dst := ManagementApiAuthConfig{
+		Insecure: (*ManagementApiAuthInsecureConfig)(src.Insecure),
+		Manual:   (*ManagementApiAuthManualConfig)(src.Manual),
+		Auto:     (*ManagementApiAuthAutoConfig)(src.Auto),
+	}
+	if src.CertManager != nil {
+		dst.CertManager = &ManagementApiAuthCertManagerConfig{
+			IssuerRef:   (*CertManagerIssuerReference)(src.CertManager.IssuerRef),
+			Duration:    src.CertManager.Duration,
+			RenewBefore: src.CertManager.RenewBefore,
+		}
+	}
+	return dst
+}
+
+func convertTLSTo(src *TLSConfig) *v1beta1.TLSConfig {
+	if src == nil {
+		return nil
+	}
+	return &v1beta1.TLSConfig{
+		KeystoreSecretName:         src.KeystoreSecretName,
+		InternodeEncryption:        src.InternodeEncryption,
+		RequireInternodeClientAuth: src.RequireInternodeClientAuth,
+		ClientEncryption:           (*v1beta1.TLSClientEncryptionConfig)(src.ClientEncryption),
+	}
+}
+
+func convertTLSFrom(src *v1beta1.TLSConfig) *TLSConfig {
+	if src == nil {
+		return nil
+	}
+	return &TLSConfig{
+		KeystoreSecretName:         src.KeystoreSecretName,
+		InternodeEncryption:        src.InternodeEncryption,
+		RequireInternodeClientAuth: src.RequireInternodeClientAuth,
+		ClientEncryption:           (*TLSClientEncryptionConfig)(src.ClientEncryption),
+	}
+}
+
+func convertReaperTo(src *ReaperConfig) *v1beta1.ReaperConfig {
+	if src == nil {
+		return nil
+	}
+	dst := &v1beta1.ReaperConfig{
+		Enabled:         src.Enabled,
+		Image:           src.Image,
+		ImagePullPolicy: src.ImagePullPolicy,
+		DeploymentMode:  v1beta1.ReaperDeploymentMode(src.DeploymentMode),
+		UISecretName:    src.UISecretName,
+		JmxSecretName:   src.JmxSecretName,
+	}
+	for _, schedule := range src.Schedules {
+		dst.Schedules = append(dst.Schedules, v1beta1.ReaperRepairSchedule{
+			Keyspace:     schedule.Keyspace,
+			Tables:       schedule.Tables,
+			Intensity:    schedule.Intensity,
+			Parallelism:  v1beta1.RepairParallelism(schedule.Parallelism),
+			IntervalDays: schedule.IntervalDays,
+		})
+	}
+	return dst
+}
+
+func convertReaperFrom(src *v1beta1.ReaperConfig) *ReaperConfig {
+	if src == nil {
+		return nil
+	}
+	dst := &ReaperConfig{
+		Enabled:         src.Enabled,
+		Image:           src.Image,
+		ImagePullPolicy: src.ImagePullPolicy,
+		DeploymentMode:  ReaperDeploymentMode(src.DeploymentMode),
+		UISecretName:    src.UISecretName,
+		JmxSecretName:   src.JmxSecretName,
+	}
+	for _, schedule := range src.Schedules {
+		dst.Schedules = append(dst.Schedules, ReaperRepairSchedule{
+			Keyspace:     schedule.Keyspace,
+			Tables:       schedule.Tables,
+			Intensity:    schedule.Intensity,
+			Parallelism:  RepairParallelism(schedule.Parallelism),
+			IntervalDays: schedule.IntervalDays,
+		})
+	}
+	return dst
+}
+
+func convertStatusTo(src *CassandraDatacenterStatus, dst *v1beta1.CassandraDatacenterStatus) {
+	dst.Conditions = nil
+	for _, condition := range src.Conditions {
+		dst.Conditions = append(dst.Conditions, v1beta1.DatacenterCondition{
+			Type:               v1beta1.DatacenterConditionType(condition.Type),
+			Status:             condition.Status,
+			LastTransitionTime: condition.LastTransitionTime,
+		})
+	}
+	dst.SuperUserUpserted = metav1.Time{}
+	dst.UsersUpserted = src.UsersUpserted
+	dst.LastServerNodeStarted = src.LastServerNodeStarted
+	dst.CassandraOperatorProgress = v1beta1.ProgressState(src.CassandraOperatorProgress)
+	dst.LastRollingRestart = src.LastRollingRestart
+	dst.NodeStatuses = nil
+	if src.NodeStatuses != nil {
+		dst.NodeStatuses = v1beta1.CassandraStatusMap{}
+		for podName, nodeStatus := range src.NodeStatuses {
+			dst.NodeStatuses[podName] = v1beta1.CassandraNodeStatus(nodeStatus)
+		}
+	}
+	dst.NodeReplacements = src.NodeReplacements
+	dst.RackStatuses = nil
+	for _, rackStatus := range src.Racks {
+		dst.RackStatuses = append(dst.RackStatuses, v1beta1.RackStatus{
+			Name:               rackStatus.Name,
+			Nodes:              rackStatus.Nodes,
+			ReadyNodes:         rackStatus.ReadyNodes,
+			State:              v1beta1.RackState(rackStatus.State),
+			RemainingNodes:     rackStatus.RemainingNodes,
+			DecommissioningPod: rackStatus.DecommissioningPod,
+			LastTransitionTime: rackStatus.LastTransitionTime,
+		})
+	}
+	dst.SystemKeyspacesReplicationFactor = src.SystemKeyspacesReplicationFactor
+	dst.SystemKeyspacesRepair = nil
+	if src.SystemKeyspacesRepair != nil {
+		systemKeyspacesRepair := v1beta1.SystemKeyspacesRepairStatus(*src.SystemKeyspacesRepair)
+		dst.SystemKeyspacesRepair = &systemKeyspacesRepair
+	}
+	dst.VolumeExpansions = nil
+	for _, expansion := range src.VolumeExpansions {
+		dst.VolumeExpansions = append(dst.VolumeExpansions, v1beta1.VolumeExpansionStatus{
+			PodName:            expansion.PodName,
+			Size:               expansion.Size,
+			State:              v1beta1.VolumeExpansionState(expansion.State),
+			LastTransitionTime: expansion.LastTransitionTime,
+		})
+	}
+	dst.Repair = nil
+	if src.Repair != nil {
+		dst.Repair = &v1beta1.RepairStatus{
+			LastScheduleTime: src.Repair.LastScheduleTime,
+			PendingKeyspaces: src.Repair.PendingKeyspaces,
+			PodName:          src.Repair.PodName,
+			RangeIndex:       src.Repair.RangeIndex,
+			JobID:            src.Repair.JobID,
+			FailedSessions:   src.Repair.FailedSessions,
+		}
+		for _, keyspace := range src.Repair.Keyspaces {
+			dst.Repair.Keyspaces = append(dst.Repair.Keyspaces, v1beta1.KeyspaceRepairStatus(keyspace))
+		}
+	}
+}
+
+func convertStatusFrom(src *v1beta1.CassandraDatacenterStatus, dst *CassandraDatacenterStatus) {
+	dst.Conditions = nil
+	for _, condition := range src.Conditions {
+		dst.Conditions = append(dst.Conditions, DatacenterCondition{
+			Type:               DatacenterConditionType(condition.Type),
+			Status:             condition.Status,
+			LastTransitionTime: condition.LastTransitionTime,
+		})
+	}
+	dst.UsersUpserted = src.UsersUpserted
+	dst.LastServerNodeStarted = src.LastServerNodeStarted
+	dst.CassandraOperatorProgress = ProgressState(src.CassandraOperatorProgress)
+	dst.LastRollingRestart = src.LastRollingRestart
+	dst.NodeStatuses = nil
+	if src.NodeStatuses != nil {
+		dst.NodeStatuses = CassandraStatusMap{}
+		for podName, nodeStatus := range src.NodeStatuses {
+			dst.NodeStatuses[podName] = CassandraNodeStatus(nodeStatus)
+		}
+	}
+	dst.NodeReplacements = src.NodeReplacements
+	dst.Racks = nil
+	for _, rackStatus := range src.RackStatuses {
+		dst.Racks = append(dst.Racks, RackStatus{
+			Name:               rackStatus.Name,
+			Nodes:              rackStatus.Nodes,
+			ReadyNodes:         rackStatus.ReadyNodes,
+			State:              RackState(rackStatus.State),
+			RemainingNodes:     rackStatus.RemainingNodes,
+			DecommissioningPod: rackStatus.DecommissioningPod,
+			LastTransitionTime: rackStatus.LastTransitionTime,
+		})
+	}
+	dst.SystemKeyspacesReplicationFactor = src.SystemKeyspacesReplicationFactor
+	dst.SystemKeyspacesRepair = nil
+	if src.SystemKeyspacesRepair != nil {
+		systemKeyspacesRepair := SystemKeyspacesRepairStatus(*src.SystemKeyspacesRepair)
+		dst.SystemKeyspacesRepair = &systemKeyspacesRepair
+	}
+	dst.VolumeExpansions = nil
+	for _, expansion := range src.VolumeExpansions {
+		dst.VolumeExpansions = append(dst.VolumeExpansions, VolumeExpansionStatus{
+			PodName:            expansion.PodName,
+			Size:               expansion.Size,
+			State:              VolumeExpansionState(expansion.State),
+			LastTransitionTime: expansion.LastTransitionTime,
+		})
+	}
+	dst.Repair = nil
+	if src.Repair != nil {
+		dst.Repair = &RepairStatus{
+			LastScheduleTime: src.Repair.LastScheduleTime,
+			PendingKeyspaces: src.Repair.PendingKeyspaces,
+			PodName:          src.Repair.PodName,
+			RangeIndex:       src.Repair.RangeIndex,
+			JobID:            src.Repair.JobID,
+			FailedSessions:   src.Repair.FailedSessions,
+		}
+		for _, keyspace := range src.Repair.Keyspaces {
+			dst.Repair.Keyspaces = append(dst.Repair.Keyspaces, KeyspaceRepairStatus(keyspace))
+		}
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+)
+
+// Times decode in the local time zone, as they do from JSON
+var transitionTime = metav1.Unix(1591014600, 0)
+
+func storageClaim(size string) *corev1.PersistentVolumeClaimSpec {
+	storageClassName := "server-storage"
+	return &corev1.PersistentVolumeClaimSpec{
+		StorageClassName: &storageClassName,
+		AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+		},
+	}
+}
+
+func hubDatacenter() *v1beta1.CassandraDatacenter {
+	duration := metav1.Duration{Duration: 24 * time.Hour}
+	return &v1beta1.CassandraDatacenter{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "dc1",
+			Namespace:   "default",
+			Labels:      map[string]string{"team": "storage"},
+			Annotations: map[string]string{"note": "kept"},
+		},
+		Spec: v1beta1.CassandraDatacenterSpec{
+			Size:             3,
+			ServerVersion:    "3.11.6",
+			ServerType:       "cassandra",
+			ClusterName:      "cluster1",
+			ImageRegistry:    "registry.example.com",
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry-credentials"}},
+			Config: json.RawMessage(`{"10-write-prom-conf":{"enabled":true},"cassandra-yaml":{"num_tokens":8},` +
+				`"jvm-options":{"max_heap_size":"800m"}}`),
+			ManagementApiAuth: v1beta1.ManagementApiAuthConfig{
+				CertManager: &v1beta1.ManagementApiAuthCertManagerConfig{
+					IssuerRef: &v1beta1.CertManagerIssuerReference{Name: "ca-issuer", Kind: "ClusterIssuer"},
+					Duration:  &duration,
+				},
+			},
+			TLS: &v1beta1.TLSConfig{
+				KeystoreSecretName:  "keystore",
+				InternodeEncryption: "all",
+				ClientEncryption:    &v1beta1.TLSClientEncryptionConfig{Enabled: true},
+			},
+			Racks: []v1beta1.Rack{{Name: "r1", Zone: "us-east-1a"}, {Name: "r2"}},
+			StorageConfig: v1beta1.StorageConfig{
+				CassandraDataVolumeClaimSpec: storageClaim("10Gi"),
+				CommitLogVolumeClaimSpec:     storageClaim("2Gi"),
+				RetentionPolicy:              v1beta1.StorageRetentionPolicySnapshot,
+				VolumeSnapshotClassName:      "csi-snapshots",
+			},
+			DeletionPolicy:  v1beta1.DeletionPolicyDecommission,
+			RestartStrategy: v1beta1.RestartStrategyProcessRestart,
+			DseWorkloads:    &v1beta1.DseWorkloads{SearchEnabled: true},
+			Users:           []v1beta1.CassandraUser{{SecretName: "app-user", Superuser: false}},
+			Reaper: &v1beta1.ReaperConfig{
+				Enabled:        true,
+				DeploymentMode: v1beta1.ReaperDeploymentModeDeployment,
+				Schedules: []v1beta1.ReaperRepairSchedule{
+					{Keyspace: "ks1", Parallelism: v1beta1.RepairParallelismParallel, IntervalDays: 7},
+				},
+			},
+			Repair: &v1beta1.RepairConfig{
+				Keyspaces: []string{"ks1"},
+				Schedule:  "0 2 * * 0",
+			},
+		},
+		Status: v1beta1.CassandraDatacenterStatus{
+			Conditions: []v1beta1.DatacenterCondition{
+				{Type: v1beta1.DatacenterReady, Status: corev1.ConditionTrue, LastTransitionTime: transitionTime},
+			},
+			SuperUserUpserted:         transitionTime,
+			UsersUpserted:             transitionTime,
+			CassandraOperatorProgress: v1beta1.ProgressReady,
+			NodeStatuses: v1beta1.CassandraStatusMap{
+				"cluster1-dc1-r1-sts-0": {HostID: "host-1"},
+				"cluster1-dc1-r1-sts-1": {HostID: "host-2", RemoveNodeRequested: &transitionTime},
+			},
+			RackStatuses: []v1beta1.RackStatus{
+				{Name: "r1", Nodes: 2, ReadyNodes: 2},
+				{Name: "r3", Nodes: 1, ReadyNodes: 1, State: v1beta1.RackRemoving, RemainingNodes: 1,
+					DecommissioningPod: "cluster1-dc1-r3-sts-0", LastTransitionTime: transitionTime},
+			},
+			SystemKeyspacesRepair: &v1beta1.SystemKeyspacesRepairStatus{
+				ReplicationFactor: 3,
+				RepairedPods:      []string{"cluster1-dc1-r1-sts-0"},
+				PodName:           "cluster1-dc1-r1-sts-1",
+				JobID:             "repair-1",
+			},
+			VolumeExpansions: []v1beta1.VolumeExpansionStatus{
+				{PodName: "cluster1-dc1-r1-sts-0", Size: resource.MustParse("20Gi"),
+					State: v1beta1.VolumeExpansionResizing, LastTransitionTime: transitionTime},
+			},
+			Repair: &v1beta1.RepairStatus{
+				LastScheduleTime: &transitionTime,
+				PendingKeyspaces: []string{"ks1"},
+				Keyspaces:        []v1beta1.KeyspaceRepairStatus{{Name: "ks1", LastCompleted: transitionTime}},
+			},
+		},
+	}
+}
+
+func TestConvertFrom(t *testing.T) {
+	hub := hubDatacenter()
+
+	dc := &CassandraDatacenter{}
+	if err := dc.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() failed: %s", err.Error())
+	}
+
+	assert.Equal(t, "dc1", dc.Name)
+	assert.Equal(t, "2020-06-01T12:30:00Z", dc.Annotations[SuperUserUpsertedAnnotation])
+	assert.Equal(t, "kept", dc.Annotations["note"])
+
+	if assert.NotNil(t, dc.Spec.Config) {
+		assert.Equal(t, `{"num_tokens":8}`, string(dc.Spec.Config.CassandraYaml))
+		assert.Equal(t, `{"max_heap_size":"800m"}`, string(dc.Spec.Config.JvmOptions))
+		assert.Nil(t, dc.Spec.Config.JvmServerOptions)
+		assert.Equal(t, map[string]json.RawMessage{"10-write-prom-conf": json.RawMessage(`{"enabled":true}`)},
+			dc.Spec.Config.AdditionalSections)
+	}
+
+	assert.Equal(t, hub.Spec.StorageConfig.CassandraDataVolumeClaimSpec, dc.Spec.StorageConfig.Volumes.Data)
+	assert.Equal(t, hub.Spec.StorageConfig.CommitLogVolumeClaimSpec, dc.Spec.StorageConfig.Volumes.CommitLog)
+	assert.Nil(t, dc.Spec.StorageConfig.Volumes.Hints)
+	assert.Equal(t, StorageRetention{Policy: StorageRetentionPolicySnapshot, VolumeSnapshotClassName: "csi-snapshots"},
+		dc.Spec.StorageConfig.Retention)
+
+	assert.Equal(t, []RackStatus{
+		{Name: "r1", Nodes: 2, ReadyNodes: 2},
+		{Name: "r3", Nodes: 1, ReadyNodes: 1, State: RackRemoving, RemainingNodes: 1,
+			DecommissioningPod: "cluster1-dc1-r3-sts-0", LastTransitionTime: transitionTime},
+	}, dc.Status.Racks)
+
+	// The source must not be modified
+	assert.Equal(t, hubDatacenter(), hub)
+}
+
+func TestConvertFrom_InvalidConfig(t *testing.T) {
+	hub := hubDatacenter()
+	hub.Spec.Config = json.RawMessage(`["cassandra-yaml"]`)
+
+	dc := &CassandraDatacenter{}
+	if err := dc.ConvertFrom(hub); err == nil {
+		t.Errorf("ConvertFrom() should have failed for a config that is not an object")
+	}
+}
+
+func TestConvertTo_DuplicateSection(t *testing.T) {
+	dc := &CassandraDatacenter{
+		Spec: CassandraDatacenterSpec{
+			Config: &ServerConfig{
+				CassandraYaml:      json.RawMessage(`{"num_tokens":8}`),
+				AdditionalSections: map[string]json.RawMessage{"cassandra-yaml": json.RawMessage(`{"num_tokens":16}`)},
+			},
+		},
+	}
+
+	hub := &v1beta1.CassandraDatacenter{}
+	err := dc.ConvertTo(hub)
+	if err == nil {
+		t.Fatalf("ConvertTo() should have failed for a section that is set twice")
+	}
+	assert.Contains(t, err.Error(), "spec.config.additionalSections.cassandra-yaml")
+}
+
+func TestConvertTo_InvalidSuperUserAnnotation(t *testing.T) {
+	dc := &CassandraDatacenter{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{SuperUserUpsertedAnnotation: "yesterday"},
+		},
+	}
+
+	hub := &v1beta1.CassandraDatacenter{}
+	if err := dc.ConvertTo(hub); err == nil {
+		t.Errorf("ConvertTo() should have failed for an invalid %s annotation", SuperUserUpsertedAnnotation)
+	}
+}
+
+func TestRoundTrip_FromHub(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(dc *v1beta1.CassandraDatacenter)
+	}{
+		{
+			name:   "Full datacenter",
+			modify: func(dc *v1beta1.CassandraDatacenter) {},
+		},
+		{
+			name: "Empty datacenter",
+			modify: func(dc *v1beta1.CassandraDatacenter) {
+				*dc = v1beta1.CassandraDatacenter{}
+			},
+		},
+		{
+			name: "Empty config",
+			modify: func(dc *v1beta1.CassandraDatacenter) {
+				dc.Spec.Config = json.RawMessage(`{}`)
+			},
+		},
+		{
+			name: "Every config section",
+			modify: func(dc *v1beta1.CassandraDatacenter) {
+				dc.Spec.Config = json.RawMessage(`{"cassandra-env-sh":{"additional-jvm-opts":["-Dfoo=bar"]},` +
+					`"cassandra-yaml":{"num_tokens":8},"dse-yaml":{"authentication_options":{"enabled":true}},` +
+					`"jvm-options":{"max_heap_size":"800m"},"jvm-server-options":{"max_heap_size":"800m"},` +
+					`"jvm11-server-options":{"garbage_collector":"G1GC"},"jvm8-server-options":{"print_gc_details":true}}`)
+			},
+		},
+		{
+			name: "Only the annotation and no superuser status",
+			modify: func(dc *v1beta1.CassandraDatacenter) {
+				dc.Annotations = nil
+				dc.Status.SuperUserUpserted = metav1.Time{}
+			},
+		},
+		{
+			name: "Rack statuses without removal",
+			modify: func(dc *v1beta1.CassandraDatacenter) {
+				dc.Status.RackStatuses = []v1beta1.RackStatus{{Name: "r1", Nodes: 3}}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := hubDatacenter()
+			tt.modify(original)
+
+			dc := &CassandraDatacenter{}
+			if err := dc.ConvertFrom(original.DeepCopy()); err != nil {
+				t.Fatalf("ConvertFrom() failed: %s", err.Error())
+			}
+			got := &v1beta1.CassandraDatacenter{}
+			if err := dc.ConvertTo(got); err != nil {
+				t.Fatalf("ConvertTo() failed: %s", err.Error())
+			}
+
+			assert.Equal(t, original, got)
+		})
+	}
+}
+
+func TestRoundTrip_ToHub(t *testing.T) {
+	hub := hubDatacenter()
+	original := &CassandraDatacenter{}
+	if err := original.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() failed: %s", err.Error())
+	}
+
+	tests := []struct {
+		name   string
+		modify func(dc *CassandraDatacenter)
+	}{
+		{
+			name:   "Full datacenter",
+			modify: func(dc *CassandraDatacenter) {},
+		},
+		{
+			name: "Empty datacenter",
+			modify: func(dc *CassandraDatacenter) {
+				*dc = CassandraDatacenter{}
+			},
+		},
+		{
+			name: "Only additional sections",
+			modify: func(dc *CassandraDatacenter) {
+				dc.Spec.Config = &ServerConfig{
+					AdditionalSections: map[string]json.RawMessage{
+						"cassandra-yaml-template": json.RawMessage(`{"path":"/config"}`),
+					},
+				}
+			},
+		},
+		{
+			name: "All volumes and retention",
+			modify: func(dc *CassandraDatacenter) {
+				dc.Spec.StorageConfig = StorageConfig{
+					Volumes: StorageVolumes{
+						Data:        storageClaim("100Gi"),
+						CommitLog:   storageClaim("5Gi"),
+						Hints:       storageClaim("1Gi"),
+						SavedCaches: storageClaim("1Gi"),
+					},
+					Retention: StorageRetention{Policy: StorageRetentionPolicyRetain},
+				}
+			},
+		},
+		{
+			name: "Without superuser annotation",
+			modify: func(dc *CassandraDatacenter) {
+				delete(dc.Annotations, SuperUserUpsertedAnnotation)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := original.DeepCopy()
+			tt.modify(dc)
+			want := dc.DeepCopy()
+
+			converted := &v1beta1.CassandraDatacenter{}
+			if err := dc.ConvertTo(converted); err != nil {
+				t.Fatalf("ConvertTo() failed: %s", err.Error())
+			}
+			got := &CassandraDatacenter{}
+			if err := got.ConvertFrom(converted); err != nil {
+				t.Fatalf("ConvertFrom() failed: %s", err.Error())
+			}
+
+			assert.Equal(t, want, got)
+			assert.Equal(t, want, dc, "ConvertTo() must not modify the source")
+		})
+	}
+}
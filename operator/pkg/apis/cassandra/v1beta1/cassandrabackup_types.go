@@ -0,0 +1,202 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// BackupLabel is the operator's label for the name of the backup or
+	// restore a Job belongs to
+	BackupLabel = "cassandra.datastax.com/backup"
+
+	defaultBackupImage = "amazon/aws-cli:2.0.30"
+)
+
+// BackupStorageConfig describes where backups are kept. Any object store that
+// implements the S3 API can be used.
+type BackupStorageConfig struct {
+	// URL of the object store, for example https://s3.us-east-1.amazonaws.com
+	// or http://minio.minio.svc.cluster.local:9000
+	Endpoint string `json:"endpoint"`
+	// Bucket to store the backups in. It must already exist.
+	Bucket string `json:"bucket"`
+	// Optional path prefix for all objects written to the bucket
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+	// +optional
+	Region string `json:"region,omitempty"`
+	// Name of a secret with AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY
+	// keys, used to authenticate with the object store
+	SecretName string `json:"secretName"`
+}
+
+// CassandraBackupSpec defines the desired state of CassandraBackup
+// +k8s:openapi-gen=true
+type CassandraBackupSpec struct {
+	// The name of the CassandraDatacenter to back up. It must be in the same
+	// namespace as the backup.
+	CassandraDatacenter string `json:"cassandraDatacenter"`
+
+	// Keyspaces to include in the backup. All keyspaces are included when
+	// left empty.
+	// +optional
+	Keyspaces []string `json:"keyspaces,omitempty"`
+
+	Storage BackupStorageConfig `json:"storage"`
+
+	// Image used by the Jobs that copy snapshots to and from the object
+	// store. It needs a shell and the aws cli.
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+type BackupConditionType string
+
+const (
+	// Per pod
+	BackupSnapshotTaken BackupConditionType = "SnapshotTaken"
+	BackupUploaded      BackupConditionType = "Uploaded"
+	BackupStaged        BackupConditionType = "Staged"
+
+	// For the whole backup or restore
+	BackupStopped BackupConditionType = "DatacenterStopped"
+	BackupStaging BackupConditionType = "Staging"
+
+	// For pods and for the whole backup or restore
+	BackupComplete BackupConditionType = "Complete"
+	BackupFailed   BackupConditionType = "Failed"
+)
+
+type BackupCondition struct {
+	Type               BackupConditionType    `json:"type"`
+	Status             corev1.ConditionStatus `json:"status"`
+	LastTransitionTime metav1.Time            `json:"lastTransitionTime,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+func NewBackupCondition(conditionType BackupConditionType, status corev1.ConditionStatus) *BackupCondition {
+	return &BackupCondition{
+		Type:   conditionType,
+		Status: status,
+	}
+}
+
+// BackupConditions is a list of conditions shared by backups, restores and
+// the pods they are working on
+type BackupConditions []BackupCondition
+
+func (conditions BackupConditions) GetConditionStatus(conditionType BackupConditionType) corev1.ConditionStatus {
+	for _, condition := range conditions {
+		if condition.Type == conditionType {
+			return condition.Status
+		}
+	}
+	return corev1.ConditionFalse
+}
+
+// SetCondition adds or replaces a condition. Returns true when the status of
+// the condition changed, in which case its transition time is updated.
+func (conditions *BackupConditions) SetCondition(condition BackupCondition) bool {
+	for i := range *conditions {
+		existing := &(*conditions)[i]
+		if existing.Type != condition.Type {
+			continue
+		}
+		if existing.Status == condition.Status && existing.Message == condition.Message {
+			return false
+		}
+		if existing.Status != condition.Status {
+			condition.LastTransitionTime = metav1.Now()
+		} else {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		*existing = condition
+		return true
+	}
+
+	condition.LastTransitionTime = metav1.Now()
+	*conditions = append(*conditions, condition)
+	return true
+}
+
+// PodBackupStatus tracks the progress of a backup or restore for one pod
+type PodBackupStatus struct {
+	Conditions BackupConditions `json:"conditions,omitempty"`
+}
+
+// CassandraBackupStatus defines the observed state of CassandraBackup
+// +k8s:openapi-gen=true
+type CassandraBackupStatus struct {
+	Conditions BackupConditions `json:"conditions,omitempty"`
+
+	// +optional
+	StartTime metav1.Time `json:"startTime,omitempty"`
+
+	// +optional
+	FinishTime metav1.Time `json:"finishTime,omitempty"`
+
+	// Progress of each pod that is part of the backup, by pod name
+	// +optional
+	Pods map[string]PodBackupStatus `json:"pods,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CassandraBackup is the Schema for the cassandrabackups API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=cassandrabackups,scope=Namespaced,shortName=cassbackup;cassbackups
+type CassandraBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CassandraBackupSpec   `json:"spec,omitempty"`
+	Status CassandraBackupStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CassandraBackupList contains a list of CassandraBackup
+type CassandraBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CassandraBackup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CassandraBackup{}, &CassandraBackupList{})
+}
+
+// GetSnapshotName returns the name of the snapshot taken on each node
+func (backup *CassandraBackup) GetSnapshotName() string {
+	return backup.Name
+}
+
+// GetImage returns the image used for the Jobs that transfer the backup
+func (backup *CassandraBackup) GetImage() string {
+	if backup.Spec.Image != "" {
+		return backup.Spec.Image
+	}
+	return defaultBackupImage
+}
+
+// GetPodPath returns the path, relative to the bucket, under which the
+// snapshot of the given pod is stored
+func (backup *CassandraBackup) GetPodPath(podName string) string {
+	path := backup.Spec.CassandraDatacenter + "/" + backup.Name + "/" + podName
+	if backup.Spec.Storage.Prefix != "" {
+		path = backup.Spec.Storage.Prefix + "/" + path
+	}
+	return path
+}
+
+func (backup *CassandraBackup) IsFinished() bool {
+	return backup.Status.Conditions.GetConditionStatus(BackupComplete) == corev1.ConditionTrue ||
+		backup.Status.Conditions.GetConditionStatus(BackupFailed) == corev1.ConditionTrue
+}
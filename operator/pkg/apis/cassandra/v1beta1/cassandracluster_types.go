@@ -0,0 +1,126 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CassandraClusterSpec defines the desired state of CassandraCluster
+// +k8s:openapi-gen=true
+type CassandraClusterSpec struct {
+	// The name of the Cassandra cluster, which is set as the clusterName of
+	// every datacenter. Defaults to the name of the resource.
+	// +optional
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// The datacenters of the cluster. They are created in order, each one
+	// once the previous one is ready. The first datacenter is the one new
+	// datacenters are rebuilt from. Removing a datacenter from this list
+	// does not delete it.
+	// +kubebuilder:validation:MinItems=1
+	Datacenters []CassandraClusterDatacenter `json:"datacenters"`
+}
+
+// CassandraClusterDatacenter is a datacenter of a CassandraCluster
+type CassandraClusterDatacenter struct {
+	// The name of the CassandraDatacenter, which is also the name of the
+	// datacenter in Cassandra
+	Name string `json:"name"`
+
+	// The spec of the CassandraDatacenter. Its clusterName is ignored.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Spec CassandraDatacenterSpec `json:"spec"`
+}
+
+// CassandraClusterDatacenterPhase is how far a datacenter has come in joining
+// the cluster
+type CassandraClusterDatacenterPhase string
+
+const (
+	// The CassandraDatacenter was created and is not ready yet
+	ClusterDatacenterCreating CassandraClusterDatacenterPhase = "Creating"
+
+	// The keyspaces are being replicated to the datacenter, and its nodes
+	// rebuilt from the first datacenter
+	ClusterDatacenterRebuilding CassandraClusterDatacenterPhase = "Rebuilding"
+
+	// The datacenter has joined the cluster
+	ClusterDatacenterReady CassandraClusterDatacenterPhase = "Ready"
+)
+
+// CassandraClusterDatacenterStatus is the observed state of a datacenter of
+// a CassandraCluster
+type CassandraClusterDatacenterStatus struct {
+	Name string `json:"name"`
+
+	Phase CassandraClusterDatacenterPhase `json:"phase"`
+
+	// The pods of the datacenter whose nodes have been rebuilt
+	// +optional
+	RebuiltPods []string `json:"rebuiltPods,omitempty"`
+
+	// The pod whose node is being rebuilt
+	// +optional
+	RebuildPodName string `json:"rebuildPodName,omitempty"`
+
+	// The management API job of the rebuild in progress
+	// +optional
+	RebuildJobID string `json:"rebuildJobId,omitempty"`
+}
+
+// CassandraClusterStatus defines the observed state of CassandraCluster
+// +k8s:openapi-gen=true
+type CassandraClusterStatus struct {
+	// The datacenters that were created, in order
+	// +optional
+	Datacenters []CassandraClusterDatacenterStatus `json:"datacenters,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CassandraCluster is the Schema for the cassandraclusters API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=cassandraclusters,scope=Namespaced,shortName=casscluster;cassclusters
+type CassandraCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CassandraClusterSpec   `json:"spec,omitempty"`
+	Status CassandraClusterStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CassandraClusterList contains a list of CassandraCluster
+type CassandraClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CassandraCluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CassandraCluster{}, &CassandraClusterList{})
+}
+
+// GetClusterName returns the name of the Cassandra cluster
+func (cluster *CassandraCluster) GetClusterName() string {
+	if cluster.Spec.ClusterName != "" {
+		return cluster.Spec.ClusterName
+	}
+	return cluster.Name
+}
+
+// GetDatacenterStatus returns the status of the datacenter with the given
+// name, or nil if it was not created yet
+func (cluster *CassandraCluster) GetDatacenterStatus(dcName string) *CassandraClusterDatacenterStatus {
+	for idx := range cluster.Status.Datacenters {
+		if cluster.Status.Datacenters[idx].Name == dcName {
+			return &cluster.Status.Datacenters[idx]
+		}
+	}
+	return nil
+}
@@ -7,13 +7,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/Jeffail/gabs"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/datastax/cass-operator/operator/pkg/images"
 	"github.com/datastax/cass-operator/operator/pkg/serverconfig"
 	"github.com/datastax/cass-operator/operator/pkg/utils"
 )
@@ -31,12 +34,51 @@ const (
 	// RackLabel is the operator's label for the rack name
 	RackLabel = "cassandra.datastax.com/rack"
 
+	// ReaperLabel is the operator's label for the pods of a Reaper
+	// Deployment, set to the datacenter name
+	ReaperLabel = "cassandra.datastax.com/reaper"
+
 	// RackLabel is the operator's label for the rack name
 	CassOperatorProgressLabel = "cassandra.datastax.com/operator-progress"
 
 	// CassNodeState
 	CassNodeState = "cassandra.datastax.com/node-state"
 
+	// LastRestartAnnotation records when Cassandra was last restarted in a
+	// pod without recreating the pod
+	LastRestartAnnotation = "cassandra.datastax.com/last-restart"
+
+	// DecommissionJobAnnotation holds the management API job decommissioning
+	// the node of a pod
+	DecommissionJobAnnotation = "cassandra.datastax.com/decommission-job-id"
+
+	// The images that the defaulting webhook wrote into the spec. An image of
+	// the spec that still matches its annotation was not chosen by the user,
+	// so it is resolved again when the server version or the image registry
+	// changes.
+	DefaultConfigBuilderImageAnnotation = "cassandra.datastax.com/default-config-builder-image"
+	DefaultReaperImageAnnotation        = "cassandra.datastax.com/default-reaper-image"
+
+	// TLSKeystoreMountPath is where the secret of Spec.TLS is mounted in the
+	// cassandra container
+	TLSKeystoreMountPath = "/etc/encryption"
+
+	// The keys of the passwords of the keystore and the truststore in the
+	// secret of Spec.TLS
+	TLSKeystorePasswordKey   = "keystore-password"
+	TLSTruststorePasswordKey = "truststore-password"
+
+	// TLSPasswordPlaceholder stands for the keystore and truststore
+	// passwords in the config, which are only read from the secret of
+	// Spec.TLS in the pods
+	TLSPasswordPlaceholder = "from-keystore-secret"
+
+	// Where the optional commit log, hints and saved caches volumes of
+	// Spec.StorageConfig are mounted in the cassandra container
+	CommitLogMountPath   = "/var/lib/cassandra/commitlog"
+	HintsMountPath       = "/var/lib/cassandra/hints"
+	SavedCachesMountPath = "/var/lib/cassandra/saved_caches"
+
 	// Progress states for status
 	ProgressUpdating ProgressState = "Updating"
 	ProgressReady    ProgressState = "Ready"
@@ -45,81 +87,62 @@ const (
 // This type exists so there's no chance of pushing random strings to our progress status
 type ProgressState string
 
+// RestartStrategy is how a rolling restart restarts each Cassandra node
+type RestartStrategy string
+
 const (
-	defaultConfigBuilderImage     = "datastax/cass-config-builder:1.0.1"
-	ubi_defaultConfigBuilderImage = "datastax/cass-config-builder:1.0.1-ubi7"
+	// RestartStrategyPodDelete drains the node and deletes its pod, so the
+	// pod is recreated from the StatefulSet
+	RestartStrategyPodDelete RestartStrategy = "podDelete"
+
+	// RestartStrategyProcessRestart drains the node and stops Cassandra
+	// through the management API, then starts it again in the same pod
+	RestartStrategyProcessRestart RestartStrategy = "processRestart"
+)
 
-	cassandra_3_11_6     = "datastax/cassandra-mgmtapi-3_11_6:v0.1.5"
-	cassandra_4_0_0      = "datastax/cassandra-mgmtapi-4_0_0:v0.1.5"
-	ubi_cassandra_3_11_6 = "datastax/cassandra:3.11.6-ubi7"
-	ubi_cassandra_4_0_0  = "datastax/cassandra:4.0-ubi7"
+type DeletionPolicy string
 
-	dse_6_8_0     = "datastax/dse-server:6.8.0"
-	dse_6_8_1     = "datastax/dse-server:6.8.1"
-	ubi_dse_6_8_0 = "datastax/dse-server:6.8.0-ubi7"
-	ubi_dse_6_8_1 = "datastax/dse-server:6.8.1-ubi7"
+const (
+	// DeletionPolicyDelete deletes the resources of the datacenter without
+	// removing its nodes from the cluster
+	DeletionPolicyDelete DeletionPolicy = "delete"
 
-	EnvBaseImageOs = "BASE_IMAGE_OS"
+	// DeletionPolicyDecommission removes the nodes of the datacenter from
+	// the cluster before its resources are deleted
+	DeletionPolicyDecommission DeletionPolicy = "decommission"
 )
 
-// getImageForServerVersion tries to look up a known image for a server type and version number.
-// In the event that no image is found, an error is returned
-func getImageForServerVersion(server, version string) (string, error) {
-	baseImageOs := os.Getenv(EnvBaseImageOs)
+const (
+	defaultServiceAccount = "default"
 
-	var imageCalc func(string) (string, bool)
-	var img string
-	var success bool
-	var errMsg string
+	defaultReaperImage                = "thelastpickle/cassandra-reaper:2.0.5"
+	defaultReaperImagePullPolicy      = corev1.PullIfNotPresent
+	defaultReaperScheduleIntervalDays = 7
 
-	if baseImageOs == "" {
-		imageCalc = getImageForDefaultBaseOs
-		errMsg = fmt.Sprintf("server '%s' and version '%s' do not work together", server, version)
-	} else {
-		// if this operator was compiled using a UBI base image
-		// such as registry.access.redhat.com/ubi7/ubi-minimal:7.8
-		// then we use specific cassandra and init container coordinates
-		// that are built accordingly
-		errMsg = fmt.Sprintf("server '%s' and version '%s', along with the specified base OS '%s', do not work together", server, version, baseImageOs)
-		imageCalc = getImageForUniversalBaseOs
-	}
+	EnvBaseImageOs = "BASE_IMAGE_OS"
+)
+
+// getImageForServerVersion tries to look up a known image for a server type and version number
+// in the version catalog, pulled from imageRegistry when it is set. In the event that no image
+// is found, an error is returned
+func getImageForServerVersion(server, version, imageRegistry string) (string, error) {
+	baseImageOs := os.Getenv(EnvBaseImageOs)
 
-	img, success = imageCalc(server + "-" + version)
+	// if this operator was compiled using a UBI base image
+	// such as registry.access.redhat.com/ubi7/ubi-minimal:7.8
+	// then we use specific cassandra and init container coordinates
+	// that are built accordingly
+	img, success := images.GetCatalog().WithRegistry(imageRegistry).ServerImage(server, version, baseImageOs != "")
 	if !success {
-		return "", fmt.Errorf(errMsg)
+		if baseImageOs == "" {
+			return "", fmt.Errorf("server '%s' and version '%s' do not work together", server, version)
+		}
+		return "", fmt.Errorf("server '%s' and version '%s', along with the specified base OS '%s', do not work together", server, version, baseImageOs)
 	}
 
 	return img, nil
 }
 
-func getImageForDefaultBaseOs(sv string) (string, bool) {
-	switch sv {
-	case "dse-6.8.0":
-		return dse_6_8_0, true
-	case "dse-6.8.1":
-		return dse_6_8_1, true
-	case "cassandra-3.11.6":
-		return cassandra_3_11_6, true
-	case "cassandra-4.0.0":
-		return cassandra_4_0_0, true
-	}
-	return "", false
-}
-
-func getImageForUniversalBaseOs(sv string) (string, bool) {
-	switch sv {
-	case "dse-6.8.0":
-		return ubi_dse_6_8_0, true
-	case "dse-6.8.1":
-		return ubi_dse_6_8_1, true
-	case "cassandra-3.11.6":
-		return ubi_cassandra_3_11_6, true
-	case "cassandra-4.0.0":
-		return ubi_cassandra_4_0_0, true
-	}
-	return "", false
-}
-
 type CassandraUser struct {
 	SecretName string `json:"secretName"`
 	Superuser  bool   `json:"superuser"`
@@ -137,24 +160,44 @@ type CassandraDatacenterSpec struct {
 	Size int32 `json:"size"`
 
 	// Version string for config builder,
-	// used to generate Cassandra server configuration
-	// +kubebuilder:validation:Enum="6.8.0";"6.8.1";"3.11.6";"4.0.0"
+	// used to generate Cassandra server configuration. The supported
+	// versions are those of the version catalog of the operator.
 	ServerVersion string `json:"serverVersion"`
 
 	// Cassandra server image name.
 	// More info: https://kubernetes.io/docs/concepts/containers/images
 	ServerImage string `json:"serverImage,omitempty"`
 
+	// The registry to pull the images that the operator picks by default from, e.g.
+	// "registry.example.com:5000". It replaces the registry of the server, config builder,
+	// system logger, Reaper and Reaper schema init images, and takes precedence over the
+	// registry of the version catalog. Images set explicitly in the spec are used as is.
+	// +optional
+	ImageRegistry string `json:"imageRegistry,omitempty"`
+
+	// Secrets to pull the images of every pod that the operator creates for the datacenter,
+	// including the Reaper Deployment and the Reaper schema init Job. They are added to the
+	// image pull secrets of the PodTemplateSpec.
+	// More info: https://kubernetes.io/docs/concepts/containers/images#specifying-imagepullsecrets-on-a-pod
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
 	// Server type: "cassandra" or "dse"
 	// +kubebuilder:validation:Enum=cassandra;dse
 	ServerType string `json:"serverType"`
 
 	// Config for the server, in YAML format
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Type=object
 	Config json.RawMessage `json:"config,omitempty"`
 
 	// Config for the Management API certificates
 	ManagementApiAuth ManagementApiAuthConfig `json:"managementApiAuth,omitempty"`
 
+	// Encryption of the traffic between nodes and of CQL client connections
+	// +optional
+	TLS *TLSConfig `json:"tls,omitempty"`
+
 	// Kubernetes resource requests and limits, per pod
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
 
@@ -180,6 +223,16 @@ type CassandraDatacenterSpec struct {
 	// will re-attach when the CassandraDatacenter workload is resumed.
 	Stopped bool `json:"stopped,omitempty"`
 
+	// What happens to the nodes of the datacenter when the CassandraDatacenter is deleted:
+	// "delete" deletes its resources, leaving the nodes listed as down in the other
+	// datacenters of the cluster. "decommission" first removes the datacenter from the
+	// system_auth, system_distributed and system_traces keyspaces, waits until no other
+	// keyspace is replicated to it, and removes each of its nodes from the cluster. Defaults
+	// to "delete".
+	// +kubebuilder:validation:Enum=delete;decommission
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
 	// Container image for the config builder init container.
 	ConfigBuilderImage string `json:"configBuilderImage,omitempty"`
 
@@ -203,6 +256,24 @@ type CassandraDatacenterSpec struct {
 	// to false once the restart is in progress.
 	RollingRestartRequested bool `json:"rollingRestartRequested,omitempty"`
 
+	// How nodes are restarted during a rolling restart: "podDelete" deletes each pod,
+	// "processRestart" stops and starts Cassandra in place through the management API,
+	// which skips rescheduling the pod and rerunning its init containers. Defaults to
+	// "podDelete".
+	// +kubebuilder:validation:Enum=podDelete;processRestart
+	// +optional
+	RestartStrategy RestartStrategy `json:"restartStrategy,omitempty"`
+
+	// The number of pods of a rack that may be restarted at the same time during rolling
+	// restarts and upgrades. Racks are still restarted one at a time, and only once every
+	// node of the other racks is started and the cluster is healthy. Setting this to the
+	// size of the racks restarts a whole rack at once, which is safe when each rack holds a
+	// full replica of the data, e.g. three racks and a replication factor of 3. Defaults to
+	// 1, which restarts one pod of the datacenter at a time.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxUnavailablePerRack int32 `json:"maxUnavailablePerRack,omitempty"`
+
 	// A map of label keys and values to restrict Cassandra node scheduling to k8s workers
 	// with matchiing labels.
 	// More info: https://kubernetes.io/docs/concepts/configuration/assign-pod-node/#nodeselector
@@ -224,6 +295,12 @@ type CassandraDatacenterSpec struct {
 	AdditionalSeeds []string `json:"additionalSeeds,omitempty"`
 
 	Reaper *ReaperConfig `json:"reaper,omitempty"`
+
+	// Repairs that the operator runs on a schedule through the management API,
+	// one node or token range at a time. Unlike the Reaper sidecar this works
+	// for every server type.
+	// +optional
+	Repair *RepairConfig `json:"repair,omitempty"`
 }
 
 type DseWorkloads struct {
@@ -234,8 +311,39 @@ type DseWorkloads struct {
 
 type StorageConfig struct {
 	CassandraDataVolumeClaimSpec *corev1.PersistentVolumeClaimSpec `json:"cassandraDataVolumeClaimSpec,omitempty"`
+
+	// Optional volume for the commit log. The commit log is kept on the cassandra
+	// data volume when it is not set.
+	CommitLogVolumeClaimSpec *corev1.PersistentVolumeClaimSpec `json:"commitLogVolumeClaimSpec,omitempty"`
+
+	// Optional volume for hints. Hints are kept on the cassandra data volume when
+	// it is not set.
+	HintsVolumeClaimSpec *corev1.PersistentVolumeClaimSpec `json:"hintsVolumeClaimSpec,omitempty"`
+
+	// Optional volume for saved caches. Saved caches are kept on the cassandra
+	// data volume when it is not set.
+	SavedCachesVolumeClaimSpec *corev1.PersistentVolumeClaimSpec `json:"savedCachesVolumeClaimSpec,omitempty"`
+
+	// What happens to the PersistentVolumeClaims of the datacenter when the
+	// CassandraDatacenter is deleted: "Delete" deletes them, "Retain" removes the
+	// operator's labels from them and leaves them in place, and "Snapshot" takes a
+	// VolumeSnapshot of each one before deleting it. Defaults to "Delete".
+	// +kubebuilder:validation:Enum=Delete;Retain;Snapshot
+	RetentionPolicy StorageRetentionPolicy `json:"retentionPolicy,omitempty"`
+
+	// The VolumeSnapshotClass of the snapshots taken with the "Snapshot" retention
+	// policy. The default class of the CSI driver is used when it is not set.
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
 }
 
+type StorageRetentionPolicy string
+
+const (
+	StorageRetentionPolicyDelete   StorageRetentionPolicy = "Delete"
+	StorageRetentionPolicyRetain   StorageRetentionPolicy = "Retain"
+	StorageRetentionPolicySnapshot StorageRetentionPolicy = "Snapshot"
+)
+
 // GetRacks is a getter for the Rack slice in the spec
 // It ensures there is always at least one rack
 func (dc *CassandraDatacenter) GetRacks() []Rack {
@@ -259,10 +367,61 @@ type Rack struct {
 
 type CassandraNodeStatus struct {
 	HostID string `json:"hostID,omitempty"`
+
+	// When the node was last asked to be removed from the ring, while the
+	// datacenter is decommissioned
+	RemoveNodeRequested *metav1.Time `json:"removeNodeRequested,omitempty"`
 }
 
 type CassandraStatusMap map[string]CassandraNodeStatus
 
+type RackState string
+
+const (
+	RackRemoving RackState = "Removing"
+)
+
+type RackStatus struct {
+	// The rack name
+	Name string `json:"name"`
+	// The number of pods of the rack
+	// +optional
+	Nodes int32 `json:"nodes,omitempty"`
+	// The number of pods of the rack whose Cassandra node is ready
+	// +optional
+	ReadyNodes int32 `json:"readyNodes,omitempty"`
+	// What the operator is currently doing with the rack, empty when it is
+	// not working on the rack
+	// +optional
+	State RackState `json:"state,omitempty"`
+	// The number of nodes still in the rack while it is being removed
+	// +optional
+	RemainingNodes int32 `json:"remainingNodes,omitempty"`
+	// The pod that is currently being decommissioned
+	// +optional
+	DecommissioningPod string `json:"decommissioningPod,omitempty"`
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+type VolumeExpansionState string
+
+const (
+	VolumeExpansionResizing                VolumeExpansionState = "Resizing"
+	VolumeExpansionFileSystemResizePending VolumeExpansionState = "FileSystemResizePending"
+)
+
+type VolumeExpansionStatus struct {
+	// The pod whose server-data volume is being expanded
+	PodName string `json:"podName"`
+	// The size the volume is being expanded to
+	Size resource.Quantity `json:"size"`
+	// Whether the volume itself or its file system is being resized
+	State VolumeExpansionState `json:"state"`
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
 type DatacenterConditionType string
 
 const (
@@ -270,10 +429,14 @@ const (
 	DatacenterInitialized    DatacenterConditionType = "Initialized"
 	DatacenterReplacingNodes DatacenterConditionType = "ReplacingNodes"
 	DatacenterScalingUp      DatacenterConditionType = "ScalingUp"
+	DatacenterScalingDown    DatacenterConditionType = "ScalingDown"
 	DatacenterUpdating       DatacenterConditionType = "Updating"
 	DatacenterStopped        DatacenterConditionType = "Stopped"
 	DatacenterResuming       DatacenterConditionType = "Resuming"
 	DatacenterRollingRestart DatacenterConditionType = "RollingRestart"
+	// The volumes of a rack cannot be expanded to the storage request of
+	// cassandraDataVolumeClaimSpec, as their StorageClass does not allow it
+	DatacenterVolumeExpansionBlocked DatacenterConditionType = "VolumeExpansionBlocked"
 )
 
 type DatacenterCondition struct {
@@ -323,6 +486,32 @@ type CassandraDatacenterStatus struct {
 	// +optional
 	NodeReplacements []string `json:"nodeReplacements"`
 
+	// The nodes of each rack, and what the operator is doing with the racks
+	// it is working on, such as racks that are being removed from the
+	// datacenter
+	// +optional
+	RackStatuses []RackStatus `json:"rackStatuses,omitempty"`
+
+	// The replication factor in this datacenter that the system_auth,
+	// system_distributed and system_traces keyspaces were last altered to
+	// +optional
+	SystemKeyspacesReplicationFactor int32 `json:"systemKeyspacesReplicationFactor,omitempty"`
+
+	// The progress of the repair of system_auth that follows altering the
+	// replication of the system keyspaces. The replication factor above is
+	// only recorded once it is done.
+	// +optional
+	SystemKeyspacesRepair *SystemKeyspacesRepairStatus `json:"systemKeyspacesRepair,omitempty"`
+
+	// The volumes that are being expanded after the storage request of
+	// cassandraDataVolumeClaimSpec was increased
+	// +optional
+	VolumeExpansions []VolumeExpansionStatus `json:"volumeExpansions,omitempty"`
+
+	// The progress of the scheduled repairs of spec.repair
+	// +optional
+	Repair *RepairStatus `json:"repair,omitempty"`
+
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 }
 
@@ -351,18 +540,297 @@ type ManagementApiAuthManualConfig struct {
 type ManagementApiAuthInsecureConfig struct {
 }
 
+// CertManagerIssuerReference points to a cert-manager Issuer or ClusterIssuer
+type CertManagerIssuerReference struct {
+	Name string `json:"name"`
+	// Defaults to Issuer
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	// +optional
+	Kind string `json:"kind,omitempty"`
+}
+
+// ManagementApiAuthCertManagerConfig has cert-manager issue the server and
+// client certificates of the Management API
+type ManagementApiAuthCertManagerConfig struct {
+	// The issuer of both certificates. It must put its CA certificate in
+	// the issued secrets, like the CA and Vault issuers do, as each side
+	// verifies the other with it. When empty, the operator creates a
+	// self-signed CA for the datacenter.
+	// +optional
+	IssuerRef *CertManagerIssuerReference `json:"issuerRef,omitempty"`
+	// How long the certificates are valid for, e.g. "2160h"
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// How long before they expire the certificates are renewed, e.g. "360h"
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
+// ManagementApiAuthAutoConfig has the operator generate a CA and the server
+// and client certificates of the Management API, and renew them
+type ManagementApiAuthAutoConfig struct {
+	// How long the server and client certificates are valid for. Defaults to
+	// "8760h", one year.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// How long before they expire the certificates are renewed. Defaults to
+	// "720h", 30 days.
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
 type ManagementApiAuthConfig struct {
-	Insecure *ManagementApiAuthInsecureConfig `json:"insecure,omitempty"`
-	Manual   *ManagementApiAuthManualConfig   `json:"manual,omitempty"`
-	// other strategy configs (e.g. Cert Manager) go here
+	Insecure    *ManagementApiAuthInsecureConfig    `json:"insecure,omitempty"`
+	Manual      *ManagementApiAuthManualConfig      `json:"manual,omitempty"`
+	CertManager *ManagementApiAuthCertManagerConfig `json:"certManager,omitempty"`
+	Auto        *ManagementApiAuthAutoConfig        `json:"auto,omitempty"`
+	// other strategy configs go here
 }
 
+// TLSConfig encrypts the traffic between nodes, and optionally CQL client
+// connections, with the keystore and truststore of a secret
+type TLSConfig struct {
+	// Name of the secret that holds the keystore and the truststore, in the
+	// keys "keystore.jks" and "truststore.jks", like the JKS keystores that
+	// cert-manager creates, and their passwords, in the keys
+	// "keystore-password" and "truststore-password". The passwords default
+	// to "cassandra" when their key is missing. The secret must exist in the
+	// namespace of the datacenter.
+	KeystoreSecretName string `json:"keystoreSecretName"`
+
+	// Which traffic between nodes is encrypted: "all", "dc" for traffic
+	// between datacenters, "rack" for traffic between racks, or "none".
+	// Defaults to "all".
+	// +kubebuilder:validation:Enum=all;dc;rack;none
+	// +optional
+	InternodeEncryption string `json:"internodeEncryption,omitempty"`
+
+	// Whether nodes must present a certificate trusted by the truststore to
+	// other nodes
+	// +optional
+	RequireInternodeClientAuth bool `json:"requireInternodeClientAuth,omitempty"`
+
+	// Encryption of CQL client connections
+	// +optional
+	ClientEncryption *TLSClientEncryptionConfig `json:"clientEncryption,omitempty"`
+}
+
+type TLSClientEncryptionConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Accept unencrypted connections as well, e.g. while clients are moved
+	// to TLS
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+
+	// Whether clients must present a certificate trusted by the truststore
+	// +optional
+	RequireClientAuth bool `json:"requireClientAuth,omitempty"`
+}
+
+// ReaperDeploymentMode is how Reaper is run for a datacenter
+type ReaperDeploymentMode string
+
+const (
+	// ReaperDeploymentModeSidecar runs Reaper as a sidecar container in each
+	// Cassandra pod
+	ReaperDeploymentModeSidecar ReaperDeploymentMode = "Sidecar"
+
+	// ReaperDeploymentModeDeployment runs a single Reaper instance per
+	// datacenter in its own Deployment, connecting to the nodes through
+	// remote JMX
+	ReaperDeploymentModeDeployment ReaperDeploymentMode = "Deployment"
+)
+
 type ReaperConfig struct {
 	Enabled bool `json:"enabled,omitempty"`
 
 	Image string `json:"image,omitempty"`
 
 	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// Whether Reaper runs as a sidecar in each Cassandra pod or as a single
+	// Deployment for the datacenter. Defaults to Sidecar.
+	// +kubebuilder:validation:Enum=Sidecar;Deployment
+	// +optional
+	DeploymentMode ReaperDeploymentMode `json:"deploymentMode,omitempty"`
+
+	// The name of a Secret in the namespace of the datacenter with "username"
+	// and "password" keys, used to authenticate to the Reaper UI and REST API.
+	// Authentication is disabled when this is not set.
+	// +optional
+	UISecretName string `json:"uiSecretName,omitempty"`
+
+	// The name of a Secret in the namespace of the datacenter with "username"
+	// and "password" keys, used by Reaper to authenticate to JMX. Required
+	// when deploymentMode is Deployment, as the nodes then open JMX to the
+	// network.
+	// +optional
+	JmxSecretName string `json:"jmxSecretName,omitempty"`
+
+	// Repair schedules that the operator creates in Reaper once the cluster is
+	// registered with it. Schedules created by the operator that are no longer
+	// listed here are deleted, while the ones created through the Reaper UI
+	// are left alone.
+	// +optional
+	Schedules []ReaperRepairSchedule `json:"schedules,omitempty"`
+}
+
+type ReaperRepairSchedule struct {
+	// The keyspace to repair
+	// +kubebuilder:validation:MinLength=1
+	Keyspace string `json:"keyspace"`
+
+	// The tables of the keyspace to repair. Every table is repaired when it
+	// is empty.
+	// +optional
+	Tables []string `json:"tables,omitempty"`
+
+	// The share of the time that Reaper spends repairing, between "0.0"
+	// exclusive and "1.0". The Reaper default is used when it is not set.
+	// +kubebuilder:validation:Pattern=`^(0?\.[0-9]*[1-9][0-9]*|1(\.0*)?)$`
+	// +optional
+	Intensity string `json:"intensity,omitempty"`
+
+	// How the replicas of a token range are repaired. The Reaper default is
+	// used when it is not set.
+	// +kubebuilder:validation:Enum=Sequential;Parallel;DatacenterAware
+	// +optional
+	Parallelism RepairParallelism `json:"parallelism,omitempty"`
+
+	// The number of days between two repairs of the keyspace. Defaults to 7.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	IntervalDays int32 `json:"intervalDays,omitempty"`
+}
+
+// GetIntervalDays returns the number of days between two repairs, defaulting
+// to 7
+func (s *ReaperRepairSchedule) GetIntervalDays() int32 {
+	if s.IntervalDays == 0 {
+		return defaultReaperScheduleIntervalDays
+	}
+	return s.IntervalDays
+}
+
+// GetDeploymentMode returns the deployment mode of Reaper, defaulting to
+// Sidecar
+func (r *ReaperConfig) GetDeploymentMode() ReaperDeploymentMode {
+	if r.DeploymentMode == "" {
+		return ReaperDeploymentModeSidecar
+	}
+	return r.DeploymentMode
+}
+
+// GetImagePullPolicy returns the pull policy of the Reaper image, defaulting
+// to IfNotPresent
+func (r *ReaperConfig) GetImagePullPolicy() corev1.PullPolicy {
+	if r.ImagePullPolicy == "" {
+		return defaultReaperImagePullPolicy
+	}
+	return r.ImagePullPolicy
+}
+
+// RepairType is how much of a node's token ranges a repair session covers
+type RepairType string
+
+const (
+	// RepairTypeFull repairs all of the token ranges of a node in one session
+	RepairTypeFull RepairType = "Full"
+
+	// RepairTypeSubrange repairs each token range of a node in a session of
+	// its own, which keeps the sessions short
+	RepairTypeSubrange RepairType = "Subrange"
+)
+
+// RepairParallelism is how the replicas of a token range are repaired
+type RepairParallelism string
+
+const (
+	RepairParallelismSequential      RepairParallelism = "Sequential"
+	RepairParallelismParallel        RepairParallelism = "Parallel"
+	RepairParallelismDatacenterAware RepairParallelism = "DatacenterAware"
+)
+
+type RepairConfig struct {
+	// The keyspaces to repair. Every keyspace except the node local system
+	// keyspaces is repaired when it is empty.
+	// +optional
+	Keyspaces []string `json:"keyspaces,omitempty"`
+
+	// When to repair, in cron format, e.g. "0 2 * * 0". Times are in UTC.
+	Schedule string `json:"schedule"`
+
+	// Whether a node repairs all of its token ranges at once ("Full") or one
+	// token range at a time ("Subrange"). Defaults to "Full".
+	// +kubebuilder:validation:Enum=Full;Subrange
+	// +optional
+	Type RepairType `json:"type,omitempty"`
+
+	// How the replicas of a token range are repaired. The server default is
+	// used when it is not set.
+	// +kubebuilder:validation:Enum=Sequential;Parallel;DatacenterAware
+	// +optional
+	Parallelism RepairParallelism `json:"parallelism,omitempty"`
+}
+
+// SystemKeyspacesRepairStatus is the progress of the repair of system_auth
+// on every node, after the replication of the system keyspaces was altered
+type SystemKeyspacesRepairStatus struct {
+	// The replication factor the system keyspaces were altered to
+	ReplicationFactor int32 `json:"replicationFactor"`
+
+	// The pods whose node has been repaired
+	// +optional
+	RepairedPods []string `json:"repairedPods,omitempty"`
+
+	// The pod whose node is being repaired
+	// +optional
+	PodName string `json:"podName,omitempty"`
+
+	// The management API job of the repair in progress
+	// +optional
+	JobID string `json:"jobId,omitempty"`
+}
+
+type KeyspaceRepairStatus struct {
+	// The keyspace name
+	Name string `json:"name"`
+	// When the keyspace was last repaired on every node of the datacenter
+	// without a failed session
+	LastCompleted metav1.Time `json:"lastCompleted"`
+}
+
+type RepairStatus struct {
+	// The scheduled time of the repair in progress, or of the last one
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// The keyspaces left to repair, starting with the one being repaired. It is
+	// empty when no repair is in progress.
+	// +optional
+	PendingKeyspaces []string `json:"pendingKeyspaces,omitempty"`
+
+	// The pod whose token ranges are being repaired
+	// +optional
+	PodName string `json:"podName,omitempty"`
+
+	// The position of the token range being repaired among the token ranges of
+	// the pod, with the "Subrange" type
+	// +optional
+	RangeIndex int32 `json:"rangeIndex,omitempty"`
+
+	// The management API job of the repair session in progress
+	// +optional
+	JobID string `json:"jobId,omitempty"`
+
+	// The sessions that failed while repairing the current keyspace
+	// +optional
+	FailedSessions int32 `json:"failedSessions,omitempty"`
+
+	// The last completed repair of each keyspace
+	// +optional
+	Keyspaces []KeyspaceRepairStatus `json:"keyspaces,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -378,16 +846,55 @@ func init() {
 	SchemeBuilder.Register(&CassandraDatacenter{}, &CassandraDatacenterList{})
 }
 
+// GetServiceAccount returns the service account of the pods, defaulting to
+// "default"
+func (dc *CassandraDatacenter) GetServiceAccount() string {
+	if dc.Spec.ServiceAccount == "" {
+		return defaultServiceAccount
+	}
+	return dc.Spec.ServiceAccount
+}
+
+// GetImageRegistry returns the registry that the default images of the
+// datacenter are pulled from: the one of the spec, or else the one of the
+// version catalog. It is empty when the images come from their own registries.
+func (dc *CassandraDatacenter) GetImageRegistry() string {
+	if dc.Spec.ImageRegistry != "" {
+		return dc.Spec.ImageRegistry
+	}
+	return images.GetCatalog().ImageRegistry
+}
+
+// ApplyImageRegistry returns a default image of the operator pulled from the
+// image registry of the datacenter
+func (dc *CassandraDatacenter) ApplyImageRegistry(image string) string {
+	return images.ReplaceRegistry(image, dc.GetImageRegistry())
+}
+
+// GetConfigBuilderImage returns the config builder image of the spec, or the
+// one of the version catalog for the server version
 func (dc *CassandraDatacenter) GetConfigBuilderImage() string {
-	var image string
-	if dc.Spec.ConfigBuilderImage != "" {
-		image = dc.Spec.ConfigBuilderImage
-	} else if baseImageOs := os.Getenv(EnvBaseImageOs); baseImageOs != "" {
-		image = ubi_defaultConfigBuilderImage
-	} else {
-		image = defaultConfigBuilderImage
+	if dc.isUserImage(DefaultConfigBuilderImageAnnotation, dc.Spec.ConfigBuilderImage) {
+		return dc.Spec.ConfigBuilderImage
 	}
-	return image
+	baseImageOs := os.Getenv(EnvBaseImageOs)
+	return images.GetCatalog().WithRegistry(dc.Spec.ImageRegistry).
+		ConfigBuilderImage(dc.Spec.ServerType, dc.Spec.ServerVersion, baseImageOs != "")
+}
+
+// GetReaperImage returns the Reaper image of the spec, defaulting to the
+// version of Reaper the operator was tested with
+func (dc *CassandraDatacenter) GetReaperImage() string {
+	if dc.Spec.Reaper != nil && dc.isUserImage(DefaultReaperImageAnnotation, dc.Spec.Reaper.Image) {
+		return dc.Spec.Reaper.Image
+	}
+	return dc.ApplyImageRegistry(defaultReaperImage)
+}
+
+// isUserImage tells whether an image of the spec was set by the user, rather
+// than left empty or written by the defaulting webhook
+func (dc *CassandraDatacenter) isUserImage(annotation string, image string) bool {
+	return image != "" && image != dc.Annotations[annotation]
 }
 
 // GetServerImage produces a fully qualified container image to pull
@@ -396,18 +903,19 @@ func (dc *CassandraDatacenter) GetConfigBuilderImage() string {
 // In the event that no valid image could be retrieved from the specified version,
 // an error is returned.
 func (dc *CassandraDatacenter) GetServerImage() (string, error) {
-	return makeImage(dc.Spec.ServerType, dc.Spec.ServerVersion, dc.Spec.ServerImage)
+	return makeImage(dc.Spec.ServerType, dc.Spec.ServerVersion, dc.Spec.ServerImage, dc.Spec.ImageRegistry)
 }
 
 // makeImage takes the server type/version and image from the spec,
 // and returns a docker pullable server container image
 // serverVersion should be a semver-like string
 // serverImage should be an empty string, or [hostname[:port]/][path/with/repo]:[Server container img tag]
-// If serverImage is empty, we attempt to find an appropriate container image based on the serverVersion
+// If serverImage is empty, we attempt to find an appropriate container image based on the serverVersion,
+// pulled from imageRegistry when it is set
 // In the event that no image is found, an error is returned
-func makeImage(serverType, serverVersion, serverImage string) (string, error) {
+func makeImage(serverType, serverVersion, serverImage, imageRegistry string) (string, error) {
 	if serverImage == "" {
-		return getImageForServerVersion(serverType, serverVersion)
+		return getImageForServerVersion(serverType, serverVersion, imageRegistry)
 	}
 	return serverImage, nil
 }
@@ -457,6 +965,119 @@ func (dc *CassandraDatacenter) SetCondition(condition DatacenterCondition) {
 	(&dc.Status).SetCondition(condition)
 }
 
+// GetRestartStrategy returns the restart strategy, defaulting to podDelete
+func (dc *CassandraDatacenter) GetRestartStrategy() RestartStrategy {
+	if dc.Spec.RestartStrategy == "" {
+		return RestartStrategyPodDelete
+	}
+	return dc.Spec.RestartStrategy
+}
+
+// getStorageConfigValues returns the directory settings of the optional
+// volumes of Spec.StorageConfig, keyed by their path in the config
+func (dc *CassandraDatacenter) getStorageConfigValues() map[string]interface{} {
+	storageConfig := dc.Spec.StorageConfig
+	values := map[string]interface{}{}
+	if storageConfig.CommitLogVolumeClaimSpec != nil {
+		values["cassandra-yaml.commitlog_directory"] = CommitLogMountPath
+	}
+	if storageConfig.HintsVolumeClaimSpec != nil {
+		values["cassandra-yaml.hints_directory"] = HintsMountPath
+	}
+	if storageConfig.SavedCachesVolumeClaimSpec != nil {
+		values["cassandra-yaml.saved_caches_directory"] = SavedCachesMountPath
+	}
+	return values
+}
+
+// getTLSConfigValues returns the cassandra.yaml settings that encrypt
+// traffic as Spec.TLS describes, keyed by their path in the config. The
+// passwords are left as placeholders, to be replaced in the pods.
+func (dc *CassandraDatacenter) getTLSConfigValues() map[string]interface{} {
+	tls := dc.Spec.TLS
+	if tls == nil {
+		return nil
+	}
+
+	keystorePassword := TLSPasswordPlaceholder
+	truststorePassword := TLSPasswordPlaceholder
+	internodeEncryption := tls.InternodeEncryption
+	if internodeEncryption == "" {
+		internodeEncryption = "all"
+	}
+
+	keystore := TLSKeystoreMountPath + "/keystore.jks"
+	truststore := TLSKeystoreMountPath + "/truststore.jks"
+
+	values := map[string]interface{}{
+		// The port the pods declare as tls-intra-node
+		"cassandra-yaml.ssl_storage_port":                               7001,
+		"cassandra-yaml.server_encryption_options.internode_encryption": internodeEncryption,
+		"cassandra-yaml.server_encryption_options.keystore":             keystore,
+		"cassandra-yaml.server_encryption_options.keystore_password":    keystorePassword,
+		"cassandra-yaml.server_encryption_options.truststore":           truststore,
+		"cassandra-yaml.server_encryption_options.truststore_password":  truststorePassword,
+		"cassandra-yaml.server_encryption_options.require_client_auth":  tls.RequireInternodeClientAuth,
+	}
+
+	// Cassandra 4.0 encrypts traffic on the storage port unless told to keep
+	// using the SSL storage port
+	if dc.Spec.ServerType == "cassandra" && strings.HasPrefix(dc.Spec.ServerVersion, "4.") {
+		values["cassandra-yaml.server_encryption_options.enable_legacy_ssl_storage_port"] = true
+	}
+
+	if tls.ClientEncryption != nil && tls.ClientEncryption.Enabled {
+		values["cassandra-yaml.client_encryption_options.enabled"] = true
+		values["cassandra-yaml.client_encryption_options.optional"] = tls.ClientEncryption.Optional
+		values["cassandra-yaml.client_encryption_options.keystore"] = keystore
+		values["cassandra-yaml.client_encryption_options.keystore_password"] = keystorePassword
+		values["cassandra-yaml.client_encryption_options.truststore"] = truststore
+		values["cassandra-yaml.client_encryption_options.truststore_password"] = truststorePassword
+		values["cassandra-yaml.client_encryption_options.require_client_auth"] = tls.ClientEncryption.RequireClientAuth
+	}
+
+	return values
+}
+
+// GetMaxUnavailablePerRack returns how many pods of a rack may be restarted
+// at the same time, defaulting to 1
+func (dc *CassandraDatacenter) GetMaxUnavailablePerRack() int {
+	if dc.Spec.MaxUnavailablePerRack < 1 {
+		return 1
+	}
+	return int(dc.Spec.MaxUnavailablePerRack)
+}
+
+// GetRackStatus returns the status entry for the given rack, or nil if there is none
+func (status *CassandraDatacenterStatus) GetRackStatus(rackName string) *RackStatus {
+	for i := range status.RackStatuses {
+		if status.RackStatuses[i].Name == rackName {
+			return &status.RackStatuses[i]
+		}
+	}
+	return nil
+}
+
+// SetRackStatus adds or replaces the status entry for a rack
+func (status *CassandraDatacenterStatus) SetRackStatus(rackStatus RackStatus) {
+	if existing := status.GetRackStatus(rackStatus.Name); existing != nil {
+		*existing = rackStatus
+		return
+	}
+	status.RackStatuses = append(status.RackStatuses, rackStatus)
+}
+
+// RemoveRackStatus drops the status entry for a rack
+func (status *CassandraDatacenterStatus) RemoveRackStatus(rackName string) {
+	rackStatuses := []RackStatus{}
+	for _, rackStatus := range status.RackStatuses {
+		if rackStatus.Name != rackName {
+			rackStatuses = append(rackStatuses, rackStatus)
+		}
+	}
+	status.RackStatuses = rackStatuses
+}
+
 // GetDatacenterLabels ...
 func (dc *CassandraDatacenter) GetDatacenterLabels() map[string]string {
 	labels := map[string]string{
@@ -562,6 +1183,24 @@ func (dc *CassandraDatacenter) GetConfigAsJSON() (string, error) {
 		}
 	}
 
+	// Spec.Config takes precedence over the settings of Spec.TLS, as merging
+	// both would turn the values into lists
+	for path, value := range dc.getTLSConfigValues() {
+		if !modelParsed.ExistsP(path) {
+			if _, err := modelParsed.SetP(value, path); err != nil {
+				return "", errors.Wrap(err, "Error setting Spec.TLS for CassandraDatacenter resource")
+			}
+		}
+	}
+
+	for path, value := range dc.getStorageConfigValues() {
+		if !modelParsed.ExistsP(path) {
+			if _, err := modelParsed.SetP(value, path); err != nil {
+				return "", errors.Wrap(err, "Error setting Spec.StorageConfig for CassandraDatacenter resource")
+			}
+		}
+	}
+
 	return modelParsed.String(), nil
 }
 
@@ -10,6 +10,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/datastax/cass-operator/operator/pkg/images"
 )
 
 func Test_makeImage(t *testing.T) {
@@ -17,6 +19,7 @@ func Test_makeImage(t *testing.T) {
 		serverType    string
 		serverImage   string
 		serverVersion string
+		imageRegistry string
 	}
 	tests := []struct {
 		name      string
@@ -74,10 +77,32 @@ func Test_makeImage(t *testing.T) {
 			want:      "datastax/dse-server:6.8.1",
 			errString: "",
 		},
+		{
+			name: "test image registry",
+			args: args{
+				serverImage:   "",
+				serverType:    "cassandra",
+				serverVersion: "3.11.6",
+				imageRegistry: "registry.example.com:5000",
+			},
+			want:      "registry.example.com:5000/datastax/cassandra-mgmtapi-3_11_6:v0.1.5",
+			errString: "",
+		},
+		{
+			name: "test image registry with private repo server",
+			args: args{
+				serverImage:   "datastax.jfrog.io/secret-debug-image/dse-server:6.8.0-test123",
+				serverType:    "dse",
+				serverVersion: "6.8.0",
+				imageRegistry: "registry.example.com:5000",
+			},
+			want:      "datastax.jfrog.io/secret-debug-image/dse-server:6.8.0-test123",
+			errString: "",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := makeImage(tt.args.serverType, tt.args.serverVersion, tt.args.serverImage)
+			got, err := makeImage(tt.args.serverType, tt.args.serverVersion, tt.args.serverImage, tt.args.imageRegistry)
 			if got != tt.want {
 				t.Errorf("makeImage() = %v, want %v", got, tt.want)
 			}
@@ -94,6 +119,69 @@ func Test_makeImage(t *testing.T) {
 	}
 }
 
+func Test_makeImage_VersionCatalog(t *testing.T) {
+	catalog := images.DefaultCatalog()
+	catalog.ImageRegistry = "registry.example.com"
+	catalog.Servers["dse"]["6.8.2"] = images.ServerVersion{
+		Variants:      images.Variants{Image: "datastax/dse-server:6.8.2"},
+		ConfigBuilder: &images.Variants{Image: "datastax/cass-config-builder:1.0.3"},
+	}
+	images.SetCatalog(catalog)
+	defer images.SetCatalog(images.DefaultCatalog())
+
+	got, err := makeImage("dse", "6.8.2", "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "registry.example.com/datastax/dse-server:6.8.2", got)
+
+	got, err = makeImage("dse", "6.8.2", "example/dse-server:custom", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "example/dse-server:custom", got, "Should not apply the registry to an explicit image")
+
+	dc := &CassandraDatacenter{
+		Spec: CassandraDatacenterSpec{
+			ServerType:    "dse",
+			ServerVersion: "6.8.2",
+		},
+	}
+	assert.Equal(t, "registry.example.com/datastax/cass-config-builder:1.0.3", dc.GetConfigBuilderImage())
+
+	dc.Spec.ServerVersion = "6.8.1"
+	assert.Equal(t, "registry.example.com/datastax/cass-config-builder:1.0.1", dc.GetConfigBuilderImage())
+}
+
+func TestCassandraDatacenter_ImageRegistry(t *testing.T) {
+	dc := &CassandraDatacenter{
+		Spec: CassandraDatacenterSpec{
+			ServerType:    "dse",
+			ServerVersion: "6.8.0",
+			Reaper:        &ReaperConfig{Enabled: true},
+		},
+	}
+	assert.Equal(t, "busybox", dc.ApplyImageRegistry("busybox"))
+	assert.Equal(t, "thelastpickle/cassandra-reaper:2.0.5", dc.GetReaperImage())
+
+	catalog := images.DefaultCatalog()
+	catalog.ImageRegistry = "registry.example.com"
+	images.SetCatalog(catalog)
+	defer images.SetCatalog(images.DefaultCatalog())
+
+	assert.Equal(t, "registry.example.com/busybox", dc.ApplyImageRegistry("busybox"))
+	assert.Equal(t, "registry.example.com/thelastpickle/cassandra-reaper:2.0.5", dc.GetReaperImage())
+
+	dc.Spec.ImageRegistry = "mirror.example.com:5000/dockerhub"
+	serverImage, err := dc.GetServerImage()
+	assert.NoError(t, err)
+	assert.Equal(t, "mirror.example.com:5000/dockerhub/datastax/dse-server:6.8.0", serverImage)
+	assert.Equal(t, "mirror.example.com:5000/dockerhub/datastax/cass-config-builder:1.0.1", dc.GetConfigBuilderImage())
+	assert.Equal(t, "mirror.example.com:5000/dockerhub/busybox", dc.ApplyImageRegistry("busybox"))
+	assert.Equal(t, "mirror.example.com:5000/dockerhub/thelastpickle/cassandra-reaper:2.0.5", dc.GetReaperImage())
+
+	dc.Spec.Reaper.Image = "example/cassandra-reaper:custom"
+	dc.Spec.ConfigBuilderImage = "example/cass-config-builder:custom"
+	assert.Equal(t, "example/cassandra-reaper:custom", dc.GetReaperImage(), "Should not apply the registry to an explicit image")
+	assert.Equal(t, "example/cass-config-builder:custom", dc.GetConfigBuilderImage(), "Should not apply the registry to an explicit image")
+}
+
 func TestCassandraDatacenter_GetServerImage(t *testing.T) {
 	type fields struct {
 		TypeMeta   metav1.TypeMeta
@@ -191,6 +279,68 @@ func Test_GenerateBaseConfigString(t *testing.T) {
 			want:      "",
 			errString: "Error parsing Spec.Config for CassandraDatacenter resource: invalid character ':' after top-level value",
 		},
+		{
+			name: "TLS Test",
+			dc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					ClusterName:   "exampleCluster",
+					ServerType:    "cassandra",
+					ServerVersion: "3.11.6",
+					TLS: &TLSConfig{
+						KeystoreSecretName: "exampleCluster-keystores",
+						ClientEncryption: &TLSClientEncryptionConfig{
+							Enabled:           true,
+							RequireClientAuth: true,
+						},
+					},
+				},
+			},
+			want:      `{"cassandra-yaml":{"client_encryption_options":{"enabled":true,"keystore":"/etc/encryption/keystore.jks","keystore_password":"from-keystore-secret","optional":false,"require_client_auth":true,"truststore":"/etc/encryption/truststore.jks","truststore_password":"from-keystore-secret"},"server_encryption_options":{"internode_encryption":"all","keystore":"/etc/encryption/keystore.jks","keystore_password":"from-keystore-secret","require_client_auth":false,"truststore":"/etc/encryption/truststore.jks","truststore_password":"from-keystore-secret"},"ssl_storage_port":7001},"cluster-info":{"name":"exampleCluster","seeds":"exampleCluster-seed-service"},"datacenter-info":{"graph-enabled":0,"name":"exampleDC","solr-enabled":0,"spark-enabled":0}}`,
+			errString: "",
+		},
+		{
+			name: "TLS Test with Spec.Config override",
+			dc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					ClusterName:   "exampleCluster",
+					ServerType:    "cassandra",
+					ServerVersion: "4.0.0",
+					Config:        []byte("{\"cassandra-yaml\":{\"server_encryption_options\":{\"internode_encryption\":\"dc\"}}}"),
+					TLS: &TLSConfig{
+						KeystoreSecretName: "exampleCluster-keystores",
+						// Spec.Config takes precedence
+						InternodeEncryption: "all",
+					},
+				},
+			},
+			want:      `{"cassandra-yaml":{"server_encryption_options":{"enable_legacy_ssl_storage_port":true,"internode_encryption":"dc","keystore":"/etc/encryption/keystore.jks","keystore_password":"from-keystore-secret","require_client_auth":false,"truststore":"/etc/encryption/truststore.jks","truststore_password":"from-keystore-secret"},"ssl_storage_port":7001},"cluster-info":{"name":"exampleCluster","seeds":"exampleCluster-seed-service"},"datacenter-info":{"graph-enabled":0,"name":"exampleDC","solr-enabled":0,"spark-enabled":0}}`,
+			errString: "",
+		},
+		{
+			name: "Additional volumes Test with Spec.Config override",
+			dc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					ClusterName: "exampleCluster",
+					Config:      []byte("{\"cassandra-yaml\":{\"hints_directory\":\"/var/lib/cassandra/data/hints\"}}"),
+					StorageConfig: StorageConfig{
+						CommitLogVolumeClaimSpec: &corev1.PersistentVolumeClaimSpec{},
+						// Spec.Config takes precedence
+						HintsVolumeClaimSpec: &corev1.PersistentVolumeClaimSpec{},
+					},
+				},
+			},
+			want:      `{"cassandra-yaml":{"commitlog_directory":"/var/lib/cassandra/commitlog","hints_directory":"/var/lib/cassandra/data/hints"},"cluster-info":{"name":"exampleCluster","seeds":"exampleCluster-seed-service"},"datacenter-info":{"graph-enabled":0,"name":"exampleDC","solr-enabled":0,"spark-enabled":0}}`,
+			errString: "",
+		},
 	}
 
 	for _, tt := range tests {
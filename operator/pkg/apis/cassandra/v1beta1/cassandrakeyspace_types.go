@@ -0,0 +1,89 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package v1beta1
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CassandraKeyspaceSpec defines the desired state of CassandraKeyspace
+// +k8s:openapi-gen=true
+type CassandraKeyspaceSpec struct {
+	// The name of the CassandraDatacenter to manage the keyspace through. The
+	// keyspace belongs to the whole cluster of that datacenter.
+	CassandraDatacenter string `json:"cassandraDatacenter"`
+
+	// The name of the keyspace in Cassandra. Defaults to the name of the
+	// resource, with dashes replaced by underscores.
+	// +kubebuilder:validation:Pattern=`^\w{1,48}$`
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// The replication factor of the keyspace in each datacenter, keyed by
+	// datacenter name. The keyspace uses NetworkTopologyStrategy.
+	// +kubebuilder:validation:MinProperties=1
+	Replication map[string]int32 `json:"replication"`
+}
+
+// CassandraKeyspaceStatus defines the observed state of CassandraKeyspace
+// +k8s:openapi-gen=true
+type CassandraKeyspaceStatus struct {
+	// The replication factor of the keyspace in each datacenter, as last
+	// read from or set in Cassandra
+	// +optional
+	Replication map[string]int32 `json:"replication,omitempty"`
+
+	// The generation of the spec the keyspace was last created or altered
+	// to match
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The last time the replication of the keyspace was found to differ
+	// from a spec it had already been set to, e.g. because the keyspace was
+	// altered outside of the operator. The operator alters it back.
+	// +optional
+	LastDriftTime *metav1.Time `json:"lastDriftTime,omitempty"`
+
+	// How the replication of the keyspace differed from the spec
+	// +optional
+	LastDriftMessage string `json:"lastDriftMessage,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CassandraKeyspace is the Schema for the cassandrakeyspaces API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=cassandrakeyspaces,scope=Namespaced,shortName=casskeyspace;casskeyspaces
+type CassandraKeyspace struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CassandraKeyspaceSpec   `json:"spec,omitempty"`
+	Status CassandraKeyspaceStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CassandraKeyspaceList contains a list of CassandraKeyspace
+type CassandraKeyspaceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CassandraKeyspace `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CassandraKeyspace{}, &CassandraKeyspaceList{})
+}
+
+// GetKeyspaceName returns the name of the keyspace in Cassandra
+func (keyspace *CassandraKeyspace) GetKeyspaceName() string {
+	if keyspace.Spec.Name != "" {
+		return keyspace.Spec.Name
+	}
+	return strings.Replace(keyspace.Name, "-", "_", -1)
+}
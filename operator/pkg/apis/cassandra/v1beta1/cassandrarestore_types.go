@@ -0,0 +1,90 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CassandraRestoreSpec defines the desired state of CassandraRestore
+// +k8s:openapi-gen=true
+type CassandraRestoreSpec struct {
+	// The name of the CassandraDatacenter to restore. The restore is done in
+	// place, so the datacenter must have the same racks and size as when the
+	// backup was taken. It will be stopped while the backup is staged.
+	CassandraDatacenter string `json:"cassandraDatacenter"`
+
+	// The name of a completed CassandraBackup of the datacenter
+	Backup string `json:"backup"`
+
+	// Image used by the Jobs that download the backup. Defaults to the image
+	// of the backup.
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+// CassandraRestoreStatus defines the observed state of CassandraRestore
+// +k8s:openapi-gen=true
+type CassandraRestoreStatus struct {
+	Conditions BackupConditions `json:"conditions,omitempty"`
+
+	// +optional
+	StartTime metav1.Time `json:"startTime,omitempty"`
+
+	// +optional
+	FinishTime metav1.Time `json:"finishTime,omitempty"`
+
+	// Progress of each pod that is being restored, by pod name
+	// +optional
+	Pods map[string]PodBackupStatus `json:"pods,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CassandraRestore is the Schema for the cassandrarestores API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=cassandrarestores,scope=Namespaced,shortName=cassrestore;cassrestores
+type CassandraRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CassandraRestoreSpec   `json:"spec,omitempty"`
+	Status CassandraRestoreStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CassandraRestoreList contains a list of CassandraRestore
+type CassandraRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CassandraRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CassandraRestore{}, &CassandraRestoreList{})
+}
+
+func (restore *CassandraRestore) IsFinished() bool {
+	return restore.Status.Conditions.GetConditionStatus(BackupComplete) == corev1.ConditionTrue ||
+		restore.Status.Conditions.GetConditionStatus(BackupFailed) == corev1.ConditionTrue
+}
+
+// IsStaging returns true while the backup is being copied onto the volumes of
+// the datacenter. Cassandra must not be started on a pod until its data has
+// been staged.
+func (restore *CassandraRestore) IsStaging() bool {
+	return !restore.IsFinished() &&
+		restore.Status.Conditions.GetConditionStatus(BackupStaging) == corev1.ConditionTrue
+}
+
+// IsPodStaged returns true once the backup has been copied onto the volumes
+// of the given pod
+func (restore *CassandraRestore) IsPodStaged(podName string) bool {
+	podStatus, ok := restore.Status.Pods[podName]
+	return ok && podStatus.Conditions.GetConditionStatus(BackupStaged) == corev1.ConditionTrue
+}
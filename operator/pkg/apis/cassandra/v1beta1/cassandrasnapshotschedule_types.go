@@ -0,0 +1,131 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// snapshotTimeFormat is appended to the name of a schedule to name the
+	// snapshots it takes, so the time of a snapshot can be read back from
+	// its name on any node
+	snapshotTimeFormat = "20060102-150405"
+)
+
+// SnapshotRetention describes which of the snapshots taken by a schedule are
+// kept. When both fields are set, a snapshot is cleared as soon as either of
+// them says so.
+type SnapshotRetention struct {
+	// The number of most recent snapshots to keep
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	KeepLast *int32 `json:"keepLast,omitempty"`
+
+	// Snapshots older than this are cleared, e.g. "168h"
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+}
+
+// CassandraSnapshotScheduleSpec defines the desired state of CassandraSnapshotSchedule
+// +k8s:openapi-gen=true
+type CassandraSnapshotScheduleSpec struct {
+	// The name of the CassandraDatacenter to take snapshots of
+	CassandraDatacenter string `json:"cassandraDatacenter"`
+
+	// The schedule in cron format, e.g. "0 2 * * *". Times are in UTC.
+	Schedule string `json:"schedule"`
+
+	// Keyspaces to snapshot. All keyspaces are included when empty.
+	// +optional
+	Keyspaces []string `json:"keyspaces,omitempty"`
+
+	// Which snapshots to keep. Snapshots are never cleared when empty.
+	// +optional
+	Retention SnapshotRetention `json:"retention,omitempty"`
+
+	// Stops new snapshots from being taken. Retention is not applied while
+	// suspended either.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// CassandraSnapshotScheduleStatus defines the observed state of CassandraSnapshotSchedule
+// +k8s:openapi-gen=true
+type CassandraSnapshotScheduleStatus struct {
+	// The time the last snapshot was scheduled for
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// The time of the last snapshot that was taken on every started node
+	// +optional
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+
+	// The time of the last snapshot that could not be taken on every started
+	// node
+	// +optional
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty"`
+
+	// Why the last failed snapshot failed
+	// +optional
+	LastFailureMessage string `json:"lastFailureMessage,omitempty"`
+
+	// Names of the snapshots of this schedule that are kept on the nodes,
+	// most recent first
+	// +optional
+	Snapshots []string `json:"snapshots,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CassandraSnapshotSchedule is the Schema for the cassandrasnapshotschedules API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=cassandrasnapshotschedules,scope=Namespaced,shortName=casssnapshotschedule;casssnapshotschedules
+type CassandraSnapshotSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CassandraSnapshotScheduleSpec   `json:"spec,omitempty"`
+	Status CassandraSnapshotScheduleStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CassandraSnapshotScheduleList contains a list of CassandraSnapshotSchedule
+type CassandraSnapshotScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CassandraSnapshotSchedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CassandraSnapshotSchedule{}, &CassandraSnapshotScheduleList{})
+}
+
+// GetSnapshotName returns the name of the snapshot scheduled at the given time
+func (schedule *CassandraSnapshotSchedule) GetSnapshotName(scheduledTime time.Time) string {
+	return fmt.Sprintf("%s-%s", schedule.Name, scheduledTime.UTC().Format(snapshotTimeFormat))
+}
+
+// ParseSnapshotTime returns the time a snapshot of this schedule was
+// scheduled at. The second return value is false when the snapshot was not
+// taken by this schedule.
+func (schedule *CassandraSnapshotSchedule) ParseSnapshotTime(snapshotName string) (time.Time, bool) {
+	prefix := schedule.Name + "-"
+	if !strings.HasPrefix(snapshotName, prefix) {
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse(snapshotTimeFormat, strings.TrimPrefix(snapshotName, prefix))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
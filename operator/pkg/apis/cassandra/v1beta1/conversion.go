@@ -0,0 +1,8 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package v1beta1
+
+// Hub marks v1beta1, the storage version, as the version that the other
+// versions of CassandraDatacenter are converted to and from
+func (*CassandraDatacenter) Hub() {}
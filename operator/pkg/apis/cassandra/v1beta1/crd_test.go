@@ -0,0 +1,105 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package v1beta1
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/pruning"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+const crdPath = "../../../../deploy/crds/cassandra.datastax.com_cassandradatacenters_crd.yaml"
+
+func loadCRD(t *testing.T) *apiextensionsv1beta1.CustomResourceDefinition {
+	contents, err := ioutil.ReadFile(crdPath)
+	if err != nil {
+		t.Fatalf("reading the CRD: %v", err)
+	}
+	crd := &apiextensionsv1beta1.CustomResourceDefinition{}
+	if err = yaml.Unmarshal(contents, crd); err != nil {
+		t.Fatalf("parsing the CRD: %v", err)
+	}
+	return crd
+}
+
+func Test_CRD_Version(t *testing.T) {
+	crd := loadCRD(t)
+
+	// The API server requires the version of the spec to be listed first
+	if crd.Spec.Version != crd.Spec.Versions[0].Name {
+		t.Errorf("CRD version = %v, want the first of the versions, %v", crd.Spec.Version, crd.Spec.Versions[0].Name)
+	}
+}
+
+func Test_CRD_PruningKeepsConfigAndPodTemplateMetadata(t *testing.T) {
+	crd := loadCRD(t)
+
+	var schema *apiextensionsv1beta1.JSONSchemaProps
+	for _, version := range crd.Spec.Versions {
+		if version.Name == SchemeGroupVersion.Version {
+			schema = version.Schema.OpenAPIV3Schema
+		}
+	}
+	if schema == nil {
+		t.Fatalf("no schema for version %v in the CRD", SchemeGroupVersion.Version)
+	}
+
+	internal := &apiextensions.JSONSchemaProps{}
+	if err := apiextensionsv1beta1.Convert_v1beta1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(schema, internal, nil); err != nil {
+		t.Fatalf("converting the schema: %v", err)
+	}
+	structural, err := structuralschema.NewStructural(internal)
+	if err != nil {
+		t.Fatalf("the schema is not structural: %v", err)
+	}
+
+	obj := map[string]interface{}{
+		"apiVersion": SchemeGroupVersion.String(),
+		"kind":       "CassandraDatacenter",
+		"metadata": map[string]interface{}{
+			"name": "exampleDC",
+		},
+		"spec": map[string]interface{}{
+			"clusterName":   "exampleCluster",
+			"serverType":    "cassandra",
+			"serverVersion": "3.11.6",
+			"size":          int64(3),
+			"config": map[string]interface{}{
+				"cassandra-yaml": map[string]interface{}{
+					"num_tokens": int64(32),
+				},
+				"jvm-options": map[string]interface{}{
+					"initial_heap_size": "800M",
+				},
+			},
+			"podTemplateSpec": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"labels": map[string]interface{}{
+						"team": "storage",
+					},
+					"annotations": map[string]interface{}{
+						"example.com/owner": "storage",
+					},
+				},
+				"spec": map[string]interface{}{
+					"containers": []interface{}{},
+				},
+			},
+		},
+	}
+	want := runtime.DeepCopyJSON(obj)
+
+	pruning.Prune(obj, structural, true)
+
+	if !reflect.DeepEqual(want, obj) {
+		t.Errorf("pruned object = %v, want %v", obj, want)
+	}
+}
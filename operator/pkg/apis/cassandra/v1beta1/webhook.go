@@ -8,9 +8,15 @@ import (
 	"fmt"
 	"reflect"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/datastax/cass-operator/operator/internal/cron"
+	"github.com/datastax/cass-operator/operator/pkg/images"
+	"github.com/datastax/cass-operator/operator/pkg/serverconfig"
 )
 
 var log = logf.Log.WithName("api")
@@ -29,19 +35,9 @@ func attemptedTo(action string, actionStrArgs ...interface{}) error {
 func ValidateSingleDatacenter(dc CassandraDatacenter) error {
 	// Ensure serverVersion and serverType are compatible
 
-	var err error
-	if dc.Spec.ServerType == "dse" {
-		switch dc.Spec.ServerVersion {
-		case "6.8.0":
-			err = nil
-		case "6.8.1":
-			err = nil
-		default:
-			err = attemptedTo("use unsupported DSE version '%s'", dc.Spec.ServerVersion)
-		}
-	}
-	if err != nil {
-		return err
+	catalog := images.GetCatalog()
+	if dc.Spec.ServerType == "dse" && !catalog.IsSupported(dc.Spec.ServerType, dc.Spec.ServerVersion) {
+		return attemptedTo("use unsupported DSE version '%s'", dc.Spec.ServerVersion)
 	}
 
 	if dc.Spec.ServerType == "cassandra" && dc.Spec.DseWorkloads != nil {
@@ -50,18 +46,29 @@ func ValidateSingleDatacenter(dc CassandraDatacenter) error {
 		}
 	}
 
-	if dc.Spec.ServerType == "cassandra" {
-		switch dc.Spec.ServerVersion {
-		case "3.11.6":
-			err = nil
-		case "4.0.0":
-			err = nil
-		default:
-			err = attemptedTo("use unsupported Cassandra version '%s'", dc.Spec.ServerVersion)
+	if dc.Spec.Repair != nil {
+		if _, err := cron.Parse(dc.Spec.Repair.Schedule); err != nil {
+			return attemptedTo("use invalid repair schedule: %s", err.Error())
 		}
 	}
 
-	return err
+	// Remote JMX is only opened to a Reaper Deployment with credentials
+	if reaper := dc.Spec.Reaper; reaper != nil && reaper.Enabled &&
+		reaper.GetDeploymentMode() == ReaperDeploymentModeDeployment && reaper.JmxSecretName == "" {
+		return attemptedTo("deploy Reaper as a Deployment without jmxSecretName")
+	}
+
+	if dc.Spec.ServerType == "cassandra" && !catalog.IsSupported(dc.Spec.ServerType, dc.Spec.ServerVersion) {
+		return attemptedTo("use unsupported Cassandra version '%s'", dc.Spec.ServerVersion)
+	}
+
+	memoryLimit := dc.Spec.Resources.Limits.Memory().Value()
+	configErrs := serverconfig.ValidateConfig(dc.Spec.ServerType, dc.Spec.ServerVersion, dc.Spec.Config, memoryLimit, field.NewPath("spec", "config"))
+	if len(configErrs) > 0 {
+		return attemptedTo("use invalid config: %s", configErrs.ToAggregate().Error())
+	}
+
+	return nil
 }
 
 // Ensure that no values are improperly set
@@ -79,26 +86,33 @@ func ValidateDatacenterFieldChanges(oldDc CassandraDatacenter, newDc CassandraDa
 		return attemptedTo("change superuserSecretName")
 	}
 
-	if oldDc.Spec.ServiceAccount != newDc.Spec.ServiceAccount {
+	if oldDc.GetServiceAccount() != newDc.GetServiceAccount() {
 		return attemptedTo("change serviceAccount")
 	}
 
-	// StorageConfig changes are disallowed
-	if !reflect.DeepEqual(oldDc.Spec.StorageConfig, newDc.Spec.StorageConfig) {
-		return attemptedTo("change storageConfig")
+	if err := validateStorageConfigChanges(oldDc.Spec.StorageConfig, newDc.Spec.StorageConfig); err != nil {
+		return err
 	}
 
 	// Topology changes - Racks
 	// - Rack Name and Zone changes are disallowed.
-	// - Removing racks is not supported.
+	// - Racks can be removed, but not while adding or renaming other racks.
 	// - Reordering the rack list is not supported.
 	// - Any new racks must be added to the end of the current rack list.
 
 	oldRacks := oldDc.GetRacks()
 	newRacks := newDc.GetRacks()
 
+	// Scaling down is done by decommissioning nodes, but every rack must keep
+	// at least one node.
+	if newDc.Spec.Size < oldDc.Spec.Size && int(newDc.Spec.Size) < len(newRacks) {
+		return attemptedTo("decrease size to %d, which is fewer than the number of racks (%d)",
+			newDc.Spec.Size,
+			len(newRacks))
+	}
+
 	if len(oldRacks) > len(newRacks) {
-		return attemptedTo("remove rack")
+		return validateRackRemoval(oldRacks, newRacks)
 	}
 
 	newRackCount := len(newRacks) - len(oldRacks)
@@ -138,6 +152,106 @@ func ValidateDatacenterFieldChanges(oldDc CassandraDatacenter, newDc CassandraDa
 	return nil
 }
 
+// validateStorageConfigChanges only allows changes to what happens to the
+// volumes when the datacenter is deleted, and increases of the storage
+// request, which the operator expands the existing volumes to
+func validateStorageConfigChanges(oldStorageConfig StorageConfig, newStorageConfig StorageConfig) error {
+	oldStorageConfig.RetentionPolicy = newStorageConfig.RetentionPolicy
+	oldStorageConfig.VolumeSnapshotClassName = newStorageConfig.VolumeSnapshotClassName
+
+	oldClaimSpec := oldStorageConfig.CassandraDataVolumeClaimSpec
+	newClaimSpec := newStorageConfig.CassandraDataVolumeClaimSpec
+	if oldClaimSpec != nil && newClaimSpec != nil {
+		oldSize, oldFound := oldClaimSpec.Resources.Requests[corev1.ResourceStorage]
+		newSize, newFound := newClaimSpec.Resources.Requests[corev1.ResourceStorage]
+		if oldFound && newFound && !newSize.Equal(oldSize) {
+			if newSize.Cmp(oldSize) < 0 {
+				return attemptedTo("decrease storage request from %s to %s", oldSize.String(), newSize.String())
+			}
+
+			oldClaimSpec = oldClaimSpec.DeepCopy()
+			oldClaimSpec.Resources.Requests[corev1.ResourceStorage] = newSize
+			oldStorageConfig.CassandraDataVolumeClaimSpec = oldClaimSpec
+		}
+	}
+
+	if !reflect.DeepEqual(oldStorageConfig, newStorageConfig) {
+		return attemptedTo("change storageConfig")
+	}
+	return nil
+}
+
+// validateRackRemoval makes sure that the remaining racks are unchanged and
+// still in the same order
+func validateRackRemoval(oldRacks []Rack, newRacks []Rack) error {
+	newIdx := 0
+	for _, oldRack := range oldRacks {
+		if newIdx >= len(newRacks) || oldRack.Name != newRacks[newIdx].Name {
+			// this rack is being removed
+			continue
+		}
+
+		newRack := newRacks[newIdx]
+		if oldRack.Zone != newRack.Zone {
+			return attemptedTo("change rack zone from '%s' to '%s'",
+				oldRack.Zone,
+				newRack.Zone)
+		}
+		newIdx++
+	}
+
+	if newIdx < len(newRacks) {
+		return attemptedTo("add or rename rack '%s' while removing racks", newRacks[newIdx].Name)
+	}
+
+	return nil
+}
+
+// +kubebuilder:webhook:path=/mutate-cassandra-datastax-com-v1beta1-cassandradatacenter,mutating=true,failurePolicy=ignore,groups=cassandra.datastax.com,resources=cassandradatacenters,verbs=create;update,versions=v1beta1,name=mutate-cassandradatacenter-webhook
+var _ webhook.Defaulter = &CassandraDatacenter{}
+
+// Default writes the defaults that the operator would otherwise pick at
+// runtime into the spec, so that the stored spec shows what the operator does
+func (dc *CassandraDatacenter) Default() {
+	log.Info("Defaulting webhook called")
+
+	if len(dc.Spec.Racks) == 0 {
+		dc.Spec.Racks = dc.GetRacks()
+	}
+
+	if dc.Spec.ServiceAccount == "" {
+		dc.Spec.ServiceAccount = dc.GetServiceAccount()
+	}
+
+	// The images written here are resolved again on every update, so that
+	// they follow changes of the server version and of the image registry
+	dc.Spec.ConfigBuilderImage = dc.defaultImage(DefaultConfigBuilderImageAnnotation,
+		dc.Spec.ConfigBuilderImage, dc.GetConfigBuilderImage())
+
+	if reaper := dc.Spec.Reaper; reaper != nil {
+		reaper.Image = dc.defaultImage(DefaultReaperImageAnnotation, reaper.Image, dc.GetReaperImage())
+		reaper.ImagePullPolicy = reaper.GetImagePullPolicy()
+		reaper.DeploymentMode = reaper.GetDeploymentMode()
+		for idx := range reaper.Schedules {
+			reaper.Schedules[idx].IntervalDays = reaper.Schedules[idx].GetIntervalDays()
+		}
+	}
+}
+
+// defaultImage returns the image to store in the spec, given the image the
+// spec holds and the resolved one, and records whether it was defaulted
+func (dc *CassandraDatacenter) defaultImage(annotation string, image string, resolved string) string {
+	if dc.isUserImage(annotation, image) {
+		delete(dc.Annotations, annotation)
+		return image
+	}
+	if dc.Annotations == nil {
+		dc.Annotations = map[string]string{}
+	}
+	dc.Annotations[annotation] = resolved
+	return resolved
+}
+
 // +kubebuilder:webhook:path=/validate-cassandradatacenter,mutating=false,failurePolicy=ignore,groups=cassandra.datastax.com,resources=cassandradatacenters,verbs=create;update,versions=v1beta1,name=validate-cassandradatacenter-webhook
 var _ webhook.Validator = &CassandraDatacenter{}
 
@@ -4,6 +4,8 @@
 package v1beta1
 
 import (
+	"os"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -11,6 +13,8 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/datastax/cass-operator/operator/pkg/images"
 )
 
 func Test_ValidateSingleDatacenter(t *testing.T) {
@@ -129,6 +133,137 @@ func Test_ValidateSingleDatacenter(t *testing.T) {
 			},
 			errString: "",
 		},
+		{
+			name: "Repair schedule invalid",
+			dc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					ServerType:    "dse",
+					ServerVersion: "6.8.1",
+					Repair: &RepairConfig{
+						Schedule: "0 2 * *",
+					},
+				},
+			},
+			errString: `use invalid repair schedule: expected 5 fields in cron schedule "0 2 * *", found 4`,
+		},
+		{
+			name: "Cassandra config valid",
+			dc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					ServerType:    "cassandra",
+					ServerVersion: "3.11.6",
+					Config:        []byte(`{"cassandra-yaml":{"num_tokens":8},"jvm-options":{"max_heap_size":"800M"}}`),
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("2Gi"),
+						},
+					},
+				},
+			},
+			errString: "",
+		},
+		{
+			name: "Cassandra config unknown key",
+			dc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					ServerType:    "cassandra",
+					ServerVersion: "3.11.6",
+					Config:        []byte(`{"cassandra-yaml":{"num_token":8}}`),
+				},
+			},
+			errString: "use invalid config: spec.config.cassandra-yaml.num_token: Invalid value: 8: is not a known setting",
+		},
+		{
+			name: "Cassandra config wrong type",
+			dc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					ServerType:    "cassandra",
+					ServerVersion: "4.0.0",
+					Config:        []byte(`{"cassandra-yaml":{"num_tokens":"many"}}`),
+				},
+			},
+			errString: `use invalid config: spec.config.cassandra-yaml.num_tokens: Invalid value: "many": must be of type integer`,
+		},
+		{
+			name: "Cassandra config heap bigger than memory limit",
+			dc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					ServerType:    "cassandra",
+					ServerVersion: "4.0.0",
+					Config:        []byte(`{"jvm-server-options":{"max_heap_size":"4G"}}`),
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("2Gi"),
+						},
+					},
+				},
+			},
+			errString: `use invalid config: spec.config.jvm-server-options.max_heap_size: Invalid value: "4G": must not exceed the memory limit of 2147483648 bytes`,
+		},
+		{
+			name: "Reaper Deployment without JMX credentials",
+			dc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					ServerType:    "cassandra",
+					ServerVersion: "3.11.6",
+					Reaper: &ReaperConfig{
+						Enabled:        true,
+						DeploymentMode: ReaperDeploymentModeDeployment,
+					},
+				},
+			},
+			errString: "deploy Reaper as a Deployment without jmxSecretName",
+		},
+		{
+			name: "Reaper Deployment with JMX credentials",
+			dc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					ServerType:    "cassandra",
+					ServerVersion: "3.11.6",
+					Reaper: &ReaperConfig{
+						Enabled:        true,
+						DeploymentMode: ReaperDeploymentModeDeployment,
+						JmxSecretName:  "reaper-jmx",
+					},
+				},
+			},
+			errString: "",
+		},
+		{
+			name: "Dse config with DSE settings valid",
+			dc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					ServerType:    "dse",
+					ServerVersion: "6.8.1",
+					Config:        []byte(`{"dse-yaml":{"authorization_options":{"enabled":true}},"cassandra-yaml":{"num_tokens":8}}`),
+				},
+			},
+			errString: "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -147,6 +282,46 @@ func Test_ValidateSingleDatacenter(t *testing.T) {
 	}
 }
 
+func Test_ValidateSingleDatacenter_VersionCatalog(t *testing.T) {
+	catalog := images.DefaultCatalog()
+	catalog.Servers["cassandra"]["3.11.7"] = images.ServerVersion{
+		Variants: images.Variants{Image: "datastax/cassandra-mgmtapi-3_11_7:v0.1.13"},
+	}
+	delete(catalog.Servers["dse"], "6.8.0")
+	images.SetCatalog(catalog)
+	defer images.SetCatalog(images.DefaultCatalog())
+
+	tests := []struct {
+		serverType    string
+		serverVersion string
+		errString     string
+	}{
+		{serverType: "cassandra", serverVersion: "3.11.7", errString: ""},
+		{serverType: "cassandra", serverVersion: "3.11.8", errString: "use unsupported Cassandra version '3.11.8'"},
+		{serverType: "dse", serverVersion: "6.8.0", errString: "use unsupported DSE version '6.8.0'"},
+		{serverType: "dse", serverVersion: "6.8.1", errString: ""},
+	}
+	for _, tt := range tests {
+		dc := CassandraDatacenter{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "exampleDC",
+			},
+			Spec: CassandraDatacenterSpec{
+				ServerType:    tt.serverType,
+				ServerVersion: tt.serverVersion,
+			},
+		}
+		err := ValidateSingleDatacenter(dc)
+		if err == nil {
+			if tt.errString != "" {
+				t.Errorf("ValidateSingleDatacenter() err = %v, want %v", err, tt.errString)
+			}
+		} else if tt.errString == "" || !strings.HasSuffix(err.Error(), tt.errString) {
+			t.Errorf("ValidateSingleDatacenter() err = %v, want suffix %v", err, tt.errString)
+		}
+	}
+}
+
 func Test_ValidateDatacenterFieldChanges(t *testing.T) {
 	storageSize := resource.MustParse("1Gi")
 	storageName := "server-data"
@@ -301,6 +476,30 @@ func Test_ValidateDatacenterFieldChanges(t *testing.T) {
 			},
 			errString: "change serviceAccount",
 		},
+		{
+			name: "ServiceAccount and racks defaulted",
+			oldDc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					Size: 3,
+				},
+			},
+			newDc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					Size:           3,
+					ServiceAccount: "default",
+					Racks: []Rack{{
+						Name: "default",
+					}},
+				},
+			},
+			errString: "",
+		},
 		{
 			name: "StorageConfig changes",
 			oldDc: &CassandraDatacenter{
@@ -338,7 +537,145 @@ func Test_ValidateDatacenterFieldChanges(t *testing.T) {
 			errString: "change storageConfig",
 		},
 		{
-			name: "Removing a rack",
+			name: "Storage request increased",
+			oldDc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					StorageConfig: StorageConfig{
+						CassandraDataVolumeClaimSpec: &corev1.PersistentVolumeClaimSpec{
+							StorageClassName: &storageName,
+							AccessModes:      []corev1.PersistentVolumeAccessMode{"ReadWriteOnce"},
+							Resources: corev1.ResourceRequirements{
+								Requests: map[corev1.ResourceName]resource.Quantity{"storage": storageSize},
+							},
+						},
+					},
+				},
+			},
+			newDc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					StorageConfig: StorageConfig{
+						CassandraDataVolumeClaimSpec: &corev1.PersistentVolumeClaimSpec{
+							StorageClassName: &storageName,
+							AccessModes:      []corev1.PersistentVolumeAccessMode{"ReadWriteOnce"},
+							Resources: corev1.ResourceRequirements{
+								Requests: map[corev1.ResourceName]resource.Quantity{"storage": resource.MustParse("2Gi")},
+							},
+						},
+					},
+				},
+			},
+			errString: "",
+		},
+		{
+			name: "Storage request decreased",
+			oldDc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					StorageConfig: StorageConfig{
+						CassandraDataVolumeClaimSpec: &corev1.PersistentVolumeClaimSpec{
+							StorageClassName: &storageName,
+							AccessModes:      []corev1.PersistentVolumeAccessMode{"ReadWriteOnce"},
+							Resources: corev1.ResourceRequirements{
+								Requests: map[corev1.ResourceName]resource.Quantity{"storage": storageSize},
+							},
+						},
+					},
+				},
+			},
+			newDc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					StorageConfig: StorageConfig{
+						CassandraDataVolumeClaimSpec: &corev1.PersistentVolumeClaimSpec{
+							StorageClassName: &storageName,
+							AccessModes:      []corev1.PersistentVolumeAccessMode{"ReadWriteOnce"},
+							Resources: corev1.ResourceRequirements{
+								Requests: map[corev1.ResourceName]resource.Quantity{"storage": resource.MustParse("500Mi")},
+							},
+						},
+					},
+				},
+			},
+			errString: "decrease storage request from 1Gi to 500Mi",
+		},
+		{
+			name: "Storage request increased with other StorageConfig changes",
+			oldDc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					StorageConfig: StorageConfig{
+						CassandraDataVolumeClaimSpec: &corev1.PersistentVolumeClaimSpec{
+							StorageClassName: &storageName,
+							AccessModes:      []corev1.PersistentVolumeAccessMode{"ReadWriteOnce"},
+							Resources: corev1.ResourceRequirements{
+								Requests: map[corev1.ResourceName]resource.Quantity{"storage": storageSize},
+							},
+						},
+					},
+				},
+			},
+			newDc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					StorageConfig: StorageConfig{
+						CassandraDataVolumeClaimSpec: &corev1.PersistentVolumeClaimSpec{
+							StorageClassName: &storageName,
+							AccessModes:      []corev1.PersistentVolumeAccessMode{"ReadWriteMany"},
+							Resources: corev1.ResourceRequirements{
+								Requests: map[corev1.ResourceName]resource.Quantity{"storage": resource.MustParse("2Gi")},
+							},
+						},
+					},
+				},
+			},
+			errString: "change storageConfig",
+		},
+		{
+			name: "Storage retention policy changed",
+			oldDc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					StorageConfig: StorageConfig{
+						CassandraDataVolumeClaimSpec: &corev1.PersistentVolumeClaimSpec{
+							StorageClassName: &storageName,
+						},
+					},
+				},
+			},
+			newDc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					StorageConfig: StorageConfig{
+						CassandraDataVolumeClaimSpec: &corev1.PersistentVolumeClaimSpec{
+							StorageClassName: &storageName,
+						},
+						RetentionPolicy:         StorageRetentionPolicySnapshot,
+						VolumeSnapshotClassName: "csi-snapclass",
+					},
+				},
+			},
+			errString: "",
+		},
+		{
+			name: "Removing a rack is allowed",
 			oldDc: &CassandraDatacenter{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "exampleDC",
@@ -370,7 +707,108 @@ func Test_ValidateDatacenterFieldChanges(t *testing.T) {
 					}},
 				},
 			},
-			errString: "remove rack",
+			errString: "",
+		},
+		{
+			name: "Removing a rack while renaming another",
+			oldDc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					Racks: []Rack{{
+						Name: "rack0",
+						Zone: "zone0",
+					}, {
+						Name: "rack1",
+						Zone: "zone1",
+					}, {
+						Name: "rack2",
+						Zone: "zone2",
+					}},
+				},
+			},
+			newDc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					Racks: []Rack{{
+						Name: "rack0",
+						Zone: "zone0",
+					}, {
+						Name: "rack2-changed",
+						Zone: "zone2",
+					}},
+				},
+			},
+			errString: "add or rename rack 'rack2-changed' while removing racks",
+		},
+		{
+			name: "Removing a rack while decreasing size below the number of racks",
+			oldDc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					Size: 3,
+					Racks: []Rack{{
+						Name: "rack0",
+						Zone: "zone0",
+					}, {
+						Name: "rack1",
+						Zone: "zone1",
+					}, {
+						Name: "rack2",
+						Zone: "zone2",
+					}},
+				},
+			},
+			newDc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					Size: 1,
+					Racks: []Rack{{
+						Name: "rack0",
+						Zone: "zone0",
+					}, {
+						Name: "rack2",
+						Zone: "zone2",
+					}},
+				},
+			},
+			errString: "decrease size to 1, which is fewer than the number of racks (2)",
+		},
+		{
+			name: "Removing a rack while changing the zone of another",
+			oldDc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					Racks: []Rack{{
+						Name: "rack0",
+						Zone: "zone0",
+					}, {
+						Name: "rack1",
+						Zone: "zone1",
+					}},
+				},
+			},
+			newDc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					Racks: []Rack{{
+						Name: "rack0",
+						Zone: "zone0-changed",
+					}},
+				},
+			},
+			errString: "change rack zone from 'zone0' to 'zone0-changed'",
 		},
 		{
 			name: "Changed a rack name",
@@ -609,6 +1047,86 @@ func Test_ValidateDatacenterFieldChanges(t *testing.T) {
 			},
 			errString: "add racks without increasing size enough to prevent existing nodes from moving to new racks to maintain balance.\nNew racks added: 2, size increased by: 7. Expected size increase to be at least 8",
 		},
+		{
+			name: "Decreasing size is allowed",
+			oldDc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					Size: 6,
+					Racks: []Rack{{
+						Name: "rack0",
+						Zone: "zone0",
+					}, {
+						Name: "rack1",
+						Zone: "zone1",
+					}, {
+						Name: "rack2",
+						Zone: "zone2",
+					}},
+				},
+			},
+			newDc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					Size: 3,
+					Racks: []Rack{{
+						Name: "rack0",
+						Zone: "zone0",
+					}, {
+						Name: "rack1",
+						Zone: "zone1",
+					}, {
+						Name: "rack2",
+						Zone: "zone2",
+					}},
+				},
+			},
+			errString: "",
+		},
+		{
+			name: "Decreasing size below the number of racks is not allowed",
+			oldDc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					Size: 6,
+					Racks: []Rack{{
+						Name: "rack0",
+						Zone: "zone0",
+					}, {
+						Name: "rack1",
+						Zone: "zone1",
+					}, {
+						Name: "rack2",
+						Zone: "zone2",
+					}},
+				},
+			},
+			newDc: &CassandraDatacenter{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "exampleDC",
+				},
+				Spec: CassandraDatacenterSpec{
+					Size: 2,
+					Racks: []Rack{{
+						Name: "rack0",
+						Zone: "zone0",
+					}, {
+						Name: "rack1",
+						Zone: "zone1",
+					}, {
+						Name: "rack2",
+						Zone: "zone2",
+					}},
+				},
+			},
+			errString: "decrease size to 2, which is fewer than the number of racks (3)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -626,3 +1144,98 @@ func Test_ValidateDatacenterFieldChanges(t *testing.T) {
 		})
 	}
 }
+
+func Test_Default(t *testing.T) {
+	dc := &CassandraDatacenter{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "exampleDC",
+		},
+		Spec: CassandraDatacenterSpec{
+			ServerType:    "cassandra",
+			ServerVersion: "3.11.6",
+			Reaper: &ReaperConfig{
+				Enabled: true,
+				Schedules: []ReaperRepairSchedule{{
+					Keyspace: "app",
+				}},
+			},
+		},
+	}
+
+	dc.Default()
+
+	if len(dc.Spec.Racks) != 1 || dc.Spec.Racks[0].Name != "default" {
+		t.Errorf("Default() racks = %v, want a single default rack", dc.Spec.Racks)
+	}
+	if dc.Spec.ServiceAccount != "default" {
+		t.Errorf("Default() serviceAccount = %v, want default", dc.Spec.ServiceAccount)
+	}
+	configBuilderImage := images.GetCatalog().ConfigBuilderImage("cassandra", "3.11.6", os.Getenv(EnvBaseImageOs) != "")
+	if dc.Spec.ConfigBuilderImage != configBuilderImage {
+		t.Errorf("Default() configBuilderImage = %v, want %v", dc.Spec.ConfigBuilderImage, configBuilderImage)
+	}
+	if dc.Spec.Reaper.Image != defaultReaperImage {
+		t.Errorf("Default() reaper image = %v, want %v", dc.Spec.Reaper.Image, defaultReaperImage)
+	}
+	if dc.Spec.Reaper.ImagePullPolicy != corev1.PullIfNotPresent {
+		t.Errorf("Default() reaper imagePullPolicy = %v, want %v", dc.Spec.Reaper.ImagePullPolicy, corev1.PullIfNotPresent)
+	}
+	if dc.Spec.Reaper.DeploymentMode != ReaperDeploymentModeSidecar {
+		t.Errorf("Default() reaper deploymentMode = %v, want %v", dc.Spec.Reaper.DeploymentMode, ReaperDeploymentModeSidecar)
+	}
+	if dc.Spec.Reaper.Schedules[0].IntervalDays != 7 {
+		t.Errorf("Default() reaper schedule intervalDays = %v, want 7", dc.Spec.Reaper.Schedules[0].IntervalDays)
+	}
+
+	dc.Spec.ServerVersion = "4.0.0"
+	dc.Spec.ImageRegistry = "registry.example.com"
+	dc.Default()
+
+	configBuilderImage = images.GetCatalog().WithRegistry("registry.example.com").
+		ConfigBuilderImage("cassandra", "4.0.0", os.Getenv(EnvBaseImageOs) != "")
+	if dc.Spec.ConfigBuilderImage != configBuilderImage {
+		t.Errorf("configBuilderImage after changing serverVersion = %v, want %v", dc.Spec.ConfigBuilderImage, configBuilderImage)
+	}
+	if want := "registry.example.com/" + defaultReaperImage; dc.Spec.Reaper.Image != want {
+		t.Errorf("reaper image after changing imageRegistry = %v, want %v", dc.Spec.Reaper.Image, want)
+	}
+
+	dc.Spec.ConfigBuilderImage = "example/config-builder:1.0"
+	dc.Default()
+
+	if dc.Spec.ConfigBuilderImage != "example/config-builder:1.0" {
+		t.Errorf("Default() configBuilderImage = %v, want the image set by the user", dc.Spec.ConfigBuilderImage)
+	}
+	if _, ok := dc.Annotations[DefaultConfigBuilderImageAnnotation]; ok {
+		t.Errorf("Default() kept the %v annotation of an image set by the user", DefaultConfigBuilderImageAnnotation)
+	}
+}
+
+func Test_Default_KeepsSetValues(t *testing.T) {
+	dc := &CassandraDatacenter{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "exampleDC",
+		},
+		Spec: CassandraDatacenterSpec{
+			ServiceAccount:     "admin",
+			ConfigBuilderImage: "example/config-builder:1.0",
+			Racks: []Rack{{
+				Name: "rack0",
+			}, {
+				Name: "rack1",
+			}},
+			Reaper: &ReaperConfig{
+				Image:          "example/reaper:2.0",
+				DeploymentMode: ReaperDeploymentModeDeployment,
+			},
+		},
+	}
+	want := dc.DeepCopy()
+	want.Spec.Reaper.ImagePullPolicy = corev1.PullIfNotPresent
+
+	dc.Default()
+
+	if !reflect.DeepEqual(want.Spec, dc.Spec) {
+		t.Errorf("Default() spec = %v, want %v", dc.Spec, want.Spec)
+	}
+}
@@ -77,7 +77,7 @@ func schema_pkg_apis_cassandra_v1beta1_CassandraDatacenterSpec(ref common.Refere
 					},
 					"serverVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Version string for config builder, used to generate Cassandra server configuration",
+							Description: "Version string for config builder, used to generate Cassandra server configuration. The supported versions are those of the version catalog of the operator.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
@@ -89,6 +89,26 @@ func schema_pkg_apis_cassandra_v1beta1_CassandraDatacenterSpec(ref common.Refere
 							Format:      "",
 						},
 					},
+					"imageRegistry": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The registry to pull the images that the operator picks by default from, e.g. \"registry.example.com:5000\". It replaces the registry of the server, config builder, system logger, Reaper and Reaper schema init images, and takes precedence over the registry of the version catalog. Images set explicitly in the spec are used as is.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"imagePullSecrets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Secrets to pull the images of every pod that the operator creates for the datacenter, including the Reaper Deployment and the Reaper schema init Job. They are added to the image pull secrets of the PodTemplateSpec. More info: https://kubernetes.io/docs/concepts/containers/images#specifying-imagepullsecrets-on-a-pod",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.LocalObjectReference"),
+									},
+								},
+							},
+						},
+					},
 					"serverType": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Server type: \"cassandra\" or \"dse\"",
@@ -230,7 +250,7 @@ func schema_pkg_apis_cassandra_v1beta1_CassandraDatacenterSpec(ref common.Refere
 			},
 		},
 		Dependencies: []string{
-			"github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1.ManagementApiAuthConfig", "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1.Rack", "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1.StorageConfig", "k8s.io/api/core/v1.PodTemplateSpec", "k8s.io/api/core/v1.ResourceRequirements"},
+			"github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1.ManagementApiAuthConfig", "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1.Rack", "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1.StorageConfig", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodTemplateSpec", "k8s.io/api/core/v1.ResourceRequirements"},
 	}
 }
 
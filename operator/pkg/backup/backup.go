@@ -0,0 +1,292 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package backup
+
+import (
+	"fmt"
+	"sort"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/datastax/cass-operator/operator/internal/dccontext"
+	"github.com/datastax/cass-operator/operator/internal/result"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/events"
+)
+
+// ReconcileBackup takes a snapshot on every started node of the datacenter,
+// uploads the snapshots to the object store and then clears them
+func (rc *ReconciliationContext) ReconcileBackup(backup *api.CassandraBackup) result.ReconcileResult {
+	rc.ReqLogger.Info("backup::ReconcileBackup")
+
+	if backup.IsFinished() {
+		return result.Done()
+	}
+
+	if recResult := rc.CheckBackupStarted(backup); recResult.Completed() {
+		return recResult
+	}
+
+	if recResult := rc.CheckSnapshots(backup); recResult.Completed() {
+		return recResult
+	}
+
+	if recResult := rc.CheckUploads(backup); recResult.Completed() {
+		return recResult
+	}
+
+	return rc.CheckBackupComplete(backup)
+}
+
+// CheckBackupStarted records which pods are part of the backup. Only nodes
+// that are started when the backup begins are included.
+func (rc *ReconciliationContext) CheckBackupStarted(backup *api.CassandraBackup) result.ReconcileResult {
+	logger := rc.ReqLogger
+	logger.Info("backup::CheckBackupStarted")
+
+	if backup.Status.Pods != nil {
+		return result.Continue()
+	}
+
+	pods := map[string]api.PodBackupStatus{}
+	for _, pod := range rc.Pods {
+		if dccontext.IsServerStarted(pod) {
+			pods[pod.Name] = api.PodBackupStatus{}
+		}
+	}
+
+	if len(pods) == 0 {
+		logger.Info("Waiting for started nodes to back up")
+		return result.RequeueSoon(10)
+	}
+
+	patch := client.MergeFrom(backup.DeepCopy())
+	backup.Status.StartTime = metav1.Now()
+	backup.Status.Pods = pods
+	if err := rc.Client.Status().Patch(rc.Ctx, backup, patch); err != nil {
+		logger.Error(err, "error patching backup status for backup started")
+		return result.Error(err)
+	}
+
+	return result.Continue()
+}
+
+// CheckSnapshots takes a snapshot on each pod of the backup that does not
+// have one yet. The snapshots are taken in a single pass so they are as close
+// together in time as possible.
+func (rc *ReconciliationContext) CheckSnapshots(backup *api.CassandraBackup) result.ReconcileResult {
+	logger := rc.ReqLogger
+	logger.Info("backup::CheckSnapshots")
+
+	patch := client.MergeFrom(backup.DeepCopy())
+	pending := false
+	updated := false
+
+	for _, podName := range getSortedPodNames(backup.Status.Pods) {
+		podStatus := backup.Status.Pods[podName]
+		if podStatus.Conditions.GetConditionStatus(api.BackupSnapshotTaken) == corev1.ConditionTrue {
+			continue
+		}
+
+		pod := dccontext.FindPodByName(rc.Pods, podName)
+		if pod == nil || !dccontext.IsServerStarted(pod) {
+			logger.Info("Waiting for node to be started before taking a snapshot", "pod", podName)
+			pending = true
+			continue
+		}
+
+		err := rc.NodeMgmtClient.CallCreateSnapshotEndpoint(pod, backup.GetSnapshotName(), backup.Spec.Keyspaces)
+		if err != nil {
+			logger.Error(err, "error taking snapshot", "pod", podName)
+			pending = true
+			continue
+		}
+
+		podStatus.Conditions.SetCondition(*api.NewBackupCondition(api.BackupSnapshotTaken, corev1.ConditionTrue))
+		backup.Status.Pods[podName] = podStatus
+		updated = true
+
+		rc.Recorder.Eventf(backup, corev1.EventTypeNormal, events.CreatedSnapshot,
+			"Created snapshot %s on pod %s", backup.GetSnapshotName(), podName)
+	}
+
+	if updated {
+		if err := rc.Client.Status().Patch(rc.Ctx, backup, patch); err != nil {
+			logger.Error(err, "error patching backup status for snapshots taken")
+			return result.Error(err)
+		}
+	}
+
+	if pending {
+		return result.RequeueSoon(10)
+	}
+
+	return result.Continue()
+}
+
+// CheckUploads runs a Job for each pod to copy its snapshot to the object
+// store. Once a snapshot has been uploaded it is cleared from the node.
+func (rc *ReconciliationContext) CheckUploads(backup *api.CassandraBackup) result.ReconcileResult {
+	logger := rc.ReqLogger
+	logger.Info("backup::CheckUploads")
+
+	patch := client.MergeFrom(backup.DeepCopy())
+	pending := false
+	updated := false
+	var failure error
+
+	for _, podName := range getSortedPodNames(backup.Status.Pods) {
+		podStatus := backup.Status.Pods[podName]
+		if podStatus.Conditions.GetConditionStatus(api.BackupUploaded) == corev1.ConditionTrue {
+			continue
+		}
+
+		pod := dccontext.FindPodByName(rc.Pods, podName)
+		job, err := rc.getOrCreateJob(backup, getJobName(backup.Name, podName), func() (*batchv1.Job, error) {
+			if pod == nil {
+				return nil, fmt.Errorf("pod %s no longer exists", podName)
+			}
+			return newUploadJob(backup, pod)
+		})
+		if err != nil {
+			logger.Error(err, "error creating upload job", "pod", podName)
+			return result.Error(err)
+		}
+
+		if isJobFailed(job) {
+			failure = fmt.Errorf("upload of snapshot failed for pod %s, see job %s", podName, job.Name)
+			condition := api.NewBackupCondition(api.BackupFailed, corev1.ConditionTrue)
+			condition.Message = failure.Error()
+			podStatus.Conditions.SetCondition(*condition)
+			backup.Status.Pods[podName] = podStatus
+			updated = true
+			break
+		}
+
+		if !isJobSucceeded(job) {
+			pending = true
+			continue
+		}
+
+		podStatus.Conditions.SetCondition(*api.NewBackupCondition(api.BackupUploaded, corev1.ConditionTrue))
+		backup.Status.Pods[podName] = podStatus
+		updated = true
+
+		rc.Recorder.Eventf(backup, corev1.EventTypeNormal, events.UploadedBackup,
+			"Uploaded snapshot %s of pod %s", backup.GetSnapshotName(), podName)
+
+		if pod != nil {
+			// The snapshot is safely stored, so there is no reason to keep
+			// it around. Failing to clear it only costs some disk space.
+			if err := rc.NodeMgmtClient.CallClearSnapshotEndpoint(pod, backup.GetSnapshotName()); err != nil {
+				logger.Error(err, "error clearing snapshot", "pod", podName)
+			}
+		}
+	}
+
+	if failure != nil {
+		rc.setBackupFailed(backup, failure)
+	}
+
+	if updated {
+		if err := rc.Client.Status().Patch(rc.Ctx, backup, patch); err != nil {
+			logger.Error(err, "error patching backup status for uploads")
+			return result.Error(err)
+		}
+	}
+
+	if failure != nil {
+		return result.Done()
+	}
+
+	if pending {
+		return result.RequeueSoon(10)
+	}
+
+	return result.Continue()
+}
+
+// CheckBackupComplete marks the backup as complete once every pod has been
+// uploaded
+func (rc *ReconciliationContext) CheckBackupComplete(backup *api.CassandraBackup) result.ReconcileResult {
+	logger := rc.ReqLogger
+	logger.Info("backup::CheckBackupComplete")
+
+	for _, podStatus := range backup.Status.Pods {
+		if podStatus.Conditions.GetConditionStatus(api.BackupUploaded) != corev1.ConditionTrue {
+			return result.RequeueSoon(10)
+		}
+	}
+
+	patch := client.MergeFrom(backup.DeepCopy())
+	backup.Status.Conditions.SetCondition(*api.NewBackupCondition(api.BackupComplete, corev1.ConditionTrue))
+	backup.Status.FinishTime = metav1.Now()
+	if err := rc.Client.Status().Patch(rc.Ctx, backup, patch); err != nil {
+		logger.Error(err, "error patching backup status for backup complete")
+		return result.Error(err)
+	}
+
+	rc.Recorder.Eventf(backup, corev1.EventTypeNormal, events.CompletedBackup,
+		"Completed backup of datacenter %s", rc.Datacenter.Name)
+
+	return result.Done()
+}
+
+func (rc *ReconciliationContext) setBackupFailed(backup *api.CassandraBackup, failure error) {
+	condition := api.NewBackupCondition(api.BackupFailed, corev1.ConditionTrue)
+	condition.Message = failure.Error()
+	backup.Status.Conditions.SetCondition(*condition)
+	backup.Status.FinishTime = metav1.Now()
+
+	rc.Recorder.Eventf(backup, corev1.EventTypeWarning, events.FailedBackup,
+		"Backup failed: %s", failure.Error())
+}
+
+// getOrCreateJob returns the Job with the given name, creating it with
+// newJob when it does not exist yet
+func (rc *ReconciliationContext) getOrCreateJob(
+	owner metav1.Object,
+	name string,
+	newJob func() (*batchv1.Job, error)) (*batchv1.Job, error) {
+
+	job := &batchv1.Job{}
+	err := rc.Client.Get(rc.Ctx, types.NamespacedName{Namespace: owner.GetNamespace(), Name: name}, job)
+	if err == nil {
+		return job, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	job, err = newJob()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := controllerutil.SetControllerReference(owner, job, rc.Scheme); err != nil {
+		return nil, err
+	}
+
+	rc.ReqLogger.Info("Creating job", "job", job.Name)
+	if err := rc.Client.Create(rc.Ctx, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func getSortedPodNames(pods map[string]api.PodBackupStatus) []string {
+	names := []string{}
+	for name := range pods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
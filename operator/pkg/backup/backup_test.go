@@ -0,0 +1,229 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/datastax/cass-operator/operator/internal/dccontext"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/httphelper"
+	"github.com/datastax/cass-operator/operator/pkg/mocks"
+)
+
+const testNamespace = "default"
+
+func newTestDatacenter() *api.CassandraDatacenter {
+	return &api.CassandraDatacenter{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dc1",
+			Namespace: testNamespace,
+		},
+		Spec: api.CassandraDatacenterSpec{
+			Size:          2,
+			ClusterName:   "cluster1",
+			ServerType:    "cassandra",
+			ServerVersion: "3.11.6",
+		},
+	}
+}
+
+func newTestPods(dc *api.CassandraDatacenter, nodeState string) []*corev1.Pod {
+	pods := []*corev1.Pod{}
+	for i := 0; i < int(dc.Spec.Size); i++ {
+		name := fmt.Sprintf("%s-%s-default-sts-%d", dc.Spec.ClusterName, dc.Name, i)
+		labels := dc.GetDatacenterLabels()
+		labels[api.CassNodeState] = nodeState
+		pods = append(pods, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: dc.Namespace,
+				Labels:    labels,
+			},
+			Spec: corev1.PodSpec{
+				NodeName: fmt.Sprintf("node-%d", i),
+				Volumes: []corev1.Volume{{
+					Name: serverDataVolumeName,
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: fmt.Sprintf("%s-%s", serverDataVolumeName, name),
+						},
+					},
+				}},
+			},
+			Status: corev1.PodStatus{
+				PodIP: fmt.Sprintf("10.0.0.%d", i+1),
+			},
+		})
+	}
+	return pods
+}
+
+func newTestBackup(dc *api.CassandraDatacenter) *api.CassandraBackup {
+	return &api.CassandraBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nightly",
+			Namespace: dc.Namespace,
+		},
+		Spec: api.CassandraBackupSpec{
+			CassandraDatacenter: dc.Name,
+			Storage: api.BackupStorageConfig{
+				Endpoint:   "http://minio.minio.svc.cluster.local:9000",
+				Bucket:     "backups",
+				SecretName: "minio-credentials",
+			},
+		},
+	}
+}
+
+// setupTest creates a reconciliation context for the datacenter with a fake
+// client tracking the given objects, and returns the management API requests
+// it makes
+func setupTest(dc *api.CassandraDatacenter, pods []*corev1.Pod, objs ...runtime.Object) (*ReconciliationContext, *[]*http.Request) {
+	s := scheme.Scheme
+	s.AddKnownTypes(api.SchemeGroupVersion,
+		&api.CassandraDatacenter{},
+		&api.CassandraBackup{},
+		&api.CassandraBackupList{},
+		&api.CassandraRestore{},
+		&api.CassandraRestoreList{},
+		&api.CassandraSnapshotSchedule{},
+		&api.CassandraSnapshotScheduleList{})
+
+	trackObjects := append([]runtime.Object{dc}, objs...)
+	for _, pod := range pods {
+		trackObjects = append(trackObjects, pod)
+	}
+
+	logger := zap.Logger(true)
+	mgmtApi := mocks.NewManagementApi(nil)
+
+	rc := &ReconciliationContext{Context: &dccontext.Context{
+		Client:         fake.NewFakeClientWithScheme(s, trackObjects...),
+		Scheme:         s,
+		Datacenter:     dc,
+		NodeMgmtClient: httphelper.NodeMgmtClient{Client: mgmtApi.HttpClient(), Log: logger, Protocol: "http"},
+		Recorder:       record.NewFakeRecorder(100),
+		ReqLogger:      logger,
+		Ctx:            context.Background(),
+		Pods:           pods,
+	}}
+
+	return rc, &mgmtApi.Requests
+}
+
+func setJobCondition(t *testing.T, rc *ReconciliationContext, name string, conditionType batchv1.JobConditionType) {
+	job := &batchv1.Job{}
+	err := rc.Client.Get(rc.Ctx, types.NamespacedName{Namespace: testNamespace, Name: name}, job)
+	assert.NoError(t, err)
+
+	job.Status.Conditions = []batchv1.JobCondition{{
+		Type:   conditionType,
+		Status: corev1.ConditionTrue,
+	}}
+	assert.NoError(t, rc.Client.Status().Update(rc.Ctx, job))
+}
+
+func TestReconcileBackup_TakesSnapshots(t *testing.T) {
+	dc := newTestDatacenter()
+	pods := newTestPods(dc, dccontext.NodeStateStarted)
+	backup := newTestBackup(dc)
+	rc, requests := setupTest(dc, pods, backup)
+
+	recResult := rc.ReconcileBackup(backup)
+	assert.True(t, recResult.Completed(), "Should requeue while uploading")
+
+	assert.Equal(t, 2, len(backup.Status.Pods))
+	assert.False(t, backup.Status.StartTime.IsZero())
+
+	snapshotRequests := 0
+	for _, req := range *requests {
+		if req.Method == http.MethodPost && req.URL.Path == "/api/v0/ops/node/snapshots" {
+			snapshotRequests++
+		}
+	}
+	assert.Equal(t, 2, snapshotRequests, "Should take a snapshot on every pod")
+
+	for _, pod := range pods {
+		podStatus := backup.Status.Pods[pod.Name]
+		assert.Equal(t, corev1.ConditionTrue, podStatus.Conditions.GetConditionStatus(api.BackupSnapshotTaken))
+
+		job := &batchv1.Job{}
+		err := rc.Client.Get(rc.Ctx, types.NamespacedName{Namespace: testNamespace, Name: getJobName(backup.Name, pod.Name)}, job)
+		assert.NoError(t, err, "Should create an upload job for every pod")
+		assert.Equal(t, backup.Name, job.OwnerReferences[0].Name)
+	}
+}
+
+func TestReconcileBackup_OnlyStartedPods(t *testing.T) {
+	dc := newTestDatacenter()
+	pods := newTestPods(dc, dccontext.NodeStateStarted)
+	pods[1].Labels[api.CassNodeState] = dccontext.NodeStateReadyToStart
+	backup := newTestBackup(dc)
+	rc, _ := setupTest(dc, pods, backup)
+
+	rc.ReconcileBackup(backup)
+
+	assert.Equal(t, 1, len(backup.Status.Pods))
+	_, ok := backup.Status.Pods[pods[0].Name]
+	assert.True(t, ok)
+}
+
+func TestReconcileBackup_Completes(t *testing.T) {
+	dc := newTestDatacenter()
+	pods := newTestPods(dc, dccontext.NodeStateStarted)
+	backup := newTestBackup(dc)
+	rc, requests := setupTest(dc, pods, backup)
+
+	rc.ReconcileBackup(backup)
+	for _, pod := range pods {
+		setJobCondition(t, rc, getJobName(backup.Name, pod.Name), batchv1.JobComplete)
+	}
+
+	recResult := rc.ReconcileBackup(backup)
+	assert.True(t, recResult.Completed())
+	assert.Equal(t, corev1.ConditionTrue, backup.Status.Conditions.GetConditionStatus(api.BackupComplete))
+	assert.False(t, backup.Status.FinishTime.IsZero())
+
+	clearRequests := 0
+	for _, req := range *requests {
+		if req.Method == http.MethodDelete && req.URL.Path == "/api/v0/ops/node/snapshots" {
+			assert.Equal(t, backup.GetSnapshotName(), req.URL.Query().Get("snapshotNames"))
+			clearRequests++
+		}
+	}
+	assert.Equal(t, 2, clearRequests, "Should clear the snapshot once it has been uploaded")
+}
+
+func TestReconcileBackup_UploadFails(t *testing.T) {
+	dc := newTestDatacenter()
+	pods := newTestPods(dc, dccontext.NodeStateStarted)
+	backup := newTestBackup(dc)
+	rc, _ := setupTest(dc, pods, backup)
+
+	rc.ReconcileBackup(backup)
+	setJobCondition(t, rc, getJobName(backup.Name, pods[0].Name), batchv1.JobFailed)
+
+	recResult := rc.ReconcileBackup(backup)
+	assert.True(t, recResult.Completed())
+	assert.Equal(t, corev1.ConditionTrue, backup.Status.Conditions.GetConditionStatus(api.BackupFailed))
+	assert.True(t, backup.IsFinished())
+
+	podStatus := backup.Status.Pods[pods[0].Name]
+	assert.Equal(t, corev1.ConditionTrue, podStatus.Conditions.GetConditionStatus(api.BackupFailed))
+}
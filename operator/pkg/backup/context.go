@@ -0,0 +1,38 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package backup
+
+import (
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/datastax/cass-operator/operator/internal/dccontext"
+)
+
+// ReconciliationContext contains everything needed to reconcile a backup or
+// a restore of a CassandraDatacenter
+type ReconciliationContext struct {
+	*dccontext.Context
+}
+
+// CreateReconciliationContext fetches the datacenter with the given name and
+// its pods, and sets up a client for the management API of those pods
+func CreateReconciliationContext(
+	namespace string,
+	dcName string,
+	cli runtimeClient.Client,
+	scheme *runtime.Scheme,
+	rec record.EventRecorder,
+	reqLogger logr.Logger) (*ReconciliationContext, error) {
+
+	reqLogger.Info("backup::CreateReconciliationContext", "datacenterName", dcName)
+
+	dcContext, err := dccontext.New(namespace, dcName, cli, scheme, rec, reqLogger)
+	if err != nil {
+		return nil, err
+	}
+	return &ReconciliationContext{Context: dcContext}, nil
+}
@@ -0,0 +1,198 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package backup
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/datastax/cass-operator/operator/internal/result"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+)
+
+var log = logf.Log.WithName("backup_handler")
+
+// ReconcileCassandraBackup reconciles a CassandraBackup object
+type ReconcileCassandraBackup struct {
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+}
+
+// ReconcileCassandraRestore reconciles a CassandraRestore object
+type ReconcileCassandraRestore struct {
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+}
+
+// ReconcileCassandraSnapshotSchedule reconciles a CassandraSnapshotSchedule object
+type ReconcileCassandraSnapshotSchedule struct {
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+}
+
+func newRequestLogger(request reconcile.Request) logr.Logger {
+	return log.
+		WithValues("requestNamespace", request.Namespace).
+		WithValues("requestName", request.Name).
+		// loopID is used to tie all events together that are spawned by the same reconciliation loop
+		WithValues("loopID", uuid.New().String())
+}
+
+// Reconcile reads the state of a CassandraBackup and moves the backup along
+func (r *ReconcileCassandraBackup) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	startReconcile := time.Now()
+	logger := newRequestLogger(request)
+
+	defer func() {
+		logger.Info("Reconcile loop completed",
+			"duration", time.Since(startReconcile).Seconds())
+	}()
+
+	logger.Info("======== backup handler::Reconcile has been called")
+
+	backup := &api.CassandraBackup{}
+	if err := r.client.Get(context.Background(), request.NamespacedName, backup); err != nil {
+		if errors.IsNotFound(err) {
+			logger.Info("CassandraBackup resource not found. Ignoring since object must be deleted.")
+			return result.Done().Output()
+		}
+		logger.Error(err, "Failed to get CassandraBackup.")
+		return result.Error(err).Output()
+	}
+
+	if backup.IsFinished() {
+		return result.Done().Output()
+	}
+
+	rc, err := CreateReconciliationContext(
+		request.Namespace, backup.Spec.CassandraDatacenter, r.client, r.scheme, r.recorder, logger)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			logger.Info("CassandraDatacenter of backup not found, waiting for it to be created",
+				"datacenter", backup.Spec.CassandraDatacenter)
+			return result.RequeueSoon(10).Output()
+		}
+		return result.Error(err).Output()
+	}
+
+	return rc.ReconcileBackup(backup).Output()
+}
+
+// Reconcile reads the state of a CassandraRestore and moves the restore along
+func (r *ReconcileCassandraRestore) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	startReconcile := time.Now()
+	logger := newRequestLogger(request)
+
+	defer func() {
+		logger.Info("Reconcile loop completed",
+			"duration", time.Since(startReconcile).Seconds())
+	}()
+
+	logger.Info("======== restore handler::Reconcile has been called")
+
+	restore := &api.CassandraRestore{}
+	if err := r.client.Get(context.Background(), request.NamespacedName, restore); err != nil {
+		if errors.IsNotFound(err) {
+			logger.Info("CassandraRestore resource not found. Ignoring since object must be deleted.")
+			return result.Done().Output()
+		}
+		logger.Error(err, "Failed to get CassandraRestore.")
+		return result.Error(err).Output()
+	}
+
+	if restore.IsFinished() {
+		return result.Done().Output()
+	}
+
+	rc, err := CreateReconciliationContext(
+		request.Namespace, restore.Spec.CassandraDatacenter, r.client, r.scheme, r.recorder, logger)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			logger.Info("CassandraDatacenter of restore not found, waiting for it to be created",
+				"datacenter", restore.Spec.CassandraDatacenter)
+			return result.RequeueSoon(10).Output()
+		}
+		return result.Error(err).Output()
+	}
+
+	return rc.ReconcileRestore(restore).Output()
+}
+
+// Reconcile reads the state of a CassandraSnapshotSchedule and takes the
+// snapshots that are due
+func (r *ReconcileCassandraSnapshotSchedule) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	startReconcile := time.Now()
+	logger := newRequestLogger(request)
+
+	defer func() {
+		logger.Info("Reconcile loop completed",
+			"duration", time.Since(startReconcile).Seconds())
+	}()
+
+	logger.Info("======== snapshot schedule handler::Reconcile has been called")
+
+	schedule := &api.CassandraSnapshotSchedule{}
+	if err := r.client.Get(context.Background(), request.NamespacedName, schedule); err != nil {
+		if errors.IsNotFound(err) {
+			logger.Info("CassandraSnapshotSchedule resource not found. Ignoring since object must be deleted.")
+			return result.Done().Output()
+		}
+		logger.Error(err, "Failed to get CassandraSnapshotSchedule.")
+		return result.Error(err).Output()
+	}
+
+	rc, err := CreateReconciliationContext(
+		request.Namespace, schedule.Spec.CassandraDatacenter, r.client, r.scheme, r.recorder, logger)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			logger.Info("CassandraDatacenter of snapshot schedule not found, waiting for it to be created",
+				"datacenter", schedule.Spec.CassandraDatacenter)
+			return result.RequeueSoon(10).Output()
+		}
+		return result.Error(err).Output()
+	}
+
+	return rc.ReconcileSnapshotSchedule(schedule, time.Now().UTC()).Output()
+}
+
+// NewBackupReconciler returns a new reconcile.Reconciler for CassandraBackups
+func NewBackupReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileCassandraBackup{
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: mgr.GetEventRecorderFor("cass-operator"),
+	}
+}
+
+// NewRestoreReconciler returns a new reconcile.Reconciler for CassandraRestores
+func NewRestoreReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileCassandraRestore{
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: mgr.GetEventRecorderFor("cass-operator"),
+	}
+}
+
+// NewSnapshotScheduleReconciler returns a new reconcile.Reconciler for
+// CassandraSnapshotSchedules
+func NewSnapshotScheduleReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileCassandraSnapshotSchedule{
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: mgr.GetEventRecorderFor("cass-operator"),
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package backup
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/oplabels"
+)
+
+const (
+	serverDataVolumeName = "server-data"
+	serverDataMountPath  = "/var/lib/cassandra"
+
+	// Job names end up in a label on their pods, so they have to fit in 63
+	// characters
+	maxJobNameLength = 63
+)
+
+// The snapshot directories of every table are copied to
+// <bucket>/<path>/<keyspace>/<table directory>/
+const uploadScript = `set -e
+cd /var/lib/cassandra/data
+found=0
+for snapshot in */*/snapshots/"$SNAPSHOT_NAME"; do
+  [ -d "$snapshot" ] || continue
+  found=1
+  table="${snapshot%/snapshots/*}"
+  aws s3 cp --recursive --only-show-errors --endpoint-url "$S3_ENDPOINT" \
+    "$snapshot" "s3://$S3_BUCKET/$S3_PATH/$table/"
+done
+if [ "$found" = 0 ]; then
+  echo "snapshot $SNAPSHOT_NAME not found"
+  exit 1
+fi
+`
+
+// Every table in the backup replaces the sstables of the same table on the
+// volume. The system keyspace holds node local state and is left alone.
+// Commit logs and hints written after the backup was taken are dropped so
+// they do not get replayed on top of the restored data.
+const stagingScript = `set -e
+staging=/var/lib/cassandra/restore-staging
+rm -rf "$staging"
+mkdir -p "$staging"
+aws s3 cp --recursive --only-show-errors --endpoint-url "$S3_ENDPOINT" \
+  "s3://$S3_BUCKET/$S3_PATH/" "$staging"
+cd "$staging"
+for table in */*; do
+  [ -d "$table" ] || continue
+  case "$table" in system/*) continue ;; esac
+  target="/var/lib/cassandra/data/$table"
+  mkdir -p "$target"
+  for file in "$target"/*; do
+    if [ -f "$file" ]; then rm -f "$file"; fi
+  done
+  mv "$table"/* "$target"/
+  rm -f "$target/manifest.json" "$target/schema.cql"
+done
+cd /
+rm -rf "$staging" /var/lib/cassandra/commitlog/* /var/lib/cassandra/hints/* /var/lib/cassandra/saved_caches/*
+`
+
+// newUploadJob creates a Job that copies the snapshot of the backup from the
+// data volume of the pod to the object store
+func newUploadJob(backup *api.CassandraBackup, pod *corev1.Pod) (*batchv1.Job, error) {
+	env := []corev1.EnvVar{
+		{Name: "SNAPSHOT_NAME", Value: backup.GetSnapshotName()},
+	}
+
+	return newTransferJob(backup.Name, backup, backup.GetImage(), uploadScript, pod, env)
+}
+
+// newStagingJob creates a Job that copies the backup of the pod from the
+// object store onto its data volume
+func newStagingJob(restore *api.CassandraRestore, backup *api.CassandraBackup, pod *corev1.Pod) (*batchv1.Job, error) {
+	image := restore.Spec.Image
+	if image == "" {
+		image = backup.GetImage()
+	}
+
+	return newTransferJob(restore.Name, backup, image, stagingScript, pod, nil)
+}
+
+func newTransferJob(
+	owner string,
+	backup *api.CassandraBackup,
+	image string,
+	script string,
+	pod *corev1.Pod,
+	extraEnv []corev1.EnvVar) (*batchv1.Job, error) {
+
+	volumes, volumeMounts := getServerVolumes(pod)
+	if len(volumes) == 0 {
+		return nil, fmt.Errorf("pod %s has no %s volume", pod.Name, serverDataVolumeName)
+	}
+
+	storage := backup.Spec.Storage
+	region := storage.Region
+	if region == "" {
+		// the aws cli insists on a region, even for stores that do not
+		// have any
+		region = "us-east-1"
+	}
+
+	env := []corev1.EnvVar{
+		{Name: "S3_ENDPOINT", Value: storage.Endpoint},
+		{Name: "S3_BUCKET", Value: storage.Bucket},
+		{Name: "S3_PATH", Value: backup.GetPodPath(pod.Name)},
+		{Name: "AWS_DEFAULT_REGION", Value: region},
+		// the cassandra user has no writable home directory
+		{Name: "HOME", Value: "/tmp"},
+	}
+	env = append(env, extraEnv...)
+
+	labels := map[string]string{
+		api.BackupLabel: owner,
+	}
+	oplabels.AddManagedByLabel(labels)
+
+	backoffLimit := int32(2)
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getJobName(owner, pod.Name),
+			Namespace: pod.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					// The data volume is usually ReadWriteOnce, so the Job
+					// has to run next to the pod that uses it
+					NodeName:         pod.Spec.NodeName,
+					Tolerations:      pod.Spec.Tolerations,
+					SecurityContext:  pod.Spec.SecurityContext.DeepCopy(),
+					ImagePullSecrets: pod.Spec.ImagePullSecrets,
+					RestartPolicy:    corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:    "backup",
+						Image:   image,
+						Command: []string{"/bin/sh", "-c"},
+						Args:    []string{script},
+						Env:     env,
+						EnvFrom: []corev1.EnvFromSource{{
+							SecretRef: &corev1.SecretEnvSource{
+								LocalObjectReference: corev1.LocalObjectReference{
+									Name: storage.SecretName,
+								},
+							},
+						}},
+						VolumeMounts: volumeMounts,
+					}},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+
+	return job, nil
+}
+
+// getServerVolumes returns the PersistentVolumeClaim volumes of the pod,
+// mounted where the cassandra container mounts them, so that the commit log,
+// hints and saved caches are reached when they have volumes of their own. The
+// data volume comes first, and none are returned when the pod has no data
+// volume.
+func getServerVolumes(pod *corev1.Pod) ([]corev1.Volume, []corev1.VolumeMount) {
+	if getServerDataClaimName(pod) == "" {
+		return nil, nil
+	}
+
+	mountPaths := map[string]string{serverDataVolumeName: serverDataMountPath}
+	for _, container := range pod.Spec.Containers {
+		if container.Name != "cassandra" {
+			continue
+		}
+		for _, volumeMount := range container.VolumeMounts {
+			mountPaths[volumeMount.Name] = volumeMount.MountPath
+		}
+	}
+
+	volumes := []corev1.Volume{}
+	volumeMounts := []corev1.VolumeMount{}
+	addVolume := func(volume corev1.Volume) {
+		volumes = append(volumes, corev1.Volume{
+			Name: volume.Name,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: volume.PersistentVolumeClaim.ClaimName,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      volume.Name,
+			MountPath: mountPaths[volume.Name],
+		})
+	}
+
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == serverDataVolumeName && volume.PersistentVolumeClaim != nil {
+			addVolume(volume)
+		}
+	}
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == serverDataVolumeName || volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		if _, ok := mountPaths[volume.Name]; ok {
+			addVolume(volume)
+		}
+	}
+
+	return volumes, volumeMounts
+}
+
+func getServerDataClaimName(pod *corev1.Pod) string {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == serverDataVolumeName && volume.PersistentVolumeClaim != nil {
+			return volume.PersistentVolumeClaim.ClaimName
+		}
+	}
+	return ""
+}
+
+// getJobName returns the name of the Job of a backup or restore for a pod,
+// shortened with a hash when it would be too long
+func getJobName(owner string, podName string) string {
+	name := fmt.Sprintf("%s-%s", owner, podName)
+	if len(name) <= maxJobNameLength {
+		return name
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(name))
+	suffix := fmt.Sprintf("-%x", hash.Sum32())
+	return name[:maxJobNameLength-len(suffix)] + suffix
+}
+
+func isJobSucceeded(job *batchv1.Job) bool {
+	return isJobConditionTrue(job, batchv1.JobComplete)
+}
+
+func isJobFailed(job *batchv1.Job) bool {
+	return isJobConditionTrue(job, batchv1.JobFailed)
+}
+
+func isJobConditionTrue(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
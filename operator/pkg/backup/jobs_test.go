@@ -0,0 +1,123 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package backup
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/datastax/cass-operator/operator/internal/dccontext"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+)
+
+func getEnvValue(container corev1.Container, name string) string {
+	for _, env := range container.Env {
+		if env.Name == name {
+			return env.Value
+		}
+	}
+	return ""
+}
+
+func Test_newUploadJob(t *testing.T) {
+	dc := newTestDatacenter()
+	pod := newTestPods(dc, dccontext.NodeStateStarted)[0]
+	backup := newTestBackup(dc)
+	backup.Spec.Storage.Prefix = "prod"
+
+	job, err := newUploadJob(backup, pod)
+	assert.NoError(t, err)
+
+	podSpec := job.Spec.Template.Spec
+	assert.Equal(t, pod.Spec.NodeName, podSpec.NodeName, "Should run on the node of the pod")
+	assert.Equal(t, corev1.RestartPolicyNever, podSpec.RestartPolicy)
+	assert.Equal(t, pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName,
+		podSpec.Volumes[0].PersistentVolumeClaim.ClaimName,
+		"Should mount the data volume of the pod")
+
+	container := podSpec.Containers[0]
+	assert.Equal(t, "amazon/aws-cli:2.0.30", container.Image)
+	assert.Equal(t, serverDataMountPath, container.VolumeMounts[0].MountPath)
+	assert.Equal(t, "minio-credentials", container.EnvFrom[0].SecretRef.Name)
+	assert.Equal(t, "http://minio.minio.svc.cluster.local:9000", getEnvValue(container, "S3_ENDPOINT"))
+	assert.Equal(t, "backups", getEnvValue(container, "S3_BUCKET"))
+	assert.Equal(t, "prod/dc1/nightly/"+pod.Name, getEnvValue(container, "S3_PATH"))
+	assert.Equal(t, "nightly", getEnvValue(container, "SNAPSHOT_NAME"))
+	assert.Equal(t, "us-east-1", getEnvValue(container, "AWS_DEFAULT_REGION"))
+	assert.Equal(t, "nightly", job.Labels[api.BackupLabel])
+}
+
+func Test_newStagingJob(t *testing.T) {
+	dc := newTestDatacenter()
+	pod := newTestPods(dc, dccontext.NodeStateReadyToStart)[1]
+	backup := newTestBackup(dc)
+	backup.Spec.Image = "example/aws-cli:latest"
+	backup.Spec.Storage.Region = "eu-west-1"
+	restore := newTestRestore(dc, backup)
+
+	job, err := newStagingJob(restore, backup, pod)
+	assert.NoError(t, err)
+
+	container := job.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "example/aws-cli:latest", container.Image, "Should default to the image of the backup")
+	assert.Equal(t, "dc1/nightly/"+pod.Name, getEnvValue(container, "S3_PATH"))
+	assert.Equal(t, "eu-west-1", getEnvValue(container, "AWS_DEFAULT_REGION"))
+	assert.Equal(t, restore.Name, job.Labels[api.BackupLabel])
+}
+
+func Test_newUploadJob_NoDataVolume(t *testing.T) {
+	dc := newTestDatacenter()
+	pod := newTestPods(dc, dccontext.NodeStateStarted)[0]
+	pod.Spec.Volumes = nil
+
+	_, err := newUploadJob(newTestBackup(dc), pod)
+	assert.Error(t, err)
+}
+
+func Test_newStagingJob_CommitLogVolume(t *testing.T) {
+	dc := newTestDatacenter()
+	pod := newTestPods(dc, dccontext.NodeStateReadyToStart)[0]
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: "server-commitlog",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: "server-commitlog-" + pod.Name,
+			},
+		},
+	})
+	pod.Spec.Containers = []corev1.Container{{
+		Name: "cassandra",
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: serverDataVolumeName, MountPath: serverDataMountPath},
+			{Name: "server-commitlog", MountPath: "/var/lib/cassandra/commitlog"},
+		},
+	}}
+	backup := newTestBackup(dc)
+
+	job, err := newStagingJob(newTestRestore(dc, backup), backup, pod)
+	assert.NoError(t, err)
+
+	podSpec := job.Spec.Template.Spec
+	if assert.Equal(t, 2, len(podSpec.Volumes)) {
+		assert.Equal(t, "server-commitlog-"+pod.Name, podSpec.Volumes[1].PersistentVolumeClaim.ClaimName)
+	}
+	volumeMounts := podSpec.Containers[0].VolumeMounts
+	if assert.Equal(t, 2, len(volumeMounts)) {
+		assert.Equal(t, serverDataMountPath, volumeMounts[0].MountPath)
+		assert.Equal(t, "/var/lib/cassandra/commitlog", volumeMounts[1].MountPath,
+			"Should mount the commit log where the server does, so staging clears it")
+	}
+}
+
+func Test_getJobName(t *testing.T) {
+	assert.Equal(t, "nightly-cluster1-dc1-default-sts-0", getJobName("nightly", "cluster1-dc1-default-sts-0"))
+
+	long := getJobName(strings.Repeat("a", 40), "cluster1-dc1-default-sts-0")
+	assert.Equal(t, maxJobNameLength, len(long))
+	assert.NotEqual(t, long, getJobName(strings.Repeat("a", 40), "cluster1-dc1-default-sts-1"),
+		"Shortened names should stay unique")
+}
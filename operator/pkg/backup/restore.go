@@ -0,0 +1,298 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package backup
+
+import (
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/datastax/cass-operator/operator/internal/dccontext"
+	"github.com/datastax/cass-operator/operator/internal/result"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/events"
+)
+
+// ReconcileRestore restores a backup in place. The datacenter is stopped,
+// and when its pods come back the backup is staged onto their volumes before
+// Cassandra is started on them.
+func (rc *ReconciliationContext) ReconcileRestore(restore *api.CassandraRestore) result.ReconcileResult {
+	rc.ReqLogger.Info("backup::ReconcileRestore")
+
+	if restore.IsFinished() {
+		return result.Done()
+	}
+
+	backup, recResult := rc.CheckRestoreBackup(restore)
+	if recResult.Completed() {
+		return recResult
+	}
+
+	if recResult := rc.CheckDatacenterStopped(restore); recResult.Completed() {
+		return recResult
+	}
+
+	if recResult := rc.CheckRestoreStaging(restore, backup); recResult.Completed() {
+		return recResult
+	}
+
+	return rc.CheckRestoreComplete(restore)
+}
+
+// CheckRestoreBackup fetches the backup to restore and makes sure it can be
+// restored onto the datacenter
+func (rc *ReconciliationContext) CheckRestoreBackup(restore *api.CassandraRestore) (*api.CassandraBackup, result.ReconcileResult) {
+	logger := rc.ReqLogger
+	logger.Info("backup::CheckRestoreBackup")
+
+	backup := &api.CassandraBackup{}
+	err := rc.Client.Get(rc.Ctx, types.NamespacedName{Namespace: restore.Namespace, Name: restore.Spec.Backup}, backup)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, rc.failRestore(restore, fmt.Errorf("backup %s not found", restore.Spec.Backup))
+		}
+		return nil, result.Error(err)
+	}
+
+	if backup.Status.Conditions.GetConditionStatus(api.BackupFailed) == corev1.ConditionTrue {
+		return nil, rc.failRestore(restore, fmt.Errorf("backup %s failed", backup.Name))
+	}
+
+	if backup.Status.Conditions.GetConditionStatus(api.BackupComplete) != corev1.ConditionTrue {
+		logger.Info("Waiting for backup to complete", "backup", backup.Name)
+		return nil, result.RequeueSoon(10)
+	}
+
+	if backup.Spec.CassandraDatacenter != restore.Spec.CassandraDatacenter {
+		return nil, rc.failRestore(restore, fmt.Errorf(
+			"backup %s is of datacenter %s, backups can only be restored to the datacenter they were taken from",
+			backup.Name, backup.Spec.CassandraDatacenter))
+	}
+
+	if int(rc.Datacenter.Spec.Size) != len(backup.Status.Pods) {
+		return nil, rc.failRestore(restore, fmt.Errorf(
+			"datacenter has %d nodes but backup %s has %d",
+			rc.Datacenter.Spec.Size, backup.Name, len(backup.Status.Pods)))
+	}
+
+	return backup, result.Continue()
+}
+
+// CheckDatacenterStopped stops the datacenter and waits for all of its pods
+// to be gone
+func (rc *ReconciliationContext) CheckDatacenterStopped(restore *api.CassandraRestore) result.ReconcileResult {
+	logger := rc.ReqLogger
+	logger.Info("backup::CheckDatacenterStopped")
+	dc := rc.Datacenter
+
+	if restore.Status.Conditions.GetConditionStatus(api.BackupStopped) == corev1.ConditionTrue {
+		return result.Continue()
+	}
+
+	if !dc.Spec.Stopped {
+		if restore.Status.StartTime.IsZero() {
+			patch := client.MergeFrom(restore.DeepCopy())
+			restore.Status.StartTime = metav1.Now()
+			if err := rc.Client.Status().Patch(rc.Ctx, restore, patch); err != nil {
+				logger.Error(err, "error patching restore status for restore started")
+				return result.Error(err)
+			}
+		}
+
+		if err := rc.setDatacenterStopped(true); err != nil {
+			return result.Error(err)
+		}
+
+		rc.Recorder.Eventf(restore, corev1.EventTypeNormal, events.StoppingDatacenter,
+			"Stopping datacenter %s to restore backup %s", dc.Name, restore.Spec.Backup)
+
+		return result.RequeueSoon(10)
+	}
+
+	if len(rc.Pods) > 0 {
+		logger.Info("Waiting for the pods of the datacenter to terminate")
+		return result.RequeueSoon(10)
+	}
+
+	patch := client.MergeFrom(restore.DeepCopy())
+	restore.Status.Conditions.SetCondition(*api.NewBackupCondition(api.BackupStopped, corev1.ConditionTrue))
+	if restore.Status.StartTime.IsZero() {
+		restore.Status.StartTime = metav1.Now()
+	}
+	if err := rc.Client.Status().Patch(rc.Ctx, restore, patch); err != nil {
+		logger.Error(err, "error patching restore status for datacenter stopped")
+		return result.Error(err)
+	}
+
+	return result.Continue()
+}
+
+// CheckRestoreStaging resumes the datacenter and runs a Job for each of its
+// pods to copy the backup onto the pod's data volume. The datacenter
+// reconciler will not start Cassandra on a pod until its Job has succeeded.
+func (rc *ReconciliationContext) CheckRestoreStaging(restore *api.CassandraRestore, backup *api.CassandraBackup) result.ReconcileResult {
+	logger := rc.ReqLogger
+	logger.Info("backup::CheckRestoreStaging")
+	dc := rc.Datacenter
+
+	if restore.Status.Conditions.GetConditionStatus(api.BackupStaging) != corev1.ConditionTrue {
+		// Mark the restore as staging before resuming the datacenter, so
+		// the datacenter reconciler knows to wait for it
+		patch := client.MergeFrom(restore.DeepCopy())
+		restore.Status.Conditions.SetCondition(*api.NewBackupCondition(api.BackupStaging, corev1.ConditionTrue))
+		if err := rc.Client.Status().Patch(rc.Ctx, restore, patch); err != nil {
+			logger.Error(err, "error patching restore status for staging started")
+			return result.Error(err)
+		}
+
+		if err := rc.setDatacenterStopped(false); err != nil {
+			return result.Error(err)
+		}
+
+		return result.RequeueSoon(10)
+	}
+
+	patch := client.MergeFrom(restore.DeepCopy())
+	if restore.Status.Pods == nil {
+		restore.Status.Pods = map[string]api.PodBackupStatus{}
+	}
+	pending := len(rc.Pods) < int(dc.Spec.Size)
+	updated := false
+	var failure error
+
+	for _, pod := range rc.Pods {
+		if restore.IsPodStaged(pod.Name) {
+			continue
+		}
+
+		if !dccontext.IsServerReadyToStart(pod) || pod.Spec.NodeName == "" {
+			logger.Info("Waiting for pod to be scheduled before staging backup", "pod", pod.Name)
+			pending = true
+			continue
+		}
+
+		if _, ok := backup.Status.Pods[pod.Name]; !ok {
+			failure = fmt.Errorf("backup %s has no data for pod %s", backup.Name, pod.Name)
+			break
+		}
+
+		job, err := rc.getOrCreateJob(restore, getJobName(restore.Name, pod.Name), func() (*batchv1.Job, error) {
+			return newStagingJob(restore, backup, pod)
+		})
+		if err != nil {
+			logger.Error(err, "error creating staging job", "pod", pod.Name)
+			return result.Error(err)
+		}
+
+		if isJobFailed(job) {
+			failure = fmt.Errorf("staging of backup failed for pod %s, see job %s", pod.Name, job.Name)
+			break
+		}
+
+		if !isJobSucceeded(job) {
+			pending = true
+			continue
+		}
+
+		podStatus := restore.Status.Pods[pod.Name]
+		podStatus.Conditions.SetCondition(*api.NewBackupCondition(api.BackupStaged, corev1.ConditionTrue))
+		restore.Status.Pods[pod.Name] = podStatus
+		updated = true
+
+		rc.Recorder.Eventf(restore, corev1.EventTypeNormal, events.StagedRestore,
+			"Staged backup %s for pod %s", backup.Name, pod.Name)
+	}
+
+	if failure != nil {
+		// Keep Cassandra from starting on top of a partially staged backup
+		if err := rc.setDatacenterStopped(true); err != nil {
+			return result.Error(err)
+		}
+		return rc.failRestore(restore, failure)
+	}
+
+	if updated {
+		if err := rc.Client.Status().Patch(rc.Ctx, restore, patch); err != nil {
+			logger.Error(err, "error patching restore status for staged pods")
+			return result.Error(err)
+		}
+	}
+
+	if pending {
+		return result.RequeueSoon(10)
+	}
+
+	return result.Continue()
+}
+
+// CheckRestoreComplete marks the restore as complete once Cassandra has been
+// started on every pod
+func (rc *ReconciliationContext) CheckRestoreComplete(restore *api.CassandraRestore) result.ReconcileResult {
+	logger := rc.ReqLogger
+	logger.Info("backup::CheckRestoreComplete")
+	dc := rc.Datacenter
+
+	for _, pod := range rc.Pods {
+		if !dccontext.IsServerStarted(pod) {
+			logger.Info("Waiting for Cassandra to be started on restored pods")
+			return result.RequeueSoon(10)
+		}
+	}
+
+	if dc.GetConditionStatus(api.DatacenterReady) != corev1.ConditionTrue {
+		logger.Info("Waiting for datacenter to be ready")
+		return result.RequeueSoon(10)
+	}
+
+	patch := client.MergeFrom(restore.DeepCopy())
+	restore.Status.Conditions.SetCondition(*api.NewBackupCondition(api.BackupStaging, corev1.ConditionFalse))
+	restore.Status.Conditions.SetCondition(*api.NewBackupCondition(api.BackupComplete, corev1.ConditionTrue))
+	restore.Status.FinishTime = metav1.Now()
+	if err := rc.Client.Status().Patch(rc.Ctx, restore, patch); err != nil {
+		logger.Error(err, "error patching restore status for restore complete")
+		return result.Error(err)
+	}
+
+	rc.Recorder.Eventf(restore, corev1.EventTypeNormal, events.CompletedRestore,
+		"Restored backup %s to datacenter %s", restore.Spec.Backup, dc.Name)
+
+	return result.Done()
+}
+
+func (rc *ReconciliationContext) failRestore(restore *api.CassandraRestore, failure error) result.ReconcileResult {
+	patch := client.MergeFrom(restore.DeepCopy())
+	condition := api.NewBackupCondition(api.BackupFailed, corev1.ConditionTrue)
+	condition.Message = failure.Error()
+	restore.Status.Conditions.SetCondition(*condition)
+	restore.Status.FinishTime = metav1.Now()
+	if err := rc.Client.Status().Patch(rc.Ctx, restore, patch); err != nil {
+		rc.ReqLogger.Error(err, "error patching restore status for restore failed")
+		return result.Error(err)
+	}
+
+	rc.Recorder.Eventf(restore, corev1.EventTypeWarning, events.FailedRestore,
+		"Restore failed: %s", failure.Error())
+
+	return result.Done()
+}
+
+func (rc *ReconciliationContext) setDatacenterStopped(stopped bool) error {
+	dc := rc.Datacenter
+	if dc.Spec.Stopped == stopped {
+		return nil
+	}
+
+	patch := client.MergeFrom(dc.DeepCopy())
+	dc.Spec.Stopped = stopped
+	if err := rc.Client.Patch(rc.Ctx, dc, patch); err != nil {
+		rc.ReqLogger.Error(err, "error patching datacenter", "stopped", stopped)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package backup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/datastax/cass-operator/operator/internal/dccontext"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+)
+
+func newTestCompletedBackup(dc *api.CassandraDatacenter, pods []*corev1.Pod) *api.CassandraBackup {
+	backup := newTestBackup(dc)
+	backup.Status.Conditions = api.BackupConditions{
+		*api.NewBackupCondition(api.BackupComplete, corev1.ConditionTrue),
+	}
+	backup.Status.Pods = map[string]api.PodBackupStatus{}
+	for _, pod := range pods {
+		backup.Status.Pods[pod.Name] = api.PodBackupStatus{
+			Conditions: api.BackupConditions{
+				*api.NewBackupCondition(api.BackupUploaded, corev1.ConditionTrue),
+			},
+		}
+	}
+	return backup
+}
+
+func newTestRestore(dc *api.CassandraDatacenter, backup *api.CassandraBackup) *api.CassandraRestore {
+	return &api.CassandraRestore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "restore-nightly",
+			Namespace: dc.Namespace,
+		},
+		Spec: api.CassandraRestoreSpec{
+			CassandraDatacenter: dc.Name,
+			Backup:              backup.Name,
+		},
+	}
+}
+
+func TestReconcileRestore_StopsDatacenter(t *testing.T) {
+	dc := newTestDatacenter()
+	pods := newTestPods(dc, dccontext.NodeStateStarted)
+	backup := newTestCompletedBackup(dc, pods)
+	restore := newTestRestore(dc, backup)
+	rc, _ := setupTest(dc, pods, backup, restore)
+
+	recResult := rc.ReconcileRestore(restore)
+	assert.True(t, recResult.Completed())
+
+	updated := &api.CassandraDatacenter{}
+	err := rc.Client.Get(rc.Ctx, types.NamespacedName{Namespace: dc.Namespace, Name: dc.Name}, updated)
+	assert.NoError(t, err)
+	assert.True(t, updated.Spec.Stopped, "Should stop the datacenter")
+	assert.False(t, restore.Status.StartTime.IsZero())
+
+	// The pods are still around
+	recResult = rc.ReconcileRestore(restore)
+	assert.True(t, recResult.Completed())
+	assert.Equal(t, corev1.ConditionFalse, restore.Status.Conditions.GetConditionStatus(api.BackupStopped))
+}
+
+func TestReconcileRestore_ResumesDatacenterForStaging(t *testing.T) {
+	dc := newTestDatacenter()
+	dc.Spec.Stopped = true
+	pods := newTestPods(dc, dccontext.NodeStateReadyToStart)
+	backup := newTestCompletedBackup(dc, pods)
+	restore := newTestRestore(dc, backup)
+	rc, _ := setupTest(dc, nil, backup, restore)
+
+	recResult := rc.ReconcileRestore(restore)
+	assert.True(t, recResult.Completed())
+	assert.Equal(t, corev1.ConditionTrue, restore.Status.Conditions.GetConditionStatus(api.BackupStopped))
+	assert.True(t, restore.IsStaging())
+
+	updated := &api.CassandraDatacenter{}
+	err := rc.Client.Get(rc.Ctx, types.NamespacedName{Namespace: dc.Namespace, Name: dc.Name}, updated)
+	assert.NoError(t, err)
+	assert.False(t, updated.Spec.Stopped, "Should resume the datacenter once the restore is staging")
+}
+
+func TestReconcileRestore_StagesPods(t *testing.T) {
+	dc := newTestDatacenter()
+	pods := newTestPods(dc, dccontext.NodeStateReadyToStart)
+	backup := newTestCompletedBackup(dc, pods)
+	restore := newTestRestore(dc, backup)
+	restore.Status.Conditions = api.BackupConditions{
+		*api.NewBackupCondition(api.BackupStopped, corev1.ConditionTrue),
+		*api.NewBackupCondition(api.BackupStaging, corev1.ConditionTrue),
+	}
+	rc, _ := setupTest(dc, pods, backup, restore)
+
+	recResult := rc.ReconcileRestore(restore)
+	assert.True(t, recResult.Completed(), "Should requeue while staging")
+	assert.False(t, restore.IsPodStaged(pods[0].Name))
+
+	setJobCondition(t, rc, getJobName(restore.Name, pods[0].Name), batchv1.JobComplete)
+
+	recResult = rc.ReconcileRestore(restore)
+	assert.True(t, recResult.Completed(), "Should requeue while staging")
+	assert.True(t, restore.IsPodStaged(pods[0].Name))
+	assert.False(t, restore.IsPodStaged(pods[1].Name))
+}
+
+func TestReconcileRestore_StagingFails(t *testing.T) {
+	dc := newTestDatacenter()
+	pods := newTestPods(dc, dccontext.NodeStateReadyToStart)
+	backup := newTestCompletedBackup(dc, pods)
+	restore := newTestRestore(dc, backup)
+	restore.Status.Conditions = api.BackupConditions{
+		*api.NewBackupCondition(api.BackupStopped, corev1.ConditionTrue),
+		*api.NewBackupCondition(api.BackupStaging, corev1.ConditionTrue),
+	}
+	rc, _ := setupTest(dc, pods, backup, restore)
+
+	rc.ReconcileRestore(restore)
+	setJobCondition(t, rc, getJobName(restore.Name, pods[1].Name), batchv1.JobFailed)
+
+	recResult := rc.ReconcileRestore(restore)
+	assert.True(t, recResult.Completed())
+	assert.Equal(t, corev1.ConditionTrue, restore.Status.Conditions.GetConditionStatus(api.BackupFailed))
+	assert.False(t, restore.IsStaging())
+
+	updated := &api.CassandraDatacenter{}
+	err := rc.Client.Get(rc.Ctx, types.NamespacedName{Namespace: dc.Namespace, Name: dc.Name}, updated)
+	assert.NoError(t, err)
+	assert.True(t, updated.Spec.Stopped, "Should stop the datacenter when staging fails")
+}
+
+func TestReconcileRestore_Completes(t *testing.T) {
+	dc := newTestDatacenter()
+	dc.SetCondition(*api.NewDatacenterCondition(api.DatacenterReady, corev1.ConditionTrue))
+	pods := newTestPods(dc, dccontext.NodeStateStarted)
+	backup := newTestCompletedBackup(dc, pods)
+	restore := newTestRestore(dc, backup)
+	restore.Status.Conditions = api.BackupConditions{
+		*api.NewBackupCondition(api.BackupStopped, corev1.ConditionTrue),
+		*api.NewBackupCondition(api.BackupStaging, corev1.ConditionTrue),
+	}
+	restore.Status.Pods = map[string]api.PodBackupStatus{}
+	for _, pod := range pods {
+		restore.Status.Pods[pod.Name] = api.PodBackupStatus{
+			Conditions: api.BackupConditions{
+				*api.NewBackupCondition(api.BackupStaged, corev1.ConditionTrue),
+			},
+		}
+	}
+	rc, _ := setupTest(dc, pods, backup, restore)
+
+	recResult := rc.ReconcileRestore(restore)
+	assert.True(t, recResult.Completed())
+	assert.Equal(t, corev1.ConditionTrue, restore.Status.Conditions.GetConditionStatus(api.BackupComplete))
+	assert.False(t, restore.IsStaging())
+}
+
+func TestReconcileRestore_WrongSize(t *testing.T) {
+	dc := newTestDatacenter()
+	pods := newTestPods(dc, dccontext.NodeStateStarted)
+	backup := newTestCompletedBackup(dc, pods[:1])
+	restore := newTestRestore(dc, backup)
+	rc, _ := setupTest(dc, pods, backup, restore)
+
+	recResult := rc.ReconcileRestore(restore)
+	assert.True(t, recResult.Completed())
+	assert.Equal(t, corev1.ConditionTrue, restore.Status.Conditions.GetConditionStatus(api.BackupFailed))
+	assert.False(t, rc.Datacenter.Spec.Stopped, "Should not touch the datacenter")
+}
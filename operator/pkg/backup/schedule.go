@@ -0,0 +1,232 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package backup
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/datastax/cass-operator/operator/internal/cron"
+	"github.com/datastax/cass-operator/operator/internal/dccontext"
+	"github.com/datastax/cass-operator/operator/internal/result"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/events"
+)
+
+// ReconcileSnapshotSchedule takes a snapshot on every started node of the
+// datacenter when one is due, clears the snapshots that fall out of the
+// retention policy and requeues for the next scheduled time
+func (rc *ReconciliationContext) ReconcileSnapshotSchedule(schedule *api.CassandraSnapshotSchedule, now time.Time) result.ReconcileResult {
+	logger := rc.ReqLogger
+	logger.Info("backup::ReconcileSnapshotSchedule")
+
+	if schedule.Spec.Suspend {
+		logger.Info("Snapshot schedule is suspended")
+		return result.Done()
+	}
+
+	cronSchedule, err := cron.Parse(schedule.Spec.Schedule)
+	if err != nil {
+		// Nothing will change until the schedule is edited, which triggers
+		// a new reconcile
+		logger.Error(err, "invalid snapshot schedule")
+		rc.Recorder.Eventf(schedule, corev1.EventTypeWarning, events.InvalidSnapshotSchedule,
+			"Invalid schedule: %s", err.Error())
+		return result.Done()
+	}
+
+	if recResult := rc.CheckScheduledSnapshot(schedule, cronSchedule, now); recResult.Completed() {
+		return recResult
+	}
+
+	if recResult := rc.CheckSnapshotRetention(schedule, now); recResult.Completed() {
+		return recResult
+	}
+
+	next := cronSchedule.Next(now)
+	if next.IsZero() {
+		logger.Info("Snapshot schedule will never run again")
+		return result.Done()
+	}
+
+	logger.Info("Waiting for next scheduled snapshot", "time", next)
+	return result.RequeueSoon(int(math.Ceil(next.Sub(now).Seconds())))
+}
+
+// CheckScheduledSnapshot takes a snapshot on every started node when a
+// scheduled time has passed since the last one. Like a CronJob, missed
+// schedules are not made up for, only the most recent one is taken.
+func (rc *ReconciliationContext) CheckScheduledSnapshot(schedule *api.CassandraSnapshotSchedule, cronSchedule *cron.Schedule, now time.Time) result.ReconcileResult {
+	logger := rc.ReqLogger
+	logger.Info("backup::CheckScheduledSnapshot")
+
+	scheduledTime := getMostRecentScheduledTime(schedule, cronSchedule, now)
+	if scheduledTime.IsZero() {
+		return result.Continue()
+	}
+
+	snapshotName := schedule.GetSnapshotName(scheduledTime)
+	logger.Info("Taking scheduled snapshot", "snapshot", snapshotName)
+
+	started := 0
+	failures := []string{}
+	for _, pod := range rc.Pods {
+		if !dccontext.IsServerStarted(pod) {
+			continue
+		}
+		started++
+
+		err := rc.NodeMgmtClient.CallCreateSnapshotEndpoint(pod, snapshotName, schedule.Spec.Keyspaces)
+		if err != nil {
+			logger.Error(err, "error taking scheduled snapshot", "pod", pod.Name)
+			failures = append(failures, fmt.Sprintf("%s: %s", pod.Name, err.Error()))
+		}
+	}
+
+	if started == 0 {
+		failures = append(failures, "no started nodes in the datacenter")
+	}
+
+	patch := client.MergeFrom(schedule.DeepCopy())
+	scheduled := metav1.NewTime(scheduledTime)
+	finished := metav1.NewTime(now)
+	schedule.Status.LastScheduleTime = &scheduled
+	if len(failures) == 0 {
+		schedule.Status.LastSuccessfulTime = &finished
+		rc.Recorder.Eventf(schedule, corev1.EventTypeNormal, events.CreatedSnapshot,
+			"Created snapshot %s on %d started nodes", snapshotName, started)
+	} else {
+		message := fmt.Sprintf("Failed to create snapshot %s: %s", snapshotName, strings.Join(failures, "; "))
+		schedule.Status.LastFailureTime = &finished
+		schedule.Status.LastFailureMessage = message
+		rc.Recorder.Event(schedule, corev1.EventTypeWarning, events.FailedSnapshot, message)
+	}
+
+	if err := rc.Client.Status().Patch(rc.Ctx, schedule, patch); err != nil {
+		logger.Error(err, "error patching snapshot schedule status")
+		return result.Error(err)
+	}
+
+	return result.Continue()
+}
+
+// CheckSnapshotRetention clears the snapshots of the schedule that are not
+// kept by its retention policy from every started node, and records the
+// snapshots that remain. Snapshots are found by name on the nodes, so ones
+// missed while a node was down are cleared once it is started again.
+func (rc *ReconciliationContext) CheckSnapshotRetention(schedule *api.CassandraSnapshotSchedule, now time.Time) result.ReconcileResult {
+	logger := rc.ReqLogger
+	logger.Info("backup::CheckSnapshotRetention")
+
+	snapshotTimes := map[string]time.Time{}
+	podSnapshots := map[string][]string{}
+	for _, pod := range rc.Pods {
+		if !dccontext.IsServerStarted(pod) {
+			continue
+		}
+
+		details, err := rc.NodeMgmtClient.CallListSnapshotsEndpoint(pod)
+		if err != nil {
+			logger.Error(err, "error listing snapshots", "pod", pod.Name)
+			continue
+		}
+
+		// There is an entry per table, so the names repeat
+		seen := map[string]bool{}
+		for _, detail := range details {
+			t, ok := schedule.ParseSnapshotTime(detail.SnapshotName)
+			if !ok || seen[detail.SnapshotName] {
+				continue
+			}
+			seen[detail.SnapshotName] = true
+			snapshotTimes[detail.SnapshotName] = t
+			podSnapshots[pod.Name] = append(podSnapshots[pod.Name], detail.SnapshotName)
+		}
+	}
+
+	kept := getRetainedSnapshots(schedule.Spec.Retention, snapshotTimes, now)
+
+	for _, pod := range rc.Pods {
+		expired := []string{}
+		for _, name := range podSnapshots[pod.Name] {
+			if !kept[name] {
+				expired = append(expired, name)
+			}
+		}
+		if len(expired) == 0 {
+			continue
+		}
+
+		sort.Strings(expired)
+		if err := rc.NodeMgmtClient.CallClearSnapshotEndpoint(pod, expired...); err != nil {
+			// The snapshots will be found again next time around
+			logger.Error(err, "error clearing expired snapshots", "pod", pod.Name)
+			continue
+		}
+		rc.Recorder.Eventf(schedule, corev1.EventTypeNormal, events.ClearedSnapshot,
+			"Cleared snapshots %s on pod %s", strings.Join(expired, ", "), pod.Name)
+	}
+
+	snapshots := []string{}
+	for name := range kept {
+		snapshots = append(snapshots, name)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshotTimes[snapshots[i]].After(snapshotTimes[snapshots[j]])
+	})
+
+	if strings.Join(snapshots, ",") != strings.Join(schedule.Status.Snapshots, ",") {
+		patch := client.MergeFrom(schedule.DeepCopy())
+		schedule.Status.Snapshots = snapshots
+		if err := rc.Client.Status().Patch(rc.Ctx, schedule, patch); err != nil {
+			logger.Error(err, "error patching snapshot schedule status")
+			return result.Error(err)
+		}
+	}
+
+	return result.Continue()
+}
+
+// getMostRecentScheduledTime returns the latest time the schedule was due
+// at, after the last snapshot it took and no later than now. It returns the
+// zero time when no snapshot is due.
+func getMostRecentScheduledTime(schedule *api.CassandraSnapshotSchedule, cronSchedule *cron.Schedule, now time.Time) time.Time {
+	last := schedule.CreationTimestamp.Time
+	if schedule.Status.LastScheduleTime != nil {
+		last = schedule.Status.LastScheduleTime.Time
+	}
+
+	return cronSchedule.MostRecent(last, now)
+}
+
+// getRetainedSnapshots returns the names of the snapshots that are kept by
+// the retention policy
+func getRetainedSnapshots(retention api.SnapshotRetention, snapshotTimes map[string]time.Time, now time.Time) map[string]bool {
+	names := []string{}
+	for name := range snapshotTimes {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return snapshotTimes[names[i]].After(snapshotTimes[names[j]])
+	})
+
+	kept := map[string]bool{}
+	for idx, name := range names {
+		if retention.KeepLast != nil && idx >= int(*retention.KeepLast) {
+			continue
+		}
+		if retention.MaxAge != nil && now.Sub(snapshotTimes[name]) > retention.MaxAge.Duration {
+			continue
+		}
+		kept[name] = true
+	}
+	return kept
+}
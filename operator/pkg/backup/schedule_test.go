@@ -0,0 +1,208 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package backup
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/datastax/cass-operator/operator/internal/dccontext"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/httphelper"
+	"github.com/datastax/cass-operator/operator/pkg/mocks"
+)
+
+func parseTime(t *testing.T, value string) time.Time {
+	parsed, err := time.Parse(time.RFC3339, value)
+	assert.NoError(t, err)
+	return parsed
+}
+
+func newTestSnapshotSchedule(dc *api.CassandraDatacenter, created time.Time) *api.CassandraSnapshotSchedule {
+	return &api.CassandraSnapshotSchedule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "daily",
+			Namespace:         dc.Namespace,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: api.CassandraSnapshotScheduleSpec{
+			CassandraDatacenter: dc.Name,
+			Schedule:            "0 2 * * *",
+		},
+	}
+}
+
+// mockSnapshots makes the management API of every pod report the given
+// snapshots, one entry per table, and returns the requests it receives
+func mockSnapshots(rc *ReconciliationContext, snapshotNames ...string) *[]*http.Request {
+	details := []httphelper.SnapshotDetails{}
+	for _, name := range snapshotNames {
+		for _, table := range []string{"table1", "table2"} {
+			details = append(details, httphelper.SnapshotDetails{
+				SnapshotName: name,
+				Keyspace:     "ks",
+				Table:        table,
+			})
+		}
+	}
+	body, _ := json.Marshal(details)
+
+	mgmtApi := mocks.NewManagementApi(nil)
+	mgmtApi.Respond = func(req *http.Request) string {
+		if req.Method == http.MethodGet {
+			return string(body)
+		}
+		return ""
+	}
+
+	rc.NodeMgmtClient.Client = mgmtApi.HttpClient()
+	return &mgmtApi.Requests
+}
+
+func filterRequests(requests []*http.Request, method string) []*http.Request {
+	filtered := []*http.Request{}
+	for _, req := range requests {
+		if req.Method == method && req.URL.Path == "/api/v0/ops/node/snapshots" {
+			filtered = append(filtered, req)
+		}
+	}
+	return filtered
+}
+
+func TestReconcileSnapshotSchedule_NotDue(t *testing.T) {
+	dc := newTestDatacenter()
+	pods := newTestPods(dc, dccontext.NodeStateStarted)
+	now := parseTime(t, "2020-07-01T01:00:00Z")
+	schedule := newTestSnapshotSchedule(dc, now.Add(-time.Minute))
+	rc, _ := setupTest(dc, pods, schedule)
+	requests := mockSnapshots(rc)
+
+	recResult := rc.ReconcileSnapshotSchedule(schedule, now)
+	assert.True(t, recResult.Completed())
+
+	res, err := recResult.Output()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Hour, res.RequeueAfter, "Should requeue for the next scheduled time")
+	assert.Empty(t, filterRequests(*requests, http.MethodPost))
+	assert.Nil(t, schedule.Status.LastScheduleTime)
+}
+
+func TestReconcileSnapshotSchedule_TakesSnapshot(t *testing.T) {
+	dc := newTestDatacenter()
+	pods := newTestPods(dc, dccontext.NodeStateStarted)
+	pods[1].Labels[api.CassNodeState] = dccontext.NodeStateReadyToStart
+	now := parseTime(t, "2020-07-03T02:00:30Z")
+	schedule := newTestSnapshotSchedule(dc, parseTime(t, "2020-07-01T00:00:00Z"))
+	rc, _ := setupTest(dc, pods, schedule)
+	requests := mockSnapshots(rc)
+
+	recResult := rc.ReconcileSnapshotSchedule(schedule, now)
+	assert.True(t, recResult.Completed())
+
+	posts := filterRequests(*requests, http.MethodPost)
+	assert.Equal(t, 1, len(posts), "Should only snapshot started nodes")
+	assert.Equal(t, "10.0.0.1", posts[0].URL.Hostname())
+
+	body, err := ioutil.ReadAll(posts[0].Body)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"daily-20200703-020000"`, "Should only take the most recent missed snapshot")
+
+	assert.Equal(t, parseTime(t, "2020-07-03T02:00:00Z"), schedule.Status.LastScheduleTime.Time.UTC())
+	assert.Equal(t, now, schedule.Status.LastSuccessfulTime.Time.UTC())
+	assert.Nil(t, schedule.Status.LastFailureTime)
+}
+
+func TestReconcileSnapshotSchedule_NoStartedNodes(t *testing.T) {
+	dc := newTestDatacenter()
+	pods := newTestPods(dc, dccontext.NodeStateReadyToStart)
+	now := parseTime(t, "2020-07-01T02:00:00Z")
+	schedule := newTestSnapshotSchedule(dc, parseTime(t, "2020-07-01T00:00:00Z"))
+	rc, _ := setupTest(dc, pods, schedule)
+	mockSnapshots(rc)
+
+	rc.ReconcileSnapshotSchedule(schedule, now)
+
+	assert.Equal(t, now, schedule.Status.LastFailureTime.Time.UTC())
+	assert.Contains(t, schedule.Status.LastFailureMessage, "no started nodes")
+	assert.Nil(t, schedule.Status.LastSuccessfulTime)
+	assert.NotNil(t, schedule.Status.LastScheduleTime, "Should not retry a failed snapshot until the next scheduled time")
+}
+
+func TestReconcileSnapshotSchedule_Retention(t *testing.T) {
+	dc := newTestDatacenter()
+	pods := newTestPods(dc, dccontext.NodeStateStarted)
+	now := parseTime(t, "2020-07-04T03:00:00Z")
+	schedule := newTestSnapshotSchedule(dc, parseTime(t, "2020-06-01T00:00:00Z"))
+	schedule.Status.LastScheduleTime = &metav1.Time{Time: parseTime(t, "2020-07-04T02:00:00Z")}
+	keepLast := int32(2)
+	schedule.Spec.Retention.KeepLast = &keepLast
+	rc, _ := setupTest(dc, pods, schedule)
+	requests := mockSnapshots(rc,
+		"daily-20200701-020000",
+		"daily-20200704-020000",
+		"daily-20200702-020000",
+		"daily-20200703-020000",
+		"weekly-20200628-020000",
+		"manual")
+
+	recResult := rc.ReconcileSnapshotSchedule(schedule, now)
+	assert.True(t, recResult.Completed())
+
+	deletes := filterRequests(*requests, http.MethodDelete)
+	assert.Equal(t, 2, len(deletes), "Should clear expired snapshots on every started node")
+	for _, req := range deletes {
+		assert.Equal(t, []string{"daily-20200701-020000", "daily-20200702-020000"}, req.URL.Query()["snapshotNames"],
+			"Should only clear snapshots of this schedule")
+	}
+	assert.Equal(t, []string{"daily-20200704-020000", "daily-20200703-020000"}, schedule.Status.Snapshots)
+}
+
+func TestReconcileSnapshotSchedule_Suspended(t *testing.T) {
+	dc := newTestDatacenter()
+	pods := newTestPods(dc, dccontext.NodeStateStarted)
+	schedule := newTestSnapshotSchedule(dc, parseTime(t, "2020-07-01T00:00:00Z"))
+	schedule.Spec.Suspend = true
+	rc, _ := setupTest(dc, pods, schedule)
+	requests := mockSnapshots(rc)
+
+	recResult := rc.ReconcileSnapshotSchedule(schedule, parseTime(t, "2020-07-02T00:00:00Z"))
+	assert.True(t, recResult.Completed())
+	assert.Empty(t, *requests)
+}
+
+func Test_getRetainedSnapshots_MaxAge(t *testing.T) {
+	now := parseTime(t, "2020-07-10T00:00:00Z")
+	snapshotTimes := map[string]time.Time{
+		"daily-1": now.Add(-6 * 24 * time.Hour),
+		"daily-2": now.Add(-8 * 24 * time.Hour),
+	}
+
+	kept := getRetainedSnapshots(api.SnapshotRetention{
+		MaxAge: &metav1.Duration{Duration: 7 * 24 * time.Hour},
+	}, snapshotTimes, now)
+	assert.Equal(t, map[string]bool{"daily-1": true}, kept)
+
+	kept = getRetainedSnapshots(api.SnapshotRetention{}, snapshotTimes, now)
+	assert.Equal(t, 2, len(kept), "Should keep everything without a retention policy")
+}
+
+func TestCassandraSnapshotSchedule_ParseSnapshotTime(t *testing.T) {
+	schedule := &api.CassandraSnapshotSchedule{ObjectMeta: metav1.ObjectMeta{Name: "daily"}}
+	scheduled := parseTime(t, "2020-07-01T02:00:00Z")
+
+	parsed, ok := schedule.ParseSnapshotTime(schedule.GetSnapshotName(scheduled))
+	assert.True(t, ok)
+	assert.Equal(t, scheduled, parsed)
+
+	_, ok = schedule.ParseSnapshotTime("daily-backup")
+	assert.False(t, ok)
+	_, ok = schedule.ParseSnapshotTime("weekly-20200701-020000")
+	assert.False(t, ok)
+}
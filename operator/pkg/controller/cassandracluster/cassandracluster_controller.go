@@ -0,0 +1,57 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package cassandracluster
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/topology"
+)
+
+// Add creates a new CassandraCluster Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, topology.NewClusterReconciler(mgr))
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	c, err := controller.New(
+		"cassandracluster-controller",
+		mgr,
+		controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource CassandraCluster
+	err = c.Watch(
+		&source.Kind{Type: &api.CassandraCluster{}},
+		&handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to the CassandraDatacenters of a CassandraCluster,
+	// to pick up when they become ready
+	err = c.Watch(
+		&source.Kind{Type: &api.CassandraDatacenter{}},
+		&handler.EnqueueRequestForOwner{
+			IsController: true,
+			OwnerType:    &api.CassandraCluster{},
+		})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// blank assignment to verify that ReconcileCassandraCluster implements reconciliation.Reconciler
+var _ reconcile.Reconciler = &topology.ReconcileCassandraCluster{}
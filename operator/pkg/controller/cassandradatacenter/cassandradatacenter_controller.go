@@ -52,7 +52,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 
 	// Here we list all the types that we create that are owned by the primary resource.
 	//
-	// Watch for changes to secondary resources StatefulSets, PodDisruptionBudgets, and Services and requeue the
+	// Watch for changes to secondary resources StatefulSets, PodDisruptionBudgets, Services and Deployments and requeue the
 	// CassandraDatacenter that owns them.
 
 	managedByCassandraOperatorPredicate := predicate.Funcs{
@@ -112,6 +112,18 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	err = c.Watch(
+		&source.Kind{Type: &appsv1.Deployment{}},
+		&handler.EnqueueRequestForOwner{
+			IsController: true,
+			OwnerType:    &api.CassandraDatacenter{},
+		},
+		managedByCassandraOperatorPredicate,
+	)
+	if err != nil {
+		return err
+	}
+
 	// Setup watches for Secrets. These secrets are often not owned by or created by
 	// the operator, so we must create a mapping back to the appropriate datacenters.
 
@@ -0,0 +1,46 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package cassandrakeyspace
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/schema"
+)
+
+// Add creates a new CassandraKeyspace Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, schema.NewKeyspaceReconciler(mgr))
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	c, err := controller.New(
+		"cassandrakeyspace-controller",
+		mgr,
+		controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource CassandraKeyspace. The
+	// reconciler requeues itself to check the keyspace for drift.
+	err = c.Watch(
+		&source.Kind{Type: &api.CassandraKeyspace{}},
+		&handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// blank assignment to verify that ReconcileCassandraKeyspace implements reconciliation.Reconciler
+var _ reconcile.Reconciler = &schema.ReconcileCassandraKeyspace{}
@@ -0,0 +1,46 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package cassandrasnapshotschedule
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/backup"
+)
+
+// Add creates a new CassandraSnapshotSchedule Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, backup.NewSnapshotScheduleReconciler(mgr))
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	c, err := controller.New(
+		"cassandrasnapshotschedule-controller",
+		mgr,
+		controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource CassandraSnapshotSchedule. The
+	// reconciler requeues itself for the next scheduled time.
+	err = c.Watch(
+		&source.Kind{Type: &api.CassandraSnapshotSchedule{}},
+		&handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// blank assignment to verify that ReconcileCassandraSnapshotSchedule implements reconciliation.Reconciler
+var _ reconcile.Reconciler = &backup.ReconcileCassandraSnapshotSchedule{}
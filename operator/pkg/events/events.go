@@ -19,12 +19,52 @@ const (
 	UnlabeledPodAsSeed                string = "UnlabeledPodAsSeed"
 	LabeledRackResource               string = "LabeledRackResource"
 	ScalingUpRack                     string = "ScalingUpRack"
+	ScalingDownRack                   string = "ScalingDownRack"
+	DecommissioningNode               string = "DecommissioningNode"
+	DecommissionedNode                string = "DecommissionedNode"
+	FailedDecommission                string = "FailedDecommission"
+	RemovingRack                      string = "RemovingRack"
+	RemovedRack                       string = "RemovedRack"
 	CreatedSuperuser                  string = "CreatedSuperuser" // deprecated
 	CreatedUsers                      string = "CreatedUsers"
 	FinishedReplaceNode               string = "FinishedReplaceNode"
 	ReplacingNode                     string = "ReplacingNode"
 	StartingCassandraAndReplacingNode string = "StartingCassandraAndReplacingNode"
 	StartingCassandra                 string = "StartingCassandra"
+	CreatedSnapshot                   string = "CreatedSnapshot"
+	UploadedBackup                    string = "UploadedBackup"
+	CompletedBackup                   string = "CompletedBackup"
+	FailedBackup                      string = "FailedBackup"
+	StagedRestore                     string = "StagedRestore"
+	CompletedRestore                  string = "CompletedRestore"
+	FailedRestore                     string = "FailedRestore"
+	FailedSnapshot                    string = "FailedSnapshot"
+	ClearedSnapshot                   string = "ClearedSnapshot"
+	InvalidSnapshotSchedule           string = "InvalidSnapshotSchedule"
+	RenewedManagementApiCertificate   string = "RenewedManagementApiCertificate"
+	IssuedManagementApiCertificate    string = "IssuedManagementApiCertificate"
+	CreatedKeyspace                   string = "CreatedKeyspace"
+	AlteredKeyspace                   string = "AlteredKeyspace"
+	KeyspaceDrift                     string = "KeyspaceDrift"
+	AlteredSystemKeyspaces            string = "AlteredSystemKeyspaces"
+	RebuiltNode                       string = "RebuiltNode"
+	FailedRebuild                     string = "FailedRebuild"
+	DatacenterJoinedCluster           string = "DatacenterJoinedCluster"
+	DecommissionBlocked               string = "DecommissionBlocked"
+	RemovingNode                      string = "RemovingNode"
+	RetainedPersistentVolumeClaim     string = "RetainedPersistentVolumeClaim"
+	AdoptedPersistentVolumeClaim      string = "AdoptedPersistentVolumeClaim"
+	CreatedVolumeSnapshot             string = "CreatedVolumeSnapshot"
+	ExpandingVolume                   string = "ExpandingVolume"
+	ExpandedVolume                    string = "ExpandedVolume"
+	VolumeExpansionBlocked            string = "VolumeExpansionBlocked"
+	StartedRepair                     string = "StartedRepair"
+	CompletedRepair                   string = "CompletedRepair"
+	FailedRepair                      string = "FailedRepair"
+	InvalidRepairSchedule             string = "InvalidRepairSchedule"
+	RegisteredReaperCluster           string = "RegisteredReaperCluster"
+	CreatedReaperSchedule             string = "CreatedReaperSchedule"
+	DeletedReaperSchedule             string = "DeletedReaperSchedule"
 )
 
 type LoggingEventRecorder struct {
@@ -0,0 +1,279 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package httphelper
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/utils"
+)
+
+const (
+	autoCertificateDuration    = 365 * 24 * time.Hour
+	autoCertificateRenewBefore = 30 * 24 * time.Hour
+
+	// The CA is only replaced when it is about to expire. The previous one
+	// stays trusted until it expires, so that pods that have not been
+	// restarted yet keep trusting the certificates of each other.
+	autoCaDuration = 10 * 365 * 24 * time.Hour
+
+	autoKeySize = 2048
+)
+
+// AutoManagementApiSecurityProvider secures the Management API the same way
+// the manual strategy does, with secrets that the operator generates from its
+// own CA and renews
+type AutoManagementApiSecurityProvider struct {
+	Namespace string
+	Config    *api.ManagementApiAuthAutoConfig
+
+	// Prefix of the names of the secrets
+	NamePrefix string
+}
+
+func buildAutoApiSecurityProvider(dc *api.CassandraDatacenter) (ManagementApiSecurityProvider, error) {
+	if dc.Spec.ManagementApiAuth.Auto != nil {
+		provider := &AutoManagementApiSecurityProvider{}
+		provider.Config = dc.Spec.ManagementApiAuth.Auto
+		provider.Namespace = dc.ObjectMeta.Namespace
+		provider.NamePrefix = dc.Spec.ClusterName + "-" + dc.Name + "-mgmt-api"
+		return provider, nil
+	}
+	return nil, nil
+}
+
+func (provider *AutoManagementApiSecurityProvider) CaSecretName() string {
+	return provider.NamePrefix + "-ca"
+}
+
+func (provider *AutoManagementApiSecurityProvider) ServerSecretName() string {
+	return provider.NamePrefix + "-server"
+}
+
+func (provider *AutoManagementApiSecurityProvider) ClientSecretName() string {
+	return provider.NamePrefix + "-client"
+}
+
+func (provider *AutoManagementApiSecurityProvider) getDuration() time.Duration {
+	if provider.Config.Duration != nil {
+		return provider.Config.Duration.Duration
+	}
+	return autoCertificateDuration
+}
+
+func (provider *AutoManagementApiSecurityProvider) getRenewBefore() time.Duration {
+	renewBefore := autoCertificateRenewBefore
+	if provider.Config.RenewBefore != nil {
+		renewBefore = provider.Config.RenewBefore.Duration
+	}
+
+	// Otherwise certificates would need renewing as soon as they are issued
+	if duration := provider.getDuration(); renewBefore >= duration {
+		renewBefore = duration / 3
+	}
+	return renewBefore
+}
+
+func (provider *AutoManagementApiSecurityProvider) manual() *ManualManagementApiSecurityProvider {
+	return &ManualManagementApiSecurityProvider{
+		Namespace: provider.Namespace,
+		Config: &api.ManagementApiAuthManualConfig{
+			ClientSecretName: provider.ClientSecretName(),
+			ServerSecretName: provider.ServerSecretName(),
+		},
+	}
+}
+
+func (provider *AutoManagementApiSecurityProvider) GetProtocol() string {
+	return "https"
+}
+
+func (provider *AutoManagementApiSecurityProvider) AddServerSecurity(pod *corev1.PodTemplateSpec) error {
+	return provider.manual().AddServerSecurity(pod)
+}
+
+func (provider *AutoManagementApiSecurityProvider) ValidateConfig(client client.Client, ctx context.Context) []error {
+	return provider.manual().ValidateConfig(client, ctx)
+}
+
+func (provider *AutoManagementApiSecurityProvider) BuildHttpClient(client client.Client, ctx context.Context) (HttpClient, error) {
+	httpClient, err := provider.manual().BuildHttpClient(client, ctx)
+	if errors.IsNotFound(err) {
+		// The datacenter has to be reconciled for the certificate to be
+		// generated in the first place
+		return &unissuedCertificateHttpClient{SecretName: provider.ClientSecretName()}, nil
+	}
+	return httpClient, err
+}
+
+// CaNeedsRenewal returns true when the secret does not hold a usable CA
+// certificate and key, or the CA expires within the renewal period
+func (provider *AutoManagementApiSecurityProvider) CaNeedsRenewal(caSecret *corev1.Secret, now time.Time) bool {
+	caCert, _, err := parseAutoCaSecret(caSecret)
+	if err != nil {
+		return true
+	}
+	return now.Add(provider.getRenewBefore()).After(caCert.NotAfter)
+}
+
+// KeyPairNeedsRenewal returns true when the secret does not hold a key pair
+// signed by one of the CAs the CA secret trusts, or its certificate expires
+// within the renewal period
+func (provider *AutoManagementApiSecurityProvider) KeyPairNeedsRenewal(secret, caSecret *corev1.Secret, now time.Time) bool {
+	if len(validateKeyAndCertificate(secret.Data["tls.crt"], secret.Data["tls.key"], secret.Data["ca.crt"])) > 0 {
+		return true
+	}
+
+	chain, err := pemToCertificateChain(secret.Data["tls.crt"])
+	if err != nil || len(chain) == 0 {
+		return true
+	}
+	trustedCas, err := pemToCertificateChain(caSecret.Data["ca.crt"])
+	if err != nil {
+		return true
+	}
+	signed := false
+	for _, caCert := range trustedCas {
+		if chain[0].CheckSignatureFrom(caCert) == nil {
+			signed = true
+			break
+		}
+	}
+	if !signed {
+		return true
+	}
+	return now.Add(provider.getRenewBefore()).After(chain[0].NotAfter)
+}
+
+// KeyPairSignedByCa returns true when the certificate in the secret is signed
+// by the current CA of the CA secret, rather than by the one it replaced
+func (provider *AutoManagementApiSecurityProvider) KeyPairSignedByCa(secret, caSecret *corev1.Secret) bool {
+	chain, err := pemToCertificateChain(secret.Data["tls.crt"])
+	if err != nil || len(chain) == 0 {
+		return false
+	}
+	caCert, _, err := parseAutoCaSecret(caSecret)
+	return err == nil && chain[0].CheckSignatureFrom(caCert) == nil
+}
+
+// KeyPairTrustsCas returns true when the secret trusts the same CAs as the CA
+// secret
+func (provider *AutoManagementApiSecurityProvider) KeyPairTrustsCas(secret, caSecret *corev1.Secret) bool {
+	return bytes.Equal(secret.Data["ca.crt"], caSecret.Data["ca.crt"])
+}
+
+// GetCaIssueTime returns when the current CA of the CA secret was generated
+func (provider *AutoManagementApiSecurityProvider) GetCaIssueTime(caSecret *corev1.Secret) (time.Time, error) {
+	caCert, _, err := parseAutoCaSecret(caSecret)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return caCert.NotBefore, nil
+}
+
+// NewCaSecret generates a new CA and returns the secret that holds it. The
+// CA of the previous secret, if any, is trusted along with the new one until
+// it expires.
+func (provider *AutoManagementApiSecurityProvider) NewCaSecret(now time.Time, previous *corev1.Secret) (*corev1.Secret, error) {
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: provider.CaSecretName()},
+		NotBefore:             now,
+		NotAfter:              now.Add(autoCaDuration),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+
+	certPem, keyPem, err := utils.GenerateCertificate(template, nil, nil, autoKeySize)
+	if err != nil {
+		return nil, err
+	}
+
+	trustedPem := certPem
+	if previous != nil {
+		if previousCert, _, err := parseAutoCaSecret(previous); err == nil && now.Before(previousCert.NotAfter) {
+			trustedPem = append(append([]byte{}, certPem...), previous.Data["tls.crt"]...)
+		}
+	}
+
+	return provider.newTlsSecret(provider.CaSecretName(), certPem, keyPem, trustedPem), nil
+}
+
+// NewKeyPairSecret generates a key pair signed by the CA in the CA secret and
+// returns the secret that holds it, trusting the same CAs as the CA secret
+func (provider *AutoManagementApiSecurityProvider) NewKeyPairSecret(name string, caSecret *corev1.Secret, now time.Time) (*corev1.Secret, error) {
+	caCert, caKey, err := parseAutoCaSecret(caSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	keyUsage, extKeyUsage, _ := managedCertificateUsages()
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: name},
+		NotBefore:   now,
+		NotAfter:    now.Add(provider.getDuration()),
+		KeyUsage:    keyUsage,
+		ExtKeyUsage: extKeyUsage,
+	}
+
+	certPem, keyPem, err := utils.GenerateCertificate(template, caCert, caKey, autoKeySize)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.newTlsSecret(name, certPem, keyPem, caSecret.Data["ca.crt"]), nil
+}
+
+func (provider *AutoManagementApiSecurityProvider) newTlsSecret(name string, certPem, keyPem, caPem []byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: provider.Namespace,
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			"ca.crt":  caPem,
+			"tls.crt": certPem,
+			"tls.key": keyPem,
+		},
+	}
+}
+
+func parseAutoCaSecret(caSecret *corev1.Secret) (*x509.Certificate, crypto.Signer, error) {
+	chain, err := pemToCertificateChain(caSecret.Data["tls.crt"])
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(chain) == 0 {
+		return nil, nil, fmt.Errorf("Did not find a CA certificate in secret %s", caSecret.Name)
+	}
+
+	block, _ := pem.Decode(caSecret.Data["tls.key"])
+	if block == nil {
+		return nil, nil, fmt.Errorf("Did not find a CA key in secret %s", caSecret.Name)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("CA key in secret %s cannot sign certificates", caSecret.Name)
+	}
+
+	return chain[0], signer, nil
+}
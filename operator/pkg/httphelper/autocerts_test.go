@@ -0,0 +1,139 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package httphelper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+)
+
+func buildTestAutoProvider(t *testing.T, config *api.ManagementApiAuthAutoConfig) *AutoManagementApiSecurityProvider {
+	dc := &api.CassandraDatacenter{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dc1",
+			Namespace: "default",
+		},
+		Spec: api.CassandraDatacenterSpec{
+			ClusterName: "cluster1",
+			ManagementApiAuth: api.ManagementApiAuthConfig{
+				Auto: config,
+			},
+		},
+	}
+
+	provider, err := BuildManagmenetApiSecurityProvider(dc)
+	assert.NoError(t, err)
+
+	auto, ok := provider.(*AutoManagementApiSecurityProvider)
+	assert.True(t, ok, "Should use the auto strategy")
+	return auto
+}
+
+func Test_AutoManagementApiSecurityProvider_NewSecrets(t *testing.T) {
+	provider := buildTestAutoProvider(t, &api.ManagementApiAuthAutoConfig{})
+	now := time.Now()
+
+	caSecret, err := provider.NewCaSecret(now, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "cluster1-dc1-mgmt-api-ca", caSecret.Name)
+
+	serverSecret, err := provider.NewKeyPairSecret(provider.ServerSecretName(), caSecret, now)
+	assert.NoError(t, err)
+	clientSecret, err := provider.NewKeyPairSecret(provider.ClientSecretName(), caSecret, now)
+	assert.NoError(t, err)
+
+	for _, secret := range []*corev1.Secret{serverSecret, clientSecret} {
+		assert.Equal(t, "default", secret.Namespace)
+		assert.Equal(t, 0, len(validateSecret(secret)),
+			"Should be a valid Management API secret")
+	}
+
+	assert.NoError(t, validatePeerACertificateSignedByPeerBCa(
+		clientSecret.Data["tls.crt"], clientSecret.Data["ca.crt"], serverSecret.Data["ca.crt"]))
+	assert.NoError(t, validatePeerACertificateSignedByPeerBCa(
+		serverSecret.Data["tls.crt"], serverSecret.Data["ca.crt"], clientSecret.Data["ca.crt"]))
+}
+
+func Test_AutoManagementApiSecurityProvider_NeedsRenewal(t *testing.T) {
+	provider := buildTestAutoProvider(t, &api.ManagementApiAuthAutoConfig{})
+	now := time.Now()
+
+	caSecret, err := provider.NewCaSecret(now, nil)
+	assert.NoError(t, err)
+	secret, err := provider.NewKeyPairSecret(provider.ServerSecretName(), caSecret, now)
+	assert.NoError(t, err)
+
+	assert.False(t, provider.CaNeedsRenewal(caSecret, now))
+	assert.True(t, provider.CaNeedsRenewal(caSecret, now.Add(autoCaDuration-24*time.Hour)),
+		"Should renew the CA shortly before it expires")
+	assert.True(t, provider.CaNeedsRenewal(&corev1.Secret{}, now))
+
+	assert.False(t, provider.KeyPairNeedsRenewal(secret, caSecret, now))
+	assert.False(t, provider.KeyPairNeedsRenewal(secret, caSecret, now.Add(300*24*time.Hour)))
+	assert.True(t, provider.KeyPairNeedsRenewal(secret, caSecret, now.Add(340*24*time.Hour)),
+		"Should renew the certificate within 30 days of it expiring")
+	assert.True(t, provider.KeyPairNeedsRenewal(&corev1.Secret{}, caSecret, now))
+
+	otherCaSecret, err := provider.NewCaSecret(now, nil)
+	assert.NoError(t, err)
+	assert.True(t, provider.KeyPairNeedsRenewal(secret, otherCaSecret, now),
+		"Should renew a certificate that is not signed by a trusted CA")
+}
+
+func Test_AutoManagementApiSecurityProvider_RenewedCa(t *testing.T) {
+	provider := buildTestAutoProvider(t, &api.ManagementApiAuthAutoConfig{})
+	now := time.Now()
+	issued := now.Add(-time.Hour)
+
+	caSecret, err := provider.NewCaSecret(issued, nil)
+	assert.NoError(t, err)
+	serverSecret, err := provider.NewKeyPairSecret(provider.ServerSecretName(), caSecret, issued)
+	assert.NoError(t, err)
+	assert.True(t, provider.KeyPairSignedByCa(serverSecret, caSecret))
+	assert.True(t, provider.KeyPairTrustsCas(serverSecret, caSecret))
+
+	renewedCaSecret, err := provider.NewCaSecret(now, caSecret)
+	assert.NoError(t, err)
+	issueTime, err := provider.GetCaIssueTime(renewedCaSecret)
+	assert.NoError(t, err)
+	assert.True(t, issueTime.After(issued))
+
+	assert.False(t, provider.KeyPairNeedsRenewal(serverSecret, renewedCaSecret, now),
+		"Should keep a certificate signed by the previous CA")
+	assert.False(t, provider.KeyPairSignedByCa(serverSecret, renewedCaSecret))
+	assert.False(t, provider.KeyPairTrustsCas(serverSecret, renewedCaSecret))
+
+	// Once it trusts both CAs, the server accepts certificates signed by
+	// either of them
+	serverSecret.Data["ca.crt"] = renewedCaSecret.Data["ca.crt"]
+	clientSecret, err := provider.NewKeyPairSecret(provider.ClientSecretName(), renewedCaSecret, now)
+	assert.NoError(t, err)
+	assert.NoError(t, validatePeerACertificateSignedByPeerBCa(
+		clientSecret.Data["tls.crt"], clientSecret.Data["ca.crt"], serverSecret.Data["ca.crt"]))
+	assert.NoError(t, validatePeerACertificateSignedByPeerBCa(
+		serverSecret.Data["tls.crt"], serverSecret.Data["ca.crt"], clientSecret.Data["ca.crt"]))
+
+	expiredCaSecret, err := provider.NewCaSecret(now.Add(autoCaDuration+time.Hour), renewedCaSecret)
+	assert.NoError(t, err)
+	assert.Equal(t, expiredCaSecret.Data["tls.crt"], expiredCaSecret.Data["ca.crt"],
+		"Should stop trusting the previous CA once it has expired")
+}
+
+func Test_AutoManagementApiSecurityProvider_getRenewBefore(t *testing.T) {
+	provider := buildTestAutoProvider(t, &api.ManagementApiAuthAutoConfig{})
+	assert.Equal(t, autoCertificateRenewBefore, provider.getRenewBefore())
+
+	provider = buildTestAutoProvider(t, &api.ManagementApiAuthAutoConfig{
+		Duration:    &metav1.Duration{Duration: 24 * time.Hour},
+		RenewBefore: &metav1.Duration{Duration: 48 * time.Hour},
+	})
+	assert.Equal(t, 8*time.Hour, provider.getRenewBefore(),
+		"Should not renew certificates as soon as they are issued")
+}
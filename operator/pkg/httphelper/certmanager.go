@@ -0,0 +1,247 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package httphelper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+)
+
+const (
+	certManagerGroupVersion = "cert-manager.io/v1alpha2"
+
+	// The self-signed CA outlives many renewals of the certificates it signs
+	certManagerCaDuration = "87600h"
+)
+
+// CertManagerGroupVersionKind returns the GroupVersionKind of a cert-manager
+// resource, e.g. Certificate or Issuer
+func CertManagerGroupVersionKind(kind string) schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(certManagerGroupVersion, kind)
+}
+
+// CertManagerManagementApiSecurityProvider secures the Management API the
+// same way the manual strategy does, with secrets that cert-manager issues and
+// renews. The cert-manager resources are created during reconciliation.
+type CertManagerManagementApiSecurityProvider struct {
+	Namespace string
+	Config    *api.ManagementApiAuthCertManagerConfig
+
+	// Prefix of the names of the cert-manager resources and secrets
+	NamePrefix string
+}
+
+func buildCertManagerApiSecurityProvider(dc *api.CassandraDatacenter) (ManagementApiSecurityProvider, error) {
+	if dc.Spec.ManagementApiAuth.CertManager != nil {
+		provider := &CertManagerManagementApiSecurityProvider{}
+		provider.Config = dc.Spec.ManagementApiAuth.CertManager
+		provider.Namespace = dc.ObjectMeta.Namespace
+		provider.NamePrefix = dc.Spec.ClusterName + "-" + dc.Name + "-mgmt-api"
+		return provider, nil
+	}
+	return nil, nil
+}
+
+func (provider *CertManagerManagementApiSecurityProvider) ServerSecretName() string {
+	return provider.NamePrefix + "-server"
+}
+
+func (provider *CertManagerManagementApiSecurityProvider) ClientSecretName() string {
+	return provider.NamePrefix + "-client"
+}
+
+func (provider *CertManagerManagementApiSecurityProvider) manual() *ManualManagementApiSecurityProvider {
+	return &ManualManagementApiSecurityProvider{
+		Namespace: provider.Namespace,
+		Config: &api.ManagementApiAuthManualConfig{
+			ClientSecretName: provider.ClientSecretName(),
+			ServerSecretName: provider.ServerSecretName(),
+		},
+	}
+}
+
+func (provider *CertManagerManagementApiSecurityProvider) GetProtocol() string {
+	return "https"
+}
+
+func (provider *CertManagerManagementApiSecurityProvider) AddServerSecurity(pod *corev1.PodTemplateSpec) error {
+	return provider.manual().AddServerSecurity(pod)
+}
+
+func (provider *CertManagerManagementApiSecurityProvider) ValidateConfig(client client.Client, ctx context.Context) []error {
+	return provider.manual().ValidateConfig(client, ctx)
+}
+
+func (provider *CertManagerManagementApiSecurityProvider) BuildHttpClient(client client.Client, ctx context.Context) (HttpClient, error) {
+	httpClient, err := provider.manual().BuildHttpClient(client, ctx)
+	if errors.IsNotFound(err) {
+		// The datacenter has to be reconciled for the certificate to be
+		// requested in the first place
+		return &unissuedCertificateHttpClient{SecretName: provider.ClientSecretName()}, nil
+	}
+	return httpClient, err
+}
+
+// unissuedCertificateHttpClient stands in for the Management API client
+// until cert-manager has issued its certificate, failing every request
+type unissuedCertificateHttpClient struct {
+	SecretName string
+}
+
+func (c *unissuedCertificateHttpClient) Do(req *http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("Management API client certificate in secret %s has not been issued yet", c.SecretName)
+}
+
+func (provider *CertManagerManagementApiSecurityProvider) newResource(kind, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	resource := &unstructured.Unstructured{}
+	resource.SetGroupVersionKind(CertManagerGroupVersionKind(kind))
+	resource.SetNamespace(provider.Namespace)
+	resource.SetName(name)
+	resource.Object["spec"] = spec
+	return resource
+}
+
+func (provider *CertManagerManagementApiSecurityProvider) newCertificate(name string, issuerRef map[string]interface{}) *unstructured.Unstructured {
+	_, _, usages := managedCertificateUsages()
+	spec := map[string]interface{}{
+		"secretName": name,
+		"commonName": name,
+		"issuerRef":  issuerRef,
+		// The Management API only accepts PKCS#8 keys
+		"keyEncoding": "pkcs8",
+		"usages":      usages,
+	}
+	if provider.Config.Duration != nil {
+		spec["duration"] = provider.Config.Duration.Duration.String()
+	}
+	if provider.Config.RenewBefore != nil {
+		spec["renewBefore"] = provider.Config.RenewBefore.Duration.String()
+	}
+	return provider.newResource("Certificate", name, spec)
+}
+
+// BuildCertManagerResources returns the cert-manager resources that issue
+// the server and client certificates. Without an issuer in the config, they
+// start with a self-signed issuer for a CA certificate and a CA issuer that
+// signs the other certificates with it.
+func (provider *CertManagerManagementApiSecurityProvider) BuildCertManagerResources() []*unstructured.Unstructured {
+	resources := []*unstructured.Unstructured{}
+
+	var issuerRef map[string]interface{}
+	if provider.Config.IssuerRef != nil {
+		kind := provider.Config.IssuerRef.Kind
+		if kind == "" {
+			kind = "Issuer"
+		}
+		issuerRef = map[string]interface{}{
+			"name": provider.Config.IssuerRef.Name,
+			"kind": kind,
+		}
+	} else {
+		selfSignedName := provider.NamePrefix + "-selfsigned"
+		caName := provider.NamePrefix + "-ca"
+
+		selfSigned := provider.newResource("Issuer", selfSignedName, map[string]interface{}{
+			"selfSigned": map[string]interface{}{},
+		})
+
+		ca := provider.newResource("Certificate", caName, map[string]interface{}{
+			"secretName": caName,
+			"commonName": caName,
+			"isCA":       true,
+			"duration":   certManagerCaDuration,
+			"issuerRef": map[string]interface{}{
+				"name": selfSignedName,
+				"kind": "Issuer",
+			},
+		})
+
+		caIssuer := provider.newResource("Issuer", caName, map[string]interface{}{
+			"ca": map[string]interface{}{
+				"secretName": caName,
+			},
+		})
+
+		resources = append(resources, selfSigned, ca, caIssuer)
+		issuerRef = map[string]interface{}{
+			"name": caName,
+			"kind": "Issuer",
+		}
+	}
+
+	resources = append(resources,
+		provider.newCertificate(provider.ServerSecretName(), issuerRef),
+		provider.newCertificate(provider.ClientSecretName(), issuerRef))
+
+	return resources
+}
+
+// GetIssuedCertificateNames returns the names of the Certificates that have
+// to be ready before the Management API can be used
+func (provider *CertManagerManagementApiSecurityProvider) GetIssuedCertificateNames() []types.NamespacedName {
+	return []types.NamespacedName{
+		{Namespace: provider.Namespace, Name: provider.ServerSecretName()},
+		{Namespace: provider.Namespace, Name: provider.ClientSecretName()},
+	}
+}
+
+// IsCertificateReady returns true when cert-manager reports that the
+// Certificate is issued and up to date
+func IsCertificateReady(certificate *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(certificate.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == "Ready" {
+			return condition["status"] == string(corev1.ConditionTrue)
+		}
+	}
+	return false
+}
+
+// GetCertificateIssueTime returns the time the first certificate in PEM
+// encoded data became valid, which is when cert-manager issued it
+func GetCertificateIssueTime(certificate []byte) (time.Time, error) {
+	chain, err := pemToCertificateChain(certificate)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(chain) == 0 {
+		return time.Time{}, fmt.Errorf("Did not find any certificates.")
+	}
+	return chain[0].NotBefore, nil
+}
+
+// GetSecretIssueTime returns the time the latest of the certificate and the
+// trusted CAs of a secret became valid, as the pods using the secret need to
+// be restarted after either is renewed
+func GetSecretIssueTime(secret *corev1.Secret) (time.Time, error) {
+	issueTime, err := GetCertificateIssueTime(secret.Data["tls.crt"])
+	if err != nil {
+		return time.Time{}, err
+	}
+	trustedCas, err := pemToCertificateChain(secret.Data["ca.crt"])
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, caCert := range trustedCas {
+		if caCert.NotBefore.After(issueTime) {
+			issueTime = caCert.NotBefore
+		}
+	}
+	return issueTime, nil
+}
@@ -0,0 +1,165 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package httphelper
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+)
+
+func newCertManagerTestDatacenter(config *api.ManagementApiAuthCertManagerConfig) *api.CassandraDatacenter {
+	return &api.CassandraDatacenter{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dc1",
+			Namespace: "default",
+		},
+		Spec: api.CassandraDatacenterSpec{
+			ClusterName: "cluster1",
+			ManagementApiAuth: api.ManagementApiAuthConfig{
+				CertManager: config,
+			},
+		},
+	}
+}
+
+func buildTestCertManagerProvider(t *testing.T, config *api.ManagementApiAuthCertManagerConfig) *CertManagerManagementApiSecurityProvider {
+	provider, err := BuildManagmenetApiSecurityProvider(newCertManagerTestDatacenter(config))
+	assert.NoError(t, err)
+
+	certManager, ok := provider.(*CertManagerManagementApiSecurityProvider)
+	assert.True(t, ok, "Should use the cert-manager strategy")
+	return certManager
+}
+
+func Test_BuildManagmenetApiSecurityProvider_CertManager(t *testing.T) {
+	provider := buildTestCertManagerProvider(t, &api.ManagementApiAuthCertManagerConfig{})
+
+	assert.Equal(t, "https", provider.GetProtocol())
+	assert.Equal(t, "cluster1-dc1-mgmt-api-server", provider.ServerSecretName())
+	assert.Equal(t, "cluster1-dc1-mgmt-api-client", provider.ClientSecretName())
+
+	dc := newCertManagerTestDatacenter(&api.ManagementApiAuthCertManagerConfig{})
+	dc.Spec.ManagementApiAuth.Insecure = &api.ManagementApiAuthInsecureConfig{}
+	_, err := BuildManagmenetApiSecurityProvider(dc)
+	assert.Error(t, err, "Should not allow more than one strategy")
+}
+
+func Test_BuildCertManagerResources_SelfSigned(t *testing.T) {
+	provider := buildTestCertManagerProvider(t, &api.ManagementApiAuthCertManagerConfig{
+		RenewBefore: &metav1.Duration{Duration: 360 * time.Hour},
+	})
+
+	resources := provider.BuildCertManagerResources()
+
+	names := []string{}
+	for _, resource := range resources {
+		assert.Equal(t, "default", resource.GetNamespace())
+		names = append(names, resource.GetKind()+"/"+resource.GetName())
+	}
+	assert.Equal(t, []string{
+		"Issuer/cluster1-dc1-mgmt-api-selfsigned",
+		"Certificate/cluster1-dc1-mgmt-api-ca",
+		"Issuer/cluster1-dc1-mgmt-api-ca",
+		"Certificate/cluster1-dc1-mgmt-api-server",
+		"Certificate/cluster1-dc1-mgmt-api-client",
+	}, names)
+
+	server := resources[3]
+	issuer, _, _ := unstructured.NestedString(server.Object, "spec", "issuerRef", "name")
+	assert.Equal(t, "cluster1-dc1-mgmt-api-ca", issuer, "Should be signed by the self-signed CA")
+	secretName, _, _ := unstructured.NestedString(server.Object, "spec", "secretName")
+	assert.Equal(t, provider.ServerSecretName(), secretName)
+	keyEncoding, _, _ := unstructured.NestedString(server.Object, "spec", "keyEncoding")
+	assert.Equal(t, "pkcs8", keyEncoding)
+	renewBefore, _, _ := unstructured.NestedString(server.Object, "spec", "renewBefore")
+	assert.Equal(t, "360h0m0s", renewBefore)
+}
+
+func Test_BuildCertManagerResources_IssuerRef(t *testing.T) {
+	provider := buildTestCertManagerProvider(t, &api.ManagementApiAuthCertManagerConfig{
+		IssuerRef: &api.CertManagerIssuerReference{Name: "company-ca", Kind: "ClusterIssuer"},
+	})
+
+	resources := provider.BuildCertManagerResources()
+
+	assert.Equal(t, 2, len(resources), "Should only create the server and client certificates")
+	for _, resource := range resources {
+		assert.Equal(t, "Certificate", resource.GetKind())
+		issuer, _, _ := unstructured.NestedStringMap(resource.Object, "spec", "issuerRef")
+		assert.Equal(t, map[string]string{"name": "company-ca", "kind": "ClusterIssuer"}, issuer)
+	}
+}
+
+func Test_IsCertificateReady(t *testing.T) {
+	certificate := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	assert.False(t, IsCertificateReady(certificate), "Should not be ready without a status")
+
+	certificate.Object["status"] = map[string]interface{}{
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Ready", "status": "False"},
+		},
+	}
+	assert.False(t, IsCertificateReady(certificate))
+
+	certificate.Object["status"] = map[string]interface{}{
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Ready", "status": "True"},
+		},
+	}
+	assert.True(t, IsCertificateReady(certificate))
+}
+
+func Test_CertManager_BuildHttpClient_NotIssued(t *testing.T) {
+	provider := buildTestCertManagerProvider(t, &api.ManagementApiAuthCertManagerConfig{})
+
+	httpClient, err := provider.BuildHttpClient(fake.NewFakeClient(), context.Background())
+	assert.NoError(t, err, "Should not fail before the certificate is issued")
+
+	req, _ := http.NewRequest("GET", "https://localhost:8080/api/v0/probes/liveness", nil)
+	_, err = httpClient.Do(req)
+	assert.Error(t, err, "Should fail requests until the certificate is issued")
+}
+
+func Test_GetCertificateIssueTime(t *testing.T) {
+	certPem := helperLoadBytes(t, "server.crt")
+	chain, err := pemToCertificateChain(certPem)
+	assert.NoError(t, err)
+
+	issueTime, err := GetCertificateIssueTime(certPem)
+	assert.NoError(t, err)
+	assert.Equal(t, chain[0].NotBefore, issueTime)
+
+	_, err = GetCertificateIssueTime([]byte("not a certificate"))
+	assert.Error(t, err)
+}
+
+func Test_GetSecretIssueTime(t *testing.T) {
+	provider := buildTestAutoProvider(t, &api.ManagementApiAuthAutoConfig{})
+	now := time.Now().Truncate(time.Second)
+
+	caSecret, err := provider.NewCaSecret(now.Add(-time.Hour), nil)
+	assert.NoError(t, err)
+	secret, err := provider.NewKeyPairSecret(provider.ServerSecretName(), caSecret, now.Add(-time.Minute))
+	assert.NoError(t, err)
+
+	issueTime, err := GetSecretIssueTime(secret)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(-time.Minute).UTC(), issueTime.UTC())
+
+	renewedCaSecret, err := provider.NewCaSecret(now, caSecret)
+	assert.NoError(t, err)
+	secret.Data["ca.crt"] = renewedCaSecret.Data["ca.crt"]
+	issueTime, err = GetSecretIssueTime(secret)
+	assert.NoError(t, err)
+	assert.Equal(t, now.UTC(), issueTime.UTC(), "Should count the renewal of a trusted CA")
+}
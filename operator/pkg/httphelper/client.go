@@ -7,17 +7,22 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 
 	corev1 "k8s.io/api/core/v1"
+
+	"github.com/datastax/cass-operator/operator/pkg/metrics"
 )
 
 type NodeMgmtClient struct {
@@ -48,10 +53,12 @@ func buildEndpoint(path string, queryParams ...string) string {
 }
 
 type EndpointState struct {
+	Datacenter             string `json:"DC"`
 	HostID                 string `json:"HOST_ID"`
 	IsAlive                string `json:"IS_ALIVE"`
 	NativeTransportAddress string `json:"NATIVE_TRANSPORT_ADDRESS"`
 	RpcAddress             string `json:"RPC_ADDRESS"`
+	Status                 string `json:"STATUS"`
 }
 
 func (x *EndpointState) GetRpcAddress() string {
@@ -68,6 +75,23 @@ type CassMetadataEndpoints struct {
 
 type NoPodIPError error
 
+// RequestError is returned when the management API answers a request with an
+// unsuccessful status code
+type RequestError struct {
+	StatusCode int
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("incorrect status code of %d when calling endpoint", e.StatusCode)
+}
+
+// IsNotFound tells whether the management API answered that what was
+// requested does not exist, e.g. a job that the node has no record of
+func IsNotFound(err error) bool {
+	var requestErr *RequestError
+	return errors.As(err, &requestErr) && requestErr.StatusCode == http.StatusNotFound
+}
+
 func newNoPodIPError(pod *corev1.Pod) NoPodIPError {
 	return fmt.Errorf("pod %s has no IP", pod.Name)
 }
@@ -196,6 +220,84 @@ func (client *NodeMgmtClient) CallDrainEndpoint(pod *corev1.Pod) error {
 	return err
 }
 
+// CallDecommissionNodeEndpoint starts decommissioning the node of the pod,
+// and returns the id of its job without waiting for it to finish
+func (client *NodeMgmtClient) CallDecommissionNodeEndpoint(pod *corev1.Pod) (string, error) {
+	client.Log.Info(
+		"calling Management API decommission node - POST /api/v0/ops/node/decommission",
+		"pod", pod.Name,
+	)
+
+	podHost, err := BuildPodHostFromPod(pod)
+	if err != nil {
+		return "", err
+	}
+
+	// decommissioning streams all of the node's data to the rest of the ring
+	// and can take a long time, so it runs in the background
+	request := nodeMgmtRequest{
+		endpoint: buildEndpoint("/api/v0/ops/node/decommission", "force", "true", "async", "true"),
+		host:     podHost,
+		method:   http.MethodPost,
+	}
+
+	body, err := callNodeMgmtEndpoint(client, request, "")
+	if err != nil {
+		return "", err
+	}
+
+	jobID := strings.TrimSpace(string(body))
+	if jobID == "" {
+		return "", fmt.Errorf("no job id in the response of the decommission of pod %s", pod.Name)
+	}
+	return jobID, nil
+}
+
+const (
+	JobStatusCompleted = "COMPLETED"
+	JobStatusError     = "ERROR"
+)
+
+// JobDetails is the state of a job the management API runs in the background
+type JobDetails struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	Error  string `json:"error"`
+}
+
+// CallJobDetailsEndpoint returns the state of a job of the management API on
+// the node of the pod
+func (client *NodeMgmtClient) CallJobDetailsEndpoint(pod *corev1.Pod, jobID string) (*JobDetails, error) {
+	client.Log.Info(
+		"calling Management API job details - GET /api/v0/ops/executor/job",
+		"pod", pod.Name,
+		"jobId", jobID,
+	)
+
+	podHost, err := BuildPodHostFromPod(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	request := nodeMgmtRequest{
+		endpoint: buildEndpoint("/api/v0/ops/executor/job", "job_id", jobID),
+		host:     podHost,
+		method:   http.MethodGet,
+	}
+
+	body, err := callNodeMgmtEndpoint(client, request, "")
+	if err != nil {
+		return nil, err
+	}
+
+	details := &JobDetails{}
+	if err := json.Unmarshal(body, details); err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
 func (client *NodeMgmtClient) CallKeyspaceCleanupEndpoint(pod *corev1.Pod, jobs int, keyspaceName string, tables []string) error {
 	client.Log.Info(
 		"calling Management API keyspace cleanup - POST /api/v0/ops/keyspace/cleanup",
@@ -236,6 +338,168 @@ func (client *NodeMgmtClient) CallKeyspaceCleanupEndpoint(pod *corev1.Pod, jobs
 	return err
 }
 
+// TokenRange is a range of tokens, from the exclusive start token to the
+// inclusive end token
+type TokenRange struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// TokenRangeEndpoints is a token range of a keyspace with the addresses of
+// its replicas, the primary replica first
+type TokenRangeEndpoints struct {
+	TokenRange
+	Endpoints []string `json:"endpoints"`
+}
+
+// RepairRequest is a repair session started with CallStartRepairEndpoint.
+// With no token ranges, all of the token ranges of the node are repaired.
+type RepairRequest struct {
+	Keyspace          string       `json:"keyspace"`
+	Tables            []string     `json:"tables"`
+	FullRepair        bool         `json:"full_repair"`
+	RepairParallelism string       `json:"repair_parallelism,omitempty"`
+	Datacenters       []string     `json:"datacenters,omitempty"`
+	TokenRanges       []TokenRange `json:"associated_tokens,omitempty"`
+}
+
+// CallRangeToEndpointEndpoint returns the token ranges of the keyspace with
+// the replicas of each one
+func (client *NodeMgmtClient) CallRangeToEndpointEndpoint(pod *corev1.Pod, keyspaceName string) ([]TokenRangeEndpoints, error) {
+	client.Log.Info(
+		"calling Management API range to endpoint - GET /api/v0/ops/tokens/rangetoendpoint",
+		"pod", pod.Name,
+		"keyspace", keyspaceName,
+	)
+
+	podHost, err := BuildPodHostFromPod(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	request := nodeMgmtRequest{
+		endpoint: buildEndpoint("/api/v0/ops/tokens/rangetoendpoint", "keyspaceName", keyspaceName),
+		host:     podHost,
+		method:   http.MethodGet,
+	}
+
+	body, err := callNodeMgmtEndpoint(client, request, "")
+	if err != nil {
+		return nil, err
+	}
+
+	ranges := []TokenRangeEndpoints{}
+	if err := json.Unmarshal(body, &ranges); err != nil {
+		return nil, err
+	}
+	return ranges, nil
+}
+
+// CallStartRepairEndpoint starts a repair session on the node of the pod and
+// returns the id of its job, without waiting for it to finish
+func (client *NodeMgmtClient) CallStartRepairEndpoint(pod *corev1.Pod, repair RepairRequest) (string, error) {
+	client.Log.Info(
+		"calling Management API start repair - POST /api/v1/repair",
+		"pod", pod.Name,
+		"keyspace", repair.Keyspace,
+	)
+
+	if repair.Tables == nil {
+		repair.Tables = []string{}
+	}
+	body, err := json.Marshal(repair)
+	if err != nil {
+		return "", err
+	}
+
+	podHost, err := BuildPodHostFromPod(pod)
+	if err != nil {
+		return "", err
+	}
+
+	request := nodeMgmtRequest{
+		endpoint: "/api/v1/repair",
+		host:     podHost,
+		method:   http.MethodPost,
+		body:     body,
+	}
+
+	body, err = callNodeMgmtEndpoint(client, request, "application/json")
+	if err != nil {
+		return "", err
+	}
+
+	response := struct {
+		RepairID string `json:"repair_id"`
+	}{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", err
+	}
+	return response.RepairID, nil
+}
+
+// CallStartRebuildEndpoint starts streaming the data the node of the pod owns
+// from the nodes of the given datacenter, and returns the id of its job
+// without waiting for it to finish
+func (client *NodeMgmtClient) CallStartRebuildEndpoint(pod *corev1.Pod, sourceDcName string) (string, error) {
+	client.Log.Info(
+		"calling Management API rebuild - POST /api/v0/ops/node/rebuild",
+		"pod", pod.Name,
+		"sourceDatacenter", sourceDcName,
+	)
+
+	podHost, err := BuildPodHostFromPod(pod)
+	if err != nil {
+		return "", err
+	}
+
+	request := nodeMgmtRequest{
+		endpoint: buildEndpoint("/api/v0/ops/node/rebuild", "src_dc", sourceDcName),
+		host:     podHost,
+		method:   http.MethodPost,
+	}
+
+	body, err := callNodeMgmtEndpoint(client, request, "")
+	if err != nil {
+		return "", err
+	}
+
+	jobID := strings.TrimSpace(string(body))
+	if jobID == "" {
+		return "", fmt.Errorf("no job id in the response of the rebuild of pod %s", pod.Name)
+	}
+	return jobID, nil
+}
+
+// CallRemoveNodeEndpoint asks the node of the pod to remove the node with the
+// given host ID from the ring, streaming its data from the remaining replicas.
+// The removed node must be down.
+func (client *NodeMgmtClient) CallRemoveNodeEndpoint(pod *corev1.Pod, hostID string) error {
+	client.Log.Info(
+		"calling Management API remove node - POST /api/v0/ops/node/removenode",
+		"pod", pod.Name,
+		"hostID", hostID,
+	)
+
+	podHost, err := BuildPodHostFromPod(pod)
+	if err != nil {
+		return err
+	}
+
+	// like decommissioning, removing a node streams its data and can take a
+	// long time, so callers should track progress through the metadata
+	// endpoints rather than rely on this call returning
+	request := nodeMgmtRequest{
+		endpoint: buildEndpoint("/api/v0/ops/node/removenode", "host_id", hostID),
+		host:     podHost,
+		method:   http.MethodPost,
+		timeout:  time.Minute * 2,
+	}
+
+	_, err = callNodeMgmtEndpoint(client, request, "")
+	return err
+}
+
 func (client *NodeMgmtClient) CallLifecycleStartEndpointWithReplaceIp(pod *corev1.Pod, replaceIp string) error {
 	// talk to the pod via IP because we are dialing up a pod that isn't ready,
 	// so it won't be reachable via the service and pod DNS
@@ -269,6 +533,31 @@ func (client *NodeMgmtClient) CallLifecycleStartEndpoint(pod *corev1.Pod) error
 	return client.CallLifecycleStartEndpointWithReplaceIp(pod, "")
 }
 
+// CallLifecycleStopEndpoint stops Cassandra while leaving the management API,
+// and so the pod, running. It can be started again with
+// CallLifecycleStartEndpoint.
+func (client *NodeMgmtClient) CallLifecycleStopEndpoint(pod *corev1.Pod) error {
+	client.Log.Info(
+		"calling Management API stop node - POST /api/v0/lifecycle/stop",
+		"pod", pod.Name,
+	)
+
+	podHost, err := BuildPodHostFromPod(pod)
+	if err != nil {
+		return err
+	}
+
+	request := nodeMgmtRequest{
+		endpoint: "/api/v0/lifecycle/stop",
+		host:     podHost,
+		method:   http.MethodPost,
+		timeout:  time.Minute * 2,
+	}
+
+	_, err = callNodeMgmtEndpoint(client, request, "")
+	return err
+}
+
 func (client *NodeMgmtClient) CallReloadSeedsEndpoint(pod *corev1.Pod) error {
 	client.Log.Info(
 		"calling Management API reload seeds - POST /api/v0/ops/seeds/reload",
@@ -290,9 +579,309 @@ func (client *NodeMgmtClient) CallReloadSeedsEndpoint(pod *corev1.Pod) error {
 	return err
 }
 
-func callNodeMgmtEndpoint(client *NodeMgmtClient, request nodeMgmtRequest, contentType string) ([]byte, error) {
+type SnapshotDetails struct {
+	SnapshotName string `json:"Snapshot name"`
+	Keyspace     string `json:"Keyspace name"`
+	Table        string `json:"Column family name"`
+	TrueSize     string `json:"True size"`
+	SizeOnDisk   string `json:"Size on disk"`
+}
+
+type CassSnapshotDetails struct {
+	Entity []SnapshotDetails `json:"entity"`
+}
+
+func parseSnapshotDetailsResponseBody(body []byte) (*CassSnapshotDetails, error) {
+	details := &CassSnapshotDetails{}
+
+	// depending on the version of the management API, the details are
+	// either wrapped in an entity or returned as a plain list
+	if len(bytes.TrimSpace(body)) > 0 && bytes.TrimSpace(body)[0] == '[' {
+		if err := json.Unmarshal(body, &details.Entity); err != nil {
+			return nil, err
+		}
+		return details, nil
+	}
+
+	if err := json.Unmarshal(body, &details); err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
+func (client *NodeMgmtClient) CallCreateSnapshotEndpoint(pod *corev1.Pod, snapshotName string, keyspaces []string) error {
+	client.Log.Info(
+		"calling Management API create snapshot - POST /api/v0/ops/node/snapshots",
+		"pod", pod.Name,
+		"snapshotName", snapshotName,
+	)
+
+	postData := make(map[string]interface{})
+	postData["snapshot_name"] = snapshotName
+	if len(keyspaces) > 0 {
+		postData["keyspaces"] = keyspaces
+	}
+
+	body, err := json.Marshal(postData)
+	if err != nil {
+		return err
+	}
+
+	podHost, err := BuildPodHostFromPod(pod)
+	if err != nil {
+		return err
+	}
+
+	// snapshots flush memtables before hard linking the sstables, which can
+	// take a while on a busy node
+	request := nodeMgmtRequest{
+		endpoint: "/api/v0/ops/node/snapshots",
+		host:     podHost,
+		method:   http.MethodPost,
+		timeout:  time.Minute * 2,
+		body:     body,
+	}
+
+	_, err = callNodeMgmtEndpoint(client, request, "application/json")
+	return err
+}
+
+func (client *NodeMgmtClient) CallListSnapshotsEndpoint(pod *corev1.Pod, snapshotNames ...string) ([]SnapshotDetails, error) {
+	client.Log.Info(
+		"calling Management API list snapshots - GET /api/v0/ops/node/snapshots",
+		"pod", pod.Name,
+	)
+
+	podHost, err := BuildPodHostFromPod(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	request := nodeMgmtRequest{
+		endpoint: buildSnapshotsEndpoint(snapshotNames),
+		host:     podHost,
+		method:   http.MethodGet,
+	}
+
+	body, err := callNodeMgmtEndpoint(client, request, "")
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := parseSnapshotDetailsResponseBody(body)
+	if err != nil {
+		return nil, err
+	}
+	return details.Entity, nil
+}
+
+func (client *NodeMgmtClient) CallClearSnapshotEndpoint(pod *corev1.Pod, snapshotNames ...string) error {
+	client.Log.Info(
+		"calling Management API clear snapshots - DELETE /api/v0/ops/node/snapshots",
+		"pod", pod.Name,
+		"snapshotNames", snapshotNames,
+	)
+
+	podHost, err := BuildPodHostFromPod(pod)
+	if err != nil {
+		return err
+	}
+
+	request := nodeMgmtRequest{
+		endpoint: buildSnapshotsEndpoint(snapshotNames),
+		host:     podHost,
+		method:   http.MethodDelete,
+	}
+
+	_, err = callNodeMgmtEndpoint(client, request, "")
+	return err
+}
+
+// buildSnapshotsEndpoint returns the snapshots endpoint restricted to the
+// given snapshot names. With no names, all snapshots are included.
+func buildSnapshotsEndpoint(snapshotNames []string) string {
+	params := url.Values{}
+	for _, name := range snapshotNames {
+		params.Add("snapshotNames", name)
+	}
+
+	url := &url.URL{
+		Path:     "/api/v0/ops/node/snapshots",
+		RawQuery: params.Encode(),
+	}
+	return url.String()
+}
+
+// ReplicationSetting is the replication factor of a keyspace in one
+// datacenter
+type ReplicationSetting struct {
+	DcName            string `json:"dc_name"`
+	ReplicationFactor int    `json:"replication_factor"`
+}
+
+// NewReplicationSettings returns the replication factor of each datacenter
+// in the format of the management API, ordered by datacenter name
+func NewReplicationSettings(replication map[string]int32) []ReplicationSetting {
+	settings := []ReplicationSetting{}
+	for dcName, replicationFactor := range replication {
+		settings = append(settings, ReplicationSetting{
+			DcName:            dcName,
+			ReplicationFactor: int(replicationFactor),
+		})
+	}
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].DcName < settings[j].DcName
+	})
+	return settings
+}
+
+// ParseNetworkTopologyReplication returns the replication factor of each
+// datacenter from the replication options of a keyspace, or an error if the
+// keyspace does not use NetworkTopologyStrategy
+func ParseNetworkTopologyReplication(options map[string]string) (map[string]int32, error) {
+	class := options["class"]
+	if !strings.HasSuffix(class, "NetworkTopologyStrategy") {
+		return nil, fmt.Errorf("uses %s instead of NetworkTopologyStrategy", class)
+	}
+
+	replication := map[string]int32{}
+	for key, value := range options {
+		if key == "class" {
+			continue
+		}
+		replicationFactor, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("has an invalid replication factor %q in %s", value, key)
+		}
+		replication[key] = int32(replicationFactor)
+	}
+	return replication, nil
+}
+
+func (client *NodeMgmtClient) CallCreateKeyspaceEndpoint(pod *corev1.Pod, keyspaceName string, replication []ReplicationSetting) error {
+	client.Log.Info(
+		"calling Management API create keyspace - POST /api/v0/ops/keyspace/create",
+		"pod", pod.Name,
+		"keyspace", keyspaceName,
+	)
+	return client.callKeyspaceReplicationEndpoint(pod, "/api/v0/ops/keyspace/create", keyspaceName, replication)
+}
+
+func (client *NodeMgmtClient) CallAlterKeyspaceEndpoint(pod *corev1.Pod, keyspaceName string, replication []ReplicationSetting) error {
+	client.Log.Info(
+		"calling Management API alter keyspace - POST /api/v0/ops/keyspace/alter",
+		"pod", pod.Name,
+		"keyspace", keyspaceName,
+	)
+	return client.callKeyspaceReplicationEndpoint(pod, "/api/v0/ops/keyspace/alter", keyspaceName, replication)
+}
+
+func (client *NodeMgmtClient) callKeyspaceReplicationEndpoint(pod *corev1.Pod, endpoint string, keyspaceName string, replication []ReplicationSetting) error {
+	postData := make(map[string]interface{})
+	postData["keyspace_name"] = keyspaceName
+	postData["replication_settings"] = replication
+
+	body, err := json.Marshal(postData)
+	if err != nil {
+		return err
+	}
+
+	podHost, err := BuildPodHostFromPod(pod)
+	if err != nil {
+		return err
+	}
+
+	// schema changes wait for the schema to agree across the cluster
+	request := nodeMgmtRequest{
+		endpoint: endpoint,
+		host:     podHost,
+		method:   http.MethodPost,
+		timeout:  time.Minute,
+		body:     body,
+	}
+
+	_, err = callNodeMgmtEndpoint(client, request, "application/json")
+	return err
+}
+
+// CallListKeyspacesEndpoint returns the names of the keyspaces of the
+// cluster, or only the given one when it exists
+func (client *NodeMgmtClient) CallListKeyspacesEndpoint(pod *corev1.Pod, keyspaceName string) ([]string, error) {
+	client.Log.Info(
+		"calling Management API list keyspaces - GET /api/v0/ops/keyspace",
+		"pod", pod.Name,
+	)
+
+	podHost, err := BuildPodHostFromPod(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := "/api/v0/ops/keyspace"
+	if keyspaceName != "" {
+		endpoint = buildEndpoint(endpoint, "keyspaceName", keyspaceName)
+	}
+
+	request := nodeMgmtRequest{
+		endpoint: endpoint,
+		host:     podHost,
+		method:   http.MethodGet,
+	}
+
+	body, err := callNodeMgmtEndpoint(client, request, "")
+	if err != nil {
+		return nil, err
+	}
+
+	keyspaces := []string{}
+	if err := json.Unmarshal(body, &keyspaces); err != nil {
+		return nil, err
+	}
+	return keyspaces, nil
+}
+
+// CallGetKeyspaceReplicationEndpoint returns the replication options of the
+// keyspace, with the replication strategy in "class" and, for
+// NetworkTopologyStrategy, the replication factor of each datacenter keyed
+// by its name
+func (client *NodeMgmtClient) CallGetKeyspaceReplicationEndpoint(pod *corev1.Pod, keyspaceName string) (map[string]string, error) {
+	client.Log.Info(
+		"calling Management API keyspace replication - GET /api/v0/ops/keyspace/replication",
+		"pod", pod.Name,
+		"keyspace", keyspaceName,
+	)
+
+	podHost, err := BuildPodHostFromPod(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	request := nodeMgmtRequest{
+		endpoint: buildEndpoint("/api/v0/ops/keyspace/replication", "keyspaceName", keyspaceName),
+		host:     podHost,
+		method:   http.MethodGet,
+	}
+
+	body, err := callNodeMgmtEndpoint(client, request, "")
+	if err != nil {
+		return nil, err
+	}
+
+	replication := map[string]string{}
+	if err := json.Unmarshal(body, &replication); err != nil {
+		return nil, err
+	}
+	return replication, nil
+}
+
+func callNodeMgmtEndpoint(client *NodeMgmtClient, request nodeMgmtRequest, contentType string) (body []byte, err error) {
 	client.Log.Info("client::callNodeMgmtEndpoint")
 
+	start := time.Now()
+	defer func() {
+		metrics.ObserveManagementApiRequest(request.method, request.endpoint, time.Since(start), err)
+	}()
+
 	url := fmt.Sprintf("%s://%s:8080%s", client.Protocol, request.host, request.endpoint)
 
 	var reqBody io.Reader
@@ -334,7 +923,7 @@ func callNodeMgmtEndpoint(client *NodeMgmtClient, request nodeMgmtRequest, conte
 		}
 	}()
 
-	body, err := ioutil.ReadAll(res.Body)
+	body, err = ioutil.ReadAll(res.Body)
 	if err != nil {
 		client.Log.Error(err, "Unable to read response from Node Management Endpoint")
 		return nil, err
@@ -346,7 +935,7 @@ func callNodeMgmtEndpoint(client *NodeMgmtClient, request nodeMgmtRequest, conte
 			"statusCode", res.StatusCode,
 			"pod", request.host)
 
-		return nil, fmt.Errorf("incorrect status code of %d when calling endpoint", res.StatusCode)
+		return nil, &RequestError{StatusCode: res.StatusCode}
 	}
 
 	return body, nil
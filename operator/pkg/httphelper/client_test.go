@@ -4,6 +4,7 @@
 package httphelper
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -101,4 +102,72 @@ func Test_parseMetadataEndpointsResponseBody(t *testing.T) {
 	assert.Equal(t, 2, len(endpoints.Entity))
 	assert.Equal(t, "10.233.90.45", endpoints.Entity[0].RpcAddress)
 	assert.Equal(t, "95c157dc-2811-446a-a541-9faaab2e6930", endpoints.Entity[0].HostID)
+	assert.Equal(t, "NORMAL,2756844028858338669", endpoints.Entity[0].Status)
+}
+
+func Test_parseSnapshotDetailsResponseBody(t *testing.T) {
+	wrapped, err := parseSnapshotDetailsResponseBody([]byte(`{
+		"entity": [
+		  {
+			"Column family name": "users",
+			"Keyspace name": "app",
+			"Size on disk": "5.29 KiB",
+			"Snapshot name": "nightly",
+			"True size": "5.29 KiB"
+		  }
+		]
+	  }`))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(wrapped.Entity))
+	assert.Equal(t, "nightly", wrapped.Entity[0].SnapshotName)
+	assert.Equal(t, "app", wrapped.Entity[0].Keyspace)
+	assert.Equal(t, "users", wrapped.Entity[0].Table)
+
+	plain, err := parseSnapshotDetailsResponseBody([]byte(`[
+		  {
+			"Column family name": "users",
+			"Keyspace name": "app",
+			"Size on disk": "5.29 KiB",
+			"Snapshot name": "nightly",
+			"True size": "5.29 KiB"
+		  }
+		]`))
+
+	assert.Nil(t, err)
+	assert.Equal(t, wrapped.Entity, plain.Entity)
+}
+
+func Test_buildSnapshotsEndpoint(t *testing.T) {
+	assert.Equal(t, "/api/v0/ops/node/snapshots", buildSnapshotsEndpoint(nil))
+	assert.Equal(t,
+		"/api/v0/ops/node/snapshots?snapshotNames=first&snapshotNames=second",
+		buildSnapshotsEndpoint([]string{"first", "second"}))
+}
+
+func Test_ParseNetworkTopologyReplication(t *testing.T) {
+	replication, err := ParseNetworkTopologyReplication(map[string]string{
+		"class": "org.apache.cassandra.locator.NetworkTopologyStrategy",
+		"dc1":   "3",
+		"dc2":   "1",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int32{"dc1": 3, "dc2": 1}, replication)
+	assert.Equal(t, []ReplicationSetting{
+		{DcName: "dc1", ReplicationFactor: 3},
+		{DcName: "dc2", ReplicationFactor: 1},
+	}, NewReplicationSettings(replication))
+
+	_, err = ParseNetworkTopologyReplication(map[string]string{
+		"class": "org.apache.cassandra.locator.NetworkTopologyStrategy",
+		"dc1":   "three",
+	})
+	assert.Error(t, err)
+}
+
+func Test_IsNotFound(t *testing.T) {
+	assert.True(t, IsNotFound(&RequestError{StatusCode: 404}))
+	assert.False(t, IsNotFound(&RequestError{StatusCode: 500}))
+	assert.False(t, IsNotFound(fmt.Errorf("connection refused")))
+	assert.False(t, IsNotFound(nil))
 }
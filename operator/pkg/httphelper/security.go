@@ -48,6 +48,8 @@ func BuildManagmenetApiSecurityProvider(dc *api.CassandraDatacenter) (Management
 	options := []func(*api.CassandraDatacenter) (ManagementApiSecurityProvider, error){
 		buildManualApiSecurityProvider,
 		buildInsecureManagementApiSecurityProvider,
+		buildCertManagerApiSecurityProvider,
+		buildAutoApiSecurityProvider,
 	}
 
 	var selectedProvider ManagementApiSecurityProvider = nil
@@ -89,6 +91,26 @@ type ManagementApiSecurityProvider interface {
 	ValidateConfig(client client.Client, ctx context.Context) []error
 }
 
+// ManagedCertificatesSecurityProvider is implemented by the mechanisms that
+// issue and renew the certificates for the datacenter, rather than use ones
+// the user provides
+type ManagedCertificatesSecurityProvider interface {
+	ManagementApiSecurityProvider
+	ServerSecretName() string
+	ClientSecretName() string
+}
+
+// managedCertificateUsages returns the usages of the server and client
+// certificates issued for the datacenter, as x509 key usages and as the
+// names cert-manager gives them. Certificates are verified for server auth
+// when the secrets are validated, so the client certificate needs it too.
+func managedCertificateUsages() (x509.KeyUsage, []x509.ExtKeyUsage, []interface{}) {
+	keyUsage := x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	extKeyUsage := []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	names := []interface{}{"digital signature", "key encipherment", "server auth", "client auth"}
+	return keyUsage, extKeyUsage, names
+}
+
 type InsecureManagementApiSecurityProvider struct {
 }
 
@@ -0,0 +1,220 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package images
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+// EnvVersionCatalogFile names the environment variable with the path of the
+// version catalog file of the operator, typically mounted from a ConfigMap
+const EnvVersionCatalogFile = "VERSION_CATALOG_FILE"
+
+// Variants holds an image and its UBI counterpart, used when the operator is
+// built on a UBI base image
+type Variants struct {
+	Image    string `yaml:"image"`
+	UBIImage string `yaml:"ubiImage,omitempty"`
+}
+
+func (v Variants) get(ubi bool) string {
+	if ubi {
+		return v.UBIImage
+	}
+	return v.Image
+}
+
+// ServerVersion holds the images of a server version
+type ServerVersion struct {
+	Variants `yaml:",inline"`
+
+	// The config builder to use with this version instead of the default one
+	ConfigBuilder *Variants `yaml:"configBuilder,omitempty"`
+}
+
+// Catalog maps server types and versions to the images the operator runs
+// them with
+type Catalog struct {
+	// When set, replaces the registry of every image of the catalog, e.g.
+	// "registry.example.com:5000"
+	ImageRegistry string `yaml:"imageRegistry,omitempty"`
+
+	// The default config builder
+	ConfigBuilder Variants `yaml:"configBuilder"`
+
+	// The supported versions of each server type, e.g. "cassandra" or "dse"
+	Servers map[string]map[string]ServerVersion `yaml:"servers"`
+}
+
+// DefaultCatalog returns the versions that the operator supports out of the
+// box
+func DefaultCatalog() *Catalog {
+	return &Catalog{
+		ConfigBuilder: Variants{
+			Image:    "datastax/cass-config-builder:1.0.1",
+			UBIImage: "datastax/cass-config-builder:1.0.1-ubi7",
+		},
+		Servers: map[string]map[string]ServerVersion{
+			"cassandra": {
+				"3.11.6": {Variants: Variants{
+					Image:    "datastax/cassandra-mgmtapi-3_11_6:v0.1.5",
+					UBIImage: "datastax/cassandra:3.11.6-ubi7",
+				}},
+				"4.0.0": {Variants: Variants{
+					Image:    "datastax/cassandra-mgmtapi-4_0_0:v0.1.5",
+					UBIImage: "datastax/cassandra:4.0-ubi7",
+				}},
+			},
+			"dse": {
+				"6.8.0": {Variants: Variants{
+					Image:    "datastax/dse-server:6.8.0",
+					UBIImage: "datastax/dse-server:6.8.0-ubi7",
+				}},
+				"6.8.1": {Variants: Variants{
+					Image:    "datastax/dse-server:6.8.1",
+					UBIImage: "datastax/dse-server:6.8.1-ubi7",
+				}},
+			},
+		},
+	}
+}
+
+// LoadCatalog reads a catalog file on top of the default catalog. The
+// versions of the file are added to the default ones, replacing the default
+// images of the versions they share. The default config builder and registry
+// are replaced when the file sets them.
+func LoadCatalog(path string) (*Catalog, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &Catalog{}
+	if err = yaml.UnmarshalStrict(contents, file); err != nil {
+		return nil, fmt.Errorf("invalid version catalog %s: %s", path, err.Error())
+	}
+
+	catalog := DefaultCatalog()
+	if file.ImageRegistry != "" {
+		catalog.ImageRegistry = file.ImageRegistry
+	}
+	if file.ConfigBuilder.Image != "" {
+		catalog.ConfigBuilder.Image = file.ConfigBuilder.Image
+	}
+	if file.ConfigBuilder.UBIImage != "" {
+		catalog.ConfigBuilder.UBIImage = file.ConfigBuilder.UBIImage
+	}
+	for serverType, versions := range file.Servers {
+		if catalog.Servers[serverType] == nil {
+			catalog.Servers[serverType] = map[string]ServerVersion{}
+		}
+		for version, images := range versions {
+			if images.Image == "" && images.UBIImage == "" {
+				return nil, fmt.Errorf("invalid version catalog %s: %s %s has no image", path, serverType, version)
+			}
+			catalog.Servers[serverType][version] = images
+		}
+	}
+
+	return catalog, nil
+}
+
+var (
+	catalogMutex   sync.RWMutex
+	currentCatalog = DefaultCatalog()
+)
+
+// GetCatalog returns the catalog that the operator resolves images with
+func GetCatalog() *Catalog {
+	catalogMutex.RLock()
+	defer catalogMutex.RUnlock()
+	return currentCatalog
+}
+
+// SetCatalog replaces the catalog that the operator resolves images with
+func SetCatalog(catalog *Catalog) {
+	catalogMutex.Lock()
+	defer catalogMutex.Unlock()
+	currentCatalog = catalog
+}
+
+// WithRegistry returns the catalog with its images pulled from the registry
+// instead, or the catalog itself when registry is empty
+func (c *Catalog) WithRegistry(registry string) *Catalog {
+	if registry == "" {
+		return c
+	}
+	catalog := *c
+	catalog.ImageRegistry = registry
+	return &catalog
+}
+
+// IsSupported tells whether the catalog has the version of the server type
+func (c *Catalog) IsSupported(serverType, version string) bool {
+	_, found := c.Servers[serverType][version]
+	return found
+}
+
+// Versions returns the supported versions of the server type, sorted
+func (c *Catalog) Versions(serverType string) []string {
+	versions := []string{}
+	for version := range c.Servers[serverType] {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// ServerImage returns the image of the version of the server type, or false
+// when the catalog has no such image
+func (c *Catalog) ServerImage(serverType, version string, ubi bool) (string, bool) {
+	serverVersion, found := c.Servers[serverType][version]
+	if !found {
+		return "", false
+	}
+	image := serverVersion.get(ubi)
+	if image == "" {
+		return "", false
+	}
+	return ReplaceRegistry(image, c.ImageRegistry), true
+}
+
+// ConfigBuilderImage returns the config builder image for the version of the
+// server type, which is the default one unless the version sets its own
+func (c *Catalog) ConfigBuilderImage(serverType, version string, ubi bool) string {
+	image := c.ConfigBuilder.get(ubi)
+	if serverVersion, found := c.Servers[serverType][version]; found && serverVersion.ConfigBuilder != nil {
+		if versionImage := serverVersion.ConfigBuilder.get(ubi); versionImage != "" {
+			image = versionImage
+		}
+	}
+	return ReplaceRegistry(image, c.ImageRegistry)
+}
+
+// ReplaceRegistry returns the image pulled from the registry instead of its
+// own one, which is Docker Hub when the image does not name a registry. The
+// image is returned unchanged when registry is empty.
+func ReplaceRegistry(image, registry string) string {
+	if registry == "" {
+		return image
+	}
+
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && isRegistry(parts[0]) {
+		image = parts[1]
+	}
+	return strings.TrimSuffix(registry, "/") + "/" + image
+}
+
+// isRegistry tells whether the first component of an image name is a
+// registry host rather than a Docker Hub namespace, the same way docker does
+func isRegistry(component string) bool {
+	return strings.ContainsAny(component, ".:") || component == "localhost"
+}
@@ -0,0 +1,218 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package images
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeCatalogFile(t *testing.T, contents string) (string, func()) {
+	dir, err := ioutil.TempDir("", "version-catalog")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err.Error())
+	}
+	path := filepath.Join(dir, "catalog.yaml")
+	if err = ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatalf("failed to write catalog: %s", err.Error())
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestReplaceRegistry(t *testing.T) {
+	tests := []struct {
+		image    string
+		registry string
+		want     string
+	}{
+		{image: "datastax/dse-server:6.8.0", registry: "", want: "datastax/dse-server:6.8.0"},
+		{image: "datastax/dse-server:6.8.0", registry: "registry.example.com", want: "registry.example.com/datastax/dse-server:6.8.0"},
+		{image: "busybox", registry: "registry.example.com/", want: "registry.example.com/busybox"},
+		{image: "docker.io/datastax/cassandra:3.11.6-ubi7", registry: "registry.example.com", want: "registry.example.com/datastax/cassandra:3.11.6-ubi7"},
+		{image: "localhost:5000/cass-config-builder:1.0.1", registry: "mirror:5000/dockerhub", want: "mirror:5000/dockerhub/cass-config-builder:1.0.1"},
+		{image: "localhost/cass-config-builder:1.0.1", registry: "registry.example.com", want: "registry.example.com/cass-config-builder:1.0.1"},
+	}
+	for _, tt := range tests {
+		if got := ReplaceRegistry(tt.image, tt.registry); got != tt.want {
+			t.Errorf("ReplaceRegistry(%v, %v) = %v, want %v", tt.image, tt.registry, got, tt.want)
+		}
+	}
+}
+
+func TestCatalog_ServerImage(t *testing.T) {
+	catalog := DefaultCatalog()
+	catalog.Servers["cassandra"]["3.11.7"] = ServerVersion{Variants: Variants{Image: "datastax/cassandra-mgmtapi-3_11_7:v0.1.13"}}
+
+	tests := []struct {
+		name       string
+		serverType string
+		version    string
+		ubi        bool
+		registry   string
+		want       string
+		wantFound  bool
+	}{
+		{
+			name:       "Default image",
+			serverType: "dse",
+			version:    "6.8.1",
+			want:       "datastax/dse-server:6.8.1",
+			wantFound:  true,
+		},
+		{
+			name:       "UBI image",
+			serverType: "cassandra",
+			version:    "4.0.0",
+			ubi:        true,
+			want:       "datastax/cassandra:4.0-ubi7",
+			wantFound:  true,
+		},
+		{
+			name:       "Registry override",
+			serverType: "cassandra",
+			version:    "3.11.6",
+			registry:   "registry.example.com",
+			want:       "registry.example.com/datastax/cassandra-mgmtapi-3_11_6:v0.1.5",
+			wantFound:  true,
+		},
+		{
+			name:       "Unknown version",
+			serverType: "dse",
+			version:    "6.7.0",
+			wantFound:  false,
+		},
+		{
+			name:       "Version without UBI image",
+			serverType: "cassandra",
+			version:    "3.11.7",
+			ubi:        true,
+			wantFound:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog.ImageRegistry = tt.registry
+			got, found := catalog.ServerImage(tt.serverType, tt.version, tt.ubi)
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("ServerImage() = %v, %v, want %v, %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestCatalog_ConfigBuilderImage(t *testing.T) {
+	catalog := DefaultCatalog()
+	catalog.Servers["cassandra"]["4.0.0"] = ServerVersion{
+		Variants:      catalog.Servers["cassandra"]["4.0.0"].Variants,
+		ConfigBuilder: &Variants{Image: "datastax/cass-config-builder:1.0.3"},
+	}
+
+	assert.Equal(t, "datastax/cass-config-builder:1.0.1", catalog.ConfigBuilderImage("dse", "6.8.0", false))
+	assert.Equal(t, "datastax/cass-config-builder:1.0.1-ubi7", catalog.ConfigBuilderImage("dse", "6.8.0", true))
+	assert.Equal(t, "datastax/cass-config-builder:1.0.3", catalog.ConfigBuilderImage("cassandra", "4.0.0", false))
+	assert.Equal(t, "datastax/cass-config-builder:1.0.1-ubi7", catalog.ConfigBuilderImage("cassandra", "4.0.0", true),
+		"Should fall back to the default config builder when the version has no UBI variant")
+
+	catalog.ImageRegistry = "registry.example.com"
+	assert.Equal(t, "registry.example.com/datastax/cass-config-builder:1.0.1", catalog.ConfigBuilderImage("cassandra", "9.9.9", false))
+}
+
+func TestCatalog_WithRegistry(t *testing.T) {
+	catalog := DefaultCatalog()
+	catalog.ImageRegistry = "registry.example.com"
+
+	assert.True(t, catalog == catalog.WithRegistry(""))
+
+	other := catalog.WithRegistry("mirror.example.com:5000")
+	image, _ := other.ServerImage("dse", "6.8.0", false)
+	assert.Equal(t, "mirror.example.com:5000/datastax/dse-server:6.8.0", image)
+	assert.Equal(t, "registry.example.com", catalog.ImageRegistry, "Should leave the catalog alone")
+}
+
+func TestLoadCatalog(t *testing.T) {
+	path, cleanup := writeCatalogFile(t, `
+imageRegistry: registry.example.com
+configBuilder:
+  image: datastax/cass-config-builder:1.0.3
+servers:
+  cassandra:
+    "3.11.7":
+      image: datastax/cassandra-mgmtapi-3_11_7:v0.1.13
+      ubiImage: datastax/cassandra:3.11.7-ubi7
+    "4.0.0":
+      image: example/cassandra-4.0:beta
+      configBuilder:
+        image: example/config-builder:4.0
+  dse:
+    "6.8.2":
+      image: datastax/dse-server:6.8.2
+`)
+	defer cleanup()
+
+	catalog, err := LoadCatalog(path)
+	if err != nil {
+		t.Fatalf("LoadCatalog() failed: %s", err.Error())
+	}
+
+	assert.Equal(t, "registry.example.com", catalog.ImageRegistry)
+	assert.Equal(t, Variants{Image: "datastax/cass-config-builder:1.0.3", UBIImage: "datastax/cass-config-builder:1.0.1-ubi7"},
+		catalog.ConfigBuilder)
+	assert.Equal(t, []string{"3.11.6", "3.11.7", "4.0.0"}, catalog.Versions("cassandra"))
+	assert.Equal(t, []string{"6.8.0", "6.8.1", "6.8.2"}, catalog.Versions("dse"))
+	assert.True(t, catalog.IsSupported("dse", "6.8.2"))
+	assert.False(t, catalog.IsSupported("dse", "6.8.3"))
+
+	image, _ := catalog.ServerImage("cassandra", "4.0.0", false)
+	assert.Equal(t, "registry.example.com/example/cassandra-4.0:beta", image)
+	assert.Equal(t, "registry.example.com/example/config-builder:4.0", catalog.ConfigBuilderImage("cassandra", "4.0.0", false))
+
+	// The default catalog is left alone
+	assert.Equal(t, []string{"3.11.6", "4.0.0"}, DefaultCatalog().Versions("cassandra"))
+}
+
+func TestLoadCatalog_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		contents  string
+		errString string
+	}{
+		{
+			name:      "Unknown field",
+			contents:  "servers:\n  dse:\n    \"6.8.2\":\n      img: datastax/dse-server:6.8.2\n",
+			errString: "field img not found",
+		},
+		{
+			name:      "Version without image",
+			contents:  "servers:\n  dse:\n    \"6.8.2\": {}\n",
+			errString: "dse 6.8.2 has no image",
+		},
+		{
+			name:      "Not YAML",
+			contents:  "servers: [",
+			errString: "invalid version catalog",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, cleanup := writeCatalogFile(t, tt.contents)
+			defer cleanup()
+
+			_, err := LoadCatalog(path)
+			if err == nil || !strings.Contains(err.Error(), tt.errString) {
+				t.Errorf("LoadCatalog() err = %v, want %v", err, tt.errString)
+			}
+		})
+	}
+}
+
+func TestLoadCatalog_MissingFile(t *testing.T) {
+	if _, err := LoadCatalog("/nonexistent/catalog.yaml"); !os.IsNotExist(err) {
+		t.Errorf("LoadCatalog() err = %v, want a missing file error", err)
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package metrics
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+)
+
+const (
+	metricsNamespace = "cass_operator"
+
+	resultDone    = "done"
+	resultRequeue = "requeue"
+	resultError   = "error"
+
+	// podStateUnknown is used for pods that do not have the node state label
+	podStateUnknown = "Unknown"
+)
+
+var (
+	reconcileDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "reconcile_duration_seconds",
+			Help:      "Duration of CassandraDatacenter reconciles, by result",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		},
+		[]string{"namespace", "datacenter", "result"},
+	)
+
+	reconcileStepTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "reconcile_step_total",
+			Help:      "Number of reconciles of the racks that ended early at a step, by step and result",
+		},
+		[]string{"namespace", "datacenter", "step", "result"},
+	)
+
+	datacenterPods = newDatacenterGauge(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "datacenter_pods",
+			Help:      "Number of pods of a CassandraDatacenter, by node state",
+		},
+		"state",
+	)
+
+	datacenterCondition = newDatacenterGauge(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "datacenter_condition",
+			Help:      "Whether a condition of a CassandraDatacenter is True (1) or not (0)",
+		},
+		"condition",
+	)
+
+	managementApiRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "management_api_request_duration_seconds",
+			Help:      "Duration of calls to the management API, by endpoint",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"method", "endpoint"},
+	)
+
+	managementApiRequestErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "management_api_request_errors_total",
+			Help:      "Number of failed calls to the management API, by endpoint",
+		},
+		[]string{"method", "endpoint"},
+	)
+)
+
+func init() {
+	// The controller-runtime registry is served by the manager on the
+	// operator metrics port
+	crmetrics.Registry.MustRegister(
+		reconcileDuration,
+		reconcileStepTotal,
+		datacenterPods.vec,
+		datacenterCondition.vec,
+		managementApiRequestDuration,
+		managementApiRequestErrors,
+	)
+}
+
+// getResultLabel returns how a reconcile ended: done, requeue or error
+func getResultLabel(res reconcile.Result, err error) string {
+	if err != nil {
+		return resultError
+	}
+	if res.Requeue || res.RequeueAfter > 0 {
+		return resultRequeue
+	}
+	return resultDone
+}
+
+// ObserveReconcile records the duration and result of a reconcile of a
+// CassandraDatacenter
+func ObserveReconcile(namespace, dcName string, res reconcile.Result, err error, duration time.Duration) {
+	reconcileDuration.
+		WithLabelValues(namespace, dcName, getResultLabel(res, err)).
+		Observe(duration.Seconds())
+}
+
+// RecordReconcileStep records the step that ended a reconcile of the racks
+// before all of them ran
+func RecordReconcileStep(namespace, dcName, step string, res reconcile.Result, err error) {
+	reconcileStepTotal.
+		WithLabelValues(namespace, dcName, step, getResultLabel(res, err)).
+		Inc()
+}
+
+// SetDatacenterPods updates the number of pods of the datacenter in each
+// node state
+func SetDatacenterPods(dc *api.CassandraDatacenter, pods []*corev1.Pod) {
+	counts := map[string]float64{}
+	for _, pod := range pods {
+		state, ok := pod.Labels[api.CassNodeState]
+		if !ok || state == "" {
+			state = podStateUnknown
+		}
+		counts[state]++
+	}
+	datacenterPods.set(dc.Namespace, dc.Name, counts)
+}
+
+// SetDatacenterConditions updates a gauge for every condition in the status
+// of the datacenter
+func SetDatacenterConditions(dc *api.CassandraDatacenter) {
+	values := map[string]float64{}
+	for _, condition := range dc.Status.Conditions {
+		value := 0.0
+		if condition.Status == corev1.ConditionTrue {
+			value = 1.0
+		}
+		values[string(condition.Type)] = value
+	}
+	datacenterCondition.set(dc.Namespace, dc.Name, values)
+}
+
+// DeleteDatacenterMetrics removes the gauges of a datacenter that no longer
+// exists
+func DeleteDatacenterMetrics(namespace, dcName string) {
+	datacenterPods.delete(namespace, dcName)
+	datacenterCondition.delete(namespace, dcName)
+}
+
+// ObserveManagementApiRequest records the duration of a call to the
+// management API and whether it failed. Query parameters are left out of the
+// endpoint so it does not vary with the arguments of the call.
+func ObserveManagementApiRequest(method, endpoint string, duration time.Duration, err error) {
+	if idx := strings.Index(endpoint, "?"); idx >= 0 {
+		endpoint = endpoint[:idx]
+	}
+
+	managementApiRequestDuration.WithLabelValues(method, endpoint).Observe(duration.Seconds())
+	if err != nil {
+		managementApiRequestErrors.WithLabelValues(method, endpoint).Inc()
+	}
+}
+
+// datacenterGauge is a gauge with a series per value of a label for each
+// datacenter. It remembers the values set for every datacenter so the series
+// that go away, e.g. a node state no pod is in anymore, can be deleted.
+type datacenterGauge struct {
+	vec *prometheus.GaugeVec
+
+	mutex  sync.Mutex
+	values map[string]map[string]bool
+}
+
+func newDatacenterGauge(opts prometheus.GaugeOpts, label string) *datacenterGauge {
+	return &datacenterGauge{
+		vec:    prometheus.NewGaugeVec(opts, []string{"namespace", "datacenter", label}),
+		values: map[string]map[string]bool{},
+	}
+}
+
+func (g *datacenterGauge) set(namespace, dcName string, values map[string]float64) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	key := namespace + "/" + dcName
+	for value := range g.values[key] {
+		if _, ok := values[value]; !ok {
+			g.vec.DeleteLabelValues(namespace, dcName, value)
+		}
+	}
+
+	current := map[string]bool{}
+	for value, v := range values {
+		g.vec.WithLabelValues(namespace, dcName, value).Set(v)
+		current[value] = true
+	}
+	g.values[key] = current
+}
+
+func (g *datacenterGauge) delete(namespace, dcName string) {
+	g.set(namespace, dcName, map[string]float64{})
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	delete(g.values, namespace+"/"+dcName)
+}
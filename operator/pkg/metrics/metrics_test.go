@@ -0,0 +1,108 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package metrics
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+)
+
+func newTestDatacenter(name string) *api.CassandraDatacenter {
+	return &api.CassandraDatacenter{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+	}
+}
+
+func newTestPod(idx int, state string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   fmt.Sprintf("pod-%d", idx),
+			Labels: map[string]string{},
+		},
+	}
+	if state != "" {
+		pod.Labels[api.CassNodeState] = state
+	}
+	return pod
+}
+
+func Test_getResultLabel(t *testing.T) {
+	assert.Equal(t, resultDone, getResultLabel(reconcile.Result{}, nil))
+	assert.Equal(t, resultRequeue, getResultLabel(reconcile.Result{Requeue: true}, nil))
+	assert.Equal(t, resultRequeue, getResultLabel(reconcile.Result{RequeueAfter: time.Second}, nil))
+	assert.Equal(t, resultError, getResultLabel(reconcile.Result{Requeue: true}, fmt.Errorf("failed")))
+}
+
+func TestRecordReconcileStep(t *testing.T) {
+	counter := reconcileStepTotal.WithLabelValues("default", "step-dc", "CheckRackScale", resultRequeue)
+	before := testutil.ToFloat64(counter)
+
+	RecordReconcileStep("default", "step-dc", "CheckRackScale", reconcile.Result{Requeue: true}, nil)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(counter))
+}
+
+func TestSetDatacenterPods(t *testing.T) {
+	dc := newTestDatacenter("pods-dc")
+
+	SetDatacenterPods(dc, []*corev1.Pod{
+		newTestPod(0, "Started"),
+		newTestPod(1, "Started"),
+		newTestPod(2, "Ready-to-Start"),
+		newTestPod(3, ""),
+	})
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(datacenterPods.vec.WithLabelValues("default", "pods-dc", "Started")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(datacenterPods.vec.WithLabelValues("default", "pods-dc", "Ready-to-Start")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(datacenterPods.vec.WithLabelValues("default", "pods-dc", podStateUnknown)))
+
+	SetDatacenterPods(dc, []*corev1.Pod{
+		newTestPod(0, "Started"),
+	})
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(datacenterPods.vec.WithLabelValues("default", "pods-dc", "Started")))
+	assert.False(t, datacenterPods.vec.DeleteLabelValues("default", "pods-dc", "Ready-to-Start"),
+		"Should have deleted the series of states no pod is in")
+}
+
+func TestSetDatacenterConditions(t *testing.T) {
+	dc := newTestDatacenter("conditions-dc")
+	dc.Status.Conditions = []api.DatacenterCondition{
+		*api.NewDatacenterCondition(api.DatacenterReady, corev1.ConditionTrue),
+		*api.NewDatacenterCondition(api.DatacenterScalingUp, corev1.ConditionFalse),
+	}
+
+	SetDatacenterConditions(dc)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(datacenterCondition.vec.WithLabelValues("default", "conditions-dc", "Ready")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(datacenterCondition.vec.WithLabelValues("default", "conditions-dc", "ScalingUp")))
+
+	DeleteDatacenterMetrics("default", "conditions-dc")
+
+	assert.False(t, datacenterCondition.vec.DeleteLabelValues("default", "conditions-dc", "Ready"),
+		"Should have deleted the series of the datacenter")
+}
+
+func TestObserveManagementApiRequest(t *testing.T) {
+	errors := managementApiRequestErrors.WithLabelValues("DELETE", "/api/v0/ops/node/snapshots")
+	before := testutil.ToFloat64(errors)
+
+	ObserveManagementApiRequest("DELETE", "/api/v0/ops/node/snapshots?snapshotNames=nightly",
+		time.Second, fmt.Errorf("failed"))
+	ObserveManagementApiRequest("DELETE", "/api/v0/ops/node/snapshots", time.Second, nil)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(errors), "Should leave the query out of the endpoint")
+}
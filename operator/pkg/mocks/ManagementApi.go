@@ -0,0 +1,85 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package mocks
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ManagementApi is a fake management API for unit tests. It answers requests
+// with a 200 status, unless StatusCodes says otherwise, and records the
+// requests it receives.
+type ManagementApi struct {
+	// The response bodies by request path. Requests to other paths are
+	// answered with "OK".
+	Responses map[string]string
+
+	// When set, returns the response body of a request instead of
+	// Responses, which is still used when it returns an empty string
+	Respond func(req *http.Request) string
+
+	// The status codes by request path, for requests that are not answered
+	// with a 200 status
+	StatusCodes map[string]int
+
+	// Every request received, in order
+	Requests []*http.Request
+
+	// The POST requests received, in order
+	Posts []*http.Request
+}
+
+// NewManagementApi returns a fake management API answering requests to the
+// given paths with the given bodies
+func NewManagementApi(responses map[string]string) *ManagementApi {
+	if responses == nil {
+		responses = map[string]string{}
+	}
+	return &ManagementApi{Responses: responses}
+}
+
+// HttpClient returns a mock HttpClient that sends its requests to the fake
+// management API
+func (m *ManagementApi) HttpClient() *HttpClient {
+	mockHttpClient := &HttpClient{}
+	mockHttpClient.On("Do",
+		mock.MatchedBy(
+			func(req *http.Request) bool {
+				return req != nil
+			})).
+		Return(m.serve, nil)
+	return mockHttpClient
+}
+
+func (m *ManagementApi) serve(req *http.Request) *http.Response {
+	m.Requests = append(m.Requests, req)
+	if req.Method == http.MethodPost {
+		m.Posts = append(m.Posts, req)
+	}
+
+	body := ""
+	if m.Respond != nil {
+		body = m.Respond(req)
+	}
+	if body == "" {
+		body = m.Responses[req.URL.Path]
+	}
+	if body == "" {
+		body = "OK"
+	}
+
+	statusCode, ok := m.StatusCodes[req.URL.Path]
+	if !ok {
+		statusCode = http.StatusOK
+	}
+
+	return &http.Response{
+		StatusCode: statusCode,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
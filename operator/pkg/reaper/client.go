@@ -0,0 +1,251 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reaper
+
+// This file defines a client for the parts of the Reaper REST API that the
+// operator uses, see http://cassandra-reaper.io/docs/api/
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetOwner returns the owner of the repair schedules that the operator
+// creates for a datacenter, which tells them apart from the ones created
+// through the Reaper UI. Reaper keeps the schedules of a cluster together, so
+// the owner also tells apart the schedules of each datacenter of the cluster.
+func GetOwner(namespace string, dcName string) string {
+	return fmt.Sprintf("cass-operator/%s/%s", namespace, dcName)
+}
+
+const (
+	ScheduleStateActive = "ACTIVE"
+	ScheduleStatePaused = "PAUSED"
+)
+
+// RepairSchedule is a repair schedule as returned by Reaper
+type RepairSchedule struct {
+	ID                   string   `json:"id"`
+	Owner                string   `json:"owner"`
+	State                string   `json:"state"`
+	ClusterName          string   `json:"cluster_name"`
+	KeyspaceName         string   `json:"keyspace_name"`
+	ColumnFamilies       []string `json:"column_families"`
+	Intensity            float64  `json:"intensity"`
+	RepairParallelism    string   `json:"repair_parallelism"`
+	ScheduledDaysBetween int32    `json:"scheduled_days_between"`
+}
+
+// RepairScheduleRequest holds the parameters of a new repair schedule. The
+// Reaper defaults are used for Intensity and RepairParallelism when they are
+// empty.
+type RepairScheduleRequest struct {
+	ClusterName          string
+	Owner                string
+	Keyspace             string
+	Tables               []string
+	Intensity            string
+	RepairParallelism    string
+	ScheduledDaysBetween int32
+}
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	username   string
+	password   string
+	loggedIn   bool
+}
+
+// NewClient returns a client for the Reaper REST API at baseURL. The client
+// logs in with the given credentials before its first request when username
+// is not empty.
+func NewClient(baseURL, username, password string) (*Client, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{
+			Jar:     jar,
+			Timeout: 10 * time.Second,
+		},
+		username: username,
+		password: password,
+	}, nil
+}
+
+var invalidClusterNameChars = regexp.MustCompile(`[^a-z0-9_\-.]`)
+
+// GetClusterName returns the name that Reaper gives a Cassandra cluster
+func GetClusterName(cassandraClusterName string) string {
+	return invalidClusterNameChars.ReplaceAllString(strings.ToLower(cassandraClusterName), "")
+}
+
+// IsHealthy tells whether Reaper answers requests
+func (c *Client) IsHealthy() bool {
+	_, err := c.do(http.MethodGet, "/ping", nil, http.StatusNoContent, http.StatusOK)
+	return err == nil
+}
+
+// ClusterExists tells whether the cluster is registered with Reaper
+func (c *Client) ClusterExists(clusterName string) (bool, error) {
+	_, err := c.do(http.MethodGet, "/cluster/"+url.PathEscape(clusterName), nil, http.StatusOK)
+	if err != nil {
+		if statusErr, ok := err.(*StatusError); ok && statusErr.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// AddCluster registers the cluster with Reaper, which discovers its nodes
+// through the seed host
+func (c *Client) AddCluster(clusterName, seedHost string) error {
+	params := url.Values{}
+	params.Set("seedHost", seedHost)
+	_, err := c.do(http.MethodPut, "/cluster/"+url.PathEscape(clusterName), params,
+		http.StatusOK, http.StatusCreated, http.StatusNoContent)
+	return err
+}
+
+// GetRepairSchedules returns the repair schedules of the cluster
+func (c *Client) GetRepairSchedules(clusterName string) ([]RepairSchedule, error) {
+	body, err := c.do(http.MethodGet, "/repair_schedule/cluster/"+url.PathEscape(clusterName), nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := []RepairSchedule{}
+	if err := json.Unmarshal(body, &schedules); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// CreateRepairSchedule creates a repair schedule with the owner of the request
+func (c *Client) CreateRepairSchedule(request RepairScheduleRequest) error {
+	params := url.Values{}
+	params.Set("clusterName", request.ClusterName)
+	params.Set("keyspace", request.Keyspace)
+	params.Set("owner", request.Owner)
+	params.Set("scheduleDaysBetween", strconv.Itoa(int(request.ScheduledDaysBetween)))
+	if len(request.Tables) > 0 {
+		params.Set("tables", strings.Join(request.Tables, ","))
+	}
+	if request.Intensity != "" {
+		params.Set("intensity", request.Intensity)
+	}
+	if request.RepairParallelism != "" {
+		params.Set("repairParallelism", request.RepairParallelism)
+	}
+
+	_, err := c.do(http.MethodPost, "/repair_schedule", params, http.StatusOK, http.StatusCreated)
+	return err
+}
+
+// DeleteRepairSchedule deletes a repair schedule of the given owner. Reaper
+// only deletes paused schedules, so the schedule is paused first.
+func (c *Client) DeleteRepairSchedule(id string, owner string) error {
+	params := url.Values{}
+	params.Set("state", ScheduleStatePaused)
+	_, err := c.do(http.MethodPut, "/repair_schedule/"+url.PathEscape(id), params,
+		http.StatusOK, http.StatusNoContent, http.StatusNotModified)
+	if err != nil {
+		return err
+	}
+
+	params = url.Values{}
+	params.Set("owner", owner)
+	_, err = c.do(http.MethodDelete, "/repair_schedule/"+url.PathEscape(id), params,
+		http.StatusOK, http.StatusAccepted, http.StatusNoContent)
+	return err
+}
+
+// StatusError is returned when Reaper answers a request with an unexpected
+// status code
+type StatusError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("reaper request %s %s failed with status code %d: %s",
+		e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+func (c *Client) login() error {
+	form := url.Values{}
+	form.Set("username", c.username)
+	form.Set("password", c.password)
+	form.Set("rememberMe", "false")
+
+	res, err := c.httpClient.PostForm(c.baseURL+"/login", form)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent {
+		return &StatusError{Method: http.MethodPost, Path: "/login", StatusCode: res.StatusCode}
+	}
+	c.loggedIn = true
+	return nil
+}
+
+func (c *Client) do(method, path string, params url.Values, expectedStatusCodes ...int) ([]byte, error) {
+	if c.username != "" && !c.loggedIn {
+		if err := c.login(); err != nil {
+			return nil, err
+		}
+	}
+
+	endpoint := c.baseURL + path
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequest(method, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, code := range expectedStatusCodes {
+		if res.StatusCode == code {
+			return body, nil
+		}
+	}
+
+	return nil, &StatusError{
+		Method:     method,
+		Path:       path,
+		StatusCode: res.StatusCode,
+		Body:       string(body),
+	}
+}
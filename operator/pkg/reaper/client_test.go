@@ -0,0 +1,141 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reaper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetClusterName(t *testing.T) {
+	assert.Equal(t, "cluster1", GetClusterName("Cluster1"))
+	assert.Equal(t, "mycluster-1_a.b", GetClusterName("My Cluster-1_a.b"))
+}
+
+func TestClient_Login(t *testing.T) {
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.URL.Path == "/login" {
+			assert.NoError(t, r.ParseForm())
+			assert.Equal(t, "admin", r.PostForm.Get("username"))
+			assert.Equal(t, "secret", r.PostForm.Get("password"))
+			http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: "session-1", Path: "/"})
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		cookie, err := r.Cookie("JSESSIONID")
+		if err != nil || cookie.Value != "session-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "admin", "secret")
+	assert.NoError(t, err)
+
+	assert.True(t, client.IsHealthy())
+	assert.True(t, client.IsHealthy())
+	assert.Equal(t, []string{"POST /login", "GET /ping", "GET /ping"}, requests, "Should only log in once")
+}
+
+func TestClient_ClusterExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/cluster/cluster1" {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"name":"cluster1"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "", "")
+	assert.NoError(t, err)
+
+	exists, err := client.ClusterExists("cluster1")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = client.ClusterExists("cluster2")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestClient_CreateRepairSchedule(t *testing.T) {
+	var query map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/repair_schedule", r.URL.Path)
+		query = r.URL.Query()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "", "")
+	assert.NoError(t, err)
+
+	err = client.CreateRepairSchedule(RepairScheduleRequest{
+		ClusterName:          "cluster1",
+		Owner:                "cass-operator/ns1/dc1",
+		Keyspace:             "app",
+		Tables:               []string{"users", "orders"},
+		Intensity:            "0.5",
+		ScheduledDaysBetween: 7,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"clusterName":         {"cluster1"},
+		"keyspace":            {"app"},
+		"owner":               {"cass-operator/ns1/dc1"},
+		"scheduleDaysBetween": {"7"},
+		"tables":              {"users,orders"},
+		"intensity":           {"0.5"},
+	}, query)
+}
+
+func TestClient_DeleteRepairSchedule(t *testing.T) {
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.String())
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "", "")
+	assert.NoError(t, err)
+
+	assert.NoError(t, client.DeleteRepairSchedule("schedule-1", "cass-operator/ns1/dc1"))
+	assert.Equal(t, []string{
+		"PUT /repair_schedule/schedule-1?state=PAUSED",
+		"DELETE /repair_schedule/schedule-1?owner=cass-operator%2Fns1%2Fdc1",
+	}, requests)
+}
+
+func TestClient_UnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("boom"))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "", "")
+	assert.NoError(t, err)
+
+	_, err = client.GetRepairSchedules("cluster1")
+	if assert.Error(t, err) {
+		statusErr, ok := err.(*StatusError)
+		if assert.True(t, ok) {
+			assert.Equal(t, http.StatusInternalServerError, statusErr.StatusCode)
+			assert.Equal(t, "boom", statusErr.Body)
+		}
+	}
+	assert.False(t, client.IsHealthy())
+}
@@ -26,6 +26,35 @@ import (
 
 const pvcName = "server-data"
 
+const tlsKeystoreVolumeName = "encryption-keystores"
+
+// serverVolume is a volume of the cassandra container that is kept on its own
+// PersistentVolumeClaim when the storage config has a claim spec for it
+type serverVolume struct {
+	name      string
+	mountPath string
+	claimSpec *corev1.PersistentVolumeClaimSpec
+}
+
+// getAdditionalServerVolumes returns the commit log, hints and saved caches
+// volumes that the storage config asks for
+func getAdditionalServerVolumes(dc *api.CassandraDatacenter) []serverVolume {
+	storageConfig := dc.Spec.StorageConfig
+	candidates := []serverVolume{
+		{"server-commitlog", api.CommitLogMountPath, storageConfig.CommitLogVolumeClaimSpec},
+		{"server-hints", api.HintsMountPath, storageConfig.HintsVolumeClaimSpec},
+		{"server-saved-caches", api.SavedCachesMountPath, storageConfig.SavedCachesVolumeClaimSpec},
+	}
+
+	volumes := []serverVolume{}
+	for _, volume := range candidates {
+		if volume.claimSpec != nil {
+			volumes = append(volumes, volume)
+		}
+	}
+	return volumes
+}
+
 // Creates a headless service object for the Datacenter, for clients wanting to
 // reach out to a ready Server node for either CQL or mgmt API
 func newServiceForCassandraDatacenter(dc *api.CassandraDatacenter) *corev1.Service {
@@ -195,6 +224,15 @@ func newStatefulSetForCassandraDatacenterHelper(
 		},
 		Spec: *dc.Spec.StorageConfig.CassandraDataVolumeClaimSpec,
 	}}
+	for _, volume := range getAdditionalServerVolumes(dc) {
+		volumeClaimTemplates = append(volumeClaimTemplates, corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: pvcLabels,
+				Name:   volume.name,
+			},
+			Spec: *volume.claimSpec,
+		})
+	}
 
 	nsName := newNamespacedNameForStatefulSet(dc, rackName)
 
@@ -239,6 +277,15 @@ func newStatefulSetForCassandraDatacenterHelper(
 	}
 	result.Annotations = map[string]string{}
 
+	// when several pods of a rack may be restarted at once, the operator
+	// replaces the pods itself rather than leaving it to the StatefulSet
+	// controller, which only replaces one pod at a time
+	if dc.GetMaxUnavailablePerRack() > 1 {
+		result.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{
+			Type: appsv1.OnDeleteStatefulSetStrategyType,
+		}
+	}
+
 	// add a hash here to facilitate checking if updates are needed
 	addHashAnnotation(result)
 
@@ -409,6 +456,12 @@ func buildContainers(dc *api.CassandraDatacenter, serverVolumeMounts []corev1.Vo
 		return nil, err
 	}
 
+	// A Reaper Deployment repairs the node through its JMX
+	if isReaperDeploymentEnabled(dc) {
+		cassContainer.Env = append(cassContainer.Env, buildRemoteJmxEnv(dc)...)
+		ports = append(ports, corev1.ContainerPort{Name: "jmx", ContainerPort: jmxPort})
+	}
+
 	cassContainer.Ports = ports
 	cassContainer.LivenessProbe = probe(8080, "/api/v0/probes/liveness", 15, 15)
 	cassContainer.ReadinessProbe = probe(8080, "/api/v0/probes/readiness", 20, 10)
@@ -422,15 +475,29 @@ func buildContainers(dc *api.CassandraDatacenter, serverVolumeMounts []corev1.Vo
 		Name:      pvcName,
 		MountPath: "/var/lib/cassandra",
 	})
+	// mounted inside of the data volume, where the server keeps them by default
+	for _, volume := range getAdditionalServerVolumes(dc) {
+		serverVolumeMounts = append(serverVolumeMounts, corev1.VolumeMount{
+			Name:      volume.name,
+			MountPath: volume.mountPath,
+		})
+	}
+	if dc.Spec.TLS != nil {
+		serverVolumeMounts = append(serverVolumeMounts, corev1.VolumeMount{
+			Name:      tlsKeystoreVolumeName,
+			MountPath: api.TLSKeystoreMountPath,
+			ReadOnly:  true,
+		})
+	}
 	cassContainer.VolumeMounts = serverVolumeMounts
 
 	// server logger container
 	loggerContainer := corev1.Container{}
 	loggerContainer.Name = "server-system-logger"
 	if baseImageOs := os.Getenv(api.EnvBaseImageOs); baseImageOs != "" {
-		loggerContainer.Image = baseImageOs
+		loggerContainer.Image = dc.ApplyImageRegistry(baseImageOs)
 	} else {
-		loggerContainer.Image = "busybox"
+		loggerContainer.Image = dc.ApplyImageRegistry("busybox")
 	}
 	loggerContainer.Args = []string{
 		"/bin/sh", "-c", "tail -n+1 -F /var/log/cassandra/system.log",
@@ -438,7 +505,7 @@ func buildContainers(dc *api.CassandraDatacenter, serverVolumeMounts []corev1.Vo
 	loggerContainer.VolumeMounts = []corev1.VolumeMount{cassServerLogsMount}
 
 	containers := []corev1.Container{cassContainer, loggerContainer}
-	if dc.Spec.Reaper != nil && dc.Spec.Reaper.Enabled && dc.Spec.ServerType == "cassandra" {
+	if isReaperSidecarEnabled(dc) {
 		reaperContainer := buildReaperContainer(dc)
 		containers = append(containers, reaperContainer)
 	}
@@ -471,7 +538,63 @@ func buildInitContainers(dc *api.CassandraDatacenter, rackName string) ([]corev1
 		{Name: "DSE_VERSION", Value: serverVersion},
 	}
 
-	return []corev1.Container{serverCfg}, nil
+	initContainers := []corev1.Container{serverCfg}
+	if dc.Spec.TLS != nil {
+		serverImage, err := dc.GetServerImage()
+		if err != nil {
+			return nil, err
+		}
+		initContainers = append(initContainers, buildTLSPasswordsInitContainer(dc, serverImage))
+	}
+	if isReaperDeploymentEnabled(dc) {
+		serverImage, err := dc.GetServerImage()
+		if err != nil {
+			return nil, err
+		}
+		initContainers = append(initContainers, buildJmxCredentialsInitContainer(dc, serverImage))
+	}
+
+	return initContainers, nil
+}
+
+// tlsPasswordsScript sets every keystore_password and truststore_password of
+// the cassandra.yaml written by the config builder, quoting the passwords for
+// YAML and escaping them for sed
+const tlsPasswordsScript = `quote() { printf '%s' "'$(printf '%s' "$1" | sed -e "s/'/''/g")'" | sed -e 's/[\\&|]/\\&/g'; }
+keystore=$(quote "${KEYSTORE_PASSWORD:-cassandra}")
+truststore=$(quote "${TRUSTSTORE_PASSWORD:-cassandra}")
+sed -i -e "s|^\([[:space:]]*keystore_password:\).*|\1 $keystore|" -e "s|^\([[:space:]]*truststore_password:\).*|\1 $truststore|" /config/cassandra.yaml`
+
+// buildTLSPasswordsInitContainer returns the init container that puts the
+// keystore and truststore passwords of the secret of Spec.TLS in the config,
+// so that they are not part of the pod spec
+func buildTLSPasswordsInitContainer(dc *api.CassandraDatacenter, serverImage string) corev1.Container {
+	optional := true
+	passwordEnvVar := func(name, key string) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: dc.Spec.TLS.KeystoreSecretName},
+					Key:                  key,
+					Optional:             &optional,
+				},
+			},
+		}
+	}
+
+	return corev1.Container{
+		Name:    "tls-passwords-init",
+		Image:   serverImage,
+		Command: []string{"/bin/sh", "-c", tlsPasswordsScript},
+		Env: []corev1.EnvVar{
+			passwordEnvVar("KEYSTORE_PASSWORD", api.TLSKeystorePasswordKey),
+			passwordEnvVar("TRUSTSTORE_PASSWORD", api.TLSTruststorePasswordKey),
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "server-config", MountPath: "/config"},
+		},
+	}
 }
 
 func buildPodTemplateSpec(dc *api.CassandraDatacenter, zone string, rackName string) (*corev1.PodTemplateSpec, error) {
@@ -510,13 +633,37 @@ func buildPodTemplateSpec(dc *api.CassandraDatacenter, zone string, rackName str
 	}
 
 	volumes := []corev1.Volume{vServerConfig, vServerLogs}
+
+	if dc.Spec.TLS != nil {
+		vKeystores := corev1.Volume{}
+		vKeystores.Name = tlsKeystoreVolumeName
+		vKeystores.VolumeSource = corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: dc.Spec.TLS.KeystoreSecretName,
+			},
+		}
+		volumes = append(volumes, vKeystores)
+	}
+
+	if isReaperDeploymentEnabled(dc) {
+		vJmxCredentials := corev1.Volume{}
+		vJmxCredentials.Name = jmxCredentialsVolume
+		vJmxCredentials.VolumeSource = corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		}
+		volumes = append(volumes, vJmxCredentials)
+	}
+
 	baseTemplate.Spec.Volumes = append(baseTemplate.Spec.Volumes, volumes...)
 
-	serviceAccount := "default"
-	if dc.Spec.ServiceAccount != "" {
-		serviceAccount = dc.Spec.ServiceAccount
+	baseTemplate.Spec.ServiceAccountName = dc.GetServiceAccount()
+
+	// image pull secrets
+	for _, secret := range dc.Spec.ImagePullSecrets {
+		if !containsImagePullSecret(baseTemplate.Spec.ImagePullSecrets, secret.Name) {
+			baseTemplate.Spec.ImagePullSecrets = append(baseTemplate.Spec.ImagePullSecrets, secret)
+		}
 	}
-	baseTemplate.Spec.ServiceAccountName = serviceAccount
 
 	// init containers
 	initContainers, err := buildInitContainers(dc, rackName)
@@ -525,9 +672,15 @@ func buildPodTemplateSpec(dc *api.CassandraDatacenter, zone string, rackName str
 	}
 	baseTemplate.Spec.InitContainers = append(initContainers, baseTemplate.Spec.InitContainers...)
 
+	// init containers may share a volume, like the server config, which
+	// must only be mounted once in the server container
 	var serverVolumeMounts []corev1.VolumeMount
 	for _, c := range initContainers {
-		serverVolumeMounts = append(serverVolumeMounts, c.VolumeMounts...)
+		for _, mount := range c.VolumeMounts {
+			if !containsVolumeMount(serverVolumeMounts, mount.MountPath) {
+				serverVolumeMounts = append(serverVolumeMounts, mount)
+			}
+		}
 	}
 
 	// containers
@@ -541,6 +694,24 @@ func buildPodTemplateSpec(dc *api.CassandraDatacenter, zone string, rackName str
 	return baseTemplate, nil
 }
 
+func containsImagePullSecret(secrets []corev1.LocalObjectReference, name string) bool {
+	for _, secret := range secrets {
+		if secret.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func containsVolumeMount(mounts []corev1.VolumeMount, mountPath string) bool {
+	for _, mount := range mounts {
+		if mount.MountPath == mountPath {
+			return true
+		}
+	}
+	return false
+}
+
 func buildInitReaperSchemaJob(dc *api.CassandraDatacenter) *v1.Job {
 	return &v1.Job{
 		TypeMeta: metav1.TypeMeta{
@@ -555,11 +726,12 @@ func buildInitReaperSchemaJob(dc *api.CassandraDatacenter) *v1.Job {
 		Spec: v1.JobSpec{
 			Template: corev1.PodTemplateSpec{
 				Spec: corev1.PodSpec{
-					RestartPolicy: corev1.RestartPolicyOnFailure,
+					RestartPolicy:    corev1.RestartPolicyOnFailure,
+					ImagePullSecrets: dc.Spec.ImagePullSecrets,
 					Containers: []corev1.Container{
 						{
 							Name:            getReaperSchemaInitJobName(dc),
-							Image:           ReaperSchemaInitJobImage,
+							Image:           dc.ApplyImageRegistry(ReaperSchemaInitJobImage),
 							ImagePullPolicy: corev1.PullIfNotPresent,
 							Env: []corev1.EnvVar{
 								{
@@ -220,3 +220,199 @@ func TestCassandraDatacenter_buildPodTemplateSpec_labels_merge(t *testing.T) {
 		t.Errorf("labels = %v, want %v", got, expected)
 	}
 }
+
+func TestCassandraDatacenter_buildPodTemplateSpec_tls(t *testing.T) {
+	dc := &api.CassandraDatacenter{
+		Spec: api.CassandraDatacenterSpec{
+			ClusterName:   "bob",
+			ServerType:    "cassandra",
+			ServerVersion: "3.11.6",
+			TLS: &api.TLSConfig{
+				KeystoreSecretName: "bob-keystores",
+			},
+		},
+	}
+
+	got, err := buildPodTemplateSpec(dc, "testzone", "testrack")
+	assert.NoError(t, err, "should not have gotten error when building podTemplateSpec")
+
+	var volume *corev1.Volume
+	for i := range got.Spec.Volumes {
+		if got.Spec.Volumes[i].Name == tlsKeystoreVolumeName {
+			volume = &got.Spec.Volumes[i]
+		}
+	}
+	if assert.NotNil(t, volume, "should have a volume for the keystores") {
+		assert.Equal(t, "bob-keystores", volume.Secret.SecretName)
+	}
+
+	assert.Equal(t, "cassandra", got.Spec.Containers[0].Name)
+	assert.Contains(t, got.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      tlsKeystoreVolumeName,
+		MountPath: api.TLSKeystoreMountPath,
+		ReadOnly:  true,
+	})
+
+	// The server config is shared by the init containers, but may only be
+	// mounted once in each container
+	for _, container := range got.Spec.Containers {
+		mountPaths := map[string]bool{}
+		for _, mount := range container.VolumeMounts {
+			assert.False(t, mountPaths[mount.MountPath], "%s should be mounted once in %s", mount.MountPath, container.Name)
+			mountPaths[mount.MountPath] = true
+		}
+	}
+
+	// The passwords are only read from the secret in the pods
+	if assert.Equal(t, 2, len(got.Spec.InitContainers)) {
+		initContainer := got.Spec.InitContainers[1]
+		assert.Equal(t, "tls-passwords-init", initContainer.Name)
+		assert.Contains(t, initContainer.VolumeMounts, corev1.VolumeMount{Name: "server-config", MountPath: "/config"})
+		if assert.Equal(t, 2, len(initContainer.Env)) {
+			keystorePassword := initContainer.Env[0].ValueFrom.SecretKeyRef
+			assert.Equal(t, "bob-keystores", keystorePassword.Name)
+			assert.Equal(t, api.TLSKeystorePasswordKey, keystorePassword.Key)
+			truststorePassword := initContainer.Env[1].ValueFrom.SecretKeyRef
+			assert.Equal(t, "bob-keystores", truststorePassword.Name)
+			assert.Equal(t, api.TLSTruststorePasswordKey, truststorePassword.Key)
+		}
+	}
+}
+
+func TestCassandraDatacenter_buildPodTemplateSpec_reaperDeploymentJmx(t *testing.T) {
+	dc := &api.CassandraDatacenter{
+		Spec: api.CassandraDatacenterSpec{
+			ClusterName:   "bob",
+			ServerType:    "cassandra",
+			ServerVersion: "3.11.6",
+			Reaper: &api.ReaperConfig{
+				Enabled:        true,
+				DeploymentMode: api.ReaperDeploymentModeDeployment,
+				JmxSecretName:  "reaper-jmx",
+			},
+		},
+	}
+
+	got, err := buildPodTemplateSpec(dc, "testzone", "testrack")
+	assert.NoError(t, err, "should not have gotten error when building podTemplateSpec")
+
+	cassContainer := got.Spec.Containers[0]
+	assert.Equal(t, "cassandra", cassContainer.Name)
+	env := map[string]string{}
+	for _, envVar := range cassContainer.Env {
+		env[envVar.Name] = envVar.Value
+	}
+	assert.Equal(t, "no", env["LOCAL_JMX"])
+	assert.Equal(t,
+		"-Dcom.sun.management.jmxremote.password.file=/etc/jmx-credentials/jmxremote.password"+
+			" -Dcom.sun.management.jmxremote.access.file=/etc/jmx-credentials/jmxremote.access",
+		env["JVM_EXTRA_OPTS"])
+	assert.Contains(t, cassContainer.Ports, corev1.ContainerPort{Name: "jmx", ContainerPort: 7199})
+	assert.Contains(t, cassContainer.VolumeMounts, corev1.VolumeMount{
+		Name:      jmxCredentialsVolume,
+		MountPath: jmxCredentialsMountPath,
+	})
+
+	if assert.Equal(t, 2, len(got.Spec.InitContainers)) {
+		jmxInit := got.Spec.InitContainers[1]
+		assert.Equal(t, "jmx-credentials-init", jmxInit.Name)
+		assert.Equal(t, cassContainer.Image, jmxInit.Image)
+		for _, envVar := range jmxInit.Env {
+			if assert.NotNil(t, envVar.ValueFrom, "%s should come from a secret", envVar.Name) {
+				assert.Equal(t, "reaper-jmx", envVar.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+
+	var volume *corev1.Volume
+	for i := range got.Spec.Volumes {
+		if got.Spec.Volumes[i].Name == jmxCredentialsVolume {
+			volume = &got.Spec.Volumes[i]
+		}
+	}
+	assert.NotNil(t, volume, "should have a volume for the JMX credentials")
+
+	// A sidecar reaches JMX from within the pod
+	dc.Spec.Reaper.DeploymentMode = api.ReaperDeploymentModeSidecar
+	got, err = buildPodTemplateSpec(dc, "testzone", "testrack")
+	assert.NoError(t, err, "should not have gotten error when building podTemplateSpec")
+	for _, envVar := range got.Spec.Containers[0].Env {
+		assert.NotEqual(t, "LOCAL_JMX", envVar.Name)
+	}
+	assert.NotContains(t, got.Spec.Containers[0].Ports, corev1.ContainerPort{Name: "jmx", ContainerPort: 7199})
+}
+
+func TestCassandraDatacenter_buildPodTemplateSpec_imageRegistry(t *testing.T) {
+	dc := &api.CassandraDatacenter{
+		Spec: api.CassandraDatacenterSpec{
+			ClusterName:   "bob",
+			ServerType:    "cassandra",
+			ServerVersion: "3.11.6",
+			ImageRegistry: "registry.example.com",
+			ImagePullSecrets: []corev1.LocalObjectReference{
+				{Name: "registry-credentials"},
+				{Name: "other-credentials"},
+			},
+			Reaper: &api.ReaperConfig{
+				Enabled: true,
+			},
+			PodTemplateSpec: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					ImagePullSecrets: []corev1.LocalObjectReference{
+						{Name: "other-credentials"},
+						{Name: "template-credentials"},
+					},
+				},
+			},
+		},
+	}
+
+	got, err := buildPodTemplateSpec(dc, "testzone", "testrack")
+	assert.NoError(t, err, "should not have gotten error when building podTemplateSpec")
+
+	images := map[string]string{}
+	for _, container := range append(got.Spec.InitContainers, got.Spec.Containers...) {
+		images[container.Name] = container.Image
+	}
+	assert.Equal(t, map[string]string{
+		"server-config-init":   "registry.example.com/datastax/cass-config-builder:1.0.1",
+		"cassandra":            "registry.example.com/datastax/cassandra-mgmtapi-3_11_6:v0.1.5",
+		"server-system-logger": "registry.example.com/busybox",
+		ReaperContainerName:    "registry.example.com/thelastpickle/cassandra-reaper:2.0.5",
+	}, images)
+
+	assert.Equal(t, []corev1.LocalObjectReference{
+		{Name: "other-credentials"},
+		{Name: "template-credentials"},
+		{Name: "registry-credentials"},
+	}, got.Spec.ImagePullSecrets)
+}
+
+func Test_newStatefulSetForCassandraDatacenter_additionalVolumes(t *testing.T) {
+	dc := &api.CassandraDatacenter{
+		Spec: api.CassandraDatacenterSpec{
+			ClusterName: "bob",
+			StorageConfig: api.StorageConfig{
+				CassandraDataVolumeClaimSpec: &corev1.PersistentVolumeClaimSpec{},
+				CommitLogVolumeClaimSpec:     &corev1.PersistentVolumeClaimSpec{},
+			},
+			ServerType:    "cassandra",
+			ServerVersion: "3.11.6",
+		},
+	}
+
+	got, err := newStatefulSetForCassandraDatacenter("testrack", dc, 1)
+	assert.NoError(t, err, "newStatefulSetForCassandraDatacenter should not have errored")
+
+	templates := got.Spec.VolumeClaimTemplates
+	if assert.Equal(t, 2, len(templates)) {
+		assert.Equal(t, pvcName, templates[0].Name)
+		assert.Equal(t, "server-commitlog", templates[1].Name)
+	}
+
+	assert.Equal(t, "cassandra", got.Spec.Template.Spec.Containers[0].Name)
+	assert.Contains(t, got.Spec.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      "server-commitlog",
+		MountPath: api.CommitLogMountPath,
+	})
+}
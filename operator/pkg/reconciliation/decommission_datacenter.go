@@ -0,0 +1,242 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/datastax/cass-operator/operator/internal/result"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/events"
+	"github.com/datastax/cass-operator/operator/pkg/httphelper"
+	"github.com/datastax/cass-operator/operator/pkg/utils"
+)
+
+// removeNodeRetryInterval is how long a node that is still in the ring is
+// given to be removed before it is asked again
+const removeNodeRetryInterval = 5 * time.Minute
+
+// CheckDatacenterDecommission removes the nodes of a datacenter that is being
+// deleted from the cluster, when its deletion policy asks for it. The
+// datacenter is first removed from the system keyspaces, and nothing else
+// happens while other keyspaces are still replicated to it. Its nodes are
+// then decommissioned one at a time, or removed through another node when
+// they are down.
+func (rc *ReconciliationContext) CheckDatacenterDecommission() result.ReconcileResult {
+	dc := rc.Datacenter
+	logger := rc.ReqLogger
+
+	if dc.Spec.DeletionPolicy != api.DeletionPolicyDecommission {
+		return result.Continue()
+	}
+
+	logger.Info("reconcile_datacenter::CheckDatacenterDecommission")
+
+	podList, err := rc.listPods(dc.GetClusterLabels())
+	if err != nil {
+		logger.Error(err, "error listing all pods in the cluster")
+		return result.Error(err)
+	}
+	rc.clusterPods = PodPtrsFromPodList(podList)
+	rc.dcPods = FilterPodListByLabels(rc.clusterPods, dc.GetDatacenterLabels())
+
+	ringPod := rc.findRingPod()
+	if ringPod == nil {
+		if len(rc.clusterPods) == 0 {
+			logger.Info("No node is left to reach the ring through, there is nothing to leave")
+			return result.Continue()
+		}
+		logger.Info("Waiting for a node to decommission through")
+		return result.RequeueSoon(10)
+	}
+
+	metadata, err := rc.NodeMgmtClient.CallMetadataEndpointsEndpoint(ringPod)
+	if err != nil {
+		logger.Error(err, "error getting the ring", "pod", ringPod.Name)
+		return result.RequeueSoon(5)
+	}
+
+	// The other datacenters can run in other namespaces or Kubernetes
+	// clusters, so only the ring tells whether there are any
+	lastDatacenter := true
+	for _, endpoint := range metadata.Entity {
+		if endpoint.Datacenter != "" && endpoint.Datacenter != dc.Name {
+			lastDatacenter = false
+			break
+		}
+	}
+	if lastDatacenter {
+		logger.Info("Datacenter is the last one of the cluster, there is nothing to leave")
+		return result.Continue()
+	}
+
+	if recResult := rc.checkKeyspacesLeftDatacenter(ringPod); recResult.Completed() {
+		return recResult
+	}
+
+	return rc.checkNodesLeftDatacenter(ringPod, metadata)
+}
+
+// findRingPod returns a started and ready pod to reach the ring through,
+// preferring the pods of other datacenters, then the pod of the datacenter
+// that is decommissioned last
+func (rc *ReconciliationContext) findRingPod() *corev1.Pod {
+	dcPods := []*corev1.Pod{}
+	for _, pod := range rc.clusterPods {
+		if !isServerStarted(pod) || !isServerReady(pod) {
+			continue
+		}
+		if pod.Labels[api.DatacenterLabel] != rc.Datacenter.Name {
+			return pod
+		}
+		dcPods = append(dcPods, pod)
+	}
+
+	sort.Slice(dcPods, func(i, j int) bool {
+		return dcPods[i].Name > dcPods[j].Name
+	})
+	if len(dcPods) > 0 {
+		return dcPods[0]
+	}
+	return nil
+}
+
+// checkKeyspacesLeftDatacenter removes the datacenter from the replication
+// of the system keyspaces, and waits while any other keyspace is still
+// replicated to it
+func (rc *ReconciliationContext) checkKeyspacesLeftDatacenter(ringPod *corev1.Pod) result.ReconcileResult {
+	dc := rc.Datacenter
+	logger := rc.ReqLogger
+
+	keyspaces, err := rc.NodeMgmtClient.CallListKeyspacesEndpoint(ringPod, "")
+	if err != nil {
+		logger.Error(err, "error listing keyspaces")
+		return result.Error(err)
+	}
+
+	replicated := []string{}
+	for _, keyspace := range keyspaces {
+		options, err := rc.NodeMgmtClient.CallGetKeyspaceReplicationEndpoint(ringPod, keyspace)
+		if err != nil {
+			logger.Error(err, "error getting keyspace replication", "keyspace", keyspace)
+			return result.Error(err)
+		}
+
+		replication, err := httphelper.ParseNetworkTopologyReplication(options)
+		if err != nil || replication[dc.Name] == 0 {
+			continue
+		}
+
+		if utils.IndexOfString(systemKeyspaces, keyspace) < 0 {
+			replicated = append(replicated, keyspace)
+			continue
+		}
+
+		delete(replication, dc.Name)
+		if err := rc.NodeMgmtClient.CallAlterKeyspaceEndpoint(ringPod, keyspace, httphelper.NewReplicationSettings(replication)); err != nil {
+			logger.Error(err, "error altering keyspace", "keyspace", keyspace)
+			return result.Error(err)
+		}
+	}
+
+	if len(replicated) > 0 {
+		sort.Strings(replicated)
+		rc.Recorder.Eventf(dc, corev1.EventTypeWarning, events.DecommissionBlocked,
+			"Waiting for keyspaces %s to no longer be replicated to datacenter %s",
+			strings.Join(replicated, ", "), dc.Name)
+		return result.RequeueSoon(30)
+	}
+
+	return result.Continue()
+}
+
+// checkNodesLeftDatacenter removes the nodes in the status of the datacenter
+// from the ring one at a time, and drops them from the status once they have
+// left
+func (rc *ReconciliationContext) checkNodesLeftDatacenter(ringPod *corev1.Pod, metadata httphelper.CassMetadataEndpoints) result.ReconcileResult {
+	dc := rc.Datacenter
+	logger := rc.ReqLogger
+
+	podNames := []string{}
+	for podName := range dc.Status.NodeStatuses {
+		podNames = append(podNames, podName)
+	}
+	sort.Strings(podNames)
+
+	for _, podName := range podNames {
+		nodeStatus := dc.Status.NodeStatuses[podName]
+		hostID := nodeStatus.HostID
+		endpoint := findEndpointByHostId(metadata.Entity, hostID)
+
+		if hostID == "" || endpoint == nil || isDoneDecommissioning(endpoint.Status) ||
+			strings.HasPrefix(endpoint.Status, "removed") {
+			dcPatch := client.MergeFrom(dc.DeepCopy())
+			delete(dc.Status.NodeStatuses, podName)
+			if err := rc.Client.Status().Patch(rc.Ctx, dc, dcPatch); err != nil {
+				logger.Error(err, "error patching datacenter status after decommissioning node")
+				return result.Error(err)
+			}
+			rc.Recorder.Eventf(dc, corev1.EventTypeNormal, events.DecommissionedNode,
+				"Decommissioned node %s", podName)
+			continue
+		}
+
+		if !strings.HasPrefix(endpoint.Status, "NORMAL") {
+			logger.Info("Waiting for node to leave the ring",
+				"pod", podName,
+				"status", endpoint.Status)
+			return result.RequeueSoon(5)
+		}
+
+		pod := findPodByName(rc.dcPods, podName)
+		if pod != nil && endpoint.IsAlive == "true" {
+			return rc.decommissionNode(pod)
+		}
+
+		// Like decommissioning, this is tracked through the ring status, and
+		// only asked again when the node has not started leaving in a while
+		requested := nodeStatus.RemoveNodeRequested
+		if requested != nil && time.Since(requested.Time) < removeNodeRetryInterval {
+			logger.Info("Waiting for node to be removed",
+				"pod", podName,
+				"requested", requested.Time)
+			return result.RequeueSoon(10)
+		}
+
+		dcPatch := client.MergeFrom(dc.DeepCopy())
+		now := metav1.Now()
+		nodeStatus.RemoveNodeRequested = &now
+		dc.Status.NodeStatuses[podName] = nodeStatus
+		if err := rc.Client.Status().Patch(rc.Ctx, dc, dcPatch); err != nil {
+			logger.Error(err, "error patching datacenter status before removing node")
+			return result.Error(err)
+		}
+
+		rc.Recorder.Eventf(dc, corev1.EventTypeNormal, events.RemovingNode,
+			"Removing node %s with host ID %s", podName, hostID)
+		if err := rc.NodeMgmtClient.CallRemoveNodeEndpoint(ringPod, hostID); err != nil {
+			logger.Info("Remove node request did not complete",
+				"pod", podName,
+				"err", err.Error())
+		}
+		return result.RequeueSoon(10)
+	}
+
+	return result.Continue()
+}
+
+func findEndpointByHostId(endpointsData []httphelper.EndpointState, hostID string) *httphelper.EndpointState {
+	for idx := range endpointsData {
+		if endpointsData[idx].HostID == hostID {
+			return &endpointsData[idx]
+		}
+	}
+	return nil
+}
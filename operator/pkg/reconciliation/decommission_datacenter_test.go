@@ -0,0 +1,264 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/httphelper"
+	"github.com/datastax/cass-operator/operator/pkg/mocks"
+)
+
+func newDecommissionTestPod(dc *api.CassandraDatacenter, dcName string, idx int, ip string) *corev1.Pod {
+	labels := dc.GetClusterLabels()
+	labels[api.DatacenterLabel] = dcName
+	labels[api.CassNodeState] = stateStarted
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s-default-sts-%d", dc.Spec.ClusterName, dcName, idx),
+			Namespace: dc.Namespace,
+			Labels:    labels,
+		},
+		Status: corev1.PodStatus{
+			PodIP: ip,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "cassandra",
+				Ready: true,
+			}},
+		},
+	}
+}
+
+// otherDatacenterEndpoint is the node of the other datacenter in the ring
+var otherDatacenterEndpoint = httphelper.EndpointState{
+	Datacenter: "dc2", HostID: "host-dc2", IsAlive: "true", RpcAddress: "10.0.1.1", Status: "NORMAL",
+}
+
+// setupDatacenterDecommissionTest gives the datacenter two nodes and another
+// datacenter one node, whose management API reports the given keyspaces and
+// ring, and returns the fake management API
+func setupDatacenterDecommissionTest(rc *ReconciliationContext, replication map[string]map[string]string, endpoints []httphelper.EndpointState) *mocks.ManagementApi {
+	dc := rc.Datacenter
+	dc.Spec.DeletionPolicy = api.DeletionPolicyDecommission
+	dc.Status.NodeStatuses = api.CassandraStatusMap{}
+
+	trackObjects := []runtime.Object{dc}
+	for idx := 0; idx < 2; idx++ {
+		pod := newDecommissionTestPod(dc, dc.Name, idx, fmt.Sprintf("10.0.0.%d", idx+1))
+		dc.Status.NodeStatuses[pod.Name] = api.CassandraNodeStatus{HostID: fmt.Sprintf("host-%d", idx)}
+		trackObjects = append(trackObjects, pod)
+	}
+	trackObjects = append(trackObjects, newDecommissionTestPod(dc, "dc2", 0, "10.0.1.1"))
+	rc.Client = fake.NewFakeClient(trackObjects...)
+
+	keyspaces := []string{}
+	for keyspace := range replication {
+		keyspaces = append(keyspaces, keyspace)
+	}
+	keyspacesBody, _ := json.Marshal(keyspaces)
+	endpointsBody, _ := json.Marshal(httphelper.CassMetadataEndpoints{Entity: endpoints})
+
+	mgmtApi := mocks.NewManagementApi(map[string]string{
+		"/api/v0/ops/keyspace":       string(keyspacesBody),
+		"/api/v0/metadata/endpoints": string(endpointsBody),
+	})
+	mgmtApi.Respond = func(req *http.Request) string {
+		if req.Method == http.MethodGet && req.URL.Path == "/api/v0/ops/keyspace/replication" {
+			replicationBody, _ := json.Marshal(replication[req.URL.Query().Get("keyspaceName")])
+			return string(replicationBody)
+		}
+		return ""
+	}
+	rc.NodeMgmtClient = httphelper.NodeMgmtClient{Client: mgmtApi.HttpClient(), Log: rc.ReqLogger, Protocol: "http"}
+
+	return mgmtApi
+}
+
+func newNetworkTopologyReplication(dc *api.CassandraDatacenter) map[string]string {
+	return map[string]string{
+		"class": "org.apache.cassandra.locator.NetworkTopologyStrategy",
+		dc.Name: "3",
+		"dc2":   "3",
+	}
+}
+
+func TestCheckDatacenterDecommission_DeletePolicy(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	mgmtApi := setupDatacenterDecommissionTest(rc, nil, nil)
+	rc.Datacenter.Spec.DeletionPolicy = api.DeletionPolicyDelete
+
+	recResult := rc.CheckDatacenterDecommission()
+	assert.False(t, recResult.Completed())
+	assert.Empty(t, mgmtApi.Posts)
+}
+
+func TestCheckDatacenterDecommission_KeyspaceStillReplicated(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	mgmtApi := setupDatacenterDecommissionTest(rc, map[string]map[string]string{
+		"system_auth": newNetworkTopologyReplication(rc.Datacenter),
+		"app_data":    newNetworkTopologyReplication(rc.Datacenter),
+	}, []httphelper.EndpointState{otherDatacenterEndpoint})
+
+	recResult := rc.CheckDatacenterDecommission()
+	assert.True(t, recResult.Completed())
+
+	if assert.Equal(t, 1, len(mgmtApi.Posts), "Should only alter system_auth") {
+		assert.Equal(t, "/api/v0/ops/keyspace/alter", mgmtApi.Posts[0].URL.Path)
+		assert.Equal(t, "10.0.1.1", mgmtApi.Posts[0].URL.Hostname())
+		body, err := ioutil.ReadAll(mgmtApi.Posts[0].Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t,
+			`{"keyspace_name":"system_auth","replication_settings":[{"dc_name":"dc2","replication_factor":3}]}`,
+			string(body))
+	}
+	assert.Equal(t, 2, len(rc.Datacenter.Status.NodeStatuses))
+}
+
+func TestCheckDatacenterDecommission_DecommissionsNode(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	mgmtApi := setupDatacenterDecommissionTest(rc, map[string]map[string]string{}, []httphelper.EndpointState{
+		{HostID: "host-0", IsAlive: "true", RpcAddress: "10.0.0.1", Status: "NORMAL"},
+		{HostID: "host-1", IsAlive: "true", RpcAddress: "10.0.0.2", Status: "NORMAL"},
+		otherDatacenterEndpoint,
+	})
+
+	recResult := rc.CheckDatacenterDecommission()
+	assert.True(t, recResult.Completed())
+
+	if assert.Equal(t, 1, len(mgmtApi.Posts)) {
+		assert.Equal(t, "/api/v0/ops/node/decommission", mgmtApi.Posts[0].URL.Path)
+		assert.Equal(t, "10.0.0.1", mgmtApi.Posts[0].URL.Hostname())
+	}
+}
+
+func TestCheckDatacenterDecommission_RemovesDownNode(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	mgmtApi := setupDatacenterDecommissionTest(rc, map[string]map[string]string{}, []httphelper.EndpointState{
+		{HostID: "host-1", IsAlive: "false", RpcAddress: "10.0.0.2", Status: "NORMAL"},
+		otherDatacenterEndpoint,
+	})
+
+	recResult := rc.CheckDatacenterDecommission()
+	assert.True(t, recResult.Completed())
+
+	if assert.Equal(t, 1, len(mgmtApi.Posts)) {
+		assert.Equal(t, "/api/v0/ops/node/removenode", mgmtApi.Posts[0].URL.Path)
+		assert.Equal(t, "10.0.1.1", mgmtApi.Posts[0].URL.Hostname())
+		assert.Equal(t, "host-1", mgmtApi.Posts[0].URL.Query().Get("host_id"))
+	}
+	assert.Equal(t, 1, len(rc.Datacenter.Status.NodeStatuses), "Should drop the node that already left")
+}
+
+func TestCheckDatacenterDecommission_AllNodesLeft(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	mgmtApi := setupDatacenterDecommissionTest(rc, map[string]map[string]string{}, []httphelper.EndpointState{
+		{HostID: "host-0", IsAlive: "false", RpcAddress: "10.0.0.1", Status: "LEFT"},
+		otherDatacenterEndpoint,
+	})
+
+	recResult := rc.CheckDatacenterDecommission()
+	assert.False(t, recResult.Completed())
+	assert.Empty(t, mgmtApi.Posts)
+	assert.Empty(t, rc.Datacenter.Status.NodeStatuses)
+}
+
+func TestCheckDatacenterDecommission_LastDatacenter(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	// The ring tells that the other datacenter is gone, even though its pod
+	// is still there
+	mgmtApi := setupDatacenterDecommissionTest(rc, map[string]map[string]string{}, []httphelper.EndpointState{
+		{Datacenter: rc.Datacenter.Name, HostID: "host-0", IsAlive: "true", RpcAddress: "10.0.0.1", Status: "NORMAL"},
+		{Datacenter: rc.Datacenter.Name, HostID: "host-1", IsAlive: "true", RpcAddress: "10.0.0.2", Status: "NORMAL"},
+	})
+
+	recResult := rc.CheckDatacenterDecommission()
+	assert.False(t, recResult.Completed())
+	assert.Empty(t, mgmtApi.Posts)
+	assert.Equal(t, 2, len(rc.Datacenter.Status.NodeStatuses))
+}
+
+func TestCheckDatacenterDecommission_OtherDatacenterElsewhere(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	mgmtApi := setupDatacenterDecommissionTest(rc, map[string]map[string]string{}, []httphelper.EndpointState{
+		{Datacenter: rc.Datacenter.Name, HostID: "host-0", IsAlive: "true", RpcAddress: "10.0.0.1", Status: "NORMAL"},
+		{Datacenter: rc.Datacenter.Name, HostID: "host-1", IsAlive: "true", RpcAddress: "10.0.0.2", Status: "NORMAL"},
+		otherDatacenterEndpoint,
+	})
+	// The other datacenter runs in another namespace
+	assert.NoError(t, rc.Client.Delete(rc.Ctx, newDecommissionTestPod(rc.Datacenter, "dc2", 0, "10.0.1.1")))
+
+	recResult := rc.CheckDatacenterDecommission()
+	assert.True(t, recResult.Completed())
+
+	if assert.NotEmpty(t, mgmtApi.Requests) {
+		assert.Equal(t, "10.0.0.2", mgmtApi.Requests[0].URL.Hostname(),
+			"Should read the ring from the node that is decommissioned last")
+	}
+	if assert.Equal(t, 1, len(mgmtApi.Posts)) {
+		assert.Equal(t, "/api/v0/ops/node/decommission", mgmtApi.Posts[0].URL.Path)
+		assert.Equal(t, "10.0.0.1", mgmtApi.Posts[0].URL.Hostname())
+	}
+}
+
+func TestCheckDatacenterDecommission_RemoveNodeRequested(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	mgmtApi := setupDatacenterDecommissionTest(rc, map[string]map[string]string{}, []httphelper.EndpointState{
+		{HostID: "host-0", IsAlive: "false", RpcAddress: "10.0.0.1", Status: "removed,host-0"},
+		{HostID: "host-1", IsAlive: "false", RpcAddress: "10.0.0.2", Status: "NORMAL"},
+		otherDatacenterEndpoint,
+	})
+
+	recResult := rc.CheckDatacenterDecommission()
+	assert.True(t, recResult.Completed())
+	assert.Equal(t, 1, len(mgmtApi.Posts))
+	assert.Equal(t, 1, len(rc.Datacenter.Status.NodeStatuses), "Should drop the node that was removed")
+
+	podName := rc.Datacenter.Spec.ClusterName + "-" + rc.Datacenter.Name + "-default-sts-1"
+	nodeStatus := rc.Datacenter.Status.NodeStatuses[podName]
+	assert.NotNil(t, nodeStatus.RemoveNodeRequested, "Should track the removal in the status")
+
+	recResult = rc.CheckDatacenterDecommission()
+	assert.True(t, recResult.Completed())
+	assert.Equal(t, 1, len(mgmtApi.Posts), "Should wait for the node to be removed")
+
+	requested := metav1.NewTime(time.Now().Add(-removeNodeRetryInterval))
+	nodeStatus.RemoveNodeRequested = &requested
+	rc.Datacenter.Status.NodeStatuses[podName] = nodeStatus
+	assert.NoError(t, rc.Client.Status().Update(rc.Ctx, rc.Datacenter))
+
+	recResult = rc.CheckDatacenterDecommission()
+	assert.True(t, recResult.Completed())
+	if assert.Equal(t, 2, len(mgmtApi.Posts), "Should ask again once the node has not left in a while") {
+		assert.Equal(t, "/api/v0/ops/node/removenode", mgmtApi.Posts[1].URL.Path)
+		assert.Equal(t, "host-1", mgmtApi.Posts[1].URL.Query().Get("host_id"))
+	}
+}
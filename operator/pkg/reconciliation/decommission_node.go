@@ -0,0 +1,380 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/datastax/cass-operator/operator/internal/result"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/events"
+	"github.com/datastax/cass-operator/operator/pkg/httphelper"
+)
+
+// DecommissionNodes looks for a rack that has more replicas than desired and
+// decommissions the pod with the highest ordinal in that rack. Only one node
+// is decommissioned at a time, and only once the cluster is healthy.
+func (rc *ReconciliationContext) DecommissionNodes() result.ReconcileResult {
+	logger := rc.ReqLogger
+	logger.Info("reconcile_racks::DecommissionNodes")
+	dc := rc.Datacenter
+
+	if dc.Spec.Stopped {
+		return result.Continue()
+	}
+
+	for idx := range rc.desiredRackInformation {
+		rackInfo := rc.desiredRackInformation[idx]
+		statefulSet := rc.statefulSets[idx]
+
+		desiredNodeCount := int32(rackInfo.NodeCount)
+		maxReplicas := *statefulSet.Spec.Replicas
+
+		if maxReplicas <= desiredNodeCount {
+			continue
+		}
+
+		dcPatch := client.MergeFrom(dc.DeepCopy())
+		updated := rc.setCondition(
+			api.NewDatacenterCondition(
+				api.DatacenterScalingDown, corev1.ConditionTrue))
+
+		if updated {
+			err := rc.Client.Status().Patch(rc.Ctx, dc, dcPatch)
+			if err != nil {
+				logger.Error(err, "error patching datacenter status for scaling down rack started")
+				return result.Error(err)
+			}
+
+			rc.Recorder.Eventf(rc.Datacenter, corev1.EventTypeNormal, events.ScalingDownRack,
+				"Scaling down rack %s", rackInfo.RackName)
+		}
+
+		if err := setOperatorProgressStatus(rc, api.ProgressUpdating); err != nil {
+			return result.Error(err)
+		}
+
+		podName := getStatefulSetPodNameForIdx(statefulSet, maxReplicas-1)
+		pod := findPodByName(rc.dcPods, podName)
+		if pod == nil || !isMgmtApiRunning(pod) {
+			logger.Info(
+				"Waiting for the management API to be available before decommissioning",
+				"pod", podName,
+			)
+			return result.RequeueSoon(2)
+		}
+
+		logger.Info(
+			"Need to decommission a node to reduce the rack's node count",
+			"Rack", rackInfo.RackName,
+			"maxReplicas", maxReplicas,
+			"desiredSize", desiredNodeCount,
+			"pod", podName,
+		)
+
+		if err := rc.labelServerPodDecommissioning(pod); err != nil {
+			return result.Error(err)
+		}
+
+		return rc.decommissionNode(pod)
+	}
+
+	return result.Continue()
+}
+
+// CheckDecommissioningNodes waits for any node that is being decommissioned to
+// leave the ring. Once it has, the pod is removed from its StatefulSet and the
+// pod's PVCs are deleted.
+func (rc *ReconciliationContext) CheckDecommissioningNodes() result.ReconcileResult {
+	logger := rc.ReqLogger
+	logger.Info("reconcile_racks::CheckDecommissioningNodes")
+	dc := rc.Datacenter
+
+	if dc.GetConditionStatus(api.DatacenterScalingDown) != corev1.ConditionTrue {
+		return result.Continue()
+	}
+
+	for _, pod := range rc.dcPods {
+		if !isServerDecommissioning(pod) {
+			continue
+		}
+
+		status, err := rc.getNodeStatusInRing(pod)
+		if err != nil {
+			logger.Error(err, "error checking whether node has left the ring",
+				"pod", pod.Name)
+			return result.RequeueSoon(5)
+		}
+
+		if strings.HasPrefix(status, "NORMAL") {
+			// The node has not started leaving yet, either because its
+			// decommission job is still getting going, or because it was
+			// never started or failed
+			return rc.decommissionNode(pod)
+		}
+
+		if !isDoneDecommissioning(status) {
+			logger.Info("Waiting for node to finish decommissioning",
+				"pod", pod.Name,
+				"status", status)
+			return result.RequeueSoon(5)
+		}
+
+		statefulSet, err := rc.getStatefulSetForPod(pod)
+		if err != nil {
+			return result.Error(err)
+		}
+
+		replicas := *statefulSet.Spec.Replicas
+		if replicas > 0 && getStatefulSetPodNameForIdx(statefulSet, replicas-1) == pod.Name {
+			if err := rc.UpdateRackNodeCount(statefulSet, replicas-1); err != nil {
+				return result.Error(err)
+			}
+
+			if err := rc.deletePodPVCs(pod); err != nil {
+				return result.Error(err)
+			}
+
+			dcPatch := client.MergeFrom(dc.DeepCopy())
+			delete(dc.Status.NodeStatuses, pod.Name)
+			if rackStatus := dc.Status.GetRackStatus(pod.Labels[api.RackLabel]); rackStatus != nil && rackStatus.State == api.RackRemoving {
+				rackStatus.RemainingNodes = replicas - 1
+				rackStatus.DecommissioningPod = ""
+			}
+			if err := rc.Client.Status().Patch(rc.Ctx, dc, dcPatch); err != nil {
+				logger.Error(err, "error patching datacenter status after decommissioning node")
+				return result.Error(err)
+			}
+
+			rc.Recorder.Eventf(rc.Datacenter, corev1.EventTypeNormal, events.DecommissionedNode,
+				"Decommissioned node %s", pod.Name)
+		}
+
+		// Wait for the pod to be removed before doing anything else
+		return result.RequeueSoon(5)
+	}
+
+	return result.Continue()
+}
+
+// decommissionNode starts decommissioning the node of the pod, unless the
+// decommission started earlier is still running. The job is recorded in an
+// annotation of the pod, and a new one is only started once it has failed
+// or has been lost, e.g. because the management API restarted. Progress
+// itself is tracked through the ring status.
+func (rc *ReconciliationContext) decommissionNode(pod *corev1.Pod) result.ReconcileResult {
+	logger := rc.ReqLogger.WithValues("pod", pod.Name)
+
+	if jobID := pod.Annotations[api.DecommissionJobAnnotation]; jobID != "" {
+		details, err := rc.NodeMgmtClient.CallJobDetailsEndpoint(pod, jobID)
+		if err == nil && details.Status != httphelper.JobStatusError {
+			logger.Info("Waiting for decommission job", "jobId", jobID, "status", details.Status)
+			return result.RequeueSoon(5)
+		}
+
+		message := "job lost"
+		if err != nil {
+			logger.Error(err, "error getting decommission job", "jobId", jobID)
+		} else {
+			message = details.Error
+		}
+		rc.Recorder.Eventf(rc.Datacenter, corev1.EventTypeWarning, events.FailedDecommission,
+			"Failed to decommission node %s: %s", pod.Name, message)
+
+		// Started again on a later pass, after a while
+		if err := rc.setPodDecommissionJob(pod, ""); err != nil {
+			return result.Error(err)
+		}
+		return result.RequeueSoon(30)
+	}
+
+	jobID, err := rc.NodeMgmtClient.CallDecommissionNodeEndpoint(pod)
+	if err != nil {
+		logger.Error(err, "error starting decommission of node")
+		return result.RequeueSoon(30)
+	}
+
+	if err := rc.setPodDecommissionJob(pod, jobID); err != nil {
+		return result.Error(err)
+	}
+	rc.Recorder.Eventf(rc.Datacenter, corev1.EventTypeNormal, events.DecommissioningNode,
+		"Decommissioning node %s", pod.Name)
+
+	return result.RequeueSoon(5)
+}
+
+func (rc *ReconciliationContext) setPodDecommissionJob(pod *corev1.Pod, jobID string) error {
+	patch := client.MergeFrom(pod.DeepCopy())
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	if jobID == "" {
+		delete(pod.Annotations, api.DecommissionJobAnnotation)
+	} else {
+		pod.Annotations[api.DecommissionJobAnnotation] = jobID
+	}
+	err := rc.Client.Patch(rc.Ctx, pod, patch)
+	if err != nil {
+		rc.ReqLogger.Error(err, "error annotating pod with its decommission job", "pod", pod.Name)
+	}
+	return err
+}
+
+// getNodeStatusInRing asks a node other than the given pod for its view of the
+// ring and returns the gossip status of the given pod. An empty status means
+// the node is no longer part of the ring.
+func (rc *ReconciliationContext) getNodeStatusInRing(pod *corev1.Pod) (string, error) {
+	if pod.Status.PodIP == "" {
+		return "", fmt.Errorf("pod %s has no IP", pod.Name)
+	}
+
+	var lastErr error
+	for _, otherPod := range rc.clusterPods {
+		if otherPod.Name == pod.Name || !isServerReady(otherPod) || !isServerStarted(otherPod) {
+			continue
+		}
+
+		metadata, err := rc.NodeMgmtClient.CallMetadataEndpointsEndpoint(otherPod)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		return getNodeStatusFromEndpointsData(metadata.Entity, pod.Status.PodIP), nil
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no other started pods available to check the ring")
+	}
+	return "", lastErr
+}
+
+func getNodeStatusFromEndpointsData(endpointsData []httphelper.EndpointState, ip string) string {
+	for _, data := range endpointsData {
+		if data.GetRpcAddress() == ip {
+			return data.Status
+		}
+	}
+	return ""
+}
+
+// isDoneDecommissioning returns true when a node has either left the ring
+// or is no longer known to it
+func isDoneDecommissioning(status string) bool {
+	return status == "" || strings.HasPrefix(status, "LEFT")
+}
+
+func isServerDecommissioning(pod *corev1.Pod) bool {
+	return pod.Labels[api.CassNodeState] == stateDecommissioning
+}
+
+// isPodLeaving tells whether the pod is leaving the datacenter, because its
+// node is being decommissioned, because its rack is being removed, or because
+// its rack has more replicas than desired and DecommissionNodes is about to
+// decommission it
+func (rc *ReconciliationContext) isPodLeaving(pod *corev1.Pod) bool {
+	if isServerDecommissioning(pod) {
+		return true
+	}
+
+	rackName := pod.Labels[api.RackLabel]
+	if rackStatus := rc.Datacenter.Status.GetRackStatus(rackName); rackStatus != nil && rackStatus.State == api.RackRemoving {
+		return true
+	}
+
+	inSpec := false
+	for idx, rackInfo := range rc.desiredRackInformation {
+		if rackInfo.RackName != rackName {
+			continue
+		}
+		inSpec = true
+
+		statefulSet := rc.statefulSets[idx]
+		for ordinal := int32(rackInfo.NodeCount); ordinal < *statefulSet.Spec.Replicas; ordinal++ {
+			if pod.Name == getStatefulSetPodNameForIdx(statefulSet, ordinal) {
+				return true
+			}
+		}
+	}
+
+	// A rack that is no longer in the spec is removed by CheckRackRemoval
+	return rackName != "" && !inSpec
+}
+
+func (rc *ReconciliationContext) labelServerPodDecommissioning(pod *corev1.Pod) error {
+	patch := client.MergeFrom(pod.DeepCopy())
+	pod.Labels[api.CassNodeState] = stateDecommissioning
+	err := rc.Client.Patch(rc.Ctx, pod, patch)
+	return err
+}
+
+func (rc *ReconciliationContext) getStatefulSetForPod(pod *corev1.Pod) (*appsv1.StatefulSet, error) {
+	rackName := pod.Labels[api.RackLabel]
+	for idx, rackInfo := range rc.desiredRackInformation {
+		if rackName == rackInfo.RackName {
+			return rc.statefulSets[idx], nil
+		}
+	}
+
+	// the pod belongs to a rack that is being removed
+	statefulSet := &appsv1.StatefulSet{}
+	err := rc.Client.Get(rc.Ctx, newNamespacedNameForStatefulSet(rc.Datacenter, rackName), statefulSet)
+	return statefulSet, err
+}
+
+// deletePodPVCs deletes every PVC mounted by the pod. The PVCs will not
+// actually go away until the pod itself has been removed.
+func (rc *ReconciliationContext) deletePodPVCs(pod *corev1.Pod) error {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := rc.Client.Get(
+			rc.Ctx,
+			types.NamespacedName{
+				Name:      volume.PersistentVolumeClaim.ClaimName,
+				Namespace: pod.Namespace,
+			},
+			pvc)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+
+		rc.ReqLogger.Info(
+			"Deleting PVC of decommissioned node",
+			"pvcNamespace", pvc.Namespace,
+			"pvcName", pvc.Name)
+
+		if err := rc.Client.Delete(rc.Ctx, pvc); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func getStatefulSetPodNameForIdx(statefulSet *appsv1.StatefulSet, idx int32) string {
+	return fmt.Sprintf("%s-%v", statefulSet.Name, idx)
+}
+
+func findPodByName(pods []*corev1.Pod, name string) *corev1.Pod {
+	for _, pod := range pods {
+		if pod.Name == name {
+			return pod
+		}
+	}
+	return nil
+}
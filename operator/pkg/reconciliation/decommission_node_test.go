@@ -0,0 +1,294 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/httphelper"
+	"github.com/datastax/cass-operator/operator/pkg/mocks"
+)
+
+func Test_isDoneDecommissioning(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		want   bool
+	}{
+		{
+			name:   "node is no longer in the ring",
+			status: "",
+			want:   true,
+		},
+		{
+			name:   "node has left the ring",
+			status: "LEFT,-1589726493696519215,1594327127519",
+			want:   true,
+		},
+		{
+			name:   "node is still leaving the ring",
+			status: "LEAVING,-1589726493696519215",
+			want:   false,
+		},
+		{
+			name:   "node has not started decommissioning",
+			status: "NORMAL,-1589726493696519215",
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDoneDecommissioning(tt.status); got != tt.want {
+				t.Errorf("isDoneDecommissioning() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func mockRunningPodsForRack(sts *appsv1.StatefulSet, dc *api.CassandraDatacenter, rackName string) []*corev1.Pod {
+	pods := mockReadyPodsForStatefulSet(sts, dc.Spec.ClusterName, dc.Name)
+	for idx, pod := range pods {
+		pod.Labels[api.RackLabel] = rackName
+		pod.Status.PodIP = fmt.Sprintf("10.0.0.%d", idx+1)
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  "cassandra",
+			Ready: true,
+			State: corev1.ContainerState{
+				Running: &corev1.ContainerStateRunning{
+					StartedAt: metav1.Date(2019, time.July, 4, 12, 12, 12, 0, time.UTC),
+				},
+			},
+		}}
+		pod.Spec.Volumes = []corev1.Volume{{
+			Name: pvcName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: fmt.Sprintf("%s-%s", pvcName, pod.Name),
+				},
+			},
+		}}
+	}
+	return pods
+}
+
+// mockMetadataEndpointsResponse gives the pods a management API reporting
+// the given ring, and returns that management API
+func mockMetadataEndpointsResponse(rc *ReconciliationContext, body string) *mocks.ManagementApi {
+	mgmtApi := mocks.NewManagementApi(map[string]string{
+		"/api/v0/metadata/endpoints":    body,
+		"/api/v0/ops/node/decommission": "decommission-1",
+		"/api/v0/ops/executor/job":      `{"id":"decommission-1","type":"decommission","status":"RUNNING"}`,
+	})
+	rc.NodeMgmtClient = httphelper.NodeMgmtClient{Client: mgmtApi.HttpClient(), Log: rc.ReqLogger, Protocol: "http"}
+	return mgmtApi
+}
+
+func setupDecommissionTest(t *testing.T, rc *ReconciliationContext) (*appsv1.StatefulSet, []*corev1.Pod) {
+	statefulSet, err := newStatefulSetForCassandraDatacenter(
+		"default",
+		rc.Datacenter,
+		2)
+	assert.NoErrorf(t, err, "error occurred creating statefulset")
+
+	pods := mockRunningPodsForRack(statefulSet, rc.Datacenter, "default")
+
+	trackObjects := []runtime.Object{
+		statefulSet,
+		rc.Datacenter,
+	}
+	for _, pod := range pods {
+		trackObjects = append(trackObjects, pod)
+		trackObjects = append(trackObjects, &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName,
+				Namespace: pod.Namespace,
+			},
+		})
+	}
+
+	rc.Client = fake.NewFakeClient(trackObjects...)
+	rc.Datacenter.Spec.Size = 1
+	rc.desiredRackInformation = []*RackInformation{{
+		RackName:  "default",
+		NodeCount: 1,
+		SeedCount: 1,
+	}}
+	rc.statefulSets = []*appsv1.StatefulSet{statefulSet}
+	rc.clusterPods = pods
+	rc.dcPods = pods
+
+	return statefulSet, pods
+}
+
+func TestDecommissionNodes(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	_, pods := setupDecommissionTest(t, rc)
+	mgmtApi := mockMetadataEndpointsResponse(rc, `{"entity": []}`)
+
+	recResult := rc.DecommissionNodes()
+	assert.True(t, recResult.Completed(), "Should requeue while decommissioning")
+
+	assert.Equal(t, corev1.ConditionTrue, rc.Datacenter.GetConditionStatus(api.DatacenterScalingDown))
+
+	if assert.Equal(t, 1, len(mgmtApi.Posts)) {
+		assert.Equal(t, "/api/v0/ops/node/decommission", mgmtApi.Posts[0].URL.Path)
+		assert.Equal(t, "async=true&force=true", mgmtApi.Posts[0].URL.RawQuery)
+	}
+
+	pod := &corev1.Pod{}
+	err := rc.Client.Get(rc.Ctx, types.NamespacedName{Name: pods[1].Name, Namespace: pods[1].Namespace}, pod)
+	assert.NoError(t, err)
+	assert.Equal(t, stateDecommissioning, pod.Labels[api.CassNodeState], "Highest ordinal pod should be decommissioning")
+	assert.Equal(t, "decommission-1", pod.Annotations[api.DecommissionJobAnnotation])
+
+	err = rc.Client.Get(rc.Ctx, types.NamespacedName{Name: pods[0].Name, Namespace: pods[0].Namespace}, pod)
+	assert.NoError(t, err)
+	assert.Equal(t, stateStarted, pod.Labels[api.CassNodeState], "Other pods should be left alone")
+}
+
+func TestDecommissionNodes_NothingToDo(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	setupDecommissionTest(t, rc)
+	rc.desiredRackInformation[0].NodeCount = 2
+
+	recResult := rc.DecommissionNodes()
+	assert.False(t, recResult.Completed(), "Should continue when no rack has too many replicas")
+	assert.Equal(t, corev1.ConditionFalse, rc.Datacenter.GetConditionStatus(api.DatacenterScalingDown))
+}
+
+func Test_countReadyAndStarted_LeavingPods(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	_, pods := setupDecommissionTest(t, rc)
+
+	ready, started := rc.countReadyAndStarted()
+	assert.Equal(t, 1, ready, "Should leave out the pod about to be decommissioned")
+	assert.Equal(t, 1, started, "Should leave out the pod about to be decommissioned")
+
+	rc.desiredRackInformation[0].NodeCount = 2
+	ready, started = rc.countReadyAndStarted()
+	assert.Equal(t, 2, ready, "Should count the pods the rack is meant to have")
+	assert.Equal(t, 2, started, "Should count the pods the rack is meant to have")
+
+	pods[1].Labels[api.CassNodeState] = stateDecommissioning
+	ready, started = rc.countReadyAndStarted()
+	assert.Equal(t, 1, ready, "Should leave out the pod being decommissioned")
+	assert.Equal(t, 1, started, "Should leave out the pod being decommissioned")
+}
+
+func TestCheckDecommissioningNodes_StillLeaving(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	statefulSet, pods := setupDecommissionTest(t, rc)
+	pods[1].Labels[api.CassNodeState] = stateDecommissioning
+	rc.Datacenter.SetCondition(*api.NewDatacenterCondition(api.DatacenterScalingDown, corev1.ConditionTrue))
+
+	mockMetadataEndpointsResponse(rc, `{"entity": [
+		{"RPC_ADDRESS": "10.0.0.1", "STATUS": "NORMAL,2756844028858338669"},
+		{"RPC_ADDRESS": "10.0.0.2", "STATUS": "LEAVING,-1589726493696519215"}
+	]}`)
+
+	recResult := rc.CheckDecommissioningNodes()
+	assert.True(t, recResult.Completed(), "Should requeue while decommissioning")
+	assert.Equal(t, int32(2), *statefulSet.Spec.Replicas, "Replicas should not change until the node has left")
+}
+
+func TestCheckDecommissioningNodes_JobRunning(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	_, pods := setupDecommissionTest(t, rc)
+	pods[1].Labels[api.CassNodeState] = stateDecommissioning
+	pods[1].Annotations = map[string]string{api.DecommissionJobAnnotation: "decommission-1"}
+	assert.NoError(t, rc.Client.Update(rc.Ctx, pods[1]))
+	rc.Datacenter.SetCondition(*api.NewDatacenterCondition(api.DatacenterScalingDown, corev1.ConditionTrue))
+
+	mgmtApi := mockMetadataEndpointsResponse(rc, `{"entity": [
+		{"RPC_ADDRESS": "10.0.0.1", "STATUS": "NORMAL,2756844028858338669"},
+		{"RPC_ADDRESS": "10.0.0.2", "STATUS": "NORMAL,-1589726493696519215"}
+	]}`)
+
+	recResult := rc.CheckDecommissioningNodes()
+	assert.True(t, recResult.Completed(), "Should requeue while decommissioning")
+	assert.Empty(t, mgmtApi.Posts, "Should not decommission again while the job is running")
+}
+
+func TestCheckDecommissioningNodes_JobFailed(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	_, pods := setupDecommissionTest(t, rc)
+	pods[1].Labels[api.CassNodeState] = stateDecommissioning
+	pods[1].Annotations = map[string]string{api.DecommissionJobAnnotation: "decommission-1"}
+	assert.NoError(t, rc.Client.Update(rc.Ctx, pods[1]))
+	rc.Datacenter.SetCondition(*api.NewDatacenterCondition(api.DatacenterScalingDown, corev1.ConditionTrue))
+
+	mgmtApi := mockMetadataEndpointsResponse(rc, `{"entity": [
+		{"RPC_ADDRESS": "10.0.0.1", "STATUS": "NORMAL,2756844028858338669"},
+		{"RPC_ADDRESS": "10.0.0.2", "STATUS": "NORMAL,-1589726493696519215"}
+	]}`)
+	mgmtApi.Responses["/api/v0/ops/executor/job"] = `{"id":"decommission-1","type":"decommission","status":"ERROR","error":"streaming failed"}`
+
+	recResult := rc.CheckDecommissioningNodes()
+	res, err := recResult.Output()
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, res.RequeueAfter, "Should wait before decommissioning again")
+	assert.Empty(t, mgmtApi.Posts)
+	assert.Equal(t, "", pods[1].Annotations[api.DecommissionJobAnnotation])
+
+	// Started again on the next pass
+	rc.CheckDecommissioningNodes()
+	if assert.Equal(t, 1, len(mgmtApi.Posts)) {
+		assert.Equal(t, "/api/v0/ops/node/decommission", mgmtApi.Posts[0].URL.Path)
+	}
+	assert.Equal(t, "decommission-1", pods[1].Annotations[api.DecommissionJobAnnotation])
+}
+
+func TestCheckDecommissioningNodes_NodeLeft(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	statefulSet, pods := setupDecommissionTest(t, rc)
+	pods[1].Labels[api.CassNodeState] = stateDecommissioning
+	rc.Datacenter.SetCondition(*api.NewDatacenterCondition(api.DatacenterScalingDown, corev1.ConditionTrue))
+
+	mockMetadataEndpointsResponse(rc, `{"entity": [
+		{"RPC_ADDRESS": "10.0.0.1", "STATUS": "NORMAL,2756844028858338669"}
+	]}`)
+
+	recResult := rc.CheckDecommissioningNodes()
+	assert.True(t, recResult.Completed(), "Should requeue until the pod is gone")
+	assert.Equal(t, int32(1), *statefulSet.Spec.Replicas, "Replicas should be reduced once the node has left")
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	err := rc.Client.Get(rc.Ctx, types.NamespacedName{
+		Name:      pods[1].Spec.Volumes[0].PersistentVolumeClaim.ClaimName,
+		Namespace: pods[1].Namespace,
+	}, pvc)
+	assert.True(t, errors.IsNotFound(err), "PVC of the decommissioned node should be deleted")
+
+	err = rc.Client.Get(rc.Ctx, types.NamespacedName{
+		Name:      pods[0].Spec.Volumes[0].PersistentVolumeClaim.ClaimName,
+		Namespace: pods[0].Namespace,
+	}, pvc)
+	assert.NoError(t, err, "PVCs of other nodes should be kept")
+}
@@ -22,6 +22,7 @@ import (
 	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
 	"github.com/datastax/cass-operator/operator/pkg/httphelper"
 	"github.com/datastax/cass-operator/operator/pkg/dynamicwatch"
+	"github.com/datastax/cass-operator/operator/pkg/metrics"
 )
 
 // Use a var so we can mock this function
@@ -87,7 +88,7 @@ type ReconcileCassandraDatacenter struct {
 // if the returned error is non-nil or Result.Requeue is true,
 // otherwise upon completion it will remove the work from the queue.
 // See: https://godoc.org/sigs.k8s.io/controller-runtime/pkg/reconcile#Result
-func (r *ReconcileCassandraDatacenter) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+func (r *ReconcileCassandraDatacenter) Reconcile(request reconcile.Request) (res reconcile.Result, err error) {
 
 	startReconcile := time.Now()
 
@@ -101,6 +102,7 @@ func (r *ReconcileCassandraDatacenter) Reconcile(request reconcile.Request) (rec
 		reconcileDuration := time.Since(startReconcile).Seconds()
 		logger.Info("Reconcile loop completed",
 			"duration", reconcileDuration)
+		metrics.ObserveReconcile(request.Namespace, request.Name, res, err, time.Since(startReconcile))
 	}()
 
 	logger.Info("======== handler::Reconcile has been called")
@@ -113,6 +115,7 @@ func (r *ReconcileCassandraDatacenter) Reconcile(request reconcile.Request) (rec
 			// Owned objects are automatically garbage collected.
 			// Return and don't requeue
 			logger.Info("CassandraDatacenter resource not found. Ignoring since object must be deleted.")
+			metrics.DeleteDatacenterMetrics(request.Namespace, request.Name)
 			return result.Done().Output()
 		}
 
@@ -121,6 +124,16 @@ func (r *ReconcileCassandraDatacenter) Reconcile(request reconcile.Request) (rec
 		return result.Error(err).Output()
 	}
 
+	// The Management API certificates have to be issued before the config
+	// can be validated
+	if recResult := rc.CheckManagementApiCertificates(); recResult.Completed() {
+		return recResult.Output()
+	}
+
+	if recResult := rc.CheckManagementApiAutoCertificates(); recResult.Completed() {
+		return recResult.Output()
+	}
+
 	if err := rc.isValid(rc.Datacenter); err != nil {
 		logger.Error(err, "CassandraDatacenter resource is invalid")
 		rc.Recorder.Eventf(rc.Datacenter, "Warning", "ValidationFailed", err.Error())
@@ -137,11 +150,12 @@ func (r *ReconcileCassandraDatacenter) Reconcile(request reconcile.Request) (rec
 		return result.RequeueSoon(secs).Output()
 	}
 
-	res, err := rc.calculateReconciliationActions()
+	res, err = rc.calculateReconciliationActions()
 	if err != nil {
 		logger.Error(err, "calculateReconciliationActions returned an error")
 		rc.Recorder.Eventf(rc.Datacenter, "Warning", "ReconcileFailed", err.Error())
 	}
+	metrics.SetDatacenterConditions(rc.Datacenter)
 	return res, err
 }
 
@@ -171,8 +185,11 @@ func (rc *ReconciliationContext) isValid(dc *api.CassandraDatacenter) error {
 	// validate any other defined users
 	errs = append(errs, rc.validateCassandraUserSecrets()...)
 
-	// Validate Management API config
-	errs = append(errs, httphelper.ValidateManagementApiConfig(dc, rc.Client, rc.Ctx)...)
+	// Validate Management API config, unless the datacenter is being
+	// deleted, as its certificates are no longer issued then
+	if dc.GetDeletionTimestamp() == nil {
+		errs = append(errs, httphelper.ValidateManagementApiConfig(dc, rc.Client, rc.Ctx)...)
+	}
 	if len(errs) > 0 {
 		return errs[0]
 	}
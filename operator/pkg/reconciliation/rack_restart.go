@@ -0,0 +1,123 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/datastax/cass-operator/operator/internal/result"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/events"
+)
+
+// isServerUp returns true when Cassandra is started and ready in the pod, and
+// the pod is not going away
+func isServerUp(pod *corev1.Pod) bool {
+	return pod.Labels[api.CassNodeState] == stateStarted &&
+		isServerReady(pod) &&
+		pod.DeletionTimestamp == nil
+}
+
+// getFirstRackWithPods returns the name of the first rack, in the order of
+// the datacenter spec, that any of the given pods belong to
+func (rc *ReconciliationContext) getFirstRackWithPods(pods []*corev1.Pod) string {
+	for _, rackInfo := range rc.desiredRackInformation {
+		if len(FilterPodListByLabel(pods, api.RackLabel, rackInfo.RackName)) > 0 {
+			return rackInfo.RackName
+		}
+	}
+	return ""
+}
+
+// getRackRestartBatch returns the candidates, which must all be pods of the
+// given rack, that can be restarted now without more than
+// MaxUnavailablePerRack pods of the rack being down at once. Nothing is
+// returned while any pod of another rack is down or the cluster is not
+// healthy, so only one rack is ever missing nodes.
+func (rc *ReconciliationContext) getRackRestartBatch(rackName string, candidates []*corev1.Pod) []*corev1.Pod {
+	logger := rc.ReqLogger
+
+	unavailable := 0
+	for _, pod := range rc.dcPods {
+		if pod.Labels[api.RackLabel] == rackName {
+			if !isServerUp(pod) {
+				unavailable++
+			}
+			continue
+		}
+
+		if !isServerUp(pod) {
+			logger.Info("Waiting for the other racks to be up before restarting pods of rack",
+				"rackName", rackName,
+				"pod", pod.Name)
+			return nil
+		}
+	}
+
+	if !rc.isClusterHealthy() {
+		logger.Info("Waiting for the cluster to be healthy before restarting pods of rack",
+			"rackName", rackName)
+		return nil
+	}
+
+	batch := []*corev1.Pod{}
+	available := rc.Datacenter.GetMaxUnavailablePerRack() - unavailable
+	for _, pod := range candidates {
+		if len(batch) >= available {
+			break
+		}
+		if isServerUp(pod) {
+			batch = append(batch, pod)
+		}
+	}
+
+	return batch
+}
+
+// CheckRackPodsUpdated replaces the pods of a rack that do not match its
+// StatefulSet, several at a time. It is only needed when the StatefulSet uses
+// the OnDelete update strategy, as the StatefulSet controller replaces the
+// pods itself otherwise.
+func (rc *ReconciliationContext) CheckRackPodsUpdated(rackName string, statefulSet *appsv1.StatefulSet) result.ReconcileResult {
+	logger := rc.ReqLogger
+
+	if statefulSet.Spec.UpdateStrategy.Type != appsv1.OnDeleteStatefulSetStrategyType {
+		return result.Continue()
+	}
+
+	outdated := []*corev1.Pod{}
+	for _, pod := range FilterPodListByLabel(rc.dcPods, api.RackLabel, rackName) {
+		if pod.Labels[appsv1.ControllerRevisionHashLabelKey] != statefulSet.Status.UpdateRevision {
+			outdated = append(outdated, pod)
+		}
+	}
+
+	if len(outdated) == 0 {
+		return result.Continue()
+	}
+
+	batch := rc.getRackRestartBatch(rackName, outdated)
+	if len(batch) == 0 {
+		return result.Continue()
+	}
+
+	names := []string{}
+	for _, pod := range batch {
+		names = append(names, pod.Name)
+	}
+	rc.Recorder.Eventf(rc.Datacenter, corev1.EventTypeNormal, events.UpdatingRack,
+		"Updating pods %s of rack %s", strings.Join(names, ", "), rackName)
+
+	for _, pod := range batch {
+		logger.Info("Deleting pod to update it", "pod", pod.Name)
+		if err := rc.Client.Delete(rc.Ctx, pod); err != nil {
+			return result.Error(err)
+		}
+	}
+
+	return result.RequeueSoon(10)
+}
@@ -0,0 +1,159 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+)
+
+// setupRackRestartTest sets up a datacenter with two racks of the given size
+// that may restart a whole rack at once
+func setupRackRestartTest(t *testing.T, rc *ReconciliationContext, podsPerRack int) ([]*appsv1.StatefulSet, map[string][]*corev1.Pod) {
+	rc.Datacenter.Spec.Size = int32(2 * podsPerRack)
+	rc.Datacenter.Spec.MaxUnavailablePerRack = int32(podsPerRack)
+	rc.Datacenter.Spec.Racks = []api.Rack{{Name: "rack1"}, {Name: "rack2"}}
+	rc.Datacenter.Status.LastRollingRestart = metav1.Date(2020, time.July, 2, 0, 0, 0, 0, time.UTC)
+
+	trackObjects := []runtime.Object{rc.Datacenter}
+	statefulSets := []*appsv1.StatefulSet{}
+	rackPods := map[string][]*corev1.Pod{}
+	rc.desiredRackInformation = nil
+	rc.dcPods = nil
+
+	for _, rackName := range []string{"rack1", "rack2"} {
+		statefulSet, err := newStatefulSetForCassandraDatacenter(rackName, rc.Datacenter, podsPerRack)
+		assert.NoErrorf(t, err, "error occurred creating statefulset")
+		assert.Equal(t, appsv1.OnDeleteStatefulSetStrategyType, statefulSet.Spec.UpdateStrategy.Type,
+			"The operator should replace the pods itself")
+		statefulSet.Status.UpdateRevision = "new"
+		statefulSets = append(statefulSets, statefulSet)
+		trackObjects = append(trackObjects, statefulSet)
+
+		pods := mockRunningPodsForRack(statefulSet, rc.Datacenter, rackName)
+		for _, pod := range pods {
+			pod.CreationTimestamp = metav1.Date(2020, time.July, 1, 0, 0, 0, 0, time.UTC)
+			pod.Labels[appsv1.ControllerRevisionHashLabelKey] = "new"
+			trackObjects = append(trackObjects, pod)
+		}
+		rackPods[rackName] = pods
+		rc.dcPods = append(rc.dcPods, pods...)
+
+		rc.desiredRackInformation = append(rc.desiredRackInformation, &RackInformation{
+			RackName:  rackName,
+			NodeCount: podsPerRack,
+		})
+	}
+
+	rc.Client = fake.NewFakeClient(trackObjects...)
+	rc.statefulSets = statefulSets
+	rc.clusterPods = rc.dcPods
+	mockMetadataEndpointsResponse(rc, "OK")
+
+	return statefulSets, rackPods
+}
+
+func assertPodsDeleted(t *testing.T, rc *ReconciliationContext, pods []*corev1.Pod, deleted bool) {
+	for _, pod := range pods {
+		err := rc.Client.Get(rc.Ctx, types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, &corev1.Pod{})
+		if deleted {
+			assert.True(t, errors.IsNotFound(err), "Pod %s should be deleted", pod.Name)
+		} else {
+			assert.NoError(t, err, "Pod %s should not be deleted", pod.Name)
+		}
+	}
+}
+
+func TestCheckRollingRestart_RackAtATime(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	_, rackPods := setupRackRestartTest(t, rc, 2)
+
+	recResult := rc.CheckRollingRestart()
+	assert.True(t, recResult.Completed())
+
+	assertPodsDeleted(t, rc, rackPods["rack1"], true)
+	assertPodsDeleted(t, rc, rackPods["rack2"], false)
+}
+
+func TestCheckRollingRestart_WaitsForOtherRacks(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	_, rackPods := setupRackRestartTest(t, rc, 2)
+	// rack1 was restarted already, but one of its nodes is still coming up
+	for _, pod := range rackPods["rack1"] {
+		pod.CreationTimestamp = metav1.Date(2020, time.July, 3, 0, 0, 0, 0, time.UTC)
+	}
+	rackPods["rack1"][1].Labels[api.CassNodeState] = stateStarting
+	rackPods["rack1"][1].Status.ContainerStatuses[0].Ready = false
+
+	recResult := rc.CheckRollingRestart()
+	assert.True(t, recResult.Completed(), "Should requeue until the other racks are up")
+
+	assertPodsDeleted(t, rc, rackPods["rack2"], false)
+}
+
+func TestCheckRackPodsUpdated(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	statefulSets, rackPods := setupRackRestartTest(t, rc, 3)
+	rc.Datacenter.Spec.MaxUnavailablePerRack = 2
+	for _, pod := range rackPods["rack1"] {
+		pod.Labels[appsv1.ControllerRevisionHashLabelKey] = "old"
+	}
+
+	recResult := rc.CheckRackPodsUpdated("rack1", statefulSets[0])
+	assert.True(t, recResult.Completed(), "Should requeue while pods are being updated")
+
+	assertPodsDeleted(t, rc, rackPods["rack1"][:2], true)
+	assertPodsDeleted(t, rc, rackPods["rack1"][2:], false)
+	assertPodsDeleted(t, rc, rackPods["rack2"], false)
+}
+
+func TestCheckRackPodsUpdated_RollingUpdate(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	statefulSets, rackPods := setupRackRestartTest(t, rc, 2)
+	statefulSets[0].Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{}
+	for _, pod := range rackPods["rack1"] {
+		pod.Labels[appsv1.ControllerRevisionHashLabelKey] = "old"
+	}
+
+	recResult := rc.CheckRackPodsUpdated("rack1", statefulSets[0])
+	assert.False(t, recResult.Completed(), "The StatefulSet controller should update the pods")
+	assertPodsDeleted(t, rc, rackPods["rack1"], false)
+}
+
+func Test_getRackRestartBatch(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	_, rackPods := setupRackRestartTest(t, rc, 3)
+	rackPods["rack1"][0].Labels[api.CassNodeState] = stateReadyToStart
+	rackPods["rack1"][0].Status.ContainerStatuses[0].Ready = false
+
+	batch := rc.getRackRestartBatch("rack1", rackPods["rack1"])
+	assert.Equal(t, []*corev1.Pod{rackPods["rack1"][1], rackPods["rack1"][2]}, batch,
+		"Should not restart pods that are already down")
+
+	rc.Datacenter.Spec.MaxUnavailablePerRack = 2
+	batch = rc.getRackRestartBatch("rack1", rackPods["rack1"])
+	assert.Equal(t, []*corev1.Pod{rackPods["rack1"][1]}, batch,
+		"Pods that are already down should count towards the limit")
+}
@@ -0,0 +1,110 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/datastax/cass-operator/operator/internal/result"
+	"github.com/datastax/cass-operator/operator/pkg/events"
+	"github.com/datastax/cass-operator/operator/pkg/httphelper"
+)
+
+// getCertManagerSecurityProvider returns the cert-manager strategy of the
+// Management API, or nil when the datacenter uses another one
+func (rc *ReconciliationContext) getCertManagerSecurityProvider() (*httphelper.CertManagerManagementApiSecurityProvider, error) {
+	provider, err := httphelper.BuildManagmenetApiSecurityProvider(rc.Datacenter)
+	if err != nil {
+		return nil, err
+	}
+	certManager, _ := provider.(*httphelper.CertManagerManagementApiSecurityProvider)
+	return certManager, nil
+}
+
+// CheckManagementApiCertificates creates or updates the cert-manager
+// resources of the Management API certificates, and waits for the server and
+// client certificates to be issued. This has to happen before the Management
+// API config can be validated. Nothing is issued for a datacenter that is
+// being deleted, which could otherwise wait forever for an issuer.
+func (rc *ReconciliationContext) CheckManagementApiCertificates() result.ReconcileResult {
+	logger := rc.ReqLogger
+	dc := rc.Datacenter
+
+	if dc.GetDeletionTimestamp() != nil {
+		return result.Continue()
+	}
+
+	provider, err := rc.getCertManagerSecurityProvider()
+	if err != nil || provider == nil {
+		// An invalid managementApiAuth is reported when the datacenter is
+		// validated
+		return result.Continue()
+	}
+
+	logger.Info("reconcile_certmanager::CheckManagementApiCertificates")
+
+	for _, desired := range provider.BuildCertManagerResources() {
+		if err := setControllerReference(dc, desired, rc.Scheme); err != nil {
+			logger.Error(err, "Could not set controller reference for cert-manager resource")
+			return result.Error(err)
+		}
+		addHashAnnotation(desired)
+
+		current := &unstructured.Unstructured{}
+		current.SetGroupVersionKind(desired.GroupVersionKind())
+		nsName := types.NamespacedName{Name: desired.GetName(), Namespace: desired.GetNamespace()}
+		err := rc.Client.Get(rc.Ctx, nsName, current)
+		if err != nil && errors.IsNotFound(err) {
+			logger.Info("Creating cert-manager resource",
+				"kind", desired.GetKind(),
+				"name", desired.GetName())
+
+			if err := rc.Client.Create(rc.Ctx, desired); err != nil {
+				logger.Error(err, "Could not create cert-manager resource")
+				return result.Error(err)
+			}
+			rc.Recorder.Eventf(dc, corev1.EventTypeNormal, events.CreatedResource,
+				"Created %s %s", desired.GetKind(), desired.GetName())
+			continue
+		} else if err != nil {
+			logger.Error(err, "Could not get cert-manager resource")
+			return result.Error(err)
+		}
+
+		if !resourcesHaveSameHash(current, desired) {
+			logger.Info("Updating cert-manager resource",
+				"kind", desired.GetKind(),
+				"name", desired.GetName())
+
+			current.SetAnnotations(desired.GetAnnotations())
+			current.Object["spec"] = desired.Object["spec"]
+			if err := rc.Client.Update(rc.Ctx, current); err != nil {
+				logger.Error(err, "Could not update cert-manager resource")
+				return result.Error(err)
+			}
+		}
+	}
+
+	for _, nsName := range provider.GetIssuedCertificateNames() {
+		certificate := &unstructured.Unstructured{}
+		certificate.SetGroupVersionKind(httphelper.CertManagerGroupVersionKind("Certificate"))
+		if err := rc.Client.Get(rc.Ctx, nsName, certificate); err != nil {
+			if errors.IsNotFound(err) {
+				return result.RequeueSoon(5)
+			}
+			return result.Error(err)
+		}
+
+		if !httphelper.IsCertificateReady(certificate) {
+			logger.Info("Waiting for cert-manager to issue the Management API certificate",
+				"certificate", nsName.Name)
+			return result.RequeueSoon(5)
+		}
+	}
+
+	return result.Continue()
+}
@@ -26,6 +26,12 @@ func (rc *ReconciliationContext) ProcessDeletion() result.ReconcileResult {
 		return result.Error(err)
 	}
 
+	// Only release the finalizer once the nodes have left the cluster, when
+	// the deletion policy asks for it
+	if recResult := rc.CheckDatacenterDecommission(); recResult.Completed() {
+		return recResult
+	}
+
 	// Clean up annotation litter on the user Secrets
 	err := rc.SecretWatches.RemoveWatcher(types.NamespacedName{
 		Name: rc.Datacenter.GetName(), Namespace: rc.Datacenter.GetNamespace(),})
@@ -34,9 +40,8 @@ func (rc *ReconciliationContext) ProcessDeletion() result.ReconcileResult {
 		rc.ReqLogger.Error(err, "Failed to remove dynamic secret watches for CassandraDatacenter")
 	}
 
-	if err := rc.deletePVCs(); err != nil {
-		rc.ReqLogger.Error(err, "Failed to delete PVCs for CassandraDatacenter")
-		return result.Error(err)
+	if recResult := rc.CheckPVCRetention(); recResult.Completed() {
+		return recResult
 	}
 
 	// Update finalizer to allow delete of CassandraDatacenter
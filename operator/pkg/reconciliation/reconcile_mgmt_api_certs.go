@@ -0,0 +1,244 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/datastax/cass-operator/operator/internal/result"
+	"github.com/datastax/cass-operator/operator/pkg/events"
+	"github.com/datastax/cass-operator/operator/pkg/httphelper"
+)
+
+// getManagedCertificatesSecurityProvider returns the strategy of the
+// Management API when its certificates are issued for the datacenter, or nil
+// when the user provides them
+func (rc *ReconciliationContext) getManagedCertificatesSecurityProvider() (httphelper.ManagedCertificatesSecurityProvider, error) {
+	provider, err := httphelper.BuildManagmenetApiSecurityProvider(rc.Datacenter)
+	if err != nil {
+		return nil, err
+	}
+	managed, _ := provider.(httphelper.ManagedCertificatesSecurityProvider)
+	return managed, nil
+}
+
+// CheckManagementApiAutoCertificates generates the CA and the server and
+// client certificates of the Management API when they are missing, and
+// renews the ones that are about to expire. This has to happen before the
+// Management API config can be validated.
+//
+// A renewed CA is trusted along with the previous one right away, but the
+// server and client certificates are only signed by it once every pod has
+// been restarted since, so that the pods trust it by then. Nothing is issued
+// for a datacenter that is being deleted.
+func (rc *ReconciliationContext) CheckManagementApiAutoCertificates() result.ReconcileResult {
+	logger := rc.ReqLogger
+
+	if rc.Datacenter.GetDeletionTimestamp() != nil {
+		return result.Continue()
+	}
+
+	provider, err := httphelper.BuildManagmenetApiSecurityProvider(rc.Datacenter)
+	auto, ok := provider.(*httphelper.AutoManagementApiSecurityProvider)
+	if err != nil || !ok {
+		// An invalid managementApiAuth is reported when the datacenter is
+		// validated
+		return result.Continue()
+	}
+
+	logger.Info("reconcile_mgmt_api_certs::CheckManagementApiAutoCertificates")
+
+	now := time.Now()
+
+	caSecret, err := rc.getManagementApiSecret(auto.CaSecretName())
+	if err != nil {
+		return result.Error(err)
+	}
+	if caSecret == nil || auto.CaNeedsRenewal(caSecret, now) {
+		renewed, err := auto.NewCaSecret(now, caSecret)
+		if err != nil {
+			logger.Error(err, "error generating Management API CA")
+			return result.Error(err)
+		}
+		if err := rc.saveManagementApiSecret(renewed, caSecret); err != nil {
+			return result.Error(err)
+		}
+		caSecret = renewed
+	}
+
+	caIssueTime, err := auto.GetCaIssueTime(caSecret)
+	if err != nil {
+		logger.Error(err, "error reading Management API CA")
+		return result.Error(err)
+	}
+	caTrusted, err := rc.podsCreatedSince(metav1.NewTime(caIssueTime))
+	if err != nil {
+		return result.Error(err)
+	}
+
+	for _, secretName := range []string{auto.ServerSecretName(), auto.ClientSecretName()} {
+		secret, err := rc.getManagementApiSecret(secretName)
+		if err != nil {
+			return result.Error(err)
+		}
+		if secret != nil && !auto.KeyPairNeedsRenewal(secret, caSecret, now) {
+			// A key pair signed by the previous CA is kept until the pods
+			// trust the current one
+			if auto.KeyPairSignedByCa(secret, caSecret) || !caTrusted {
+				if !auto.KeyPairTrustsCas(secret, caSecret) {
+					if err := rc.updateManagementApiTrustedCas(secret, caSecret); err != nil {
+						return result.Error(err)
+					}
+				}
+				continue
+			}
+		}
+
+		renewed, err := auto.NewKeyPairSecret(secretName, caSecret, now)
+		if err != nil {
+			logger.Error(err, "error generating Management API certificate")
+			return result.Error(err)
+		}
+		if err := rc.saveManagementApiSecret(renewed, secret); err != nil {
+			return result.Error(err)
+		}
+	}
+
+	return result.Continue()
+}
+
+// podsCreatedSince returns true when every pod of the datacenter has been
+// created since the given time
+func (rc *ReconciliationContext) podsCreatedSince(since metav1.Time) (bool, error) {
+	podList, err := rc.listPods(rc.Datacenter.GetDatacenterLabels())
+	if err != nil {
+		rc.ReqLogger.Error(err, "error listing pods of the datacenter")
+		return false, err
+	}
+	for i := range podList.Items {
+		if podList.Items[i].CreationTimestamp.Before(&since) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// updateManagementApiTrustedCas makes the secret trust the CAs of the CA
+// secret, keeping its key pair
+func (rc *ReconciliationContext) updateManagementApiTrustedCas(secret, caSecret *corev1.Secret) error {
+	patch := client.MergeFrom(secret.DeepCopy())
+	secret.Data["ca.crt"] = caSecret.Data["ca.crt"]
+	if err := rc.Client.Patch(rc.Ctx, secret, patch); err != nil {
+		rc.ReqLogger.Error(err, "Could not update Management API secret")
+		return err
+	}
+	rc.Recorder.Eventf(rc.Datacenter, corev1.EventTypeNormal, events.IssuedManagementApiCertificate,
+		"Updated the trusted CAs in secret %s", secret.Name)
+	return nil
+}
+
+// getManagementApiSecret returns the secret with the given name in the
+// namespace of the datacenter, or nil if it does not exist
+func (rc *ReconciliationContext) getManagementApiSecret(name string) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	nsName := types.NamespacedName{Name: name, Namespace: rc.Datacenter.Namespace}
+	err := rc.Client.Get(rc.Ctx, nsName, secret)
+	if err != nil && errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		rc.ReqLogger.Error(err, "error getting Management API secret", "secret", name)
+		return nil, err
+	}
+	return secret, nil
+}
+
+// saveManagementApiSecret creates the desired secret, owned by the
+// datacenter, or replaces the data of the current one with it
+func (rc *ReconciliationContext) saveManagementApiSecret(desired, current *corev1.Secret) error {
+	logger := rc.ReqLogger
+	dc := rc.Datacenter
+
+	if current == nil {
+		if err := setControllerReference(dc, desired, rc.Scheme); err != nil {
+			logger.Error(err, "Could not set controller reference for Management API secret")
+			return err
+		}
+		if err := rc.Client.Create(rc.Ctx, desired); err != nil {
+			logger.Error(err, "Could not create Management API secret")
+			return err
+		}
+		rc.Recorder.Eventf(dc, corev1.EventTypeNormal, events.CreatedResource,
+			"Created secret %s", desired.Name)
+		return nil
+	}
+
+	patch := client.MergeFrom(current.DeepCopy())
+	current.Data = desired.Data
+	if err := rc.Client.Patch(rc.Ctx, current, patch); err != nil {
+		logger.Error(err, "Could not update Management API secret")
+		return err
+	}
+	rc.Recorder.Eventf(dc, corev1.EventTypeNormal, events.IssuedManagementApiCertificate,
+		"Renewed the certificate in secret %s", desired.Name)
+	return nil
+}
+
+// CheckManagementApiCertificateRotation restarts, one at a time, the pods
+// created before the Management API server certificate, or the CAs it trusts,
+// were renewed, as the Management API only reads its certificates on start.
+// Pods are deleted whatever the restart strategy, since restarting Cassandra
+// in place leaves the Management API running.
+func (rc *ReconciliationContext) CheckManagementApiCertificateRotation() result.ReconcileResult {
+	logger := rc.ReqLogger
+	dc := rc.Datacenter
+
+	provider, err := rc.getManagedCertificatesSecurityProvider()
+	if err != nil {
+		return result.Error(err)
+	}
+	if provider == nil {
+		return result.Continue()
+	}
+
+	logger.Info("reconcile_mgmt_api_certs::CheckManagementApiCertificateRotation")
+
+	secret := &corev1.Secret{}
+	nsName := types.NamespacedName{Name: provider.ServerSecretName(), Namespace: dc.Namespace}
+	if err := rc.Client.Get(rc.Ctx, nsName, secret); err != nil {
+		logger.Error(err, "error getting Management API server secret")
+		return result.Error(err)
+	}
+
+	issueTime, err := httphelper.GetSecretIssueTime(secret)
+	if err != nil {
+		logger.Error(err, "error reading Management API server certificate")
+		return result.Error(err)
+	}
+	issued := metav1.NewTime(issueTime)
+
+	for _, pod := range rc.dcPods {
+		if !pod.CreationTimestamp.Before(&issued) {
+			continue
+		}
+
+		rc.Recorder.Eventf(dc, corev1.EventTypeNormal, events.RenewedManagementApiCertificate,
+			"Restarting pod %s to load the Management API certificate renewed at %s", pod.Name, issueTime)
+		if err := rc.NodeMgmtClient.CallDrainEndpoint(pod); err != nil {
+			logger.Error(err, "error draining node before restarting it", "pod", pod.Name)
+		}
+		if err := rc.Client.Delete(rc.Ctx, pod); err != nil {
+			logger.Error(err, "error deleting pod to load the Management API certificate", "pod", pod.Name)
+			return result.Error(err)
+		}
+		return result.RequeueSoon(10)
+	}
+
+	return result.Continue()
+}
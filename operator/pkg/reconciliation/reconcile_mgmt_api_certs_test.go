@@ -0,0 +1,312 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/httphelper"
+)
+
+func newTestCertificatePem(t *testing.T, notBefore time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "cluster1-dc1-mgmt-api-server"},
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.Add(90 * 24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// setupCertificateRotationTest sets up a datacenter using cert-manager, whose
+// pods were started on July 1st, and a server certificate issued at the given
+// time
+func setupCertificateRotationTest(t *testing.T, rc *ReconciliationContext, issued time.Time) {
+	rc.Datacenter.Spec.ManagementApiAuth = api.ManagementApiAuthConfig{
+		CertManager: &api.ManagementApiAuthCertManagerConfig{},
+	}
+
+	statefulSet, err := newStatefulSetForCassandraDatacenter("default", rc.Datacenter, 2)
+	assert.NoErrorf(t, err, "error occurred creating statefulset")
+	rc.dcPods = mockRunningPodsForRack(statefulSet, rc.Datacenter, "default")
+
+	trackObjects := []runtime.Object{rc.Datacenter}
+	for _, pod := range rc.dcPods {
+		pod.CreationTimestamp = metav1.Date(2020, time.July, 1, 0, 0, 0, 0, time.UTC)
+		trackObjects = append(trackObjects, pod)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      rc.Datacenter.Spec.ClusterName + "-" + rc.Datacenter.Name + "-mgmt-api-server",
+			Namespace: rc.Datacenter.Namespace,
+		},
+		Type: "kubernetes.io/tls",
+		Data: map[string][]byte{
+			"tls.crt": newTestCertificatePem(t, issued),
+		},
+	}
+	trackObjects = append(trackObjects, secret)
+
+	rc.Client = fake.NewFakeClient(trackObjects...)
+}
+
+// podExists tells whether the fake client still has the pod
+func podExists(t *testing.T, rc *ReconciliationContext, pod *corev1.Pod) bool {
+	err := rc.Client.Get(rc.Ctx, types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, &corev1.Pod{})
+	if errors.IsNotFound(err) {
+		return false
+	}
+	assert.NoError(t, err)
+	return true
+}
+
+func TestCheckManagementApiCertificateRotation_Renewed(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	issued := time.Date(2020, time.July, 2, 0, 0, 0, 0, time.UTC)
+	setupCertificateRotationTest(t, rc, issued)
+
+	recResult := rc.CheckManagementApiCertificateRotation()
+
+	assert.True(t, recResult.Completed(), "Should wait for the restarted pod")
+	assert.False(t, podExists(t, rc, rc.dcPods[0]),
+		"Should restart a pod started before the certificate was renewed")
+	assert.True(t, podExists(t, rc, rc.dcPods[1]), "Should restart one pod at a time")
+}
+
+func TestCheckManagementApiCertificateRotation_UpToDate(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	setupCertificateRotationTest(t, rc, time.Date(2020, time.June, 30, 0, 0, 0, 0, time.UTC))
+
+	recResult := rc.CheckManagementApiCertificateRotation()
+
+	assert.False(t, recResult.Completed())
+	for _, pod := range rc.dcPods {
+		assert.True(t, podExists(t, rc, pod),
+			"Should not restart pods started after the certificate was issued")
+	}
+}
+
+func TestCheckManagementApiCertificateRotation_RestartedInPlace(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	rc.Datacenter.Spec.RestartStrategy = api.RestartStrategyProcessRestart
+	setupCertificateRotationTest(t, rc, time.Date(2020, time.July, 2, 0, 0, 0, 0, time.UTC))
+	for _, pod := range rc.dcPods {
+		pod.Annotations = map[string]string{api.LastRestartAnnotation: "2020-07-03T00:00:00Z"}
+		assert.NoError(t, rc.Client.Update(rc.Ctx, pod))
+	}
+
+	recResult := rc.CheckManagementApiCertificateRotation()
+
+	assert.True(t, recResult.Completed(), "Should wait for the restarted pod")
+	assert.False(t, podExists(t, rc, rc.dcPods[0]),
+		"Should delete the pod, as restarting Cassandra in place does not reload the certificate")
+}
+
+func getTestSecretData(t *testing.T, rc *ReconciliationContext, name string) map[string][]byte {
+	secret := &corev1.Secret{}
+	nsName := types.NamespacedName{Name: name, Namespace: rc.Datacenter.Namespace}
+	assert.NoError(t, rc.Client.Get(rc.Ctx, nsName, secret))
+	return secret.Data
+}
+
+func TestCheckManagementApiAutoCertificates(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	rc.Datacenter.Spec.ManagementApiAuth = api.ManagementApiAuthConfig{
+		Auto: &api.ManagementApiAuthAutoConfig{},
+	}
+	rc.Client = fake.NewFakeClient(rc.Datacenter)
+
+	recResult := rc.CheckManagementApiAutoCertificates()
+	assert.False(t, recResult.Completed())
+
+	prefix := rc.Datacenter.Spec.ClusterName + "-" + rc.Datacenter.Name + "-mgmt-api"
+	caData := getTestSecretData(t, rc, prefix+"-ca")
+	serverData := getTestSecretData(t, rc, prefix+"-server")
+	clientData := getTestSecretData(t, rc, prefix+"-client")
+	assert.Equal(t, caData["tls.crt"], serverData["ca.crt"])
+	assert.Equal(t, caData["tls.crt"], clientData["ca.crt"])
+
+	recResult = rc.CheckManagementApiAutoCertificates()
+	assert.False(t, recResult.Completed())
+
+	assert.Equal(t, serverData, getTestSecretData(t, rc, prefix+"-server"),
+		"Should not renew a certificate that is still valid")
+	assert.Equal(t, clientData, getTestSecretData(t, rc, prefix+"-client"),
+		"Should not renew a certificate that is still valid")
+}
+
+func TestCheckManagementApiAutoCertificates_OtherCa(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	rc.Datacenter.Spec.ManagementApiAuth = api.ManagementApiAuthConfig{
+		Auto: &api.ManagementApiAuthAutoConfig{},
+	}
+	rc.Client = fake.NewFakeClient(rc.Datacenter)
+
+	recResult := rc.CheckManagementApiAutoCertificates()
+	assert.False(t, recResult.Completed())
+
+	prefix := rc.Datacenter.Spec.ClusterName + "-" + rc.Datacenter.Name + "-mgmt-api"
+	serverData := getTestSecretData(t, rc, prefix+"-server")
+
+	// Replace the CA, as if the user had deleted its secret
+	ca := &corev1.Secret{}
+	nsName := types.NamespacedName{Name: prefix + "-ca", Namespace: rc.Datacenter.Namespace}
+	assert.NoError(t, rc.Client.Get(rc.Ctx, nsName, ca))
+	assert.NoError(t, rc.Client.Delete(rc.Ctx, ca))
+
+	recResult = rc.CheckManagementApiAutoCertificates()
+	assert.False(t, recResult.Completed())
+
+	caData := getTestSecretData(t, rc, prefix+"-ca")
+	renewedData := getTestSecretData(t, rc, prefix+"-server")
+	assert.NotEqual(t, serverData["tls.crt"], renewedData["tls.crt"],
+		"Should renew a certificate signed by another CA")
+	assert.Equal(t, caData["tls.crt"], renewedData["ca.crt"])
+}
+
+func TestCheckManagementApiAutoCertificates_OtherStrategy(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	rc.Datacenter.Spec.ManagementApiAuth = api.ManagementApiAuthConfig{
+		Insecure: &api.ManagementApiAuthInsecureConfig{},
+	}
+	rc.Client = fake.NewFakeClient(rc.Datacenter)
+
+	recResult := rc.CheckManagementApiAutoCertificates()
+	assert.False(t, recResult.Completed())
+
+	secrets := &corev1.SecretList{}
+	assert.NoError(t, rc.Client.List(rc.Ctx, secrets))
+	assert.Equal(t, 0, len(secrets.Items))
+}
+
+func TestCheckManagementApiAutoCertificates_RenewedCa(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	rc.Datacenter.Spec.ManagementApiAuth = api.ManagementApiAuthConfig{
+		Auto: &api.ManagementApiAuthAutoConfig{},
+	}
+	provider, err := httphelper.BuildManagmenetApiSecurityProvider(rc.Datacenter)
+	assert.NoError(t, err)
+	auto := provider.(*httphelper.AutoManagementApiSecurityProvider)
+
+	// A CA that expires tomorrow, and certificates it signed an hour ago
+	now := time.Now()
+	caSecret, err := auto.NewCaSecret(now.Add(-10*365*24*time.Hour+24*time.Hour), nil)
+	assert.NoError(t, err)
+	serverSecret, err := auto.NewKeyPairSecret(auto.ServerSecretName(), caSecret, now.Add(-time.Hour))
+	assert.NoError(t, err)
+	clientSecret, err := auto.NewKeyPairSecret(auto.ClientSecretName(), caSecret, now.Add(-time.Hour))
+	assert.NoError(t, err)
+
+	statefulSet, err := newStatefulSetForCassandraDatacenter("default", rc.Datacenter, 2)
+	assert.NoErrorf(t, err, "error occurred creating statefulset")
+	pods := mockRunningPodsForRack(statefulSet, rc.Datacenter, "default")
+
+	trackObjects := []runtime.Object{rc.Datacenter, caSecret, serverSecret, clientSecret}
+	for _, pod := range pods {
+		pod.CreationTimestamp = metav1.NewTime(now.Add(-time.Hour))
+		trackObjects = append(trackObjects, pod)
+	}
+	rc.Client = fake.NewFakeClient(trackObjects...)
+
+	recResult := rc.CheckManagementApiAutoCertificates()
+	assert.False(t, recResult.Completed())
+
+	caData := getTestSecretData(t, rc, auto.CaSecretName())
+	assert.NotEqual(t, caSecret.Data["tls.crt"], caData["tls.crt"], "Should renew the CA")
+	serverData := getTestSecretData(t, rc, auto.ServerSecretName())
+	assert.Equal(t, serverSecret.Data["tls.crt"], serverData["tls.crt"],
+		"Should keep the certificate until the pods trust the renewed CA")
+	assert.Equal(t, caData["ca.crt"], serverData["ca.crt"], "Should trust both CAs")
+	assert.Equal(t, clientSecret.Data["tls.crt"], getTestSecretData(t, rc, auto.ClientSecretName())["tls.crt"],
+		"Should keep the certificate until the pods trust the renewed CA")
+
+	// The pods are restarted to load the trusted CAs
+	for _, pod := range pods {
+		pod.CreationTimestamp = metav1.NewTime(now.Add(time.Minute))
+		assert.NoError(t, rc.Client.Update(rc.Ctx, pod))
+	}
+
+	recResult = rc.CheckManagementApiAutoCertificates()
+	assert.False(t, recResult.Completed())
+
+	renewedCaSecret := &corev1.Secret{Data: caData}
+	for _, name := range []string{auto.ServerSecretName(), auto.ClientSecretName()} {
+		renewed := &corev1.Secret{Data: getTestSecretData(t, rc, name)}
+		assert.True(t, auto.KeyPairSignedByCa(renewed, renewedCaSecret),
+			"Should sign the certificates with the renewed CA once the pods trust it")
+		assert.Equal(t, caData["ca.crt"], renewed.Data["ca.crt"])
+	}
+}
+
+func TestCheckManagementApiAutoCertificates_Deleting(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	rc.Datacenter.Spec.ManagementApiAuth = api.ManagementApiAuthConfig{
+		Auto: &api.ManagementApiAuthAutoConfig{},
+	}
+	now := metav1.Now()
+	rc.Datacenter.SetDeletionTimestamp(&now)
+	rc.Client = fake.NewFakeClient(rc.Datacenter)
+
+	recResult := rc.CheckManagementApiAutoCertificates()
+	assert.False(t, recResult.Completed())
+
+	secrets := &corev1.SecretList{}
+	assert.NoError(t, rc.Client.List(rc.Ctx, secrets))
+	assert.Equal(t, 0, len(secrets.Items), "Should not issue certificates for a datacenter being deleted")
+}
+
+func TestCheckManagementApiCertificates_Deleting(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	rc.Datacenter.Spec.ManagementApiAuth = api.ManagementApiAuthConfig{
+		CertManager: &api.ManagementApiAuthCertManagerConfig{},
+	}
+	now := metav1.Now()
+	rc.Datacenter.SetDeletionTimestamp(&now)
+	rc.Client = fake.NewFakeClient(rc.Datacenter)
+
+	recResult := rc.CheckManagementApiCertificates()
+	assert.False(t, recResult.Completed(), "Should not wait for an issuer while the datacenter is deleted")
+}
@@ -25,6 +25,7 @@ import (
 	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
 	"github.com/datastax/cass-operator/operator/pkg/events"
 	"github.com/datastax/cass-operator/operator/pkg/httphelper"
+	"github.com/datastax/cass-operator/operator/pkg/metrics"
 	"github.com/datastax/cass-operator/operator/pkg/oplabels"
 	"github.com/datastax/cass-operator/operator/pkg/utils"
 )
@@ -41,6 +42,7 @@ const (
 	stateStartedNotReady = "Started-not-Ready"
 	stateStarted         = "Started"
 	stateStarting        = "Starting"
+	stateDecommissioning = "Decommissioning"
 )
 
 // CalculateRackInformation determine how many nodes per rack are needed
@@ -113,6 +115,14 @@ func (rc *ReconciliationContext) CheckSuperuserSecretCreation() result.Reconcile
 
 func (rc *ReconciliationContext) CheckRackCreation() result.ReconcileResult {
 	rc.ReqLogger.Info("reconcile_racks::CheckRackCreation")
+
+	// The claims retained by an earlier datacenter of the same name have to
+	// be found again when the datacenter is deleted or its volumes expanded
+	if err := rc.adoptRetainedPVCs(); err != nil {
+		rc.ReqLogger.Error(err, "Could not adopt retained PVCs")
+		return result.Error(err)
+	}
+
 	for idx := range rc.desiredRackInformation {
 		rackInfo := rc.desiredRackInformation[idx]
 
@@ -131,6 +141,13 @@ func (rc *ReconciliationContext) CheckRackCreation() result.ReconcileResult {
 			rc.ReqLogger.Info(
 				"Need to create new StatefulSet for",
 				"Rack", rackInfo.RackName)
+
+			// A statefulset that was deleted to expand its volumes left its
+			// pods running, and has to adopt them rather than scale them away
+			if replicas := getOrphanedPodReplicas(statefulSet, rc.dcPods); replicas > 0 {
+				statefulSet.Spec.Replicas = &replicas
+			}
+
 			err := rc.ReconcileNextRack(statefulSet)
 			if err != nil {
 				rc.ReqLogger.Error(
@@ -160,6 +177,14 @@ func (rc *ReconciliationContext) desiredStatefulSetForExistingStatefulSet(sts *a
 	// StatefulSet. Consequently, we must preserve the old labels in this case.
 	usesDefunct := usesDefunctPvcManagedByLabel(sts)
 
+	// The storage request of the volume claim templates cannot be updated
+	// either. It only changes when CheckVolumeExpansion expands the volumes
+	// and creates the StatefulSet again, which may be blocked, so until then
+	// the StatefulSet keeps the storage request it has.
+	if currentSize, ok := getStatefulSetStorageRequest(sts); ok {
+		dc = withStorageRequest(dc, currentSize)
+	}
+
 	if usesDefunct {
 		desiredSts, err = newStatefulSetForCassandraDatacenterWithDefunctPvcManagedBy(rackName, dc, replicas)
 	} else {
@@ -262,9 +287,17 @@ func (rc *ReconciliationContext) CheckRackPodTemplate() result.ReconcileResult {
 			// or are missing, we should not move onto the next rack,
 			// because there's an upgrade in progress
 
+			if recResult := rc.CheckRackPodsUpdated(rackName, statefulSet); recResult.Completed() {
+				return recResult
+			}
+
+			// with the OnDelete update strategy, the StatefulSet controller
+			// does not move the current revision along, so only the updated
+			// replicas tell whether the upgrade is done
 			status := statefulSet.Status
+			onDelete := statefulSet.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType
 			if status.Replicas != status.ReadyReplicas ||
-				status.Replicas != status.CurrentReplicas ||
+				(status.Replicas != status.CurrentReplicas && !onDelete) ||
 				status.Replicas != status.UpdatedReplicas {
 
 				logger.Info(
@@ -303,11 +336,9 @@ func (rc *ReconciliationContext) CheckRackForceUpgrade() result.ReconcileResult
 
 			statefulSet := rc.statefulSets[idx]
 
-			// have to use zero here, because each statefulset is created with no replicas
-			// in GetStatefulSetForRack()
-			desiredSts, err := newStatefulSetForCassandraDatacenter(rackName, dc, 0)
+			desiredSts, err := rc.desiredStatefulSetForExistingStatefulSet(statefulSet, rackName)
 			if err != nil {
-				logger.Error(err, "error calling newStatefulSetForCassandraDatacenter")
+				logger.Error(err, "error calling desiredStatefulSetForExistingStatefulSet")
 				return result.Error(err)
 			}
 
@@ -603,7 +634,8 @@ func (rc *ReconciliationContext) CheckPodsReady(endpointData httphelper.CassMeta
 }
 
 // CheckRackScale loops over each statefulset and makes sure that it has the right
-// amount of desired replicas. At this time we can only increase the amount of replicas.
+// amount of desired replicas. Only increases are handled here, racks with too many
+// replicas are scaled down by DecommissionNodes once the cluster is healthy.
 func (rc *ReconciliationContext) CheckRackScale() result.ReconcileResult {
 	logger := rc.ReqLogger
 	logger.Info("reconcile_racks::CheckRackScale")
@@ -661,17 +693,6 @@ func (rc *ReconciliationContext) CheckRackScale() result.ReconcileResult {
 				return result.Error(err)
 			}
 		}
-
-		currentReplicas := statefulSet.Status.CurrentReplicas
-		if currentReplicas > desiredNodeCount {
-			// too many ready replicas, how did this happen?
-			rc.ReqLogger.Info(
-				"Too many replicas for StatefulSet",
-				"desiredCount", desiredNodeCount,
-				"currentCount", currentReplicas)
-			err := fmt.Errorf("too many replicas")
-			return result.Error(err)
-		}
 	}
 
 	return result.Continue()
@@ -744,8 +765,19 @@ func (rc *ReconciliationContext) UpdateSecretWatches() error {
 		name := types.NamespacedName{Name: user.SecretName, Namespace: dc.Namespace}
 		names = append(names, name)
 	}
+
+	// Watch the Management API server certificate for renewals
+	provider, err := rc.getManagedCertificatesSecurityProvider()
+	if err != nil {
+		return err
+	}
+	if provider != nil {
+		name := types.NamespacedName{Name: provider.ServerSecretName(), Namespace: dc.Namespace}
+		names = append(names, name)
+	}
+
 	dcNamespacedName := types.NamespacedName{Name: dc.Name, Namespace: dc.Namespace,}
-	err := rc.SecretWatches.UpdateWatch(dcNamespacedName, names)
+	err = rc.SecretWatches.UpdateWatch(dcNamespacedName, names)
 
 	return err
 }
@@ -846,6 +878,42 @@ func (rc *ReconciliationContext) UpdateCassandraNodeStatus() error {
 	return nil
 }
 
+// updateRackStatuses counts the pods and the ready Cassandra nodes of each
+// rack. Racks that are no longer in the spec keep their entry only while the
+// operator is working on them, e.g. until they are removed.
+func (rc *ReconciliationContext) updateRackStatuses() {
+	dc := rc.Datacenter
+
+	specRacks := map[string]bool{}
+	for _, rack := range dc.GetRacks() {
+		specRacks[rack.Name] = true
+		if dc.Status.GetRackStatus(rack.Name) == nil {
+			dc.Status.SetRackStatus(api.RackStatus{Name: rack.Name})
+		}
+	}
+
+	for _, rackStatus := range dc.Status.RackStatuses {
+		if !specRacks[rackStatus.Name] && rackStatus.State == "" {
+			dc.Status.RemoveRackStatus(rackStatus.Name)
+		}
+	}
+
+	for idx := range dc.Status.RackStatuses {
+		rackStatus := &dc.Status.RackStatuses[idx]
+		rackPods := FilterPodListByLabels(rc.dcPods, dc.GetRackLabels(rackStatus.Name))
+
+		readyNodes := 0
+		for _, pod := range rackPods {
+			if isServerReady(pod) {
+				readyNodes++
+			}
+		}
+
+		rackStatus.Nodes = int32(len(rackPods))
+		rackStatus.ReadyNodes = int32(readyNodes)
+	}
+}
+
 func (rc *ReconciliationContext) updateCurrentReplacePodsProgress() error {
 	dc := rc.Datacenter
 	logger := rc.ReqLogger
@@ -926,6 +994,8 @@ func (rc *ReconciliationContext) UpdateStatus() result.ReconcileResult {
 		return result.Error(err)
 	}
 
+	rc.updateRackStatuses()
+
 	status = &api.CassandraDatacenterStatus{}
 	dc.Status.DeepCopyInto(status)
 	oldDc.Status.DeepCopyInto(&dc.Status)
@@ -1651,10 +1721,16 @@ func (rc *ReconciliationContext) startAllNodes(endpointData httphelper.CassMetad
 	return false, nil
 }
 
+// countReadyAndStarted counts the pods that are ready and the pods labeled
+// as started, leaving out the pods that are leaving the datacenter
 func (rc *ReconciliationContext) countReadyAndStarted() (int, int) {
 	ready := 0
 	started := 0
 	for _, pod := range rc.dcPods {
+		if rc.isPodLeaving(pod) {
+			continue
+		}
+
 		if isServerReady(pod) {
 			ready++
 			rc.ReqLogger.Info(
@@ -1791,29 +1867,98 @@ func (rc *ReconciliationContext) CheckRollingRestart() result.ReconcileResult {
 	}
 
 	cutoff := &dc.Status.LastRollingRestart
+	podsToRestart := []*corev1.Pod{}
 	for _, pod := range rc.dcPods {
-		podStartTime := pod.GetCreationTimestamp()
+		podStartTime := getPodRestartTime(pod)
 		if podStartTime.Before(cutoff) {
-			rc.Recorder.Eventf(rc.Datacenter, corev1.EventTypeNormal, events.RestartingCassandra,
-				"Restarting Cassandra for pod %s", pod.Name)
+			podsToRestart = append(podsToRestart, pod)
+		}
+	}
 
-			// drain the node
-			err := rc.NodeMgmtClient.CallDrainEndpoint(pod)
-			if err != nil {
-				logger.Error(err, "error during drain during rolling restart",
-					"pod", pod.Name)
-			}
+	if len(podsToRestart) == 0 {
+		return result.Continue()
+	}
+
+	if dc.GetMaxUnavailablePerRack() > 1 {
+		// restart as many pods of one rack at a time as we are allowed to
+		rackName := rc.getFirstRackWithPods(podsToRestart)
+		podsToRestart = rc.getRackRestartBatch(
+			rackName, FilterPodListByLabel(podsToRestart, api.RackLabel, rackName))
+		if len(podsToRestart) == 0 {
+			return result.RequeueSoon(10)
+		}
+	} else {
+		podsToRestart = podsToRestart[:1]
+	}
+
+	for _, pod := range podsToRestart {
+		rc.Recorder.Eventf(rc.Datacenter, corev1.EventTypeNormal, events.RestartingCassandra,
+			"Restarting Cassandra for pod %s", pod.Name)
+
+		// drain the node
+		err := rc.NodeMgmtClient.CallDrainEndpoint(pod)
+		if err != nil {
+			logger.Error(err, "error during drain during rolling restart",
+				"pod", pod.Name)
+		}
+
+		if dc.GetRestartStrategy() == api.RestartStrategyProcessRestart {
+			err = rc.restartCassandraProcess(pod)
+		} else {
 			// get a fresh pod
-			// TODO should we keep the pod and cycle the DB with mgmt api?
 			err = rc.Client.Delete(rc.Ctx, pod)
-			if err != nil {
-				return result.Error(err)
-			}
-			return result.Done()
+		}
+		if err != nil {
+			return result.Error(err)
 		}
 	}
 
-	return result.Continue()
+	if dc.GetRestartStrategy() == api.RestartStrategyProcessRestart {
+		return result.RequeueSoon(2)
+	}
+	return result.Done()
+}
+
+// restartCassandraProcess stops Cassandra in the pod and hands it back to
+// CheckPodsReady to be started again, the same way as a pod that was just
+// created. The next pod is not restarted until this one is ready, since
+// CheckPodsReady holds up the reconcile until every node is.
+func (rc *ReconciliationContext) restartCassandraProcess(pod *corev1.Pod) error {
+	logger := rc.ReqLogger
+
+	err := rc.NodeMgmtClient.CallLifecycleStopEndpoint(pod)
+	if err != nil {
+		logger.Error(err, "error stopping Cassandra during rolling restart",
+			"pod", pod.Name)
+		return err
+	}
+
+	patch := client.MergeFrom(pod.DeepCopy())
+	pod.Labels[api.CassNodeState] = stateReadyToStart
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[api.LastRestartAnnotation] = metav1.Now().Format(time.RFC3339)
+	err = rc.Client.Patch(rc.Ctx, pod, patch)
+	if err != nil {
+		logger.Error(err, "error labeling pod as ready to start after stopping Cassandra",
+			"pod", pod.Name)
+	}
+	return err
+}
+
+// getPodRestartTime returns when Cassandra was last restarted in the pod,
+// which is when the pod was created unless Cassandra has been restarted in
+// place since
+func getPodRestartTime(pod *corev1.Pod) *metav1.Time {
+	restartTime := pod.GetCreationTimestamp()
+	if value, ok := pod.Annotations[api.LastRestartAnnotation]; ok {
+		lastRestart, err := time.Parse(time.RFC3339, value)
+		if err == nil && lastRestart.After(restartTime.Time) {
+			restartTime = metav1.NewTime(lastRestart)
+		}
+	}
+	return &restartTime
 }
 
 func (rc *ReconciliationContext) setCondition(condition *api.DatacenterCondition) bool {
@@ -1896,6 +2041,7 @@ func (rc *ReconciliationContext) CheckClearActionConditions() result.ReconcileRe
 		api.DatacenterUpdating,
 		api.DatacenterRollingRestart,
 		api.DatacenterResuming,
+		api.DatacenterScalingDown,
 	}
 	updated := false
 
@@ -1950,79 +2096,112 @@ func (rc *ReconciliationContext) ReconcileAllRacks() (reconcile.Result, error) {
 
 	dcSelector := rc.Datacenter.GetDatacenterLabels()
 	rc.dcPods = FilterPodListByLabels(rc.clusterPods, dcSelector)
+	metrics.SetDatacenterPods(rc.Datacenter, rc.dcPods)
 
 	endpointData := rc.getCassMetadataEndpoints()
 
 	if recResult := rc.UpdateStatus(); recResult.Completed() {
-		return recResult.Output()
+		return rc.endReconcileAtStep("UpdateStatus", recResult)
 	}
 
 	if recResult := rc.CheckSuperuserSecretCreation(); recResult.Completed() {
-		return recResult.Output()
+		return rc.endReconcileAtStep("CheckSuperuserSecretCreation", recResult)
 	}
 
 	if recResult := rc.CheckRackCreation(); recResult.Completed() {
-		return recResult.Output()
+		return rc.endReconcileAtStep("CheckRackCreation", recResult)
 	}
 
 	if recResult := rc.CheckRackLabels(); recResult.Completed() {
-		return recResult.Output()
+		return rc.endReconcileAtStep("CheckRackLabels", recResult)
+	}
+
+	if recResult := rc.CheckVolumeExpansion(); recResult.Completed() {
+		return rc.endReconcileAtStep("CheckVolumeExpansion", recResult)
 	}
 
 	if recResult := rc.CheckRackStoppedState(); recResult.Completed() {
-		return recResult.Output()
+		return rc.endReconcileAtStep("CheckRackStoppedState", recResult)
 	}
 
 	if recResult := rc.CheckRackForceUpgrade(); recResult.Completed() {
-		return recResult.Output()
+		return rc.endReconcileAtStep("CheckRackForceUpgrade", recResult)
+	}
+
+	if recResult := rc.CheckDecommissioningNodes(); recResult.Completed() {
+		return rc.endReconcileAtStep("CheckDecommissioningNodes", recResult)
 	}
 
 	if recResult := rc.CheckRackScale(); recResult.Completed() {
-		return recResult.Output()
+		return rc.endReconcileAtStep("CheckRackScale", recResult)
+	}
+
+	if recResult := rc.CheckRestoreStaged(); recResult.Completed() {
+		return rc.endReconcileAtStep("CheckRestoreStaged", recResult)
 	}
 
 	if recResult := rc.CheckPodsReady(endpointData); recResult.Completed() {
-		return recResult.Output()
+		return rc.endReconcileAtStep("CheckPodsReady", recResult)
 	}
 
 	if recResult := rc.CheckCassandraNodeStatuses(); recResult.Completed() {
-		return recResult.Output()
+		return rc.endReconcileAtStep("CheckCassandraNodeStatuses", recResult)
+	}
+
+	if recResult := rc.DecommissionNodes(); recResult.Completed() {
+		return rc.endReconcileAtStep("DecommissionNodes", recResult)
+	}
+
+	if recResult := rc.CheckRackRemoval(); recResult.Completed() {
+		return rc.endReconcileAtStep("CheckRackRemoval", recResult)
 	}
 
 	if recResult := rc.CheckReaperService(); recResult.Completed() {
-		return recResult.Output()
+		return rc.endReconcileAtStep("CheckReaperService", recResult)
 	}
 
 	if recResult := rc.CheckReaperSchemaInitialized(); recResult.Completed() {
-		return recResult.Output()
+		return rc.endReconcileAtStep("CheckReaperSchemaInitialized", recResult)
+	}
+
+	if recResult := rc.CheckReaperDeployment(); recResult.Completed() {
+		return rc.endReconcileAtStep("CheckReaperDeployment", recResult)
+	}
+
+	if recResult := rc.CheckManagementApiCertificateRotation(); recResult.Completed() {
+		return rc.endReconcileAtStep("CheckManagementApiCertificateRotation", recResult)
 	}
 
 	if recResult := rc.CheckRollingRestart(); recResult.Completed() {
-		return recResult.Output()
+		return rc.endReconcileAtStep("CheckRollingRestart", recResult)
 	}
 
 	if recResult := rc.CheckDcPodDisruptionBudget(); recResult.Completed() {
-		return recResult.Output()
+		return rc.endReconcileAtStep("CheckDcPodDisruptionBudget", recResult)
 	}
 
 	if recResult := rc.CheckRackPodTemplate(); recResult.Completed() {
-		return recResult.Output()
+		return rc.endReconcileAtStep("CheckRackPodTemplate", recResult)
 	}
 
 	if recResult := rc.CheckRackPodLabels(); recResult.Completed() {
-		return recResult.Output()
+		return rc.endReconcileAtStep("CheckRackPodLabels", recResult)
 	}
 
 	if recResult := rc.CreateUsers(); recResult.Completed() {
-		return recResult.Output()
+		return rc.endReconcileAtStep("CreateUsers", recResult)
 	}
 
 	if recResult := rc.CheckClearActionConditions(); recResult.Completed() {
-		return recResult.Output()
+		return rc.endReconcileAtStep("CheckClearActionConditions", recResult)
 	}
 
 	if recResult := rc.CheckConditionInitializedAndReady(); recResult.Completed() {
-		return recResult.Output()
+		return rc.endReconcileAtStep("CheckConditionInitializedAndReady", recResult)
+	}
+
+	if recResult := rc.CheckSystemKeyspacesReplication(); recResult.Completed() {
+		return rc.endReconcileAtStep("CheckSystemKeyspacesReplication", recResult)
 	}
 
 	if err := setOperatorProgressStatus(rc, api.ProgressReady); err != nil {
@@ -2031,5 +2210,64 @@ func (rc *ReconciliationContext) ReconcileAllRacks() (reconcile.Result, error) {
 
 	rc.ReqLogger.Info("All StatefulSets should now be reconciled.")
 
-	return result.Done().Output()
+	// The last steps run in the background of a ready datacenter: Reaper can
+	// only register the cluster once its nodes are up, repairs wait for their
+	// schedule or for a node that is down elsewhere in the cluster, and the
+	// last steps of volume expansions only need the pods to be restarted.
+	// None of them holds up the others.
+	return rc.runBackgroundSteps([]backgroundStep{
+		{"CheckSystemAuthRepair", rc.CheckSystemAuthRepair},
+		{"CheckReaperSchedules", rc.CheckReaperSchedules},
+		{"CheckRepair", rc.CheckRepair},
+		{"CheckVolumeExpansionProgress", rc.CheckVolumeExpansionProgress},
+	})
+}
+
+// backgroundStep is a step of the reconcile that runs once the datacenter is
+// ready
+type backgroundStep struct {
+	name  string
+	check func() result.ReconcileResult
+}
+
+// runBackgroundSteps runs every step, then ends the reconcile at the first
+// step that failed, or else at the step that requeues the soonest
+func (rc *ReconciliationContext) runBackgroundSteps(steps []backgroundStep) (reconcile.Result, error) {
+	endStep := ""
+	var endResult result.ReconcileResult
+	var endRes reconcile.Result
+	var endErr error
+	for _, step := range steps {
+		recResult := step.check()
+		if !recResult.Completed() || endErr != nil {
+			continue
+		}
+
+		res, err := recResult.Output()
+		if endResult == nil || err != nil || requeuesSooner(res, endRes) {
+			endStep, endResult, endRes, endErr = step.name, recResult, res, err
+		}
+	}
+
+	if endResult == nil {
+		return result.Done().Output()
+	}
+	return rc.endReconcileAtStep(endStep, endResult)
+}
+
+// requeuesSooner tells whether the first result requeues before the second,
+// a result that does not requeue coming last
+func requeuesSooner(res, other reconcile.Result) bool {
+	if !res.Requeue {
+		return false
+	}
+	return !other.Requeue || res.RequeueAfter < other.RequeueAfter
+}
+
+// endReconcileAtStep returns the output of the step that ended the
+// reconcile of the racks early, recording which step it was
+func (rc *ReconciliationContext) endReconcileAtStep(step string, recResult result.ReconcileResult) (reconcile.Result, error) {
+	res, err := recResult.Output()
+	metrics.RecordReconcileStep(rc.Datacenter.Namespace, rc.Datacenter.Name, step, res, err)
+	return res, err
 }
@@ -13,6 +13,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/datastax/cass-operator/operator/internal/result"
 	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
 	"github.com/datastax/cass-operator/operator/pkg/httphelper"
 	"github.com/datastax/cass-operator/operator/pkg/mocks"
@@ -23,6 +24,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -1330,3 +1332,143 @@ func Test_callPodEndpoint_RequestFail(t *testing.T) {
 		assert.Fail(t, "Should have returned error")
 	}
 }
+
+func setupRollingRestartTest(t *testing.T, rc *ReconciliationContext) ([]*corev1.Pod, *[]*http.Request) {
+	statefulSet, err := newStatefulSetForCassandraDatacenter("default", rc.Datacenter, 2)
+	assert.NoErrorf(t, err, "error occurred creating statefulset")
+
+	pods := mockRunningPodsForRack(statefulSet, rc.Datacenter, "default")
+	trackObjects := []runtime.Object{rc.Datacenter}
+	for _, pod := range pods {
+		pod.CreationTimestamp = metav1.Date(2020, time.July, 1, 0, 0, 0, 0, time.UTC)
+		trackObjects = append(trackObjects, pod)
+	}
+	rc.Client = fake.NewFakeClient(trackObjects...)
+	rc.dcPods = pods
+	rc.Datacenter.Status.LastRollingRestart = metav1.Date(2020, time.July, 2, 0, 0, 0, 0, time.UTC)
+
+	mgmtApi := mocks.NewManagementApi(nil)
+	rc.NodeMgmtClient = httphelper.NodeMgmtClient{Client: mgmtApi.HttpClient(), Log: rc.ReqLogger, Protocol: "http"}
+
+	return pods, &mgmtApi.Requests
+}
+
+func TestCheckRollingRestart_PodDelete(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	pods, _ := setupRollingRestartTest(t, rc)
+
+	recResult := rc.CheckRollingRestart()
+	assert.True(t, recResult.Completed())
+
+	pod := &corev1.Pod{}
+	err := rc.Client.Get(rc.Ctx, types.NamespacedName{Name: pods[0].Name, Namespace: pods[0].Namespace}, pod)
+	assert.True(t, errors.IsNotFound(err), "Pod should be deleted by default")
+}
+
+func TestCheckRollingRestart_ProcessRestart(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	rc.Datacenter.Spec.RestartStrategy = api.RestartStrategyProcessRestart
+	pods, requests := setupRollingRestartTest(t, rc)
+
+	recResult := rc.CheckRollingRestart()
+	assert.True(t, recResult.Completed(), "Should wait for the node to be started again")
+
+	paths := []string{}
+	for _, req := range *requests {
+		assert.Equal(t, "10.0.0.1", req.URL.Hostname(), "Should only restart one node at a time")
+		paths = append(paths, req.URL.Path)
+	}
+	assert.Equal(t, []string{"/api/v0/ops/node/drain", "/api/v0/lifecycle/stop"}, paths)
+
+	pod := &corev1.Pod{}
+	err := rc.Client.Get(rc.Ctx, types.NamespacedName{Name: pods[0].Name, Namespace: pods[0].Namespace}, pod)
+	assert.NoError(t, err, "Pod should not be deleted")
+	assert.Equal(t, stateReadyToStart, pod.Labels[api.CassNodeState], "Pod should be started again like a new pod")
+	assert.False(t, getPodRestartTime(pod).Before(&rc.Datacenter.Status.LastRollingRestart))
+}
+
+func TestCheckRollingRestart_ProcessRestartDone(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	rc.Datacenter.Spec.RestartStrategy = api.RestartStrategyProcessRestart
+	pods, requests := setupRollingRestartTest(t, rc)
+	for _, pod := range pods {
+		pod.Annotations = map[string]string{
+			api.LastRestartAnnotation: "2020-07-02T00:05:00Z",
+		}
+	}
+
+	recResult := rc.CheckRollingRestart()
+	assert.False(t, recResult.Completed(), "Nodes restarted in place after the request should not be restarted again")
+	assert.Empty(t, *requests)
+}
+
+func TestUpdateRackStatuses(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	dc := rc.Datacenter
+	dc.Spec.Racks = []api.Rack{{Name: "r1"}, {Name: "r2"}}
+	dc.Status.RackStatuses = []api.RackStatus{
+		{Name: "r3", State: api.RackRemoving, RemainingNodes: 1},
+		{Name: "r4", Nodes: 3, ReadyNodes: 3},
+	}
+
+	statefulSet, err := newStatefulSetForCassandraDatacenter("r1", dc, 2)
+	assert.NoError(t, err)
+	pods := mockRunningPodsForRack(statefulSet, dc, "r1")
+	pods[1].Status.ContainerStatuses[0].Ready = false
+
+	removedStatefulSet, err := newStatefulSetForCassandraDatacenter("r3", dc, 1)
+	assert.NoError(t, err)
+	rc.dcPods = append(pods, mockRunningPodsForRack(removedStatefulSet, dc, "r3")...)
+
+	rc.updateRackStatuses()
+
+	assert.Equal(t, []api.RackStatus{
+		{Name: "r3", Nodes: 1, ReadyNodes: 1, State: api.RackRemoving, RemainingNodes: 1},
+		{Name: "r1", Nodes: 2, ReadyNodes: 1},
+		{Name: "r2"},
+	}, dc.Status.RackStatuses)
+}
+
+func TestRunBackgroundSteps(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	ran := []string{}
+	step := func(name string, recResult result.ReconcileResult) backgroundStep {
+		return backgroundStep{name, func() result.ReconcileResult {
+			ran = append(ran, name)
+			return recResult
+		}}
+	}
+
+	res, err := rc.runBackgroundSteps([]backgroundStep{
+		step("waiting", result.RequeueSoon(3600)),
+		step("continuing", result.Continue()),
+		step("busy", result.RequeueSoon(10)),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second}, res,
+		"Should requeue for the soonest step")
+	assert.Equal(t, []string{"waiting", "continuing", "busy"}, ran,
+		"Should not let a step waiting for later hold up the others")
+
+	_, err = rc.runBackgroundSteps([]backgroundStep{
+		step("busy", result.RequeueSoon(10)),
+		step("failing", result.Error(fmt.Errorf("failed"))),
+	})
+	assert.Error(t, err, "Should return the error of a failed step")
+
+	res, err = rc.runBackgroundSteps([]backgroundStep{
+		step("continuing", result.Continue()),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, reconcile.Result{}, res)
+}
@@ -7,6 +7,10 @@ import (
 	"fmt"
 	"github.com/datastax/cass-operator/operator/internal/result"
 	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/events"
+	"github.com/datastax/cass-operator/operator/pkg/oplabels"
+	"github.com/datastax/cass-operator/operator/pkg/reaper"
+	appsv1 "k8s.io/api/apps/v1"
 	v1batch "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -14,20 +18,24 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"math"
+	"reflect"
+	"sort"
 	"strconv"
 )
 
 const (
 	ReaperUIPort             = 7080
 	ReaperAdminPort          = 7081
-	ReaperDefaultImage     = "thelastpickle/cassandra-reaper:2.0.5"
-	ReaperDefaultPullPolicy  = corev1.PullIfNotPresent
 	ReaperContainerName      = "reaper"
 	ReaperHealthCheckPath    = "/healthcheck"
 	ReaperKeyspace           = "reaper_db"
 	ReaperSchemaInitJob      = "ReaperSchemaInitJob"
 	// This code currently lives at https://github.com/jsanda/create_keyspace.
 	ReaperSchemaInitJobImage = "jsanda/reaper-init-keyspace:latest"
+
+	jmxPort                 = 7199
+	jmxCredentialsVolume    = "jmx-credentials"
+	jmxCredentialsMountPath = "/etc/jmx-credentials"
 )
 
 func buildReaperContainer(dc *api.CassandraDatacenter) corev1.Container {
@@ -36,47 +44,124 @@ func buildReaperContainer(dc *api.CassandraDatacenter) corev1.Container {
 		{Name: "admin", ContainerPort: ReaperAdminPort, Protocol: "TCP"},
 	}
 
+	// A sidecar only talks to the JMX of its own node, while a Deployment
+	// talks to the JMX of every node of the datacenter
+	availability := "SIDECAR"
+	if dc.Spec.Reaper.GetDeploymentMode() == api.ReaperDeploymentModeDeployment {
+		availability = "LOCAL"
+	}
+
+	env := []corev1.EnvVar{
+		{Name: "REAPER_STORAGE_TYPE", Value: "cassandra"},
+		{Name: "REAPER_ENABLE_DYNAMIC_SEED_LIST", Value: "false"},
+		{Name: "REAPER_DATACENTER_AVAILABILITY", Value: availability},
+		{Name: "REAPER_SERVER_APP_PORT", Value: strconv.Itoa(ReaperUIPort)},
+		{Name: "REAPER_SERVER_ADMIN_PORT", Value: strconv.Itoa(ReaperAdminPort)},
+		{Name: "REAPER_CASS_CLUSTER_NAME", Value: dc.ClusterName},
+		{Name: "REAPER_CASS_CONTACT_POINTS", Value: fmt.Sprintf("[%s]", dc.GetSeedServiceName())},
+	}
+
+	if dc.Spec.Reaper.UISecretName != "" {
+		env = append(env,
+			corev1.EnvVar{Name: "REAPER_AUTH_ENABLED", Value: "true"},
+			secretKeyEnvVar("REAPER_AUTH_USER", dc.Spec.Reaper.UISecretName, "username"),
+			secretKeyEnvVar("REAPER_AUTH_PASSWORD", dc.Spec.Reaper.UISecretName, "password"))
+	} else {
+		env = append(env, corev1.EnvVar{Name: "REAPER_AUTH_ENABLED", Value: "false"})
+	}
+
+	if dc.Spec.Reaper.JmxSecretName != "" {
+		env = append(env,
+			secretKeyEnvVar("REAPER_JMX_AUTH_USERNAME", dc.Spec.Reaper.JmxSecretName, "username"),
+			secretKeyEnvVar("REAPER_JMX_AUTH_PASSWORD", dc.Spec.Reaper.JmxSecretName, "password"))
+	} else {
+		env = append(env,
+			corev1.EnvVar{Name: "REAPER_JMX_AUTH_USERNAME", Value: ""},
+			corev1.EnvVar{Name: "REAPER_JMX_AUTH_PASSWORD", Value: ""})
+	}
+
 	container := corev1.Container{
 		Name: ReaperContainerName,
-		Image: getReaperImage(dc),
-		ImagePullPolicy: getReaperPullPolicy(dc),
+		Image: dc.GetReaperImage(),
+		ImagePullPolicy: dc.Spec.Reaper.GetImagePullPolicy(),
 		Ports: ports,
 		LivenessProbe: probe(ReaperAdminPort, ReaperHealthCheckPath, int(60 * dc.Spec.Size), 10),
 		ReadinessProbe: probe(ReaperAdminPort, ReaperHealthCheckPath, 30, 15),
-		Env: []corev1.EnvVar{
-			{Name: "REAPER_STORAGE_TYPE", Value: "cassandra"},
-			{Name: "REAPER_ENABLE_DYNAMIC_SEED_LIST", Value: "false"},
-			{Name: "REAPER_DATACENTER_AVAILABILITY", Value: "SIDECAR"},
-			{Name: "REAPER_SERVER_APP_PORT", Value: strconv.Itoa(ReaperUIPort)},
-			{Name: "REAPER_SERVER_ADMIN_PORT", Value: strconv.Itoa(ReaperAdminPort)},
-			{Name: "REAPER_CASS_CLUSTER_NAME", Value: dc.ClusterName},
-			{Name: "REAPER_CASS_CONTACT_POINTS", Value: fmt.Sprintf("[%s]", dc.GetSeedServiceName())},
-			{Name: "REAPER_AUTH_ENABLED", Value: "false"},
-			{Name: "REAPER_JMX_AUTH_USERNAME", Value: ""},
-			{Name: "REAPER_JMX_AUTH_PASSWORD", Value: ""},
-		},
+		Env: env,
 	}
 
 	return container
 }
 
-func getReaperImage(dc *api.CassandraDatacenter) string {
-	if len(dc.Spec.Reaper.Image) == 0 {
-		return ReaperDefaultImage
+// buildRemoteJmxEnv returns the environment of the Cassandra container that
+// lets a Reaper Deployment reach its JMX from outside of the pod, requiring
+// the JMX credentials of the datacenter from JMX clients
+func buildRemoteJmxEnv(dc *api.CassandraDatacenter) []corev1.EnvVar {
+	jvmOpts := fmt.Sprintf("-Dcom.sun.management.jmxremote.password.file=%s/jmxremote.password"+
+		" -Dcom.sun.management.jmxremote.access.file=%s/jmxremote.access",
+		jmxCredentialsMountPath, jmxCredentialsMountPath)
+
+	return []corev1.EnvVar{
+		{Name: "LOCAL_JMX", Value: "no"},
+		{Name: "JVM_EXTRA_OPTS", Value: jvmOpts},
+	}
+}
+
+// buildJmxCredentialsInitContainer returns the container writing the JMX
+// password and access files from the JMX credentials of the datacenter. It
+// runs the server image, so that the files belong to the user running the
+// server, which the JVM requires of the password file.
+func buildJmxCredentialsInitContainer(dc *api.CassandraDatacenter, serverImage string) corev1.Container {
+	script := fmt.Sprintf("umask 077"+
+		" && printf '%%s %%s\\n' \"$JMX_USERNAME\" \"$JMX_PASSWORD\" > %[1]s/jmxremote.password"+
+		" && printf '%%s readwrite\\n' \"$JMX_USERNAME\" > %[1]s/jmxremote.access",
+		jmxCredentialsMountPath)
+
+	return corev1.Container{
+		Name:    "jmx-credentials-init",
+		Image:   serverImage,
+		Command: []string{"/bin/sh", "-c", script},
+		Env: []corev1.EnvVar{
+			secretKeyEnvVar("JMX_USERNAME", dc.Spec.Reaper.JmxSecretName, "username"),
+			secretKeyEnvVar("JMX_PASSWORD", dc.Spec.Reaper.JmxSecretName, "password"),
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: jmxCredentialsVolume, MountPath: jmxCredentialsMountPath},
+		},
 	}
-	return dc.Spec.Reaper.Image
 }
 
-func getReaperPullPolicy(dc *api.CassandraDatacenter) corev1.PullPolicy {
-	if len(dc.Spec.Reaper.ImagePullPolicy) == 0 {
-		return ReaperDefaultPullPolicy
+// secretKeyEnvVar returns an environment variable set from a key of a Secret
+func secretKeyEnvVar(name, secretName, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Key:                  key,
+			},
+		},
 	}
-	return dc.Spec.Reaper.ImagePullPolicy
+}
+
+// isReaperSidecarEnabled tells whether the Cassandra pods of the datacenter
+// run Reaper as a sidecar
+func isReaperSidecarEnabled(dc *api.CassandraDatacenter) bool {
+	return dc.Spec.Reaper != nil && dc.Spec.Reaper.Enabled && dc.Spec.ServerType == "cassandra" &&
+		dc.Spec.Reaper.GetDeploymentMode() == api.ReaperDeploymentModeSidecar
+}
+
+// isReaperDeploymentEnabled tells whether the datacenter runs Reaper in a
+// Deployment of its own
+func isReaperDeploymentEnabled(dc *api.CassandraDatacenter) bool {
+	return dc.Spec.Reaper != nil && dc.Spec.Reaper.Enabled && dc.Spec.ServerType == "cassandra" &&
+		dc.Spec.Reaper.GetDeploymentMode() == api.ReaperDeploymentModeDeployment
 }
 
 func (rc *ReconciliationContext) CheckReaperSchemaInitialized() result.ReconcileResult {
 	// Using a job eventually get replaced with calls to the mgmt api once it has support for
-	// creating keyspaces and tables.
+	// creating keyspaces and tables. Keyspaces can be created with it now, see the schema
+	// package, but the job also creates the tables of Reaper, which it cannot.
 
 	rc.ReqLogger.Info("reconcile_reaper::CheckReaperSchemaInitialized")
 
@@ -129,9 +214,16 @@ func jobFinished(job *v1batch.Job) bool {
 	return false
 }
 
+// CheckReaperService creates the Service of the Reaper UI of the datacenter,
+// keeps it pointed at the pods that run Reaper, and deletes it when Reaper is
+// disabled. Only a Service that the datacenter controls is changed.
 func (rc *ReconciliationContext) CheckReaperService() result.ReconcileResult {
 	rc.ReqLogger.Info("reconcile_reaper::CheckReaperService")
 
+	if err := rc.deleteLegacyReaperService(); err != nil {
+		return result.Error(err)
+	}
+
 	serviceName := getReaperServiceName(rc.Datacenter)
 	service := &corev1.Service{}
 
@@ -153,15 +245,55 @@ func (rc *ReconciliationContext) CheckReaperService() result.ReconcileResult {
 		}
 	} else if err != nil {
 		return result.Error(err)
+	} else if !metav1.IsControlledBy(service, rc.Datacenter) {
+		rc.ReqLogger.Info("Reaper service is not controlled by the datacenter, leaving it alone",
+			"ReaperService", serviceName)
 	} else if rc.Datacenter.Spec.Reaper == nil || !rc.Datacenter.Spec.Reaper.Enabled {
 		if err := rc.Client.Delete(rc.Ctx, service); err != nil {
 			rc.ReqLogger.Error(err, "failed to delete Reaper service", "ReaperService", serviceName)
 		}
+	} else if selector := getReaperSelector(rc.Datacenter); !reflect.DeepEqual(service.Spec.Selector, selector) {
+		// The deployment mode changed, so the service has to point at the
+		// other pods
+		rc.ReqLogger.Info("updating Reaper service selector")
+		service.Spec.Selector = selector
+		if err := rc.Client.Update(rc.Ctx, service); err != nil {
+			rc.ReqLogger.Error(err, "failed to update Reaper service", "ReaperService", serviceName)
+			return result.Error(err)
+		}
 	}
 	return result.Continue()
 }
 
+// deleteLegacyReaperService deletes the Reaper Service that earlier versions
+// of the operator named after the cluster alone, when the datacenter controls
+// it. The datacenters of a cluster each have a Service of their own now.
+func (rc *ReconciliationContext) deleteLegacyReaperService() error {
+	service := &corev1.Service{}
+	name := types.NamespacedName{Namespace: rc.Datacenter.Namespace, Name: getLegacyReaperServiceName(rc.Datacenter)}
+	if err := rc.Client.Get(rc.Ctx, name, service); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !metav1.IsControlledBy(service, rc.Datacenter) {
+		return nil
+	}
+
+	rc.ReqLogger.Info("deleting legacy Reaper service", "ReaperService", name.Name)
+	if err := rc.Client.Delete(rc.Ctx, service); err != nil && !errors.IsNotFound(err) {
+		rc.ReqLogger.Error(err, "failed to delete legacy Reaper service", "ReaperService", name.Name)
+		return err
+	}
+	return nil
+}
+
 func getReaperServiceName(dc *api.CassandraDatacenter) string {
+	return fmt.Sprintf("%s-%s-reaper-service", dc.Spec.ClusterName, dc.Name)
+}
+
+func getLegacyReaperServiceName(dc *api.CassandraDatacenter) string {
 	return fmt.Sprintf("%s-reaper-service", dc.Spec.ClusterName)
 }
 
@@ -188,7 +320,303 @@ func newReaperService(dc *api.CassandraDatacenter) *corev1.Service {
 					},
 				},
 			},
-			Selector: dc.GetDatacenterLabels(),
+			Selector: getReaperSelector(dc),
 		},
 	}
-}
\ No newline at end of file
+}
+
+// getReaperSelector returns the labels of the pods that run Reaper, which are
+// the Cassandra pods in sidecar mode
+func getReaperSelector(dc *api.CassandraDatacenter) map[string]string {
+	if dc.Spec.Reaper != nil && dc.Spec.Reaper.GetDeploymentMode() == api.ReaperDeploymentModeDeployment {
+		return getReaperDeploymentLabels(dc)
+	}
+	return dc.GetDatacenterLabels()
+}
+
+// getReaperDeploymentLabels returns the labels of the pods of the Reaper
+// Deployment. They deliberately leave out the datacenter and cluster labels,
+// which would make the pods look like Cassandra nodes.
+func getReaperDeploymentLabels(dc *api.CassandraDatacenter) map[string]string {
+	return map[string]string{
+		api.ReaperLabel: dc.Name,
+	}
+}
+
+func getReaperDeploymentName(dc *api.CassandraDatacenter) string {
+	return fmt.Sprintf("%s-%s-reaper", dc.Spec.ClusterName, dc.Name)
+}
+
+// CheckReaperDeployment creates or updates the Reaper Deployment of the
+// datacenter when Reaper runs in Deployment mode, and deletes it otherwise
+func (rc *ReconciliationContext) CheckReaperDeployment() result.ReconcileResult {
+	logger := rc.ReqLogger
+	dc := rc.Datacenter
+
+	logger.Info("reconcile_reaper::CheckReaperDeployment")
+
+	deploymentName := getReaperDeploymentName(dc)
+	current := &appsv1.Deployment{}
+	err := rc.Client.Get(rc.Ctx, types.NamespacedName{Namespace: dc.Namespace, Name: deploymentName}, current)
+	if err != nil && !errors.IsNotFound(err) {
+		return result.Error(err)
+	}
+	found := err == nil
+
+	if !isReaperDeploymentEnabled(dc) {
+		if found {
+			logger.Info("deleting Reaper deployment", "ReaperDeployment", deploymentName)
+			if err := rc.Client.Delete(rc.Ctx, current); err != nil && !errors.IsNotFound(err) {
+				logger.Error(err, "failed to delete Reaper deployment", "ReaperDeployment", deploymentName)
+				return result.Error(err)
+			}
+		}
+		return result.Continue()
+	}
+
+	desired := newReaperDeployment(dc)
+	if err := setControllerReference(dc, desired, rc.Scheme); err != nil {
+		logger.Error(err, "failed to set owner reference", "ReaperDeployment", deploymentName)
+		return result.Error(err)
+	}
+
+	if !found {
+		logger.Info("creating Reaper deployment", "ReaperDeployment", deploymentName)
+		if err := rc.Client.Create(rc.Ctx, desired); err != nil {
+			logger.Error(err, "failed to create Reaper deployment", "ReaperDeployment", deploymentName)
+			return result.Error(err)
+		}
+		return result.Continue()
+	}
+
+	if !resourcesHaveSameHash(current, desired) {
+		logger.Info("updating Reaper deployment", "ReaperDeployment", deploymentName)
+		current.SetLabels(desired.GetLabels())
+		current.SetAnnotations(desired.GetAnnotations())
+		current.Spec = desired.Spec
+		if err := rc.Client.Update(rc.Ctx, current); err != nil {
+			logger.Error(err, "failed to update Reaper deployment", "ReaperDeployment", deploymentName)
+			return result.Error(err)
+		}
+	}
+
+	return result.Continue()
+}
+
+// newReaperDeployment returns a Deployment running a single Reaper instance
+// for the datacenter
+func newReaperDeployment(dc *api.CassandraDatacenter) *appsv1.Deployment {
+	podLabels := getReaperDeploymentLabels(dc)
+	oplabels.AddManagedByLabel(podLabels)
+
+	labels := dc.GetDatacenterLabels()
+	oplabels.AddManagedByLabel(labels)
+
+	replicas := int32(1)
+	deployment := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Deployment",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getReaperDeploymentName(dc),
+			Namespace: dc.Namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: getReaperDeploymentLabels(dc),
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: podLabels,
+				},
+				Spec: corev1.PodSpec{
+					ImagePullSecrets: dc.Spec.ImagePullSecrets,
+					Containers:       []corev1.Container{buildReaperContainer(dc)},
+				},
+			},
+		},
+	}
+	addHashAnnotation(deployment)
+
+	return deployment
+}
+
+// The values of the repairParallelism parameter of the Reaper REST API
+var reaperRepairParallelismOptions = map[api.RepairParallelism]string{
+	api.RepairParallelismSequential:      "SEQUENTIAL",
+	api.RepairParallelismParallel:        "PARALLEL",
+	api.RepairParallelismDatacenterAware: "DATACENTER_AWARE",
+}
+
+// reaperBaseURL returns the URL of the Reaper REST API of the datacenter. It
+// is a variable so that tests can point it at a stub.
+var reaperBaseURL = func(dc *api.CassandraDatacenter) string {
+	return fmt.Sprintf("http://%s.%s.svc:%d", getReaperServiceName(dc), dc.Namespace, ReaperUIPort)
+}
+
+// CheckReaperSchedules registers the cluster with Reaper once Reaper is
+// healthy, then creates the repair schedules of spec.reaper.schedules that
+// are missing in Reaper and deletes the ones the operator created for this
+// datacenter that are no longer in the spec. Schedules created for other
+// datacenters of the cluster are left to them.
+func (rc *ReconciliationContext) CheckReaperSchedules() result.ReconcileResult {
+	logger := rc.ReqLogger
+	dc := rc.Datacenter
+
+	if !isReaperSidecarEnabled(dc) && !isReaperDeploymentEnabled(dc) {
+		return result.Continue()
+	}
+
+	logger.Info("reconcile_reaper::CheckReaperSchedules")
+
+	client, err := rc.newReaperClient()
+	if err != nil {
+		logger.Error(err, "failed to create Reaper client")
+		return result.Error(err)
+	}
+
+	if !client.IsHealthy() {
+		logger.Info("Waiting for Reaper to be healthy")
+		return result.RequeueSoon(10)
+	}
+
+	clusterName := reaper.GetClusterName(dc.Spec.ClusterName)
+	exists, err := client.ClusterExists(clusterName)
+	if err != nil {
+		logger.Error(err, "failed to get cluster from Reaper")
+		return result.Error(err)
+	}
+	if !exists {
+		if err := client.AddCluster(clusterName, dc.GetSeedServiceName()); err != nil {
+			logger.Error(err, "failed to register cluster with Reaper")
+			return result.Error(err)
+		}
+		rc.Recorder.Eventf(dc, corev1.EventTypeNormal, events.RegisteredReaperCluster,
+			"Registered cluster %s with Reaper", clusterName)
+	}
+
+	schedules, err := client.GetRepairSchedules(clusterName)
+	if err != nil {
+		logger.Error(err, "failed to get repair schedules from Reaper")
+		return result.Error(err)
+	}
+
+	owner := reaper.GetOwner(dc.Namespace, dc.Name)
+	desired := dc.Spec.Reaper.Schedules
+	found := make([]bool, len(desired))
+	for _, schedule := range schedules {
+		if schedule.Owner != owner {
+			continue
+		}
+
+		idx := findReaperSchedule(desired, found, schedule)
+		if idx >= 0 {
+			found[idx] = true
+			continue
+		}
+
+		if err := client.DeleteRepairSchedule(schedule.ID, owner); err != nil {
+			logger.Error(err, "failed to delete Reaper repair schedule", "id", schedule.ID)
+			return result.Error(err)
+		}
+		rc.Recorder.Eventf(dc, corev1.EventTypeNormal, events.DeletedReaperSchedule,
+			"Deleted Reaper repair schedule of keyspace %s", schedule.KeyspaceName)
+	}
+
+	for idx, schedule := range desired {
+		if found[idx] {
+			continue
+		}
+
+		err := client.CreateRepairSchedule(reaper.RepairScheduleRequest{
+			ClusterName:          clusterName,
+			Owner:                owner,
+			Keyspace:             schedule.Keyspace,
+			Tables:               schedule.Tables,
+			Intensity:            schedule.Intensity,
+			RepairParallelism:    reaperRepairParallelismOptions[schedule.Parallelism],
+			ScheduledDaysBetween: schedule.GetIntervalDays(),
+		})
+		if err != nil {
+			logger.Error(err, "failed to create Reaper repair schedule", "keyspace", schedule.Keyspace)
+			return result.Error(err)
+		}
+		rc.Recorder.Eventf(dc, corev1.EventTypeNormal, events.CreatedReaperSchedule,
+			"Created Reaper repair schedule of keyspace %s", schedule.Keyspace)
+	}
+
+	return result.Continue()
+}
+
+// newReaperClient returns a client for the Reaper REST API, logged in with
+// the credentials of spec.reaper.uiSecretName when it is set
+func (rc *ReconciliationContext) newReaperClient() (*reaper.Client, error) {
+	dc := rc.Datacenter
+
+	username, password := "", ""
+	if dc.Spec.Reaper.UISecretName != "" {
+		secret := &corev1.Secret{}
+		nsName := types.NamespacedName{Namespace: dc.Namespace, Name: dc.Spec.Reaper.UISecretName}
+		if err := rc.Client.Get(rc.Ctx, nsName, secret); err != nil {
+			return nil, err
+		}
+		username = string(secret.Data["username"])
+		password = string(secret.Data["password"])
+		if username == "" || password == "" {
+			return nil, fmt.Errorf("secret %s must have username and password keys", secret.Name)
+		}
+	}
+
+	return reaper.NewClient(reaperBaseURL(dc), username, password)
+}
+
+// findReaperSchedule returns the index of the first desired schedule not
+// found yet that the Reaper schedule matches, or -1
+func findReaperSchedule(desired []api.ReaperRepairSchedule, found []bool, schedule reaper.RepairSchedule) int {
+	for idx := range desired {
+		if !found[idx] && reaperScheduleMatches(desired[idx], schedule) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// reaperScheduleMatches tells whether the Reaper schedule repairs the way the
+// desired schedule asks for. The intensity and parallelism match anything
+// when they are not set, since Reaper picks them then.
+func reaperScheduleMatches(desired api.ReaperRepairSchedule, schedule reaper.RepairSchedule) bool {
+	if desired.Keyspace != schedule.KeyspaceName ||
+		desired.GetIntervalDays() != schedule.ScheduledDaysBetween {
+		return false
+	}
+
+	desiredTables := append([]string{}, desired.Tables...)
+	tables := append([]string{}, schedule.ColumnFamilies...)
+	sort.Strings(desiredTables)
+	sort.Strings(tables)
+	if len(desiredTables) != len(tables) {
+		return false
+	}
+	for idx := range tables {
+		if desiredTables[idx] != tables[idx] {
+			return false
+		}
+	}
+
+	if desired.Intensity != "" {
+		intensity, err := strconv.ParseFloat(desired.Intensity, 64)
+		if err != nil || math.Abs(intensity-schedule.Intensity) > 1e-6 {
+			return false
+		}
+	}
+
+	if desired.Parallelism != "" &&
+		reaperRepairParallelismOptions[desired.Parallelism] != schedule.RepairParallelism {
+		return false
+	}
+
+	return true
+}
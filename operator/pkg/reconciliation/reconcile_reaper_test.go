@@ -3,12 +3,15 @@ package reconciliation
 import (
 	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
 	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
 	v1batch "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"net/http/httptest"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"testing"
 )
@@ -33,6 +36,33 @@ func TestReconcileReaper_buildInitReaperSchemaJob(t *testing.T) {
 	assert.ElementsMatch(t, expectedEnvVars, container.Env)
 }
 
+func TestReconcileReaper_buildInitReaperSchemaJobImageRegistry(t *testing.T) {
+	dc := newCassandraDatacenter()
+	dc.Spec.ImageRegistry = "registry.example.com:5000"
+	dc.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry-credentials"}}
+	job := buildInitReaperSchemaJob(dc)
+
+	assert.Equal(t, "registry.example.com:5000/jsanda/reaper-init-keyspace:latest", job.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, dc.Spec.ImagePullSecrets, job.Spec.Template.Spec.ImagePullSecrets)
+}
+
+func TestReconcileReaper_newReaperDeploymentImageRegistry(t *testing.T) {
+	dc := newCassandraDatacenter()
+	dc.Spec.ImageRegistry = "registry.example.com:5000"
+	dc.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry-credentials"}}
+	dc.Spec.Reaper = &api.ReaperConfig{Enabled: true, DeploymentMode: api.ReaperDeploymentModeDeployment}
+	deployment := newReaperDeployment(dc)
+
+	assert.Equal(t, "registry.example.com:5000/thelastpickle/cassandra-reaper:2.0.5",
+		deployment.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, dc.Spec.ImagePullSecrets, deployment.Spec.Template.Spec.ImagePullSecrets)
+
+	dc.Spec.Reaper.Image = "example/cassandra-reaper:custom"
+	deployment = newReaperDeployment(dc)
+	assert.Equal(t, "example/cassandra-reaper:custom", deployment.Spec.Template.Spec.Containers[0].Image,
+		"should not apply the registry to an explicit image")
+}
+
 func TestReconcileReaper_newReaperService(t *testing.T) {
 	dc := newCassandraDatacenter()
 	service := newReaperService(dc)
@@ -46,6 +76,42 @@ func TestReconcileReaper_newReaperService(t *testing.T) {
 	assert.Equal(t, dc.GetDatacenterLabels(), service.Spec.Selector)
 }
 
+func TestReconcileReaper_newReaperServiceDeploymentMode(t *testing.T) {
+	dc := newCassandraDatacenter()
+	dc.Spec.Reaper = &api.ReaperConfig{Enabled: true, DeploymentMode: api.ReaperDeploymentModeDeployment}
+	service := newReaperService(dc)
+
+	assert.Equal(t, map[string]string{api.ReaperLabel: dc.Name}, service.Spec.Selector)
+}
+
+func TestReconcileReaper_buildReaperContainerSecrets(t *testing.T) {
+	dc := newCassandraDatacenter()
+	dc.Spec.Reaper = &api.ReaperConfig{
+		Enabled:       true,
+		UISecretName:  "reaper-ui",
+		JmxSecretName: "reaper-jmx",
+	}
+	container := buildReaperContainer(dc)
+
+	env := map[string]corev1.EnvVar{}
+	for _, envVar := range container.Env {
+		env[envVar.Name] = envVar
+	}
+
+	assert.Equal(t, "true", env["REAPER_AUTH_ENABLED"].Value)
+	assert.Equal(t, "SIDECAR", env["REAPER_DATACENTER_AVAILABILITY"].Value)
+	for name, secret := range map[string]string{
+		"REAPER_AUTH_USER":         "reaper-ui",
+		"REAPER_AUTH_PASSWORD":     "reaper-ui",
+		"REAPER_JMX_AUTH_USERNAME": "reaper-jmx",
+		"REAPER_JMX_AUTH_PASSWORD": "reaper-jmx",
+	} {
+		if assert.NotNil(t, env[name].ValueFrom, "%s should come from a secret", name) {
+			assert.Equal(t, secret, env[name].ValueFrom.SecretKeyRef.Name)
+		}
+	}
+}
+
 func TestReconcileReaper_CheckReaperSchemaInitialized(t *testing.T) {
 	rc, _, cleanupMockScr := setupTest()
 	rc.Datacenter.Spec.Reaper = &api.ReaperConfig{Enabled: true}
@@ -117,6 +183,9 @@ func TestReconcileReaper_CheckReaperServiceReaperDisabled(t *testing.T) {
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: rc.Datacenter.Namespace,
 			Name: serviceName,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(rc.Datacenter, api.SchemeGroupVersion.WithKind("CassandraDatacenter")),
+			},
 		},
 	}
 
@@ -132,6 +201,221 @@ func TestReconcileReaper_CheckReaperServiceReaperDisabled(t *testing.T) {
 	assert.True(t, errors.IsNotFound(err), "did not expect to find service %s", serviceName)
 }
 
+func TestReconcileReaper_CheckReaperServiceTwoDatacenters(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+	// The services have to be owned by their datacenter
+	cleanupMockScr()
+
+	dc1 := rc.Datacenter
+	dc1.UID = "dc1-uid"
+	dc1.Spec.Reaper = &api.ReaperConfig{Enabled: true}
+
+	dc2 := dc1.DeepCopy()
+	dc2.Name = "dc2"
+	dc2.UID = "dc2-uid"
+	dc2.Spec.Reaper = &api.ReaperConfig{Enabled: true, DeploymentMode: api.ReaperDeploymentModeDeployment}
+
+	// Created by an earlier version of the operator, for the first datacenter
+	legacyService := newReaperService(dc1)
+	legacyService.Name = getLegacyReaperServiceName(dc1)
+	legacyService.OwnerReferences = []metav1.OwnerReference{
+		*metav1.NewControllerRef(dc1, api.SchemeGroupVersion.WithKind("CassandraDatacenter")),
+	}
+
+	rc.Client = fake.NewFakeClient(dc1, dc2, legacyService)
+	rc2 := *rc
+	rc2.Datacenter = dc2
+
+	getService := func(name string) (*corev1.Service, error) {
+		service := &corev1.Service{}
+		err := rc.Client.Get(rc.Ctx, types.NamespacedName{Namespace: dc1.Namespace, Name: name}, service)
+		return service, err
+	}
+
+	assert.False(t, rc2.CheckReaperService().Completed())
+	_, err := getService(legacyService.Name)
+	assert.NoError(t, err, "should not delete the legacy service of another datacenter")
+
+	for i := 0; i < 2; i++ {
+		assert.False(t, rc.CheckReaperService().Completed())
+		assert.False(t, rc2.CheckReaperService().Completed())
+	}
+
+	_, err = getService(legacyService.Name)
+	assert.True(t, errors.IsNotFound(err), "should delete the legacy service of the datacenter")
+
+	assert.NotEqual(t, getReaperServiceName(dc1), getReaperServiceName(dc2))
+	for _, dc := range []*api.CassandraDatacenter{dc1, dc2} {
+		service, err := getService(getReaperServiceName(dc))
+		if assert.NoErrorf(t, err, "failed to get the Reaper service of %s", dc.Name) {
+			assert.True(t, metav1.IsControlledBy(service, dc))
+			assert.Equal(t, getReaperSelector(dc), service.Spec.Selector)
+		}
+	}
+
+	// A service of the same name that the datacenter does not control is
+	// left alone
+	service, _ := getService(getReaperServiceName(dc1))
+	service.OwnerReferences = []metav1.OwnerReference{
+		*metav1.NewControllerRef(dc2, api.SchemeGroupVersion.WithKind("CassandraDatacenter")),
+	}
+	assert.NoError(t, rc.Client.Update(rc.Ctx, service))
+	dc1.Spec.Reaper.DeploymentMode = api.ReaperDeploymentModeDeployment
+
+	assert.False(t, rc.CheckReaperService().Completed())
+	service, _ = getService(getReaperServiceName(dc1))
+	assert.Equal(t, dc1.GetDatacenterLabels(), service.Spec.Selector)
+}
+
+func TestReconcileReaper_CheckReaperDeployment(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	rc.Datacenter.Spec.ServerType = "cassandra"
+	rc.Datacenter.Spec.ServerVersion = "3.11.6"
+	rc.Datacenter.Spec.Reaper = &api.ReaperConfig{Enabled: true, DeploymentMode: api.ReaperDeploymentModeDeployment}
+
+	trackObjects := []runtime.Object{rc.Datacenter}
+
+	rc.Client = fake.NewFakeClient(trackObjects...)
+
+	reconcileResult := rc.CheckReaperDeployment()
+	assert.False(t, reconcileResult.Completed())
+
+	deployment := &appsv1.Deployment{}
+	deploymentName := getReaperDeploymentName(rc.Datacenter)
+	err := rc.Client.Get(rc.Ctx, types.NamespacedName{Namespace: rc.Datacenter.Namespace, Name: deploymentName}, deployment)
+	if assert.NoErrorf(t, err, "failed to get deployment %s", deploymentName) {
+		podLabels := deployment.Spec.Template.Labels
+		assert.Equal(t, rc.Datacenter.Name, podLabels[api.ReaperLabel])
+		assert.NotContains(t, podLabels, api.DatacenterLabel, "Reaper pods should not look like Cassandra pods")
+		assert.Equal(t, 1, len(deployment.Spec.Template.Spec.Containers))
+	}
+
+	// The cassandra pods should not get the sidecar
+	containers, err := buildContainers(rc.Datacenter, nil)
+	assert.NoError(t, err)
+	for _, container := range containers {
+		assert.NotEqual(t, ReaperContainerName, container.Name)
+	}
+}
+
+func TestReconcileReaper_CheckReaperDeploymentSidecarMode(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	rc.Datacenter.Spec.ServerType = "cassandra"
+	rc.Datacenter.Spec.ServerVersion = "3.11.6"
+	rc.Datacenter.Spec.Reaper = &api.ReaperConfig{Enabled: true}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: rc.Datacenter.Namespace,
+			Name:      getReaperDeploymentName(rc.Datacenter),
+		},
+	}
+
+	trackObjects := []runtime.Object{rc.Datacenter, deployment}
+
+	rc.Client = fake.NewFakeClient(trackObjects...)
+
+	reconcileResult := rc.CheckReaperDeployment()
+	assert.False(t, reconcileResult.Completed())
+
+	err := rc.Client.Get(rc.Ctx, types.NamespacedName{Namespace: deployment.Namespace, Name: deployment.Name}, deployment)
+	assert.True(t, errors.IsNotFound(err), "did not expect to find deployment %s", deployment.Name)
+}
+
+func TestReconcileReaper_CheckReaperSchedules(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	rc.Datacenter.Spec.ServerType = "cassandra"
+	rc.Datacenter.Spec.Reaper = &api.ReaperConfig{
+		Enabled: true,
+		Schedules: []api.ReaperRepairSchedule{
+			{Keyspace: "app", Intensity: "0.5", Parallelism: api.RepairParallelismParallel},
+			{Keyspace: "users", Tables: []string{"profiles"}, IntervalDays: 3},
+		},
+	}
+	rc.Client = fake.NewFakeClient(rc.Datacenter)
+
+	// The cluster is not registered yet, the schedule of app is up to date,
+	// the one of orders was removed from the spec, the one of audit was
+	// created through the Reaper UI and the one of events belongs to another
+	// datacenter of the cluster
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.URL.Path == "/ping":
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodGet && r.URL.Path == "/cluster/cassandradatacenter-example-cluster":
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodPut && r.URL.Path == "/cluster/cassandradatacenter-example-cluster":
+			assert.Equal(t, rc.Datacenter.GetSeedServiceName(), r.URL.Query().Get("seedHost"))
+			w.WriteHeader(http.StatusCreated)
+		case r.URL.Path == "/repair_schedule/cluster/cassandradatacenter-example-cluster":
+			_, _ = w.Write([]byte(`[` +
+				`{"id":"1","owner":"cass-operator/default/cassandradatacenter-example","keyspace_name":"app","column_families":[],"intensity":0.5,` +
+				`"repair_parallelism":"PARALLEL","scheduled_days_between":7},` +
+				`{"id":"2","owner":"cass-operator/default/cassandradatacenter-example","keyspace_name":"orders","column_families":[],"intensity":0.9,` +
+				`"repair_parallelism":"DATACENTER_AWARE","scheduled_days_between":7},` +
+				`{"id":"3","owner":"admin","keyspace_name":"audit","column_families":[],"intensity":0.9,` +
+				`"repair_parallelism":"DATACENTER_AWARE","scheduled_days_between":7},` +
+				`{"id":"4","owner":"cass-operator/default/dc2","keyspace_name":"events","column_families":[],"intensity":0.9,` +
+				`"repair_parallelism":"DATACENTER_AWARE","scheduled_days_between":7}]`))
+		case r.URL.Path == "/repair_schedule":
+			query := r.URL.Query()
+			assert.Equal(t, "cass-operator/default/cassandradatacenter-example", query.Get("owner"))
+			assert.Equal(t, "users", query.Get("keyspace"))
+			assert.Equal(t, "profiles", query.Get("tables"))
+			assert.Equal(t, "3", query.Get("scheduleDaysBetween"))
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	oldReaperBaseURL := reaperBaseURL
+	reaperBaseURL = func(dc *api.CassandraDatacenter) string { return server.URL }
+	defer func() { reaperBaseURL = oldReaperBaseURL }()
+
+	reconcileResult := rc.CheckReaperSchedules()
+	assert.False(t, reconcileResult.Completed())
+
+	assert.Equal(t, []string{
+		"GET /ping",
+		"GET /cluster/cassandradatacenter-example-cluster",
+		"PUT /cluster/cassandradatacenter-example-cluster",
+		"GET /repair_schedule/cluster/cassandradatacenter-example-cluster",
+		"PUT /repair_schedule/2",
+		"DELETE /repair_schedule/2",
+		"POST /repair_schedule",
+	}, requests)
+}
+
+func TestReconcileReaper_CheckReaperSchedulesNotHealthy(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	rc.Datacenter.Spec.ServerType = "cassandra"
+	rc.Datacenter.Spec.Reaper = &api.ReaperConfig{Enabled: true}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	oldReaperBaseURL := reaperBaseURL
+	reaperBaseURL = func(dc *api.CassandraDatacenter) string { return server.URL }
+	defer func() { reaperBaseURL = oldReaperBaseURL }()
+
+	reconcileResult := rc.CheckReaperSchedules()
+	assert.True(t, reconcileResult.Completed(), "should wait for Reaper")
+}
+
 func newCassandraDatacenter() *api.CassandraDatacenter {
 	return &api.CassandraDatacenter{
 		ObjectMeta: metav1.ObjectMeta{
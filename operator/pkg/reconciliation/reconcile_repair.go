@@ -0,0 +1,415 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/datastax/cass-operator/operator/internal/cron"
+	"github.com/datastax/cass-operator/operator/internal/result"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/events"
+	"github.com/datastax/cass-operator/operator/pkg/httphelper"
+)
+
+// The keyspaces that are local to each node, and so are never repaired
+var localSystemKeyspaces = map[string]bool{
+	"system":                true,
+	"system_schema":         true,
+	"system_views":          true,
+	"system_virtual_schema": true,
+	"dse_system_local":      true,
+}
+
+// The values of the repair_parallelism option of the management API
+var repairParallelismOptions = map[api.RepairParallelism]string{
+	api.RepairParallelismSequential:      "sequential",
+	api.RepairParallelismParallel:        "parallel",
+	api.RepairParallelismDatacenterAware: "dc_parallel",
+}
+
+// CheckRepair repairs the keyspaces of spec.repair when a scheduled time has
+// passed. The repair goes through the keyspaces, and the pods of each
+// keyspace, one repair session at a time, keeping track of where it is in the
+// status of the datacenter. Otherwise it requeues for the next scheduled time.
+func (rc *ReconciliationContext) CheckRepair() result.ReconcileResult {
+	return rc.checkRepair(time.Now())
+}
+
+func (rc *ReconciliationContext) checkRepair(now time.Time) result.ReconcileResult {
+	dc := rc.Datacenter
+
+	if dc.Spec.Repair == nil || dc.Spec.Stopped {
+		return result.Continue()
+	}
+
+	rc.ReqLogger.Info("reconcile_repair::CheckRepair")
+
+	status := dc.Status.Repair
+	if status == nil || len(status.PendingKeyspaces) == 0 {
+		return rc.startScheduledRepair(now)
+	}
+	if status.JobID != "" {
+		return rc.checkRepairSession()
+	}
+	return rc.startRepairSession()
+}
+
+// startScheduledRepair records the keyspaces to repair when a repair is due.
+// Like a CronJob, missed schedules are not made up for.
+func (rc *ReconciliationContext) startScheduledRepair(now time.Time) result.ReconcileResult {
+	dc := rc.Datacenter
+	logger := rc.ReqLogger
+	repair := dc.Spec.Repair
+
+	cronSchedule, err := cron.Parse(repair.Schedule)
+	if err != nil {
+		// The schedule is checked by the webhook, so this only happens when
+		// it is not installed
+		logger.Error(err, "invalid repair schedule")
+		rc.Recorder.Eventf(dc, corev1.EventTypeWarning, events.InvalidRepairSchedule,
+			"Invalid repair schedule: %s", err.Error())
+		return result.Continue()
+	}
+
+	scheduledTime := getMostRecentRepairTime(dc, cronSchedule, now)
+	if scheduledTime.IsZero() {
+		next := cronSchedule.Next(now)
+		if next.IsZero() {
+			logger.Info("Repair schedule will never run again")
+			return result.Continue()
+		}
+		logger.Info("Waiting for next scheduled repair", "time", next)
+		return result.RequeueSoon(int(math.Ceil(next.Sub(now).Seconds())))
+	}
+
+	pods := ListAllStartedPods(rc.dcPods)
+	if len(pods) == 0 {
+		logger.Info("Waiting for a started node to list the keyspaces to repair")
+		return result.RequeueSoon(30)
+	}
+
+	allKeyspaces, err := rc.NodeMgmtClient.CallListKeyspacesEndpoint(pods[0], "")
+	if err != nil {
+		logger.Error(err, "error listing keyspaces to repair")
+		return result.Error(err)
+	}
+
+	keyspaces := getRepairableKeyspaces(allKeyspaces)
+	if len(repair.Keyspaces) > 0 {
+		// The keyspaces of the spec are not checked against the cluster, and
+		// one that does not exist could not be repaired
+		var unknown []string
+		keyspaces, unknown = splitRepairKeyspaces(repair.Keyspaces, allKeyspaces)
+		if len(unknown) > 0 {
+			rc.Recorder.Eventf(dc, corev1.EventTypeWarning, events.FailedRepair,
+				"Skipped repair of unknown keyspaces %s", strings.Join(unknown, ", "))
+		}
+	}
+
+	dcPatch := client.MergeFrom(dc.DeepCopy())
+	status := dc.Status.Repair
+	if status == nil {
+		status = &api.RepairStatus{}
+		dc.Status.Repair = status
+	}
+	scheduled := metav1.NewTime(scheduledTime)
+	status.LastScheduleTime = &scheduled
+	status.PendingKeyspaces = keyspaces
+	status.PodName = getFirstRepairPodName(rc.dcPods)
+	status.RangeIndex = 0
+	status.JobID = ""
+	status.FailedSessions = 0
+	if err := rc.Client.Status().Patch(rc.Ctx, dc, dcPatch); err != nil {
+		logger.Error(err, "error patching datacenter status for repair")
+		return result.Error(err)
+	}
+
+	if len(keyspaces) == 0 {
+		logger.Info("No keyspaces to repair")
+		return result.Continue()
+	}
+
+	rc.Recorder.Eventf(dc, corev1.EventTypeNormal, events.StartedRepair,
+		"Started repair of keyspaces %s", strings.Join(keyspaces, ", "))
+	return result.RequeueSoon(2)
+}
+
+// startRepairSession starts the repair of the token ranges of the current
+// pod, or of its current token range with the Subrange type
+func (rc *ReconciliationContext) startRepairSession() result.ReconcileResult {
+	dc := rc.Datacenter
+	logger := rc.ReqLogger
+	repair := dc.Spec.Repair
+	status := dc.Status.Repair
+	keyspace := status.PendingKeyspaces[0]
+
+	pod := findPodByName(rc.dcPods, status.PodName)
+	if pod == nil {
+		// The pod is no longer part of the datacenter
+		return rc.finishRepairSession(false, true)
+	}
+
+	// A repair needs every replica of the token ranges
+	for _, dcPod := range rc.dcPods {
+		if !isServerStarted(dcPod) {
+			logger.Info("Waiting for all nodes to be started before repairing",
+				"pod", dcPod.Name)
+			return result.RequeueSoon(30)
+		}
+	}
+
+	ranges, err := rc.getPrimaryTokenRanges(pod, keyspace)
+	if err != nil {
+		// The keyspace may have been dropped since the repair started, so
+		// the session is counted as failed rather than retried forever
+		logger.Error(err, "error getting token ranges to repair", "keyspace", keyspace)
+		rc.Recorder.Eventf(dc, corev1.EventTypeWarning, events.FailedRepair,
+			"Could not get the token ranges of keyspace %s on pod %s: %s", keyspace, pod.Name, err.Error())
+		return rc.finishRepairSession(true, true)
+	}
+
+	request := httphelper.RepairRequest{
+		Keyspace:          keyspace,
+		FullRepair:        true,
+		RepairParallelism: repairParallelismOptions[repair.Parallelism],
+		Datacenters:       []string{dc.Name},
+	}
+	if repair.Type == api.RepairTypeSubrange {
+		if int(status.RangeIndex) >= len(ranges) {
+			return rc.finishRepairSession(false, true)
+		}
+		request.TokenRanges = ranges[status.RangeIndex : status.RangeIndex+1]
+	} else {
+		if len(ranges) == 0 {
+			return rc.finishRepairSession(false, true)
+		}
+		request.TokenRanges = ranges
+	}
+
+	jobID, err := rc.NodeMgmtClient.CallStartRepairEndpoint(pod, request)
+	if err != nil {
+		logger.Error(err, "error starting repair", "keyspace", keyspace, "pod", pod.Name)
+		return result.Error(err)
+	}
+
+	dcPatch := client.MergeFrom(dc.DeepCopy())
+	status.JobID = jobID
+	if err := rc.Client.Status().Patch(rc.Ctx, dc, dcPatch); err != nil {
+		logger.Error(err, "error patching datacenter status for repair")
+		return result.Error(err)
+	}
+
+	return result.RequeueSoon(10)
+}
+
+// checkRepairSession waits for the repair session in progress to finish
+func (rc *ReconciliationContext) checkRepairSession() result.ReconcileResult {
+	dc := rc.Datacenter
+	logger := rc.ReqLogger
+	status := dc.Status.Repair
+	keyspace := status.PendingKeyspaces[0]
+
+	pod := findPodByName(rc.dcPods, status.PodName)
+	if pod == nil {
+		return rc.finishRepairSession(true, true)
+	}
+
+	details, err := rc.NodeMgmtClient.CallJobDetailsEndpoint(pod, status.JobID)
+	if err != nil {
+		// The job is lost when the node restarts, so the session is counted
+		// as failed rather than waited on
+		logger.Error(err, "error getting repair job", "pod", pod.Name, "jobId", status.JobID)
+		rc.Recorder.Eventf(dc, corev1.EventTypeWarning, events.FailedRepair,
+			"Lost track of the repair of keyspace %s on pod %s: %s", keyspace, pod.Name, err.Error())
+		return rc.finishRepairSession(true, false)
+	}
+
+	switch details.Status {
+	case httphelper.JobStatusCompleted:
+		return rc.finishRepairSession(false, false)
+	case httphelper.JobStatusError:
+		rc.Recorder.Eventf(dc, corev1.EventTypeWarning, events.FailedRepair,
+			"Failed to repair keyspace %s on pod %s: %s", keyspace, pod.Name, details.Error)
+		return rc.finishRepairSession(true, false)
+	}
+
+	logger.Info("Waiting for repair session to finish",
+		"keyspace", keyspace, "pod", pod.Name, "jobId", status.JobID)
+	return result.RequeueSoon(10)
+}
+
+// finishRepairSession moves the repair on to the next token range of the
+// pod, or to the next pod when the pod is done, or to the next keyspace when
+// every pod is done. A keyspace is only recorded as repaired when none of its
+// sessions failed.
+func (rc *ReconciliationContext) finishRepairSession(failed bool, podDone bool) result.ReconcileResult {
+	dc := rc.Datacenter
+	logger := rc.ReqLogger
+	status := dc.Status.Repair
+	keyspace := status.PendingKeyspaces[0]
+
+	dcPatch := client.MergeFrom(dc.DeepCopy())
+	status.JobID = ""
+	if failed {
+		status.FailedSessions++
+	}
+
+	if dc.Spec.Repair.Type == api.RepairTypeSubrange && !podDone {
+		status.RangeIndex++
+	} else if next := getNextRepairPodName(rc.dcPods, status.PodName); next != "" {
+		status.PodName = next
+		status.RangeIndex = 0
+	} else {
+		if status.FailedSessions == 0 {
+			setKeyspaceRepairStatus(status, api.KeyspaceRepairStatus{
+				Name:          keyspace,
+				LastCompleted: metav1.Now(),
+			})
+			rc.Recorder.Eventf(dc, corev1.EventTypeNormal, events.CompletedRepair,
+				"Repaired keyspace %s", keyspace)
+		} else {
+			rc.Recorder.Eventf(dc, corev1.EventTypeWarning, events.FailedRepair,
+				"Repair of keyspace %s finished with %d failed sessions", keyspace, status.FailedSessions)
+		}
+
+		status.PendingKeyspaces = status.PendingKeyspaces[1:]
+		status.PodName = getFirstRepairPodName(rc.dcPods)
+		status.RangeIndex = 0
+		status.FailedSessions = 0
+		if len(status.PendingKeyspaces) == 0 {
+			status.PodName = ""
+		}
+	}
+
+	if err := rc.Client.Status().Patch(rc.Ctx, dc, dcPatch); err != nil {
+		logger.Error(err, "error patching datacenter status for repair")
+		return result.Error(err)
+	}
+
+	return result.RequeueSoon(2)
+}
+
+// getPrimaryTokenRanges returns the token ranges of the keyspace that the
+// pod is the first replica of in this datacenter, so that each token range is
+// repaired by a single pod of the datacenter
+func (rc *ReconciliationContext) getPrimaryTokenRanges(pod *corev1.Pod, keyspace string) ([]httphelper.TokenRange, error) {
+	rangeEndpoints, err := rc.NodeMgmtClient.CallRangeToEndpointEndpoint(pod, keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	dcAddresses := map[string]bool{}
+	for _, dcPod := range rc.dcPods {
+		if dcPod.Status.PodIP != "" {
+			dcAddresses[dcPod.Status.PodIP] = true
+		}
+	}
+
+	ranges := []httphelper.TokenRange{}
+	for _, rangeEndpoint := range rangeEndpoints {
+		for _, endpoint := range rangeEndpoint.Endpoints {
+			if !dcAddresses[endpoint] {
+				continue
+			}
+			if endpoint == pod.Status.PodIP {
+				ranges = append(ranges, rangeEndpoint.TokenRange)
+			}
+			break
+		}
+	}
+	return ranges, nil
+}
+
+// getMostRecentRepairTime returns the latest time the repair schedule was
+// due at, after the last repair and no later than now. It returns the zero
+// time when no repair is due.
+func getMostRecentRepairTime(dc *api.CassandraDatacenter, cronSchedule *cron.Schedule, now time.Time) time.Time {
+	last := dc.CreationTimestamp.Time
+	if dc.Status.Repair != nil && dc.Status.Repair.LastScheduleTime != nil {
+		last = dc.Status.Repair.LastScheduleTime.Time
+	}
+
+	return cronSchedule.MostRecent(last, now)
+}
+
+// getRepairableKeyspaces returns the keyspaces that are not local to each
+// node, sorted by name
+func getRepairableKeyspaces(keyspaces []string) []string {
+	repairable := []string{}
+	for _, keyspace := range keyspaces {
+		if !localSystemKeyspaces[keyspace] {
+			repairable = append(repairable, keyspace)
+		}
+	}
+	sort.Strings(repairable)
+	return repairable
+}
+
+// splitRepairKeyspaces splits the keyspaces to repair into the ones that exist
+// in the cluster and the unknown ones, keeping their order
+func splitRepairKeyspaces(keyspaces []string, clusterKeyspaces []string) ([]string, []string) {
+	exists := map[string]bool{}
+	for _, keyspace := range clusterKeyspaces {
+		exists[keyspace] = true
+	}
+
+	known := []string{}
+	unknown := []string{}
+	for _, keyspace := range keyspaces {
+		if exists[keyspace] {
+			known = append(known, keyspace)
+		} else {
+			unknown = append(unknown, keyspace)
+		}
+	}
+	return known, unknown
+}
+
+func getRepairPodNames(pods []*corev1.Pod) []string {
+	names := []string{}
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func getFirstRepairPodName(pods []*corev1.Pod) string {
+	names := getRepairPodNames(pods)
+	if len(names) == 0 {
+		return ""
+	}
+	return names[0]
+}
+
+// getNextRepairPodName returns the pod to repair after the given one, or ""
+// when it was the last one
+func getNextRepairPodName(pods []*corev1.Pod, podName string) string {
+	for _, name := range getRepairPodNames(pods) {
+		if name > podName {
+			return name
+		}
+	}
+	return ""
+}
+
+// setKeyspaceRepairStatus adds or replaces the last completed repair of a
+// keyspace
+func setKeyspaceRepairStatus(status *api.RepairStatus, keyspaceStatus api.KeyspaceRepairStatus) {
+	for idx := range status.Keyspaces {
+		if status.Keyspaces[idx].Name == keyspaceStatus.Name {
+			status.Keyspaces[idx] = keyspaceStatus
+			return
+		}
+	}
+	status.Keyspaces = append(status.Keyspaces, keyspaceStatus)
+}
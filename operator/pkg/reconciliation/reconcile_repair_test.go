@@ -0,0 +1,211 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/httphelper"
+	"github.com/datastax/cass-operator/operator/pkg/mocks"
+)
+
+// setupRepairTest gives the datacenter two started pods and a daily repair
+// schedule, with a management API that answers each path with the given
+// body, and returns the POST requests it receives
+func setupRepairTest(t *testing.T, rc *ReconciliationContext, responses map[string]string) *[]*http.Request {
+	dc := rc.Datacenter
+	dc.CreationTimestamp = metav1.NewTime(time.Date(2020, time.July, 1, 0, 0, 0, 0, time.UTC))
+	dc.Spec.Repair = &api.RepairConfig{Schedule: "0 2 * * *"}
+
+	desiredStatefulSet, err := newStatefulSetForCassandraDatacenter("default", dc, 2)
+	assert.NoErrorf(t, err, "error occurred creating statefulset")
+
+	pods := mockRunningPodsForRack(desiredStatefulSet, dc, "default")
+	trackObjects := []runtime.Object{dc}
+	for _, pod := range pods {
+		trackObjects = append(trackObjects, pod)
+	}
+	rc.Client = fake.NewFakeClient(trackObjects...)
+	rc.dcPods = pods
+
+	mgmtApi := mocks.NewManagementApi(responses)
+	rc.NodeMgmtClient = httphelper.NodeMgmtClient{Client: mgmtApi.HttpClient(), Log: rc.ReqLogger, Protocol: "http"}
+
+	return &mgmtApi.Posts
+}
+
+func TestCheckRepair_NotDue(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	setupRepairTest(t, rc, nil)
+
+	recResult := rc.checkRepair(time.Date(2020, time.July, 1, 1, 0, 0, 0, time.UTC))
+	assert.True(t, recResult.Completed())
+	res, err := recResult.Output()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Hour, res.RequeueAfter, "Should requeue for the next scheduled repair")
+	assert.Nil(t, rc.Datacenter.Status.Repair)
+}
+
+func TestCheckRepair_StartsScheduledRepair(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	setupRepairTest(t, rc, map[string]string{
+		"/api/v0/ops/keyspace": `["system_auth","system","app","system_schema"]`,
+	})
+
+	recResult := rc.checkRepair(time.Date(2020, time.July, 1, 3, 0, 0, 0, time.UTC))
+	assert.True(t, recResult.Completed())
+
+	status := rc.Datacenter.Status.Repair
+	if assert.NotNil(t, status) {
+		assert.Equal(t, []string{"app", "system_auth"}, status.PendingKeyspaces)
+		assert.Equal(t, rc.dcPods[0].Name, status.PodName)
+		assert.Equal(t, time.Date(2020, time.July, 1, 2, 0, 0, 0, time.UTC), status.LastScheduleTime.Time.UTC())
+	}
+}
+
+func TestCheckRepair_SkipsUnknownKeyspaces(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	setupRepairTest(t, rc, map[string]string{
+		"/api/v0/ops/keyspace": `["system_auth","system","app","system_schema"]`,
+	})
+	rc.Datacenter.Spec.Repair.Keyspaces = []string{"ap", "app"}
+
+	recResult := rc.checkRepair(time.Date(2020, time.July, 1, 3, 0, 0, 0, time.UTC))
+	assert.True(t, recResult.Completed())
+
+	status := rc.Datacenter.Status.Repair
+	if assert.NotNil(t, status) {
+		assert.Equal(t, []string{"app"}, status.PendingKeyspaces)
+	}
+}
+
+func TestCheckRepair_MovesOnWithoutTokenRanges(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	// The keyspace was dropped after the repair started, so the management
+	// API cannot return its token ranges
+	posts := setupRepairTest(t, rc, nil)
+	rc.Datacenter.Status.Repair = &api.RepairStatus{
+		PendingKeyspaces: []string{"dropped", "app"},
+		PodName:          rc.dcPods[0].Name,
+	}
+
+	recResult := rc.CheckRepair()
+	assert.True(t, recResult.Completed())
+	_, err := recResult.Output()
+	assert.NoError(t, err)
+
+	status := rc.Datacenter.Status.Repair
+	assert.Empty(t, *posts, "Should not start a repair")
+	assert.Equal(t, int32(1), status.FailedSessions)
+	assert.Equal(t, rc.dcPods[1].Name, status.PodName, "Should move on to the next pod")
+
+	recResult = rc.CheckRepair()
+	assert.True(t, recResult.Completed())
+	assert.Equal(t, []string{"app"}, status.PendingKeyspaces, "Should move on to the next keyspace")
+	assert.Empty(t, status.Keyspaces)
+}
+
+func TestCheckRepair_StartsSubrangeSession(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	// The first pod is the first replica in this datacenter of the first and
+	// last ranges
+	posts := setupRepairTest(t, rc, map[string]string{
+		"/api/v0/ops/tokens/rangetoendpoint": `[` +
+			`{"start":"0","end":"100","endpoints":["10.0.0.1","10.0.0.2"]},` +
+			`{"start":"100","end":"200","endpoints":["10.0.0.2","10.0.0.1"]},` +
+			`{"start":"200","end":"300","endpoints":["10.1.0.1","10.0.0.1","10.0.0.2"]}]`,
+		"/api/v1/repair": `{"repair_id":"repair-1"}`,
+	})
+	rc.Datacenter.Spec.Repair.Type = api.RepairTypeSubrange
+	rc.Datacenter.Spec.Repair.Parallelism = api.RepairParallelismDatacenterAware
+	rc.Datacenter.Status.Repair = &api.RepairStatus{
+		PendingKeyspaces: []string{"app"},
+		PodName:          rc.dcPods[0].Name,
+		RangeIndex:       1,
+	}
+
+	recResult := rc.CheckRepair()
+	assert.True(t, recResult.Completed())
+
+	if assert.Equal(t, 1, len(*posts)) {
+		body, err := ioutil.ReadAll((*posts)[0].Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t,
+			`{"keyspace":"app","tables":[],"full_repair":true,"repair_parallelism":"dc_parallel",`+
+				`"datacenters":["cassandradatacenter-example"],"associated_tokens":[{"start":"200","end":"300"}]}`,
+			string(body))
+	}
+	assert.Equal(t, "repair-1", rc.Datacenter.Status.Repair.JobID)
+}
+
+func TestCheckRepair_CompletesKeyspace(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	setupRepairTest(t, rc, map[string]string{
+		"/api/v0/ops/executor/job": `{"id":"repair-1","type":"repair","status":"COMPLETED"}`,
+	})
+	rc.Datacenter.Status.Repair = &api.RepairStatus{
+		PendingKeyspaces: []string{"app"},
+		PodName:          rc.dcPods[1].Name,
+		JobID:            "repair-1",
+	}
+
+	recResult := rc.CheckRepair()
+	assert.True(t, recResult.Completed())
+
+	status := rc.Datacenter.Status.Repair
+	assert.Empty(t, status.PendingKeyspaces)
+	assert.Empty(t, status.JobID)
+	if assert.Equal(t, 1, len(status.Keyspaces)) {
+		assert.Equal(t, "app", status.Keyspaces[0].Name)
+	}
+}
+
+func TestCheckRepair_FailedSession(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	setupRepairTest(t, rc, map[string]string{
+		"/api/v0/ops/executor/job": `{"id":"repair-1","type":"repair","status":"ERROR","error":"node down"}`,
+	})
+	rc.Datacenter.Status.Repair = &api.RepairStatus{
+		PendingKeyspaces: []string{"app"},
+		PodName:          rc.dcPods[0].Name,
+		JobID:            "repair-1",
+	}
+
+	recResult := rc.CheckRepair()
+	assert.True(t, recResult.Completed())
+
+	status := rc.Datacenter.Status.Repair
+	assert.Equal(t, int32(1), status.FailedSessions)
+	assert.Equal(t, rc.dcPods[1].Name, status.PodName, "Should move on to the next pod")
+	assert.Empty(t, status.Keyspaces)
+}
+
+func Test_getRepairableKeyspaces(t *testing.T) {
+	assert.Equal(t,
+		[]string{"app", "system_auth", "system_distributed"},
+		getRepairableKeyspaces([]string{"system_distributed", "system", "app", "system_auth", "system_schema"}))
+}
@@ -0,0 +1,54 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/datastax/cass-operator/operator/internal/result"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+)
+
+// CheckRestoreStaged holds off starting Cassandra on any pod while a
+// CassandraRestore of this datacenter is still copying the backup onto the
+// pod's volume
+func (rc *ReconciliationContext) CheckRestoreStaged() result.ReconcileResult {
+	logger := rc.ReqLogger
+	logger.Info("reconcile_racks::CheckRestoreStaged")
+	dc := rc.Datacenter
+
+	if dc.Spec.Stopped {
+		return result.Continue()
+	}
+
+	restoreList := &api.CassandraRestoreList{}
+	err := rc.Client.List(rc.Ctx, restoreList, client.InNamespace(dc.Namespace))
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			// the CassandraRestore CRD is not installed
+			return result.Continue()
+		}
+		logger.Error(err, "error listing restores")
+		return result.Error(err)
+	}
+
+	for idx := range restoreList.Items {
+		restore := &restoreList.Items[idx]
+		if restore.Spec.CassandraDatacenter != dc.Name || !restore.IsStaging() {
+			continue
+		}
+
+		for _, pod := range rc.dcPods {
+			if isServerReadyToStart(pod) && !restore.IsPodStaged(pod.Name) {
+				logger.Info("Waiting for backup to be staged before starting Cassandra",
+					"restore", restore.Name,
+					"pod", pod.Name)
+				return result.RequeueSoon(5)
+			}
+		}
+	}
+
+	return result.Continue()
+}
@@ -0,0 +1,82 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+)
+
+func setupRestoreStagedTest(t *testing.T, rc *ReconciliationContext, staged bool) {
+	statefulSet, err := newStatefulSetForCassandraDatacenter("default", rc.Datacenter, 2)
+	assert.NoErrorf(t, err, "error occurred creating statefulset")
+
+	pods := mockRunningPodsForRack(statefulSet, rc.Datacenter, "default")
+	for _, pod := range pods {
+		pod.Labels[api.CassNodeState] = stateReadyToStart
+	}
+
+	restore := &api.CassandraRestore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "restore",
+			Namespace: rc.Datacenter.Namespace,
+		},
+		Spec: api.CassandraRestoreSpec{
+			CassandraDatacenter: rc.Datacenter.Name,
+			Backup:              "backup",
+		},
+		Status: api.CassandraRestoreStatus{
+			Conditions: api.BackupConditions{
+				*api.NewBackupCondition(api.BackupStaging, corev1.ConditionTrue),
+			},
+			Pods: map[string]api.PodBackupStatus{},
+		},
+	}
+	if staged {
+		for _, pod := range pods {
+			restore.Status.Pods[pod.Name] = api.PodBackupStatus{
+				Conditions: api.BackupConditions{
+					*api.NewBackupCondition(api.BackupStaged, corev1.ConditionTrue),
+				},
+			}
+		}
+	}
+
+	rc.Client = fake.NewFakeClient(rc.Datacenter, restore)
+	rc.dcPods = pods
+}
+
+func TestCheckRestoreStaged_WaitsForStaging(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	setupRestoreStagedTest(t, rc, false)
+
+	recResult := rc.CheckRestoreStaged()
+	assert.True(t, recResult.Completed(), "Should not start Cassandra until the backup is staged")
+}
+
+func TestCheckRestoreStaged_Staged(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	setupRestoreStagedTest(t, rc, true)
+
+	recResult := rc.CheckRestoreStaged()
+	assert.False(t, recResult.Completed(), "Should continue once the backup is staged")
+}
+
+func TestCheckRestoreStaged_NoRestore(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	recResult := rc.CheckRestoreStaged()
+	assert.False(t, recResult.Completed(), "Should continue when nothing is being restored")
+}
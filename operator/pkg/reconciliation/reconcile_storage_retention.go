@@ -0,0 +1,201 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/datastax/cass-operator/operator/internal/result"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/events"
+	"github.com/datastax/cass-operator/operator/pkg/oplabels"
+)
+
+// The annotation on a VolumeSnapshot naming the PersistentVolumeClaim it was
+// taken of
+const sourcePVCAnnotation = "cassandra.datastax.com/source-pvc"
+
+var volumeSnapshotGroupVersionKind = schema.GroupVersionKind{
+	Group:   "snapshot.storage.k8s.io",
+	Version: "v1beta1",
+	Kind:    "VolumeSnapshot",
+}
+
+// CheckPVCRetention applies the retention policy of the storage config to
+// the PersistentVolumeClaims of a datacenter that is being deleted
+func (rc *ReconciliationContext) CheckPVCRetention() result.ReconcileResult {
+	logger := rc.ReqLogger
+
+	switch rc.Datacenter.Spec.StorageConfig.RetentionPolicy {
+	case api.StorageRetentionPolicyRetain:
+		if err := rc.retainPVCs(); err != nil {
+			logger.Error(err, "Failed to retain PVCs for CassandraDatacenter")
+			return result.Error(err)
+		}
+		return result.Continue()
+
+	case api.StorageRetentionPolicySnapshot:
+		if recResult := rc.checkPVCSnapshots(); recResult.Completed() {
+			return recResult
+		}
+	}
+
+	if err := rc.deletePVCs(); err != nil {
+		logger.Error(err, "Failed to delete PVCs for CassandraDatacenter")
+		return result.Error(err)
+	}
+	return result.Continue()
+}
+
+// retainPVCs removes the managed-by label and the owners from the
+// PersistentVolumeClaims of the datacenter and leaves them in place. They keep
+// their cluster, datacenter and rack labels, so that a datacenter created
+// again with the same name and racks reuses and adopts them.
+func (rc *ReconciliationContext) retainPVCs() error {
+	dc := rc.Datacenter
+	logger := rc.ReqLogger
+	logger.Info("reconciler::retainPVCs")
+
+	persistentVolumeClaimList, err := rc.listPVCs()
+	if err != nil {
+		return err
+	}
+
+	for idx := range persistentVolumeClaimList.Items {
+		pvc := &persistentVolumeClaimList.Items[idx]
+		pvcPatch := client.MergeFrom(pvc.DeepCopy())
+
+		labels := pvc.GetLabels()
+		delete(labels, oplabels.ManagedByLabel)
+		pvc.SetLabels(labels)
+		pvc.SetOwnerReferences(nil)
+
+		if err := rc.Client.Patch(rc.Ctx, pvc, pvcPatch); err != nil {
+			return err
+		}
+		rc.Recorder.Eventf(dc, corev1.EventTypeNormal, events.RetainedPersistentVolumeClaim,
+			"Retained PersistentVolumeClaim %s", pvc.Name)
+	}
+
+	return nil
+}
+
+// adoptRetainedPVCs puts the managed-by label back on the
+// PersistentVolumeClaims that an earlier datacenter of the same name retained
+// when it was deleted, which the StatefulSets of the datacenter reuse
+func (rc *ReconciliationContext) adoptRetainedPVCs() error {
+	dc := rc.Datacenter
+
+	persistentVolumeClaimList, err := rc.listPVCs()
+	if err != nil {
+		return err
+	}
+
+	for idx := range persistentVolumeClaimList.Items {
+		pvc := &persistentVolumeClaimList.Items[idx]
+		if _, ok := pvc.Labels[oplabels.ManagedByLabel]; ok {
+			continue
+		}
+
+		pvcPatch := client.MergeFrom(pvc.DeepCopy())
+		labels := pvc.GetLabels()
+		oplabels.AddManagedByLabel(labels)
+		pvc.SetLabels(labels)
+
+		if err := rc.Client.Patch(rc.Ctx, pvc, pvcPatch); err != nil {
+			return err
+		}
+		rc.Recorder.Eventf(dc, corev1.EventTypeNormal, events.AdoptedPersistentVolumeClaim,
+			"Adopted retained PersistentVolumeClaim %s", pvc.Name)
+	}
+
+	return nil
+}
+
+// checkPVCSnapshots takes a VolumeSnapshot of each PersistentVolumeClaim of
+// the datacenter, and waits until they are all ready to use. The snapshots
+// are not owned by the datacenter, so they outlive it.
+func (rc *ReconciliationContext) checkPVCSnapshots() result.ReconcileResult {
+	dc := rc.Datacenter
+	logger := rc.ReqLogger
+	logger.Info("reconciler::checkPVCSnapshots")
+
+	persistentVolumeClaimList, err := rc.listPVCs()
+	if err != nil {
+		logger.Error(err, "Failed to list PVCs for CassandraDatacenter")
+		return result.Error(err)
+	}
+
+	ready := true
+	for idx := range persistentVolumeClaimList.Items {
+		pvc := &persistentVolumeClaimList.Items[idx]
+		desired := newVolumeSnapshotForPVC(dc, pvc)
+
+		snapshot := &unstructured.Unstructured{}
+		snapshot.SetGroupVersionKind(volumeSnapshotGroupVersionKind)
+		nsName := types.NamespacedName{Name: desired.GetName(), Namespace: desired.GetNamespace()}
+		err := rc.Client.Get(rc.Ctx, nsName, snapshot)
+		if err != nil && errors.IsNotFound(err) {
+			if err := rc.Client.Create(rc.Ctx, desired); err != nil {
+				logger.Error(err, "Could not create VolumeSnapshot")
+				return result.Error(err)
+			}
+			rc.Recorder.Eventf(dc, corev1.EventTypeNormal, events.CreatedVolumeSnapshot,
+				"Created VolumeSnapshot %s of PersistentVolumeClaim %s", desired.GetName(), pvc.Name)
+			ready = false
+			continue
+		} else if err != nil {
+			logger.Error(err, "Could not get VolumeSnapshot")
+			return result.Error(err)
+		}
+
+		if readyToUse, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse"); !readyToUse {
+			logger.Info("Waiting for VolumeSnapshot to be ready to use",
+				"volumeSnapshot", snapshot.GetName())
+			ready = false
+		}
+	}
+
+	if !ready {
+		return result.RequeueSoon(10)
+	}
+	return result.Continue()
+}
+
+// newVolumeSnapshotForPVC returns the VolumeSnapshot of a PersistentVolumeClaim
+// of the datacenter. It is named after the claim and the time the datacenter
+// was deleted, and keeps the labels of the claim so that it can be found by
+// cluster, datacenter and rack.
+func newVolumeSnapshotForPVC(dc *api.CassandraDatacenter, pvc *corev1.PersistentVolumeClaim) *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGroupVersionKind)
+	snapshot.SetNamespace(pvc.Namespace)
+
+	name := pvc.Name
+	if deletionTimestamp := dc.GetDeletionTimestamp(); deletionTimestamp != nil {
+		name = fmt.Sprintf("%s-%s", pvc.Name, deletionTimestamp.UTC().Format("20060102150405"))
+	}
+	snapshot.SetName(name)
+	snapshot.SetLabels(pvc.GetLabels())
+	snapshot.SetAnnotations(map[string]string{sourcePVCAnnotation: pvc.Name})
+
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{
+			"persistentVolumeClaimName": pvc.Name,
+		},
+	}
+	if className := dc.Spec.StorageConfig.VolumeSnapshotClassName; className != "" {
+		spec["volumeSnapshotClassName"] = className
+	}
+	snapshot.Object["spec"] = spec
+
+	return snapshot
+}
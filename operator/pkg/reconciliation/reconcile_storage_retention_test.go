@@ -0,0 +1,166 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/oplabels"
+)
+
+const testPVCName = "server-data-cluster1-dc1-default-sts-0"
+
+func setupStorageRetentionTest(rc *ReconciliationContext, policy api.StorageRetentionPolicy, trackObjects ...runtime.Object) {
+	dc := rc.Datacenter
+	dc.Spec.StorageConfig.RetentionPolicy = policy
+	deletionTimestamp := metav1.NewTime(time.Date(2020, time.July, 1, 12, 0, 0, 0, time.UTC))
+	dc.SetDeletionTimestamp(&deletionTimestamp)
+
+	pvcLabels := dc.GetRackLabels("default")
+	oplabels.AddManagedByLabel(pvcLabels)
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testPVCName,
+			Namespace: dc.Namespace,
+			Labels:    pvcLabels,
+		},
+	}
+
+	trackObjects = append(trackObjects, dc, pvc)
+	rc.Client = fake.NewFakeClient(trackObjects...)
+}
+
+func getTestPVC(t *testing.T, rc *ReconciliationContext) *corev1.PersistentVolumeClaim {
+	pvc := &corev1.PersistentVolumeClaim{}
+	nsName := types.NamespacedName{Name: testPVCName, Namespace: rc.Datacenter.Namespace}
+	if err := rc.Client.Get(rc.Ctx, nsName, pvc); err != nil {
+		assert.True(t, errors.IsNotFound(err))
+		return nil
+	}
+	return pvc
+}
+
+func getTestVolumeSnapshot(t *testing.T, rc *ReconciliationContext) *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGroupVersionKind)
+	nsName := types.NamespacedName{Name: testPVCName + "-20200701120000", Namespace: rc.Datacenter.Namespace}
+	if err := rc.Client.Get(rc.Ctx, nsName, snapshot); err != nil {
+		assert.True(t, errors.IsNotFound(err))
+		return nil
+	}
+	return snapshot
+}
+
+func TestCheckPVCRetention_Delete(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	setupStorageRetentionTest(rc, "")
+
+	recResult := rc.CheckPVCRetention()
+	assert.False(t, recResult.Completed())
+	assert.Nil(t, getTestPVC(t, rc))
+	assert.Nil(t, getTestVolumeSnapshot(t, rc))
+}
+
+func TestCheckPVCRetention_Retain(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	setupStorageRetentionTest(rc, api.StorageRetentionPolicyRetain)
+
+	recResult := rc.CheckPVCRetention()
+	assert.False(t, recResult.Completed())
+
+	pvc := getTestPVC(t, rc)
+	if assert.NotNil(t, pvc) {
+		assert.Equal(t, rc.Datacenter.GetRackLabels("default"), pvc.Labels)
+	}
+}
+
+func TestCheckPVCRetention_RetainRecreateAndDelete(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	setupStorageRetentionTest(rc, api.StorageRetentionPolicyRetain)
+
+	recResult := rc.CheckPVCRetention()
+	assert.False(t, recResult.Completed())
+
+	// The datacenter is created again with the same name
+	rc.Datacenter.SetDeletionTimestamp(nil)
+	if err := rc.CalculateRackInformation(); err != nil {
+		t.Fatalf("failed to calculate rack information: %s", err)
+	}
+
+	recResult = rc.CheckRackCreation()
+	assert.False(t, recResult.Completed())
+
+	pvc := getTestPVC(t, rc)
+	if assert.NotNil(t, pvc) {
+		assert.Equal(t, oplabels.ManagedByLabelValue, pvc.Labels[oplabels.ManagedByLabel])
+	}
+	pvcs, err := rc.listRackDataPVCs("default")
+	assert.NoError(t, err)
+	assert.Len(t, pvcs, 1, "Should be found for volume expansions")
+
+	// Then it is deleted for good
+	deletionTimestamp := metav1.NewTime(time.Date(2020, time.August, 1, 12, 0, 0, 0, time.UTC))
+	rc.Datacenter.SetDeletionTimestamp(&deletionTimestamp)
+	rc.Datacenter.Spec.StorageConfig.RetentionPolicy = api.StorageRetentionPolicyDelete
+
+	recResult = rc.CheckPVCRetention()
+	assert.False(t, recResult.Completed())
+	assert.Nil(t, getTestPVC(t, rc))
+}
+
+func TestCheckPVCRetention_Snapshot(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	setupStorageRetentionTest(rc, api.StorageRetentionPolicySnapshot)
+	rc.Datacenter.Spec.StorageConfig.VolumeSnapshotClassName = "csi-snapclass"
+
+	recResult := rc.CheckPVCRetention()
+	assert.True(t, recResult.Completed(), "Should wait for the snapshot to be ready")
+	assert.NotNil(t, getTestPVC(t, rc))
+
+	snapshot := getTestVolumeSnapshot(t, rc)
+	if assert.NotNil(t, snapshot) {
+		source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+		assert.Equal(t, testPVCName, source)
+		className, _, _ := unstructured.NestedString(snapshot.Object, "spec", "volumeSnapshotClassName")
+		assert.Equal(t, "csi-snapclass", className)
+		assert.Equal(t, rc.Datacenter.Name, snapshot.GetLabels()[api.DatacenterLabel])
+		assert.Empty(t, snapshot.GetOwnerReferences(), "Should outlive the datacenter")
+	}
+}
+
+func TestCheckPVCRetention_SnapshotReady(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGroupVersionKind)
+	snapshot.SetName(testPVCName + "-20200701120000")
+	snapshot.SetNamespace(rc.Datacenter.Namespace)
+	snapshot.Object["status"] = map[string]interface{}{"readyToUse": true}
+	setupStorageRetentionTest(rc, api.StorageRetentionPolicySnapshot, snapshot)
+
+	recResult := rc.CheckPVCRetention()
+	assert.False(t, recResult.Completed())
+	assert.Nil(t, getTestPVC(t, rc))
+	assert.NotNil(t, getTestVolumeSnapshot(t, rc))
+}
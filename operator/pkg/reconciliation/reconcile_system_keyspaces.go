@@ -0,0 +1,222 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/datastax/cass-operator/operator/internal/result"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/events"
+	"github.com/datastax/cass-operator/operator/pkg/httphelper"
+	"github.com/datastax/cass-operator/operator/pkg/utils"
+)
+
+// The keyspaces whose replication follows the size of the datacenter
+var systemKeyspaces = []string{"system_auth", "system_distributed", "system_traces"}
+
+// The highest replication factor the system keyspaces are altered to
+const maxSystemKeyspacesReplicationFactor = 3
+
+// CheckSystemKeyspacesReplication alters the replication of the system
+// keyspaces in this datacenter to follow its size. The repair of system_auth
+// that must follow is left to CheckSystemAuthRepair, which runs in the
+// background of the ready datacenter.
+func (rc *ReconciliationContext) CheckSystemKeyspacesReplication() result.ReconcileResult {
+	dc := rc.Datacenter
+	logger := rc.ReqLogger
+
+	if dc.Spec.Stopped {
+		logger.Info("cluster is stopped, skipping CheckSystemKeyspacesReplication")
+		return result.Continue()
+	}
+
+	replicationFactor := getSystemKeyspacesReplicationFactor(dc)
+	status := dc.Status.SystemKeyspacesRepair
+	if status == nil && dc.Status.SystemKeyspacesReplicationFactor == replicationFactor {
+		return result.Continue()
+	}
+	if status != nil && status.ReplicationFactor == replicationFactor {
+		return result.Continue()
+	}
+
+	logger.Info("reconcile_racks::CheckSystemKeyspacesReplication")
+
+	pods := ListAllStartedPods(rc.dcPods)
+	if len(pods) == 0 {
+		return result.Continue()
+	}
+
+	for _, keyspace := range systemKeyspaces {
+		options, err := rc.NodeMgmtClient.CallGetKeyspaceReplicationEndpoint(pods[0], keyspace)
+		if err != nil {
+			logger.Error(err, "error getting keyspace replication", "keyspace", keyspace)
+			return result.Error(err)
+		}
+
+		settings := getSystemKeyspaceReplicationSettings(dc.Name, replicationFactor, options)
+		if err := rc.NodeMgmtClient.CallAlterKeyspaceEndpoint(pods[0], keyspace, settings); err != nil {
+			logger.Error(err, "error altering keyspace", "keyspace", keyspace)
+			return result.Error(err)
+		}
+	}
+
+	dcPatch := client.MergeFrom(dc.DeepCopy())
+	dc.Status.SystemKeyspacesRepair = &api.SystemKeyspacesRepairStatus{ReplicationFactor: replicationFactor}
+	if err := rc.Client.Status().Patch(rc.Ctx, dc, dcPatch); err != nil {
+		logger.Error(err, "error patching datacenter status")
+		return result.Error(err)
+	}
+
+	return result.Continue()
+}
+
+// CheckSystemAuthRepair repairs system_auth after its replication was
+// altered, so that every node has the credentials it now owns. The repair
+// runs on one node at a time, and its progress is kept in the status so that
+// it carries on from where it stopped. The replication factor is only
+// recorded in the status once every node is repaired.
+func (rc *ReconciliationContext) CheckSystemAuthRepair() result.ReconcileResult {
+	dc := rc.Datacenter
+	logger := rc.ReqLogger
+
+	status := dc.Status.SystemKeyspacesRepair
+	if status == nil || dc.Spec.Stopped {
+		return result.Continue()
+	}
+
+	logger.Info("reconcile_racks::CheckSystemAuthRepair")
+
+	if status.JobID != "" {
+		return rc.checkSystemAuthRepair()
+	}
+
+	for _, pod := range ListAllStartedPods(rc.dcPods) {
+		if utils.IndexOfString(status.RepairedPods, pod.Name) >= 0 {
+			continue
+		}
+
+		jobID, err := rc.NodeMgmtClient.CallStartRepairEndpoint(pod, httphelper.RepairRequest{
+			Keyspace:   "system_auth",
+			FullRepair: true,
+		})
+		if err != nil {
+			logger.Error(err, "error starting repair of system_auth", "pod", pod.Name)
+			return result.Error(err)
+		}
+
+		dcPatch := client.MergeFrom(dc.DeepCopy())
+		status.PodName = pod.Name
+		status.JobID = jobID
+		if err := rc.Client.Status().Patch(rc.Ctx, dc, dcPatch); err != nil {
+			logger.Error(err, "error patching datacenter status")
+			return result.Error(err)
+		}
+		return result.RequeueSoon(10)
+	}
+
+	replicationFactor := status.ReplicationFactor
+	dcPatch := client.MergeFrom(dc.DeepCopy())
+	dc.Status.SystemKeyspacesReplicationFactor = replicationFactor
+	dc.Status.SystemKeyspacesRepair = nil
+	if err := rc.Client.Status().Patch(rc.Ctx, dc, dcPatch); err != nil {
+		logger.Error(err, "error patching datacenter status")
+		return result.Error(err)
+	}
+
+	rc.Recorder.Eventf(dc, corev1.EventTypeNormal, events.AlteredSystemKeyspaces,
+		"Altered the replication factor of %s to %d in datacenter %s",
+		strings.Join(systemKeyspaces, ", "), replicationFactor, dc.Name)
+
+	return result.Continue()
+}
+
+// checkSystemAuthRepair waits for the repair of system_auth in progress to
+// finish, and records its pod as repaired once it has. A repair that failed
+// is started again on the same pod after a while, as is one that the node no
+// longer knows of, which happens when it restarts. Other errors getting the
+// job are retried.
+func (rc *ReconciliationContext) checkSystemAuthRepair() result.ReconcileResult {
+	dc := rc.Datacenter
+	status := dc.Status.SystemKeyspacesRepair
+	logger := rc.ReqLogger.WithValues("pod", status.PodName, "jobId", status.JobID)
+
+	pod := findPodByName(rc.dcPods, status.PodName)
+	if pod == nil || !isServerStarted(pod) {
+		logger.Info("Waiting for the node being repaired to be started")
+		return result.RequeueSoon(10)
+	}
+
+	details, err := rc.NodeMgmtClient.CallJobDetailsEndpoint(pod, status.JobID)
+	if httphelper.IsNotFound(err) {
+		rc.Recorder.Eventf(dc, corev1.EventTypeWarning, events.FailedRepair,
+			"Lost track of the repair of keyspace system_auth on pod %s: %s", pod.Name, err.Error())
+		return rc.finishSystemAuthRepair(false)
+	}
+	if err != nil {
+		logger.Error(err, "error getting repair job")
+		return result.RequeueSoon(10)
+	}
+
+	switch details.Status {
+	case httphelper.JobStatusCompleted:
+		return rc.finishSystemAuthRepair(true)
+	case httphelper.JobStatusError:
+		rc.Recorder.Eventf(dc, corev1.EventTypeWarning, events.FailedRepair,
+			"Failed to repair keyspace system_auth on pod %s: %s", pod.Name, details.Error)
+		return rc.finishSystemAuthRepair(false)
+	}
+
+	logger.Info("Waiting for repair of system_auth to finish")
+	return result.RequeueSoon(10)
+}
+
+// finishSystemAuthRepair forgets the repair job in progress, recording its
+// pod as repaired when it succeeded
+func (rc *ReconciliationContext) finishSystemAuthRepair(succeeded bool) result.ReconcileResult {
+	dc := rc.Datacenter
+	status := dc.Status.SystemKeyspacesRepair
+
+	dcPatch := client.MergeFrom(dc.DeepCopy())
+	if succeeded {
+		status.RepairedPods = append(status.RepairedPods, status.PodName)
+	}
+	status.PodName = ""
+	status.JobID = ""
+	if err := rc.Client.Status().Patch(rc.Ctx, dc, dcPatch); err != nil {
+		rc.ReqLogger.Error(err, "error patching datacenter status")
+		return result.Error(err)
+	}
+
+	if succeeded {
+		return result.RequeueSoon(0)
+	}
+	return result.RequeueSoon(30)
+}
+
+// getSystemKeyspacesReplicationFactor returns the replication factor of the
+// system keyspaces for the size of the datacenter
+func getSystemKeyspacesReplicationFactor(dc *api.CassandraDatacenter) int32 {
+	if dc.Spec.Size < maxSystemKeyspacesReplicationFactor {
+		return dc.Spec.Size
+	}
+	return maxSystemKeyspacesReplicationFactor
+}
+
+// getSystemKeyspaceReplicationSettings returns the replication of a system
+// keyspace with the given replication factor in this datacenter. The
+// replication of other datacenters is kept when the keyspace already uses
+// NetworkTopologyStrategy; otherwise, as with the default SimpleStrategy, it
+// is replicated to this datacenter only.
+func getSystemKeyspaceReplicationSettings(dcName string, replicationFactor int32, options map[string]string) []httphelper.ReplicationSetting {
+	replication, err := httphelper.ParseNetworkTopologyReplication(options)
+	if err != nil {
+		replication = map[string]int32{}
+	}
+	replication[dcName] = replicationFactor
+	return httphelper.NewReplicationSettings(replication)
+}
@@ -0,0 +1,251 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/httphelper"
+	"github.com/datastax/cass-operator/operator/pkg/mocks"
+)
+
+// setupSystemKeyspacesTest gives the datacenter started pods whose
+// management API reports the given replication for every keyspace and whose
+// repairs complete, and returns that management API
+func setupSystemKeyspacesTest(t *testing.T, rc *ReconciliationContext, replication map[string]string) *mocks.ManagementApi {
+	desiredStatefulSet, err := newStatefulSetForCassandraDatacenter("default", rc.Datacenter, 2)
+	assert.NoErrorf(t, err, "error occurred creating statefulset")
+
+	pods := mockRunningPodsForRack(desiredStatefulSet, rc.Datacenter, "default")
+	trackObjects := []runtime.Object{rc.Datacenter}
+	for _, pod := range pods {
+		trackObjects = append(trackObjects, pod)
+	}
+	rc.Client = fake.NewFakeClient(trackObjects...)
+	rc.dcPods = pods
+
+	replicationBody, _ := json.Marshal(replication)
+	mgmtApi := mocks.NewManagementApi(map[string]string{
+		"/api/v0/ops/keyspace/replication": string(replicationBody),
+		"/api/v1/repair":                   `{"repair_id":"repair-1"}`,
+		"/api/v0/ops/executor/job":         `{"id":"repair-1","type":"repair","status":"COMPLETED"}`,
+	})
+	rc.NodeMgmtClient = httphelper.NodeMgmtClient{Client: mgmtApi.HttpClient(), Log: rc.ReqLogger, Protocol: "http"}
+
+	return mgmtApi
+}
+
+func TestCheckSystemKeyspacesReplication(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	mgmtApi := setupSystemKeyspacesTest(t, rc, map[string]string{
+		"class":            "org.apache.cassandra.locator.NetworkTopologyStrategy",
+		"dc2":              "3",
+		rc.Datacenter.Name: "1",
+	})
+
+	recResult := rc.CheckSystemKeyspacesReplication()
+	assert.False(t, recResult.Completed(), "Should leave the repair to the background")
+
+	if assert.Equal(t, 3, len(mgmtApi.Posts), "Should alter 3 keyspaces") {
+		for idx, keyspace := range systemKeyspaces {
+			assert.Equal(t, "/api/v0/ops/keyspace/alter", mgmtApi.Posts[idx].URL.Path)
+			body, err := ioutil.ReadAll(mgmtApi.Posts[idx].Body)
+			assert.NoError(t, err)
+			assert.JSONEq(t,
+				`{"keyspace_name":"`+keyspace+`","replication_settings":[`+
+					`{"dc_name":"cassandradatacenter-example","replication_factor":2},`+
+					`{"dc_name":"dc2","replication_factor":3}]}`,
+				string(body))
+		}
+	}
+	assert.Equal(t, &api.SystemKeyspacesRepairStatus{ReplicationFactor: 2}, rc.Datacenter.Status.SystemKeyspacesRepair)
+
+	recResult = rc.CheckSystemKeyspacesReplication()
+	assert.False(t, recResult.Completed())
+	assert.Equal(t, 3, len(mgmtApi.Posts), "Should not alter the keyspaces again while repairing")
+
+	recResult = rc.CheckSystemAuthRepair()
+	assert.True(t, recResult.Completed(), "Should requeue while repairing")
+	if assert.Equal(t, 4, len(mgmtApi.Posts), "Should start repairing the first node") {
+		assert.Equal(t, "/api/v1/repair", mgmtApi.Posts[3].URL.Path)
+		body, err := ioutil.ReadAll(mgmtApi.Posts[3].Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"keyspace":"system_auth","tables":[],"full_repair":true}`, string(body))
+	}
+
+	assert.Equal(t, &api.SystemKeyspacesRepairStatus{
+		ReplicationFactor: 2,
+		PodName:           rc.dcPods[0].Name,
+		JobID:             "repair-1",
+	}, rc.Datacenter.Status.SystemKeyspacesRepair)
+	assert.Equal(t, int32(0), rc.Datacenter.Status.SystemKeyspacesReplicationFactor,
+		"Should only record the replication factor once every node is repaired")
+
+	for i := 0; i < 3; i++ {
+		recResult = rc.CheckSystemAuthRepair()
+		assert.True(t, recResult.Completed(), "Should requeue while repairing")
+	}
+	assert.Equal(t, 5, len(mgmtApi.Posts), "Should repair the second node")
+
+	recResult = rc.CheckSystemAuthRepair()
+	assert.False(t, recResult.Completed())
+	assert.Equal(t, int32(2), rc.Datacenter.Status.SystemKeyspacesReplicationFactor)
+	assert.Nil(t, rc.Datacenter.Status.SystemKeyspacesRepair)
+}
+
+func TestCheckSystemKeyspacesReplication_SizeChangedWhileRepairing(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	mgmtApi := setupSystemKeyspacesTest(t, rc, map[string]string{})
+	rc.Datacenter.Spec.Size = 3
+	rc.Datacenter.Status.SystemKeyspacesRepair = &api.SystemKeyspacesRepairStatus{
+		ReplicationFactor: 2,
+		RepairedPods:      []string{rc.dcPods[0].Name},
+	}
+	assert.NoError(t, rc.Client.Status().Update(rc.Ctx, rc.Datacenter))
+
+	recResult := rc.CheckSystemKeyspacesReplication()
+	assert.False(t, recResult.Completed())
+	assert.Equal(t, 3, len(mgmtApi.Posts), "Should alter the keyspaces to the new replication factor")
+	assert.Equal(t, &api.SystemKeyspacesRepairStatus{ReplicationFactor: 3}, rc.Datacenter.Status.SystemKeyspacesRepair,
+		"Should repair every node again")
+}
+
+func TestCheckSystemAuthRepair_Resumes(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	mgmtApi := setupSystemKeyspacesTest(t, rc, map[string]string{})
+	rc.Datacenter.Status.SystemKeyspacesRepair = &api.SystemKeyspacesRepairStatus{
+		ReplicationFactor: 2,
+		RepairedPods:      []string{rc.dcPods[0].Name},
+	}
+	assert.NoError(t, rc.Client.Status().Update(rc.Ctx, rc.Datacenter))
+
+	recResult := rc.CheckSystemAuthRepair()
+	assert.True(t, recResult.Completed(), "Should requeue while repairing")
+	if assert.Equal(t, 1, len(mgmtApi.Posts), "Should only repair the node left") {
+		assert.Equal(t, "/api/v1/repair", mgmtApi.Posts[0].URL.Path)
+	}
+	assert.Equal(t, rc.dcPods[1].Name, rc.Datacenter.Status.SystemKeyspacesRepair.PodName)
+}
+
+func TestCheckSystemAuthRepair_RepairFailed(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	mgmtApi := setupSystemKeyspacesTest(t, rc, map[string]string{})
+	mgmtApi.Responses["/api/v0/ops/executor/job"] = `{"id":"repair-1","type":"repair","status":"ERROR","error":"node down"}`
+	rc.Datacenter.Status.SystemKeyspacesRepair = &api.SystemKeyspacesRepairStatus{
+		ReplicationFactor: 2,
+		PodName:           rc.dcPods[0].Name,
+		JobID:             "repair-1",
+	}
+	assert.NoError(t, rc.Client.Status().Update(rc.Ctx, rc.Datacenter))
+
+	recResult := rc.CheckSystemAuthRepair()
+	res, err := recResult.Output()
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, res.RequeueAfter, "Should wait before repairing again")
+	assert.Empty(t, mgmtApi.Posts)
+	assert.Equal(t, &api.SystemKeyspacesRepairStatus{ReplicationFactor: 2}, rc.Datacenter.Status.SystemKeyspacesRepair)
+}
+
+func TestCheckSystemAuthRepair_RepairLost(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	mgmtApi := setupSystemKeyspacesTest(t, rc, map[string]string{})
+	mgmtApi.StatusCodes = map[string]int{"/api/v0/ops/executor/job": http.StatusNotFound}
+	rc.Datacenter.Status.SystemKeyspacesRepair = &api.SystemKeyspacesRepairStatus{
+		ReplicationFactor: 2,
+		PodName:           rc.dcPods[0].Name,
+		JobID:             "repair-1",
+	}
+	assert.NoError(t, rc.Client.Status().Update(rc.Ctx, rc.Datacenter))
+
+	recResult := rc.CheckSystemAuthRepair()
+	res, err := recResult.Output()
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, res.RequeueAfter, "Should wait before repairing again")
+	assert.Equal(t, &api.SystemKeyspacesRepairStatus{ReplicationFactor: 2}, rc.Datacenter.Status.SystemKeyspacesRepair,
+		"Should forget a repair the node no longer knows of")
+}
+
+func TestCheckSystemAuthRepair_JobUnavailable(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	mgmtApi := setupSystemKeyspacesTest(t, rc, map[string]string{})
+	mgmtApi.StatusCodes = map[string]int{"/api/v0/ops/executor/job": http.StatusInternalServerError}
+	status := &api.SystemKeyspacesRepairStatus{
+		ReplicationFactor: 2,
+		PodName:           rc.dcPods[0].Name,
+		JobID:             "repair-1",
+	}
+	rc.Datacenter.Status.SystemKeyspacesRepair = status.DeepCopy()
+	assert.NoError(t, rc.Client.Status().Update(rc.Ctx, rc.Datacenter))
+
+	recResult := rc.CheckSystemAuthRepair()
+	res, err := recResult.Output()
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Second, res.RequeueAfter, "Should check the repair again")
+	assert.Equal(t, status, rc.Datacenter.Status.SystemKeyspacesRepair,
+		"Should keep track of the repair when its job could not be read")
+}
+
+func TestCheckSystemKeyspacesReplication_UpToDate(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	mgmtApi := setupSystemKeyspacesTest(t, rc, map[string]string{})
+	rc.Datacenter.Status.SystemKeyspacesReplicationFactor = 2
+
+	recResult := rc.CheckSystemKeyspacesReplication()
+	assert.False(t, recResult.Completed())
+	assert.Empty(t, mgmtApi.Posts)
+}
+
+func TestCheckSystemKeyspacesReplication_Stopped(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	mgmtApi := setupSystemKeyspacesTest(t, rc, map[string]string{})
+	rc.Datacenter.Spec.Stopped = true
+
+	recResult := rc.CheckSystemKeyspacesReplication()
+	assert.False(t, recResult.Completed())
+	assert.Empty(t, mgmtApi.Posts)
+	assert.Equal(t, int32(0), rc.Datacenter.Status.SystemKeyspacesReplicationFactor)
+}
+
+func Test_getSystemKeyspaceReplicationSettings(t *testing.T) {
+	settings := getSystemKeyspaceReplicationSettings("dc1", 3, map[string]string{
+		"class":              "org.apache.cassandra.locator.SimpleStrategy",
+		"replication_factor": "1",
+	})
+	assert.Equal(t, []httphelper.ReplicationSetting{{DcName: "dc1", ReplicationFactor: 3}}, settings)
+}
+
+func Test_getSystemKeyspacesReplicationFactor(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	for size, expected := range map[int32]int32{1: 1, 3: 3, 6: 3} {
+		rc.Datacenter.Spec.Size = size
+		assert.Equal(t, expected, getSystemKeyspacesReplicationFactor(rc.Datacenter))
+	}
+}
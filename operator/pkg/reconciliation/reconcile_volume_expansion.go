@@ -0,0 +1,375 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/datastax/cass-operator/operator/internal/result"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/events"
+)
+
+// CheckVolumeExpansion expands the volumes of the racks whose StatefulSet
+// requests less storage than cassandraDataVolumeClaimSpec. The volume claim
+// templates of a StatefulSet cannot be changed, so once the
+// PersistentVolumeClaims are expanded the StatefulSet is deleted, leaving its
+// pods running, and created again by CheckRackCreation. The expansion itself
+// is followed by CheckVolumeExpansionProgress. A rack whose volumes cannot be
+// expanded is reported with the VolumeExpansionBlocked condition, and keeps
+// its current storage request while the rest of the datacenter is reconciled.
+func (rc *ReconciliationContext) CheckVolumeExpansion() result.ReconcileResult {
+	logger := rc.ReqLogger
+	dc := rc.Datacenter
+
+	claimSpec := dc.Spec.StorageConfig.CassandraDataVolumeClaimSpec
+	if claimSpec == nil {
+		return result.Continue()
+	}
+	desiredSize, ok := claimSpec.Resources.Requests[corev1.ResourceStorage]
+	if !ok {
+		return result.Continue()
+	}
+
+	logger.Info("reconcile_racks::CheckVolumeExpansion")
+
+	blockedRacks := map[string]error{}
+	for idx := range rc.desiredRackInformation {
+		rackName := rc.desiredRackInformation[idx].RackName
+		statefulSet := rc.statefulSets[idx]
+
+		if statefulSet.GetDeletionTimestamp() != nil {
+			logger.Info("Waiting for statefulset to be deleted before creating it again",
+				"statefulSet", statefulSet.Name)
+			return result.RequeueSoon(2)
+		}
+
+		currentSize, ok := getStatefulSetStorageRequest(statefulSet)
+		if !ok || desiredSize.Cmp(currentSize) <= 0 {
+			continue
+		}
+
+		pvcs, err := rc.listRackDataPVCs(rackName)
+		if err != nil {
+			logger.Error(err, "Failed to list PVCs for CassandraDatacenter")
+			return result.Error(err)
+		}
+
+		// All of the volumes are checked first, so that none of them is
+		// expanded when the rack cannot be
+		for _, pvc := range pvcs {
+			if err := rc.checkVolumeExpansionAllowed(pvc); err != nil {
+				blockedRacks[rackName] = err
+				break
+			}
+		}
+		if blockedRacks[rackName] != nil {
+			continue
+		}
+
+		return rc.expandRackVolumes(rackName, statefulSet, pvcs, desiredSize)
+	}
+
+	return rc.setVolumeExpansionBlocked(blockedRacks)
+}
+
+// setVolumeExpansionBlocked sets the VolumeExpansionBlocked condition when
+// the volumes of some racks cannot be expanded, emitting an event for each of
+// those racks when the condition is first set, and clears it otherwise
+func (rc *ReconciliationContext) setVolumeExpansionBlocked(blockedRacks map[string]error) result.ReconcileResult {
+	dc := rc.Datacenter
+	if len(blockedRacks) == 0 && dc.GetConditionStatus(api.DatacenterVolumeExpansionBlocked) != corev1.ConditionTrue {
+		return result.Continue()
+	}
+	dcPatch := client.MergeFrom(dc.DeepCopy())
+
+	status := corev1.ConditionFalse
+	if len(blockedRacks) > 0 {
+		status = corev1.ConditionTrue
+	}
+	if !rc.setCondition(api.NewDatacenterCondition(api.DatacenterVolumeExpansionBlocked, status)) {
+		return result.Continue()
+	}
+
+	for _, rackInfo := range rc.desiredRackInformation {
+		if err := blockedRacks[rackInfo.RackName]; err != nil {
+			rc.Recorder.Eventf(dc, corev1.EventTypeWarning, events.VolumeExpansionBlocked,
+				"Cannot expand the volumes of rack %s: %s", rackInfo.RackName, err.Error())
+		}
+	}
+
+	if err := rc.Client.Status().Patch(rc.Ctx, dc, dcPatch); err != nil {
+		rc.ReqLogger.Error(err, "error patching datacenter status for volume expansion")
+		return result.Error(err)
+	}
+	return result.Continue()
+}
+
+// listRackDataPVCs returns the server-data PersistentVolumeClaims of a rack
+func (rc *ReconciliationContext) listRackDataPVCs(rackName string) ([]*corev1.PersistentVolumeClaim, error) {
+	persistentVolumeClaimList, err := rc.listPVCs()
+	if err != nil {
+		return nil, err
+	}
+
+	pvcs := []*corev1.PersistentVolumeClaim{}
+	for idx := range persistentVolumeClaimList.Items {
+		pvc := &persistentVolumeClaimList.Items[idx]
+		if pvc.Labels[api.RackLabel] == rackName && isServerDataPVC(pvc) {
+			pvcs = append(pvcs, pvc)
+		}
+	}
+	return pvcs, nil
+}
+
+// expandRackVolumes increases the storage request of the
+// PersistentVolumeClaims of a rack, then deletes the StatefulSet of the rack
+// without deleting its pods
+func (rc *ReconciliationContext) expandRackVolumes(rackName string, statefulSet *appsv1.StatefulSet, pvcs []*corev1.PersistentVolumeClaim, desiredSize resource.Quantity) result.ReconcileResult {
+	logger := rc.ReqLogger.WithValues("rackName", rackName)
+	dc := rc.Datacenter
+
+	dcPatch := client.MergeFrom(dc.DeepCopy())
+	for _, pvc := range pvcs {
+		currentSize := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if desiredSize.Cmp(currentSize) <= 0 {
+			continue
+		}
+
+		pvcPatch := client.MergeFrom(pvc.DeepCopy())
+		if pvc.Spec.Resources.Requests == nil {
+			pvc.Spec.Resources.Requests = corev1.ResourceList{}
+		}
+		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = desiredSize
+		if err := rc.Client.Patch(rc.Ctx, pvc, pvcPatch); err != nil {
+			logger.Error(err, "Failed to expand PVC", "pvc", pvc.Name)
+			return result.Error(err)
+		}
+
+		podName := getPVCPodName(pvc)
+		rc.Recorder.Eventf(dc, corev1.EventTypeNormal, events.ExpandingVolume,
+			"Expanding volume of pod %s to %s", podName, desiredSize.String())
+		setVolumeExpansionStatus(dc, api.VolumeExpansionStatus{
+			PodName: podName,
+			Size:    desiredSize,
+			State:   api.VolumeExpansionResizing,
+		})
+	}
+
+	if err := rc.Client.Status().Patch(rc.Ctx, dc, dcPatch); err != nil {
+		logger.Error(err, "error patching datacenter status for volume expansion")
+		return result.Error(err)
+	}
+
+	rc.Recorder.Eventf(dc, corev1.EventTypeNormal, events.UpdatingRack,
+		"Recreating statefulset %s to expand its volumes", statefulSet.Name)
+	err := rc.Client.Delete(rc.Ctx, statefulSet, client.PropagationPolicy(metav1.DeletePropagationOrphan))
+	if err != nil {
+		logger.Error(err, "Failed to delete statefulset", "statefulSet", statefulSet.Name)
+		return result.Error(err)
+	}
+
+	return result.RequeueSoon(2)
+}
+
+// checkVolumeExpansionAllowed returns an error when the StorageClass of the
+// PersistentVolumeClaim does not allow it to be expanded
+func (rc *ReconciliationContext) checkVolumeExpansionAllowed(pvc *corev1.PersistentVolumeClaim) error {
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return fmt.Errorf("PersistentVolumeClaim %s has no StorageClass", pvc.Name)
+	}
+
+	storageClass := &storagev1.StorageClass{}
+	if err := rc.Client.Get(rc.Ctx, types.NamespacedName{Name: *pvc.Spec.StorageClassName}, storageClass); err != nil {
+		return err
+	}
+
+	if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+		return fmt.Errorf("StorageClass %s does not allow volume expansion", storageClass.Name)
+	}
+	return nil
+}
+
+// CheckVolumeExpansionProgress follows the volumes being expanded through
+// their PersistentVolumeClaims, and removes them from the status once they
+// have their new size. The file system of some volumes is only resized once
+// they are mounted again, so their pods are restarted, one at a time.
+func (rc *ReconciliationContext) CheckVolumeExpansionProgress() result.ReconcileResult {
+	logger := rc.ReqLogger
+	dc := rc.Datacenter
+
+	if len(dc.Status.VolumeExpansions) == 0 {
+		return result.Continue()
+	}
+
+	persistentVolumeClaimList, err := rc.listPVCs()
+	if err != nil {
+		logger.Error(err, "Failed to list PVCs for CassandraDatacenter")
+		return result.Error(err)
+	}
+
+	pvcs := map[string]*corev1.PersistentVolumeClaim{}
+	for idx := range persistentVolumeClaimList.Items {
+		pvc := &persistentVolumeClaimList.Items[idx]
+		if isServerDataPVC(pvc) {
+			pvcs[getPVCPodName(pvc)] = pvc
+		}
+	}
+
+	dcPatch := client.MergeFrom(dc.DeepCopy())
+	changed := false
+	expansions := []api.VolumeExpansionStatus{}
+	for _, expansion := range dc.Status.VolumeExpansions {
+		pvc, found := pvcs[expansion.PodName]
+		if !found {
+			changed = true
+			continue
+		}
+
+		capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+		if capacity.Cmp(expansion.Size) >= 0 {
+			rc.Recorder.Eventf(dc, corev1.EventTypeNormal, events.ExpandedVolume,
+				"Expanded volume of pod %s to %s", expansion.PodName, capacity.String())
+			changed = true
+			continue
+		}
+
+		state := api.VolumeExpansionResizing
+		for _, condition := range pvc.Status.Conditions {
+			if condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending &&
+				condition.Status == corev1.ConditionTrue {
+				state = api.VolumeExpansionFileSystemResizePending
+			}
+		}
+		if expansion.State != state {
+			expansion.State = state
+			expansion.LastTransitionTime = metav1.Now()
+			changed = true
+		}
+		expansions = append(expansions, expansion)
+	}
+
+	if changed {
+		dc.Status.VolumeExpansions = expansions
+		if err := rc.Client.Status().Patch(rc.Ctx, dc, dcPatch); err != nil {
+			logger.Error(err, "error patching datacenter status for volume expansion")
+			return result.Error(err)
+		}
+	}
+
+	for _, expansion := range expansions {
+		if expansion.State != api.VolumeExpansionFileSystemResizePending {
+			continue
+		}
+
+		// A pod created since the file system resize became pending has
+		// already been restarted, and its volume is being resized
+		pod := findPodByName(rc.dcPods, expansion.PodName)
+		if pod == nil || !pod.CreationTimestamp.Before(&expansion.LastTransitionTime) {
+			continue
+		}
+
+		rc.Recorder.Eventf(dc, corev1.EventTypeNormal, events.RestartingCassandra,
+			"Restarting pod %s to resize the file system of its volume", pod.Name)
+		if err := rc.NodeMgmtClient.CallDrainEndpoint(pod); err != nil {
+			logger.Error(err, "error draining node before restarting it", "pod", pod.Name)
+		}
+		if err := rc.Client.Delete(rc.Ctx, pod); err != nil {
+			logger.Error(err, "error deleting pod to resize its volume", "pod", pod.Name)
+			return result.Error(err)
+		}
+		return result.RequeueSoon(10)
+	}
+
+	if len(expansions) > 0 {
+		logger.Info("Waiting for volumes to be expanded",
+			"remaining", len(expansions))
+		return result.RequeueSoon(10)
+	}
+
+	return result.Continue()
+}
+
+// setVolumeExpansionStatus adds or replaces the expansion of the volume of a
+// pod in the status of the datacenter
+func setVolumeExpansionStatus(dc *api.CassandraDatacenter, expansion api.VolumeExpansionStatus) {
+	expansion.LastTransitionTime = metav1.Now()
+	for idx := range dc.Status.VolumeExpansions {
+		if dc.Status.VolumeExpansions[idx].PodName == expansion.PodName {
+			dc.Status.VolumeExpansions[idx] = expansion
+			return
+		}
+	}
+	dc.Status.VolumeExpansions = append(dc.Status.VolumeExpansions, expansion)
+}
+
+// getStatefulSetStorageRequest returns the storage request of the server-data
+// volume claim template of the StatefulSet
+func getStatefulSetStorageRequest(statefulSet *appsv1.StatefulSet) (resource.Quantity, bool) {
+	for _, template := range statefulSet.Spec.VolumeClaimTemplates {
+		if template.Name == pvcName {
+			size, ok := template.Spec.Resources.Requests[corev1.ResourceStorage]
+			return size, ok
+		}
+	}
+	return resource.Quantity{}, false
+}
+
+// withStorageRequest returns the datacenter, or a copy of it whose
+// cassandraDataVolumeClaimSpec has the given storage request when it differs
+func withStorageRequest(dc *api.CassandraDatacenter, size resource.Quantity) *api.CassandraDatacenter {
+	claimSpec := dc.Spec.StorageConfig.CassandraDataVolumeClaimSpec
+	if claimSpec == nil {
+		return dc
+	}
+	if desiredSize, ok := claimSpec.Resources.Requests[corev1.ResourceStorage]; !ok || desiredSize.Equal(size) {
+		return dc
+	}
+
+	dc = dc.DeepCopy()
+	dc.Spec.StorageConfig.CassandraDataVolumeClaimSpec.Resources.Requests[corev1.ResourceStorage] = size
+	return dc
+}
+
+// isServerDataPVC tells whether the PersistentVolumeClaim is the data volume
+// of a pod, rather than one of its commit log, hints or saved caches volumes
+func isServerDataPVC(pvc *corev1.PersistentVolumeClaim) bool {
+	return strings.HasPrefix(pvc.Name, pvcName+"-")
+}
+
+// getPVCPodName returns the name of the pod that a server-data
+// PersistentVolumeClaim belongs to
+func getPVCPodName(pvc *corev1.PersistentVolumeClaim) string {
+	return strings.TrimPrefix(pvc.Name, pvcName+"-")
+}
+
+// getOrphanedPodReplicas returns the number of replicas a StatefulSet needs
+// to adopt the pods it left running when it was deleted, so that creating it
+// again does not delete them
+func getOrphanedPodReplicas(statefulSet *appsv1.StatefulSet, pods []*corev1.Pod) int32 {
+	replicas := int32(0)
+	for _, pod := range pods {
+		if !strings.HasPrefix(pod.Name, statefulSet.Name+"-") {
+			continue
+		}
+		ordinal, err := strconv.Atoi(strings.TrimPrefix(pod.Name, statefulSet.Name+"-"))
+		if err != nil {
+			continue
+		}
+		if int32(ordinal)+1 > replicas {
+			replicas = int32(ordinal) + 1
+		}
+	}
+	return replicas
+}
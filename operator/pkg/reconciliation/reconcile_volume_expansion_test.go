@@ -0,0 +1,231 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+)
+
+// setupVolumeExpansionTest gives the datacenter a rack of two nodes whose
+// StatefulSet and PersistentVolumeClaims request 1Gi, and raises the storage
+// request of the datacenter to 2Gi
+func setupVolumeExpansionTest(t *testing.T, rc *ReconciliationContext, allowVolumeExpansion bool) *appsv1.StatefulSet {
+	dc := rc.Datacenter
+	statefulSet, err := newStatefulSetForCassandraDatacenter("default", dc, 2)
+	assert.NoError(t, err)
+
+	storageClass := &storagev1.StorageClass{
+		ObjectMeta:           metav1.ObjectMeta{Name: *dc.Spec.StorageConfig.CassandraDataVolumeClaimSpec.StorageClassName},
+		AllowVolumeExpansion: &allowVolumeExpansion,
+	}
+
+	trackObjects := []runtime.Object{dc, statefulSet, storageClass}
+	for i := 0; i < 2; i++ {
+		trackObjects = append(trackObjects, &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%s-%d", pvcName, statefulSet.Name, i),
+				Namespace: dc.Namespace,
+				Labels:    dc.GetRackLabels("default"),
+			},
+			Spec: *dc.Spec.StorageConfig.CassandraDataVolumeClaimSpec.DeepCopy(),
+			Status: corev1.PersistentVolumeClaimStatus{
+				Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		})
+	}
+
+	claimSpec := dc.Spec.StorageConfig.CassandraDataVolumeClaimSpec.DeepCopy()
+	claimSpec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("2Gi")
+	dc.Spec.StorageConfig.CassandraDataVolumeClaimSpec = claimSpec
+
+	rc.Client = fake.NewFakeClient(trackObjects...)
+	rc.desiredRackInformation = []*RackInformation{{
+		RackName:  "default",
+		NodeCount: 2,
+	}}
+	rc.statefulSets = []*appsv1.StatefulSet{statefulSet}
+
+	return statefulSet
+}
+
+func getTestPVCRequest(t *testing.T, rc *ReconciliationContext, name string) resource.Quantity {
+	pvc := &corev1.PersistentVolumeClaim{}
+	nsName := types.NamespacedName{Name: name, Namespace: rc.Datacenter.Namespace}
+	assert.NoError(t, rc.Client.Get(rc.Ctx, nsName, pvc))
+	return pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+}
+
+func TestCheckVolumeExpansion(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	statefulSet := setupVolumeExpansionTest(t, rc, true)
+
+	recResult := rc.CheckVolumeExpansion()
+	assert.True(t, recResult.Completed())
+
+	for i := 0; i < 2; i++ {
+		size := getTestPVCRequest(t, rc, fmt.Sprintf("%s-%s-%d", pvcName, statefulSet.Name, i))
+		assert.Equal(t, "2Gi", size.String())
+	}
+
+	expansions := rc.Datacenter.Status.VolumeExpansions
+	if assert.Equal(t, 2, len(expansions)) {
+		assert.Equal(t, statefulSet.Name+"-0", expansions[0].PodName)
+		assert.Equal(t, "2Gi", expansions[0].Size.String())
+		assert.Equal(t, api.VolumeExpansionResizing, expansions[0].State)
+	}
+
+	err := rc.Client.Get(rc.Ctx, types.NamespacedName{Name: statefulSet.Name, Namespace: statefulSet.Namespace}, &appsv1.StatefulSet{})
+	assert.True(t, errors.IsNotFound(err), "Should delete the statefulset so it is created again")
+}
+
+func TestCheckVolumeExpansion_NotAllowed(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	statefulSet := setupVolumeExpansionTest(t, rc, false)
+	fakeRecorder := record.NewFakeRecorder(100)
+	rc.Recorder = fakeRecorder
+
+	recResult := rc.CheckVolumeExpansion()
+	assert.False(t, recResult.Completed(), "Should carry on with the rest of the reconcile")
+
+	size := getTestPVCRequest(t, rc, fmt.Sprintf("%s-%s-0", pvcName, statefulSet.Name))
+	assert.Equal(t, "1Gi", size.String())
+	assert.Empty(t, rc.Datacenter.Status.VolumeExpansions)
+	assert.Equal(t, corev1.ConditionTrue, rc.Datacenter.GetConditionStatus(api.DatacenterVolumeExpansionBlocked))
+	assert.Equal(t, 1, len(fakeRecorder.Events))
+
+	err := rc.Client.Get(rc.Ctx, types.NamespacedName{Name: statefulSet.Name, Namespace: statefulSet.Namespace}, &appsv1.StatefulSet{})
+	assert.NoError(t, err)
+
+	recResult = rc.CheckVolumeExpansion()
+	assert.False(t, recResult.Completed())
+	assert.Equal(t, 1, len(fakeRecorder.Events), "Should only report the blocked expansion once")
+
+	// The StatefulSet keeps the storage request its volumes have
+	desiredSts, err := rc.desiredStatefulSetForExistingStatefulSet(statefulSet, "default")
+	assert.NoError(t, err)
+	assert.Equal(t, statefulSet.Spec.VolumeClaimTemplates, desiredSts.Spec.VolumeClaimTemplates)
+
+	// Once the StorageClass allows it, the volumes are expanded and the
+	// condition is cleared
+	storageClass := &storagev1.StorageClass{}
+	assert.NoError(t, rc.Client.Get(rc.Ctx, types.NamespacedName{Name: *statefulSet.Spec.VolumeClaimTemplates[0].Spec.StorageClassName}, storageClass))
+	allowVolumeExpansion := true
+	storageClass.AllowVolumeExpansion = &allowVolumeExpansion
+	assert.NoError(t, rc.Client.Update(rc.Ctx, storageClass))
+
+	recResult = rc.CheckVolumeExpansion()
+	assert.True(t, recResult.Completed())
+	assert.Equal(t, 2, len(rc.Datacenter.Status.VolumeExpansions))
+
+	rc.statefulSets = []*appsv1.StatefulSet{desiredSts}
+	desiredSts.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("2Gi")}
+	recResult = rc.CheckVolumeExpansion()
+	assert.False(t, recResult.Completed())
+	assert.Equal(t, corev1.ConditionFalse, rc.Datacenter.GetConditionStatus(api.DatacenterVolumeExpansionBlocked))
+}
+
+func TestCheckVolumeExpansion_Progress(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	statefulSet := setupVolumeExpansionTest(t, rc, true)
+	// The statefulset was already created again with the new size
+	statefulSet.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("2Gi")
+	rc.Datacenter.Status.VolumeExpansions = []api.VolumeExpansionStatus{
+		{PodName: statefulSet.Name + "-0", Size: resource.MustParse("1Gi"), State: api.VolumeExpansionResizing},
+		{PodName: statefulSet.Name + "-1", Size: resource.MustParse("2Gi"), State: api.VolumeExpansionResizing},
+	}
+
+	recResult := rc.CheckVolumeExpansion()
+	assert.False(t, recResult.Completed(), "Should not hold up the other steps while volumes expand")
+
+	recResult = rc.CheckVolumeExpansionProgress()
+	assert.True(t, recResult.Completed(), "Should wait for the second volume")
+
+	expansions := rc.Datacenter.Status.VolumeExpansions
+	if assert.Equal(t, 1, len(expansions)) {
+		assert.Equal(t, statefulSet.Name+"-1", expansions[0].PodName)
+	}
+}
+
+func TestCheckVolumeExpansionProgress_FileSystemResizePending(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	statefulSet := setupVolumeExpansionTest(t, rc, true)
+	pods := mockRunningPodsForRack(statefulSet, rc.Datacenter, "default")
+	created := metav1.NewTime(time.Now().Add(-time.Hour))
+	for _, pod := range pods {
+		pod.CreationTimestamp = created
+		assert.NoError(t, rc.Client.Create(rc.Ctx, pod))
+	}
+	rc.dcPods = pods
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	pvcName := types.NamespacedName{Name: fmt.Sprintf("%s-%s", pvcName, pods[0].Name), Namespace: pods[0].Namespace}
+	assert.NoError(t, rc.Client.Get(rc.Ctx, pvcName, pvc))
+	pvc.Status.Conditions = []corev1.PersistentVolumeClaimCondition{{
+		Type:   corev1.PersistentVolumeClaimFileSystemResizePending,
+		Status: corev1.ConditionTrue,
+	}}
+	assert.NoError(t, rc.Client.Status().Update(rc.Ctx, pvc))
+	rc.Datacenter.Status.VolumeExpansions = []api.VolumeExpansionStatus{
+		{PodName: pods[0].Name, Size: resource.MustParse("2Gi"), State: api.VolumeExpansionResizing},
+	}
+
+	recResult := rc.CheckVolumeExpansionProgress()
+	assert.True(t, recResult.Completed(), "Should wait for the pod to be restarted")
+
+	expansions := rc.Datacenter.Status.VolumeExpansions
+	if assert.Equal(t, 1, len(expansions)) {
+		assert.Equal(t, api.VolumeExpansionFileSystemResizePending, expansions[0].State)
+	}
+	err := rc.Client.Get(rc.Ctx, types.NamespacedName{Name: pods[0].Name, Namespace: pods[0].Namespace}, &corev1.Pod{})
+	assert.True(t, errors.IsNotFound(err), "Should restart the pod so its volume is mounted again")
+	err = rc.Client.Get(rc.Ctx, types.NamespacedName{Name: pods[1].Name, Namespace: pods[1].Namespace}, &corev1.Pod{})
+	assert.NoError(t, err, "Should leave the other pods alone")
+
+	// The pod created again is not restarted while its volume is resized
+	pods[0].CreationTimestamp = metav1.NewTime(time.Now().Add(time.Minute))
+	pods[0].ResourceVersion = ""
+	assert.NoError(t, rc.Client.Create(rc.Ctx, pods[0]))
+	recResult = rc.CheckVolumeExpansionProgress()
+	assert.True(t, recResult.Completed(), "Should wait for the file system to be resized")
+	err = rc.Client.Get(rc.Ctx, types.NamespacedName{Name: pods[0].Name, Namespace: pods[0].Namespace}, &corev1.Pod{})
+	assert.NoError(t, err)
+}
+
+func Test_getOrphanedPodReplicas(t *testing.T) {
+	statefulSet := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "cluster1-dc1-r1-sts"}}
+	newPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	assert.Equal(t, int32(0), getOrphanedPodReplicas(statefulSet, nil))
+	assert.Equal(t, int32(3), getOrphanedPodReplicas(statefulSet, []*corev1.Pod{
+		newPod("cluster1-dc1-r1-sts-0"),
+		newPod("cluster1-dc1-r1-sts-2"),
+		newPod("cluster1-dc1-r2-sts-4"),
+	}))
+}
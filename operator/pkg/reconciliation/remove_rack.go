@@ -0,0 +1,258 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/datastax/cass-operator/operator/internal/result"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/events"
+)
+
+// CheckRackRemoval looks for StatefulSets of racks that are no longer in the
+// datacenter spec. The nodes of such a rack are decommissioned one at a time,
+// and once the rack is empty its StatefulSet and PVCs are deleted.
+func (rc *ReconciliationContext) CheckRackRemoval() result.ReconcileResult {
+	logger := rc.ReqLogger
+	logger.Info("reconcile_racks::CheckRackRemoval")
+	dc := rc.Datacenter
+
+	if dc.Spec.Stopped {
+		return result.Continue()
+	}
+
+	removedStatefulSets, err := rc.listRemovedRackStatefulSets()
+	if err != nil {
+		return result.Error(err)
+	}
+
+	if len(removedStatefulSets) == 0 {
+		return result.Continue()
+	}
+
+	statefulSet := removedStatefulSets[0]
+	rackName := statefulSet.Labels[api.RackLabel]
+	replicas := *statefulSet.Spec.Replicas
+	rackPods := FilterPodListByLabels(rc.dcPods, dc.GetRackLabels(rackName))
+
+	if rackStatus := dc.Status.GetRackStatus(rackName); rackStatus == nil || rackStatus.State != api.RackRemoving {
+		if err := rc.startRackRemoval(rackName, replicas, rackPods); err != nil {
+			return result.Error(err)
+		}
+		return result.RequeueSoon(2)
+	}
+
+	if err := setOperatorProgressStatus(rc, api.ProgressUpdating); err != nil {
+		return result.Error(err)
+	}
+
+	if replicas > 0 {
+		podName := getStatefulSetPodNameForIdx(statefulSet, replicas-1)
+		pod := findPodByName(rackPods, podName)
+		if pod == nil {
+			logger.Info("Waiting for pod of removed rack to be created", "pod", podName)
+			return result.RequeueSoon(5)
+		}
+
+		if isServerDecommissioning(pod) {
+			// CheckDecommissioningNodes keeps track of this one
+			return result.RequeueSoon(5)
+		}
+
+		if !isServerStarted(pod) && !isServerStarting(pod) {
+			// Cassandra was never started on this pod, so it is not part of
+			// the ring and there is nothing to decommission
+			logger.Info("Removing pod of removed rack that never joined the ring", "pod", podName)
+			if err := rc.UpdateRackNodeCount(statefulSet, replicas-1); err != nil {
+				return result.Error(err)
+			}
+			if err := rc.deletePodPVCs(pod); err != nil {
+				return result.Error(err)
+			}
+			return result.RequeueSoon(2)
+		}
+
+		if !isServerStarted(pod) || !isMgmtApiRunning(pod) {
+			logger.Info(
+				"Waiting for the management API to be available before decommissioning",
+				"pod", podName,
+			)
+			return result.RequeueSoon(5)
+		}
+
+		if err := rc.labelServerPodDecommissioning(pod); err != nil {
+			return result.Error(err)
+		}
+
+		dcPatch := client.MergeFrom(dc.DeepCopy())
+		rackStatus := dc.Status.GetRackStatus(rackName)
+		rackStatus.RemainingNodes = replicas
+		rackStatus.DecommissioningPod = podName
+		if err := rc.Client.Status().Patch(rc.Ctx, dc, dcPatch); err != nil {
+			logger.Error(err, "error patching datacenter status for rack removal")
+			return result.Error(err)
+		}
+
+		return rc.decommissionNode(pod)
+	}
+
+	if len(rackPods) > 0 {
+		logger.Info("Waiting for the pods of removed rack to terminate", "rack", rackName)
+		return result.RequeueSoon(5)
+	}
+
+	if err := rc.finishRackRemoval(statefulSet, rackName); err != nil {
+		return result.Error(err)
+	}
+
+	return result.RequeueSoon(2)
+}
+
+func (rc *ReconciliationContext) startRackRemoval(rackName string, replicas int32, rackPods []*corev1.Pod) error {
+	dc := rc.Datacenter
+	logger := rc.ReqLogger
+
+	// The removed rack must not provide seeds while its nodes are decommissioned.
+	// New seeds are picked from the remaining racks.
+	for _, pod := range rackPods {
+		if pod.Labels[api.SeedNodeLabel] != "true" {
+			continue
+		}
+
+		patch := client.MergeFrom(pod.DeepCopy())
+		delete(pod.Labels, api.SeedNodeLabel)
+		if err := rc.Client.Patch(rc.Ctx, pod, patch); err != nil {
+			logger.Error(err, "Unable to remove seed label from pod", "pod", pod.Name)
+			return err
+		}
+
+		rc.Recorder.Eventf(rc.Datacenter, corev1.EventTypeNormal, events.UnlabeledPodAsSeed,
+			"Unlabled as seed node pod %s", pod.Name)
+	}
+
+	if _, err := rc.checkSeedLabels(); err != nil {
+		return err
+	}
+
+	if err := rc.refreshSeeds(); err != nil {
+		return err
+	}
+
+	dcPatch := client.MergeFrom(dc.DeepCopy())
+	rc.setCondition(
+		api.NewDatacenterCondition(
+			api.DatacenterScalingDown, corev1.ConditionTrue))
+	rackStatus := api.RackStatus{Name: rackName}
+	if existing := dc.Status.GetRackStatus(rackName); existing != nil {
+		rackStatus = *existing
+	}
+	rackStatus.State = api.RackRemoving
+	rackStatus.RemainingNodes = replicas
+	rackStatus.LastTransitionTime = metav1.Now()
+	dc.Status.SetRackStatus(rackStatus)
+
+	if err := rc.Client.Status().Patch(rc.Ctx, dc, dcPatch); err != nil {
+		logger.Error(err, "error patching datacenter status for rack removal started")
+		return err
+	}
+
+	rc.Recorder.Eventf(rc.Datacenter, corev1.EventTypeNormal, events.RemovingRack,
+		"Removing rack %s", rackName)
+
+	return nil
+}
+
+func (rc *ReconciliationContext) finishRackRemoval(statefulSet *appsv1.StatefulSet, rackName string) error {
+	dc := rc.Datacenter
+	logger := rc.ReqLogger
+
+	logger.Info("Deleting StatefulSet of removed rack", "rack", rackName)
+	if err := rc.Client.Delete(rc.Ctx, statefulSet); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	listOptions := &client.ListOptions{
+		Namespace:     dc.Namespace,
+		LabelSelector: labels.SelectorFromSet(dc.GetRackLabels(rackName)),
+	}
+	if err := rc.Client.List(rc.Ctx, pvcList, listOptions); err != nil {
+		return err
+	}
+
+	for idx := range pvcList.Items {
+		pvc := &pvcList.Items[idx]
+		if err := rc.Client.Delete(rc.Ctx, pvc); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to delete PVC of removed rack", "pvcName", pvc.Name)
+			return err
+		}
+		logger.Info(
+			"Deleted PVC",
+			"pvcNamespace", pvc.Namespace,
+			"pvcName", pvc.Name)
+	}
+
+	dcPatch := client.MergeFrom(dc.DeepCopy())
+	dc.Status.RemoveRackStatus(rackName)
+	if err := rc.Client.Status().Patch(rc.Ctx, dc, dcPatch); err != nil {
+		logger.Error(err, "error patching datacenter status for rack removal finished")
+		return err
+	}
+
+	rc.Recorder.Eventf(rc.Datacenter, corev1.EventTypeNormal, events.RemovedRack,
+		"Removed rack %s", rackName)
+
+	return nil
+}
+
+// listRemovedRackStatefulSets returns the StatefulSets of this datacenter that
+// belong to racks that are no longer part of the spec
+func (rc *ReconciliationContext) listRemovedRackStatefulSets() ([]*appsv1.StatefulSet, error) {
+	dc := rc.Datacenter
+
+	statefulSetList := &appsv1.StatefulSetList{}
+	listOptions := &client.ListOptions{
+		Namespace:     dc.Namespace,
+		LabelSelector: labels.SelectorFromSet(dc.GetDatacenterLabels()),
+	}
+	if err := rc.Client.List(rc.Ctx, statefulSetList, listOptions); err != nil {
+		return nil, err
+	}
+
+	desiredRacks := map[string]bool{}
+	for _, rack := range dc.GetRacks() {
+		desiredRacks[rack.Name] = true
+	}
+
+	removed := []*appsv1.StatefulSet{}
+	for idx := range statefulSetList.Items {
+		statefulSet := &statefulSetList.Items[idx]
+		rackName, ok := statefulSet.Labels[api.RackLabel]
+		if !ok || desiredRacks[rackName] {
+			continue
+		}
+
+		// only consider StatefulSets that we created for this datacenter
+		expectedName := newNamespacedNameForStatefulSet(dc, rackName)
+		if statefulSet.Name != expectedName.Name {
+			continue
+		}
+
+		removed = append(removed, statefulSet)
+	}
+
+	sort.SliceStable(removed, func(i, j int) bool {
+		return removed[i].Name < removed[j].Name
+	})
+
+	return removed, nil
+}
@@ -0,0 +1,186 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package reconciliation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+)
+
+// setupRackRemovalTest creates a datacenter with rack r1 in its spec, and
+// StatefulSets, pods and PVCs for both r1 and the removed rack r2
+func setupRackRemovalTest(t *testing.T, rc *ReconciliationContext, removedReplicas int) (*appsv1.StatefulSet, []*corev1.Pod) {
+	rc.Datacenter.Spec.Racks = []api.Rack{{Name: "r1"}}
+
+	keptStatefulSet, err := newStatefulSetForCassandraDatacenter("r1", rc.Datacenter, 2)
+	assert.NoErrorf(t, err, "error occurred creating statefulset")
+
+	removedStatefulSet, err := newStatefulSetForCassandraDatacenter("r2", rc.Datacenter, removedReplicas)
+	assert.NoErrorf(t, err, "error occurred creating statefulset")
+
+	keptPods := mockRunningPodsForRack(keptStatefulSet, rc.Datacenter, "r1")
+	removedPods := mockRunningPodsForRack(removedStatefulSet, rc.Datacenter, "r2")
+	for _, pod := range removedPods {
+		pod.Labels[api.SeedNodeLabel] = "true"
+	}
+
+	trackObjects := []runtime.Object{
+		keptStatefulSet,
+		removedStatefulSet,
+		rc.Datacenter,
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "server-data-removed",
+				Namespace: rc.Datacenter.Namespace,
+				Labels:    rc.Datacenter.GetRackLabels("r2"),
+			},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "server-data-kept",
+				Namespace: rc.Datacenter.Namespace,
+				Labels:    rc.Datacenter.GetRackLabels("r1"),
+			},
+		},
+	}
+	for _, pod := range append(keptPods, removedPods...) {
+		trackObjects = append(trackObjects, pod)
+	}
+
+	rc.Client = fake.NewFakeClient(trackObjects...)
+	rc.desiredRackInformation = []*RackInformation{{
+		RackName:  "r1",
+		NodeCount: 2,
+		SeedCount: 2,
+	}}
+	rc.statefulSets = []*appsv1.StatefulSet{keptStatefulSet}
+	rc.dcPods = append(keptPods, removedPods...)
+	rc.clusterPods = rc.dcPods
+
+	return removedStatefulSet, removedPods
+}
+
+func TestCheckRackRemoval_NothingToRemove(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	setupRackRemovalTest(t, rc, 2)
+	rc.Datacenter.Spec.Racks = []api.Rack{{Name: "r1"}, {Name: "r2"}}
+
+	recResult := rc.CheckRackRemoval()
+	assert.False(t, recResult.Completed(), "Should continue when no rack was removed")
+	assert.Nil(t, rc.Datacenter.Status.RackStatuses)
+}
+
+func TestCheckRackRemoval_StartsRemoval(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	_, removedPods := setupRackRemovalTest(t, rc, 2)
+
+	recResult := rc.CheckRackRemoval()
+	assert.True(t, recResult.Completed(), "Should requeue after starting the rack removal")
+
+	assert.Equal(t, corev1.ConditionTrue, rc.Datacenter.GetConditionStatus(api.DatacenterScalingDown))
+	rackStatus := rc.Datacenter.Status.GetRackStatus("r2")
+	assert.NotNil(t, rackStatus)
+	assert.Equal(t, api.RackRemoving, rackStatus.State)
+	assert.Equal(t, int32(2), rackStatus.RemainingNodes)
+
+	for _, removedPod := range removedPods {
+		pod := &corev1.Pod{}
+		err := rc.Client.Get(rc.Ctx, types.NamespacedName{Name: removedPod.Name, Namespace: removedPod.Namespace}, pod)
+		assert.NoError(t, err)
+		assert.Empty(t, pod.Labels[api.SeedNodeLabel], "Pods of a removed rack should not be seeds")
+	}
+}
+
+func TestCheckRackRemoval_StartsRemovalOfRackWithStatus(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	setupRackRemovalTest(t, rc, 2)
+	rc.Datacenter.Status.SetRackStatus(api.RackStatus{Name: "r2", Nodes: 2, ReadyNodes: 2})
+
+	recResult := rc.CheckRackRemoval()
+	assert.True(t, recResult.Completed(), "Should requeue after starting the rack removal")
+
+	rackStatus := rc.Datacenter.Status.GetRackStatus("r2")
+	if assert.NotNil(t, rackStatus) {
+		assert.Equal(t, api.RackRemoving, rackStatus.State)
+		assert.Equal(t, int32(2), rackStatus.RemainingNodes)
+		assert.Equal(t, int32(2), rackStatus.Nodes, "Should keep the node counts of the rack")
+	}
+}
+
+func TestCheckRackRemoval_DecommissionsHighestOrdinal(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	_, removedPods := setupRackRemovalTest(t, rc, 2)
+	rc.Datacenter.Status.SetRackStatus(api.RackStatus{Name: "r2", State: api.RackRemoving, RemainingNodes: 2})
+
+	recResult := rc.CheckRackRemoval()
+	assert.True(t, recResult.Completed(), "Should requeue while decommissioning")
+
+	pod := &corev1.Pod{}
+	err := rc.Client.Get(rc.Ctx, types.NamespacedName{Name: removedPods[1].Name, Namespace: removedPods[1].Namespace}, pod)
+	assert.NoError(t, err)
+	assert.Equal(t, stateDecommissioning, pod.Labels[api.CassNodeState])
+
+	err = rc.Client.Get(rc.Ctx, types.NamespacedName{Name: removedPods[0].Name, Namespace: removedPods[0].Namespace}, pod)
+	assert.NoError(t, err)
+	assert.Equal(t, stateStarted, pod.Labels[api.CassNodeState], "Only one node should be decommissioned at a time")
+
+	assert.Equal(t, removedPods[1].Name, rc.Datacenter.Status.GetRackStatus("r2").DecommissioningPod)
+}
+
+func TestCheckRackRemoval_DeletesEmptyRack(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	removedStatefulSet, _ := setupRackRemovalTest(t, rc, 0)
+	rc.Datacenter.Status.SetRackStatus(api.RackStatus{Name: "r2", State: api.RackRemoving})
+
+	recResult := rc.CheckRackRemoval()
+	assert.True(t, recResult.Completed(), "Should requeue after removing the rack")
+
+	err := rc.Client.Get(rc.Ctx, types.NamespacedName{Name: removedStatefulSet.Name, Namespace: removedStatefulSet.Namespace}, &appsv1.StatefulSet{})
+	assert.True(t, errors.IsNotFound(err), "StatefulSet of the removed rack should be deleted")
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	err = rc.Client.Get(rc.Ctx, types.NamespacedName{Name: "server-data-removed", Namespace: rc.Datacenter.Namespace}, pvc)
+	assert.True(t, errors.IsNotFound(err), "PVCs of the removed rack should be deleted")
+
+	err = rc.Client.Get(rc.Ctx, types.NamespacedName{Name: "server-data-kept", Namespace: rc.Datacenter.Namespace}, pvc)
+	assert.NoError(t, err, "PVCs of other racks should be kept")
+
+	assert.Nil(t, rc.Datacenter.Status.GetRackStatus("r2"))
+}
+
+func Test_countReadyAndStarted_RemovedRack(t *testing.T) {
+	rc, _, cleanupMockScr := setupTest()
+	defer cleanupMockScr()
+
+	setupRackRemovalTest(t, rc, 2)
+
+	ready, started := rc.countReadyAndStarted()
+	assert.Equal(t, 2, ready, "Should leave out the pods of the rack removed from the spec")
+	assert.Equal(t, 2, started, "Should leave out the pods of the rack removed from the spec")
+
+	rc.Datacenter.Status.SetRackStatus(api.RackStatus{Name: "r1", State: api.RackRemoving, RemainingNodes: 2})
+	ready, started = rc.countReadyAndStarted()
+	assert.Equal(t, 0, ready, "Should leave out the pods of a rack being removed")
+	assert.Equal(t, 0, started, "Should leave out the pods of a rack being removed")
+}
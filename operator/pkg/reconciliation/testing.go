@@ -95,7 +95,7 @@ func CreateMockReconciliationContext(
 	}
 
 	s := scheme.Scheme
-	s.AddKnownTypes(api.SchemeGroupVersion, cassandraDatacenter)
+	s.AddKnownTypes(api.SchemeGroupVersion, cassandraDatacenter, &api.CassandraRestore{}, &api.CassandraRestoreList{})
 
 	fakeClient := fake.NewFakeClient(trackObjects...)
 
@@ -0,0 +1,48 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package schema
+
+import (
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/datastax/cass-operator/operator/internal/dccontext"
+)
+
+// ReconciliationContext contains everything needed to reconcile the schema
+// of the cluster of a CassandraDatacenter
+type ReconciliationContext struct {
+	*dccontext.Context
+}
+
+// CreateReconciliationContext fetches the datacenter with the given name and
+// its pods, and sets up a client for the management API of those pods
+func CreateReconciliationContext(
+	namespace string,
+	dcName string,
+	cli runtimeClient.Client,
+	scheme *runtime.Scheme,
+	rec record.EventRecorder,
+	reqLogger logr.Logger) (*ReconciliationContext, error) {
+
+	reqLogger.Info("schema::CreateReconciliationContext", "datacenterName", dcName)
+
+	dcContext, err := dccontext.New(namespace, dcName, cli, scheme, rec, reqLogger)
+	if err != nil {
+		return nil, err
+	}
+	return &ReconciliationContext{Context: dcContext}, nil
+}
+
+// findStartedPod returns a pod whose Cassandra node is started, to send
+// schema changes to, or nil if there is none
+func (rc *ReconciliationContext) findStartedPod() *corev1.Pod {
+	if pods := rc.StartedPods(); len(pods) > 0 {
+		return pods[0]
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package schema
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/events"
+	"github.com/datastax/cass-operator/operator/pkg/httphelper"
+	"github.com/datastax/cass-operator/operator/pkg/utils"
+)
+
+// AddDatacenterToKeyspaces replicates the keyspaces of the source datacenter
+// to this datacenter too, with the replication factor they have in the source
+// datacenter, up to the size of this datacenter. Only keyspaces that use
+// NetworkTopologyStrategy are replicated, and keyspaces declared by a
+// CassandraKeyspace are left to their spec.
+func (rc *ReconciliationContext) AddDatacenterToKeyspaces(sourceDcName string) error {
+	logger := rc.ReqLogger
+	logger.Info("schema::AddDatacenterToKeyspaces")
+
+	dc := rc.Datacenter
+	pod := rc.findStartedPod()
+	if pod == nil {
+		return fmt.Errorf("no started node in datacenter %s to alter keyspaces through", dc.Name)
+	}
+
+	declared, err := rc.listDeclaredKeyspaceNames()
+	if err != nil {
+		logger.Error(err, "error listing keyspace resources")
+		return err
+	}
+
+	keyspaces, err := rc.NodeMgmtClient.CallListKeyspacesEndpoint(pod, "")
+	if err != nil {
+		logger.Error(err, "error listing keyspaces")
+		return err
+	}
+
+	for _, name := range keyspaces {
+		if utils.IndexOfString(declared, name) >= 0 {
+			continue
+		}
+
+		options, err := rc.NodeMgmtClient.CallGetKeyspaceReplicationEndpoint(pod, name)
+		if err != nil {
+			logger.Error(err, "error getting keyspace replication", "keyspace", name)
+			return err
+		}
+
+		// Local and SimpleStrategy keyspaces are not replicated per datacenter
+		replication, err := httphelper.ParseNetworkTopologyReplication(options)
+		if err != nil {
+			continue
+		}
+
+		replicationFactor, ok := replication[sourceDcName]
+		if _, exists := replication[dc.Name]; !ok || exists {
+			continue
+		}
+		if replicationFactor > dc.Spec.Size {
+			replicationFactor = dc.Spec.Size
+		}
+		replication[dc.Name] = replicationFactor
+
+		logger.Info("Adding datacenter to keyspace", "keyspace", name)
+		if err := rc.NodeMgmtClient.CallAlterKeyspaceEndpoint(pod, name, httphelper.NewReplicationSettings(replication)); err != nil {
+			logger.Error(err, "error altering keyspace", "keyspace", name)
+			return err
+		}
+		rc.Recorder.Eventf(dc, corev1.EventTypeNormal, events.AlteredKeyspace,
+			"Replicated keyspace %s to datacenter %s", name, dc.Name)
+	}
+
+	return nil
+}
+
+// listDeclaredKeyspaceNames returns the names of the keyspaces declared by
+// CassandraKeyspaces in the namespace of the datacenter
+func (rc *ReconciliationContext) listDeclaredKeyspaceNames() ([]string, error) {
+	keyspaceList := &api.CassandraKeyspaceList{}
+	listOptions := &runtimeClient.ListOptions{Namespace: rc.Datacenter.Namespace}
+	if err := rc.Client.List(rc.Ctx, keyspaceList, listOptions); err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for idx := range keyspaceList.Items {
+		names = append(names, keyspaceList.Items[idx].GetKeyspaceName())
+	}
+	return names, nil
+}
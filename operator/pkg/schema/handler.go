@@ -0,0 +1,81 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package schema
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/datastax/cass-operator/operator/internal/result"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+)
+
+var log = logf.Log.WithName("schema_handler")
+
+// ReconcileCassandraKeyspace reconciles a CassandraKeyspace object
+type ReconcileCassandraKeyspace struct {
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+}
+
+// Reconcile reads the state of a CassandraKeyspace and creates or alters
+// the keyspace to match it
+func (r *ReconcileCassandraKeyspace) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	startReconcile := time.Now()
+	logger := log.
+		WithValues("requestNamespace", request.Namespace).
+		WithValues("requestName", request.Name).
+		// loopID is used to tie all events together that are spawned by the same reconciliation loop
+		WithValues("loopID", uuid.New().String())
+
+	defer func() {
+		logger.Info("Reconcile loop completed",
+			"duration", time.Since(startReconcile).Seconds())
+	}()
+
+	logger.Info("======== keyspace handler::Reconcile has been called")
+
+	keyspace := &api.CassandraKeyspace{}
+	if err := r.client.Get(context.Background(), request.NamespacedName, keyspace); err != nil {
+		if errors.IsNotFound(err) {
+			logger.Info("CassandraKeyspace resource not found. Ignoring since object must be deleted.")
+			return result.Done().Output()
+		}
+		logger.Error(err, "Failed to get CassandraKeyspace.")
+		return result.Error(err).Output()
+	}
+
+	rc, err := CreateReconciliationContext(
+		request.Namespace, keyspace.Spec.CassandraDatacenter, r.client, r.scheme, r.recorder, logger)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			logger.Info("CassandraDatacenter of keyspace not found, waiting for it to be created",
+				"datacenter", keyspace.Spec.CassandraDatacenter)
+			return result.RequeueSoon(10).Output()
+		}
+		return result.Error(err).Output()
+	}
+
+	return rc.ReconcileKeyspace(keyspace).Output()
+}
+
+// NewKeyspaceReconciler returns a new reconcile.Reconciler for
+// CassandraKeyspaces
+func NewKeyspaceReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileCassandraKeyspace{
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: mgr.GetEventRecorderFor("cass-operator"),
+	}
+}
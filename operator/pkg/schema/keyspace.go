@@ -0,0 +1,121 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/datastax/cass-operator/operator/internal/result"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/events"
+	"github.com/datastax/cass-operator/operator/pkg/httphelper"
+	"github.com/datastax/cass-operator/operator/pkg/utils"
+)
+
+// How often, in seconds, the replication of a keyspace is compared with its
+// spec to detect drift
+const keyspaceDriftCheckInterval = 300
+
+// ReconcileKeyspace creates the keyspace when it does not exist, and alters
+// it when its replication differs from the spec. Differences that were not
+// caused by a change to the spec are recorded in the status as drift.
+func (rc *ReconciliationContext) ReconcileKeyspace(keyspace *api.CassandraKeyspace) result.ReconcileResult {
+	logger := rc.ReqLogger
+	logger.Info("schema::ReconcileKeyspace")
+
+	pod := rc.findStartedPod()
+	if pod == nil {
+		logger.Info("Waiting for a started node to manage the keyspace through")
+		return result.RequeueSoon(10)
+	}
+
+	name := keyspace.GetKeyspaceName()
+	desired := keyspace.Spec.Replication
+	patch := client.MergeFrom(keyspace.DeepCopy())
+
+	existing, err := rc.NodeMgmtClient.CallListKeyspacesEndpoint(pod, name)
+	if err != nil {
+		logger.Error(err, "error listing keyspaces")
+		return result.Error(err)
+	}
+
+	if utils.IndexOfString(existing, name) < 0 {
+		logger.Info("Creating keyspace", "keyspace", name)
+		if err := rc.NodeMgmtClient.CallCreateKeyspaceEndpoint(pod, name, httphelper.NewReplicationSettings(desired)); err != nil {
+			logger.Error(err, "error creating keyspace")
+			return result.Error(err)
+		}
+		rc.Recorder.Eventf(keyspace, corev1.EventTypeNormal, events.CreatedKeyspace,
+			"Created keyspace %s", name)
+	} else {
+		options, err := rc.NodeMgmtClient.CallGetKeyspaceReplicationEndpoint(pod, name)
+		if err != nil {
+			logger.Error(err, "error getting keyspace replication")
+			return result.Error(err)
+		}
+
+		observed, err := httphelper.ParseNetworkTopologyReplication(options)
+		if err != nil || !reflect.DeepEqual(observed, desired) {
+			// The keyspace already matched this spec, so something else
+			// changed it since
+			if keyspace.Status.Replication != nil && keyspace.Status.ObservedGeneration == keyspace.Generation {
+				message := describeReplicationDrift(observed, err)
+				logger.Info("Keyspace replication drifted from spec", "keyspace", name, "drift", message)
+				now := metav1.NewTime(time.Now())
+				keyspace.Status.LastDriftTime = &now
+				keyspace.Status.LastDriftMessage = message
+				rc.Recorder.Eventf(keyspace, corev1.EventTypeWarning, events.KeyspaceDrift,
+					"Keyspace %s %s", name, message)
+			}
+
+			logger.Info("Altering keyspace", "keyspace", name)
+			if err := rc.NodeMgmtClient.CallAlterKeyspaceEndpoint(pod, name, httphelper.NewReplicationSettings(desired)); err != nil {
+				logger.Error(err, "error altering keyspace")
+				return result.Error(err)
+			}
+			rc.Recorder.Eventf(keyspace, corev1.EventTypeNormal, events.AlteredKeyspace,
+				"Altered the replication of keyspace %s, a repair is needed to move existing data", name)
+		}
+	}
+
+	keyspace.Status.Replication = map[string]int32{}
+	for dcName, replicationFactor := range desired {
+		keyspace.Status.Replication[dcName] = replicationFactor
+	}
+	keyspace.Status.ObservedGeneration = keyspace.Generation
+	if err := rc.Client.Status().Patch(rc.Ctx, keyspace, patch); err != nil {
+		logger.Error(err, "error patching keyspace status")
+		return result.Error(err)
+	}
+
+	return result.RequeueSoon(keyspaceDriftCheckInterval)
+}
+
+// describeReplicationDrift describes the replication a keyspace was found
+// with, or why it could not be read
+func describeReplicationDrift(observed map[string]int32, parseErr error) string {
+	if parseErr != nil {
+		return parseErr.Error()
+	}
+
+	dcNames := []string{}
+	for dcName := range observed {
+		dcNames = append(dcNames, dcName)
+	}
+	sort.Strings(dcNames)
+
+	factors := []string{}
+	for _, dcName := range dcNames {
+		factors = append(factors, fmt.Sprintf("%s: %d", dcName, observed[dcName]))
+	}
+	return fmt.Sprintf("had replication {%s}", strings.Join(factors, ", "))
+}
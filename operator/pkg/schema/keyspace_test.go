@@ -0,0 +1,229 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/datastax/cass-operator/operator/internal/dccontext"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/httphelper"
+	"github.com/datastax/cass-operator/operator/pkg/mocks"
+)
+
+func newTestDatacenter() *api.CassandraDatacenter {
+	return &api.CassandraDatacenter{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "dc1",
+			Namespace: "default",
+		},
+		Spec: api.CassandraDatacenterSpec{
+			Size:          2,
+			ClusterName:   "cluster1",
+			ServerType:    "cassandra",
+			ServerVersion: "3.11.6",
+		},
+	}
+}
+
+func newTestPods(dc *api.CassandraDatacenter, nodeState string) []*corev1.Pod {
+	pods := []*corev1.Pod{}
+	for i := 0; i < int(dc.Spec.Size); i++ {
+		labels := dc.GetDatacenterLabels()
+		labels[api.CassNodeState] = nodeState
+		pods = append(pods, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%s-default-sts-%d", dc.Spec.ClusterName, dc.Name, i),
+				Namespace: dc.Namespace,
+				Labels:    labels,
+			},
+			Status: corev1.PodStatus{
+				PodIP: fmt.Sprintf("10.0.0.%d", i+1),
+			},
+		})
+	}
+	return pods
+}
+
+func newTestKeyspace(dc *api.CassandraDatacenter) *api.CassandraKeyspace {
+	return &api.CassandraKeyspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "app-data",
+			Namespace:  dc.Namespace,
+			Generation: 1,
+		},
+		Spec: api.CassandraKeyspaceSpec{
+			CassandraDatacenter: dc.Name,
+			Replication:         map[string]int32{"dc1": 3},
+		},
+	}
+}
+
+// setupTest creates a reconciliation context for the datacenter whose
+// management API reports the given keyspaces and replication, and returns
+// the POST requests it receives
+func setupTest(dc *api.CassandraDatacenter, pods []*corev1.Pod, keyspace *api.CassandraKeyspace, keyspaces []string, replication map[string]string) (*ReconciliationContext, *[]*http.Request) {
+	s := scheme.Scheme
+	s.AddKnownTypes(api.SchemeGroupVersion,
+		&api.CassandraDatacenter{},
+		&api.CassandraKeyspace{},
+		&api.CassandraKeyspaceList{})
+
+	trackObjects := []runtime.Object{dc, keyspace}
+	for _, pod := range pods {
+		trackObjects = append(trackObjects, pod)
+	}
+
+	keyspacesBody, _ := json.Marshal(keyspaces)
+	replicationBody, _ := json.Marshal(replication)
+
+	logger := zap.Logger(true)
+	mgmtApi := mocks.NewManagementApi(map[string]string{
+		"/api/v0/ops/keyspace":             string(keyspacesBody),
+		"/api/v0/ops/keyspace/replication": string(replicationBody),
+	})
+
+	rc := &ReconciliationContext{Context: &dccontext.Context{
+		Client:         fake.NewFakeClientWithScheme(s, trackObjects...),
+		Scheme:         s,
+		Datacenter:     dc,
+		NodeMgmtClient: httphelper.NodeMgmtClient{Client: mgmtApi.HttpClient(), Log: logger, Protocol: "http"},
+		Recorder:       record.NewFakeRecorder(100),
+		ReqLogger:      logger,
+		Ctx:            context.Background(),
+		Pods:           pods,
+	}}
+
+	return rc, &mgmtApi.Posts
+}
+
+func TestReconcileKeyspace_Creates(t *testing.T) {
+	dc := newTestDatacenter()
+	pods := newTestPods(dc, dccontext.NodeStateStarted)
+	keyspace := newTestKeyspace(dc)
+	rc, posts := setupTest(dc, pods, keyspace, []string{}, nil)
+
+	recResult := rc.ReconcileKeyspace(keyspace)
+	assert.True(t, recResult.Completed())
+	res, err := recResult.Output()
+	assert.NoError(t, err)
+	assert.True(t, res.RequeueAfter > 0, "Should requeue to check for drift")
+
+	if assert.Equal(t, 1, len(*posts)) {
+		assert.Equal(t, "/api/v0/ops/keyspace/create", (*posts)[0].URL.Path)
+		body, err := ioutil.ReadAll((*posts)[0].Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t,
+			`{"keyspace_name":"app_data","replication_settings":[{"dc_name":"dc1","replication_factor":3}]}`,
+			string(body))
+	}
+
+	assert.Equal(t, map[string]int32{"dc1": 3}, keyspace.Status.Replication)
+	assert.Equal(t, int64(1), keyspace.Status.ObservedGeneration)
+	assert.Nil(t, keyspace.Status.LastDriftTime)
+}
+
+func TestReconcileKeyspace_UpToDate(t *testing.T) {
+	dc := newTestDatacenter()
+	pods := newTestPods(dc, dccontext.NodeStateStarted)
+	keyspace := newTestKeyspace(dc)
+	rc, posts := setupTest(dc, pods, keyspace, []string{"app_data"}, map[string]string{
+		"class": "org.apache.cassandra.locator.NetworkTopologyStrategy",
+		"dc1":   "3",
+	})
+
+	rc.ReconcileKeyspace(keyspace)
+
+	assert.Empty(t, *posts)
+	assert.Equal(t, map[string]int32{"dc1": 3}, keyspace.Status.Replication)
+}
+
+func TestReconcileKeyspace_SpecChanged(t *testing.T) {
+	dc := newTestDatacenter()
+	pods := newTestPods(dc, dccontext.NodeStateStarted)
+	keyspace := newTestKeyspace(dc)
+	keyspace.Generation = 2
+	keyspace.Status.Replication = map[string]int32{"dc1": 1}
+	keyspace.Status.ObservedGeneration = 1
+	rc, posts := setupTest(dc, pods, keyspace, []string{"app_data"}, map[string]string{
+		"class": "org.apache.cassandra.locator.NetworkTopologyStrategy",
+		"dc1":   "1",
+	})
+
+	rc.ReconcileKeyspace(keyspace)
+
+	if assert.Equal(t, 1, len(*posts)) {
+		assert.Equal(t, "/api/v0/ops/keyspace/alter", (*posts)[0].URL.Path)
+	}
+	assert.Equal(t, map[string]int32{"dc1": 3}, keyspace.Status.Replication)
+	assert.Equal(t, int64(2), keyspace.Status.ObservedGeneration)
+	assert.Nil(t, keyspace.Status.LastDriftTime, "Should not report a spec change as drift")
+}
+
+func TestReconcileKeyspace_Drift(t *testing.T) {
+	dc := newTestDatacenter()
+	pods := newTestPods(dc, dccontext.NodeStateStarted)
+	keyspace := newTestKeyspace(dc)
+	keyspace.Status.Replication = map[string]int32{"dc1": 3}
+	keyspace.Status.ObservedGeneration = 1
+	rc, posts := setupTest(dc, pods, keyspace, []string{"app_data"}, map[string]string{
+		"class": "org.apache.cassandra.locator.NetworkTopologyStrategy",
+		"dc1":   "1",
+		"dc2":   "3",
+	})
+
+	rc.ReconcileKeyspace(keyspace)
+
+	if assert.Equal(t, 1, len(*posts)) {
+		assert.Equal(t, "/api/v0/ops/keyspace/alter", (*posts)[0].URL.Path)
+	}
+	assert.NotNil(t, keyspace.Status.LastDriftTime)
+	assert.Equal(t, "had replication {dc1: 1, dc2: 3}", keyspace.Status.LastDriftMessage)
+}
+
+func TestReconcileKeyspace_NoStartedNodes(t *testing.T) {
+	dc := newTestDatacenter()
+	pods := newTestPods(dc, "Ready-to-Start")
+	keyspace := newTestKeyspace(dc)
+	rc, posts := setupTest(dc, pods, keyspace, []string{}, nil)
+
+	recResult := rc.ReconcileKeyspace(keyspace)
+	assert.True(t, recResult.Completed())
+
+	assert.Empty(t, *posts)
+	assert.Nil(t, keyspace.Status.Replication)
+}
+
+func Test_describeReplicationDrift(t *testing.T) {
+	replication, err := httphelper.ParseNetworkTopologyReplication(map[string]string{
+		"class":              "org.apache.cassandra.locator.SimpleStrategy",
+		"replication_factor": "1",
+	})
+	assert.Error(t, err)
+	assert.Nil(t, replication)
+	assert.Equal(t, "uses org.apache.cassandra.locator.SimpleStrategy instead of NetworkTopologyStrategy",
+		describeReplicationDrift(replication, err))
+}
+
+func TestCassandraKeyspace_GetKeyspaceName(t *testing.T) {
+	keyspace := newTestKeyspace(newTestDatacenter())
+	assert.Equal(t, "app_data", keyspace.GetKeyspaceName())
+
+	keyspace.Spec.Name = "AppData"
+	assert.Equal(t, "AppData", keyspace.GetKeyspaceName())
+}
@@ -0,0 +1,481 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package serverconfig
+
+// This file describes the keys that the config builder accepts in the
+// sections of Spec.Config, per server type and version
+
+// valueType is the JSON type of the value of a config key
+type valueType int
+
+const (
+	typeString valueType = iota
+	typeInt
+	typeNumber
+	typeBool
+	typeList
+	typeObject
+	typeAny
+)
+
+func (t valueType) String() string {
+	switch t {
+	case typeString:
+		return "string"
+	case typeInt:
+		return "integer"
+	case typeNumber:
+		return "number"
+	case typeBool:
+		return "boolean"
+	case typeList:
+		return "list"
+	case typeObject:
+		return "object"
+	default:
+		return "any"
+	}
+}
+
+// sectionSchema holds the keys of a config section. A nil sectionSchema
+// accepts any key.
+type sectionSchema map[string]valueType
+
+// serverSchema holds the sections of the config of a server version. When
+// strict is false, sections and keys that are not listed are accepted, and
+// only the types of the listed keys are checked.
+type serverSchema struct {
+	sections map[string]sectionSchema
+	strict   bool
+}
+
+// withKeys returns a copy of the section with the added keys and without the
+// removed ones
+func (s sectionSchema) withKeys(added sectionSchema, removed ...string) sectionSchema {
+	keys := sectionSchema{}
+	for key, t := range s {
+		keys[key] = t
+	}
+	for key, t := range added {
+		keys[key] = t
+	}
+	for _, key := range removed {
+		delete(keys, key)
+	}
+	return keys
+}
+
+// The sections that the operator fills in itself
+var modelSections = map[string]sectionSchema{
+	"cluster-info":    nil,
+	"datacenter-info": nil,
+}
+
+var cassandraYaml311 = sectionSchema{
+	"allocate_tokens_for_keyspace":                         typeString,
+	"authenticator":                                        typeString,
+	"authorizer":                                           typeString,
+	"auto_bootstrap":                                       typeBool,
+	"auto_snapshot":                                        typeBool,
+	"automatic_sstable_upgrade":                            typeBool,
+	"back_pressure_enabled":                                typeBool,
+	"back_pressure_strategy":                               typeAny,
+	"batch_size_fail_threshold_in_kb":                      typeInt,
+	"batch_size_warn_threshold_in_kb":                      typeInt,
+	"batchlog_replay_throttle_in_kb":                       typeInt,
+	"broadcast_address":                                    typeString,
+	"broadcast_rpc_address":                                typeString,
+	"buffer_pool_use_heap_if_exhausted":                    typeBool,
+	"cas_contention_timeout_in_ms":                         typeInt,
+	"cdc_enabled":                                          typeBool,
+	"cdc_free_space_check_interval_ms":                     typeInt,
+	"cdc_raw_directory":                                    typeString,
+	"cdc_total_space_in_mb":                                typeInt,
+	"client_encryption_options":                            typeObject,
+	"cluster_name":                                         typeString,
+	"column_index_cache_size_in_kb":                        typeInt,
+	"column_index_size_in_kb":                              typeInt,
+	"commit_failure_policy":                                typeString,
+	"commitlog_compression":                                typeList,
+	"commitlog_directory":                                  typeString,
+	"commitlog_max_compression_buffers_in_pool":            typeInt,
+	"commitlog_periodic_queue_size":                        typeInt,
+	"commitlog_segment_size_in_mb":                         typeInt,
+	"commitlog_sync":                                       typeString,
+	"commitlog_sync_batch_window_in_ms":                    typeNumber,
+	"commitlog_sync_period_in_ms":                          typeInt,
+	"commitlog_total_space_in_mb":                          typeInt,
+	"compaction_large_partition_warning_threshold_mb":      typeInt,
+	"compaction_throughput_mb_per_sec":                     typeInt,
+	"concurrent_compactors":                                typeInt,
+	"concurrent_counter_writes":                            typeInt,
+	"concurrent_materialized_view_writes":                  typeInt,
+	"concurrent_reads":                                     typeInt,
+	"concurrent_replicates":                                typeInt,
+	"concurrent_writes":                                    typeInt,
+	"counter_cache_keys_to_save":                           typeInt,
+	"counter_cache_save_period":                            typeInt,
+	"counter_cache_size_in_mb":                             typeInt,
+	"counter_write_request_timeout_in_ms":                  typeInt,
+	"credentials_cache_max_entries":                        typeInt,
+	"credentials_update_interval_in_ms":                    typeInt,
+	"credentials_validity_in_ms":                           typeInt,
+	"cross_node_timeout":                                   typeBool,
+	"data_file_directories":                                typeList,
+	"disk_access_mode":                                     typeString,
+	"disk_failure_policy":                                  typeString,
+	"disk_optimization_estimate_percentile":                typeNumber,
+	"disk_optimization_page_cross_chance":                  typeNumber,
+	"disk_optimization_strategy":                           typeString,
+	"dynamic_snitch":                                       typeBool,
+	"dynamic_snitch_badness_threshold":                     typeNumber,
+	"dynamic_snitch_reset_interval_in_ms":                  typeInt,
+	"dynamic_snitch_update_interval_in_ms":                 typeInt,
+	"enable_materialized_views":                            typeBool,
+	"enable_sasi_indexes":                                  typeBool,
+	"enable_scripted_user_defined_functions":               typeBool,
+	"enable_user_defined_functions":                        typeBool,
+	"enable_user_defined_functions_threads":                typeBool,
+	"endpoint_snitch":                                      typeString,
+	"file_cache_round_up":                                  typeBool,
+	"file_cache_size_in_mb":                                typeInt,
+	"gc_log_threshold_in_ms":                               typeInt,
+	"gc_warn_threshold_in_ms":                              typeInt,
+	"hinted_handoff_disabled_datacenters":                  typeList,
+	"hinted_handoff_enabled":                               typeBool,
+	"hinted_handoff_throttle_in_kb":                        typeInt,
+	"hints_compression":                                    typeList,
+	"hints_directory":                                      typeString,
+	"hints_flush_period_in_ms":                             typeInt,
+	"ideal_consistency_level":                              typeString,
+	"incremental_backups":                                  typeBool,
+	"index_summary_capacity_in_mb":                         typeInt,
+	"index_summary_resize_interval_in_minutes":             typeInt,
+	"initial_token":                                        typeString,
+	"inter_dc_stream_throughput_outbound_megabits_per_sec": typeInt,
+	"inter_dc_tcp_nodelay":                                 typeBool,
+	"internode_authenticator":                              typeString,
+	"internode_compression":                                typeString,
+	"internode_recv_buff_size_in_bytes":                    typeInt,
+	"internode_send_buff_size_in_bytes":                    typeInt,
+	"key_cache_keys_to_save":                               typeInt,
+	"key_cache_save_period":                                typeInt,
+	"key_cache_size_in_mb":                                 typeInt,
+	"listen_address":                                       typeString,
+	"listen_interface":                                     typeString,
+	"listen_interface_prefer_ipv6":                         typeBool,
+	"listen_on_broadcast_address":                          typeBool,
+	"max_concurrent_automatic_sstable_upgrades":            typeInt,
+	"max_hint_window_in_ms":                                typeInt,
+	"max_hints_delivery_threads":                           typeInt,
+	"max_hints_file_size_in_mb":                            typeInt,
+	"max_mutation_size_in_kb":                              typeInt,
+	"max_value_size_in_mb":                                 typeInt,
+	"memtable_allocation_type":                             typeString,
+	"memtable_cleanup_threshold":                           typeNumber,
+	"memtable_flush_writers":                               typeInt,
+	"memtable_heap_space_in_mb":                            typeInt,
+	"memtable_offheap_space_in_mb":                         typeInt,
+	"min_free_space_per_drive_in_mb":                       typeInt,
+	"native_transport_flush_in_batches_legacy":             typeBool,
+	"native_transport_max_concurrent_connections":          typeInt,
+	"native_transport_max_concurrent_connections_per_ip":   typeInt,
+	"native_transport_max_frame_size_in_mb":                typeInt,
+	"native_transport_max_threads":                         typeInt,
+	"native_transport_port":                                typeInt,
+	"native_transport_port_ssl":                            typeInt,
+	"num_tokens":                                           typeInt,
+	"otc_backlog_expiration_interval_ms":                   typeInt,
+	"otc_coalescing_enough_coalesced_messages":             typeInt,
+	"otc_coalescing_strategy":                              typeString,
+	"otc_coalescing_window_us":                             typeInt,
+	"partitioner":                                          typeString,
+	"permissions_cache_max_entries":                        typeInt,
+	"permissions_update_interval_in_ms":                    typeInt,
+	"permissions_validity_in_ms":                           typeInt,
+	"phi_convict_threshold":                                typeNumber,
+	"prepared_statements_cache_size_mb":                    typeInt,
+	"range_request_timeout_in_ms":                          typeInt,
+	"read_request_timeout_in_ms":                           typeInt,
+	"repair_session_max_tree_depth":                        typeInt,
+	"request_scheduler":                                    typeString,
+	"request_scheduler_id":                                 typeString,
+	"request_scheduler_options":                            typeObject,
+	"request_timeout_in_ms":                                typeInt,
+	"role_manager":                                         typeString,
+	"roles_cache_max_entries":                              typeInt,
+	"roles_update_interval_in_ms":                          typeInt,
+	"roles_validity_in_ms":                                 typeInt,
+	"row_cache_class_name":                                 typeString,
+	"row_cache_keys_to_save":                               typeInt,
+	"row_cache_save_period":                                typeInt,
+	"row_cache_size_in_mb":                                 typeInt,
+	"rpc_address":                                          typeString,
+	"rpc_interface":                                        typeString,
+	"rpc_interface_prefer_ipv6":                            typeBool,
+	"rpc_keepalive":                                        typeBool,
+	"rpc_listen_backlog":                                   typeInt,
+	"rpc_max_threads":                                      typeInt,
+	"rpc_min_threads":                                      typeInt,
+	"rpc_port":                                             typeInt,
+	"rpc_recv_buff_size_in_bytes":                          typeInt,
+	"rpc_send_buff_size_in_bytes":                          typeInt,
+	"rpc_server_type":                                      typeString,
+	"saved_caches_directory":                               typeString,
+	"seed_provider":                                        typeList,
+	"server_encryption_options":                            typeObject,
+	"slow_query_log_timeout_in_ms":                         typeInt,
+	"snapshot_before_compaction":                           typeBool,
+	"ssl_storage_port":                                     typeInt,
+	"sstable_preemptive_open_interval_in_mb":               typeInt,
+	"start_native_transport":                               typeBool,
+	"start_rpc":                                            typeBool,
+	"storage_port":                                         typeInt,
+	"stream_throughput_outbound_megabits_per_sec":          typeInt,
+	"streaming_keep_alive_period_in_secs":                  typeInt,
+	"streaming_socket_timeout_in_ms":                       typeInt,
+	"thrift_framed_transport_size_in_mb":                   typeInt,
+	"thrift_prepared_statements_cache_size_mb":             typeInt,
+	"tombstone_failure_threshold":                          typeInt,
+	"tombstone_warn_threshold":                             typeInt,
+	"tracetype_query_ttl":                                  typeInt,
+	"tracetype_repair_ttl":                                 typeInt,
+	"transparent_data_encryption_options":                  typeObject,
+	"trickle_fsync":                                        typeBool,
+	"trickle_fsync_interval_in_kb":                         typeInt,
+	"truncate_request_timeout_in_ms":                       typeInt,
+	"unlogged_batch_across_partitions_warn_threshold":      typeInt,
+	"user_defined_function_fail_timeout":                   typeInt,
+	"user_defined_function_warn_timeout":                   typeInt,
+	"user_function_timeout_policy":                         typeString,
+	"windows_timer_interval":                               typeInt,
+	"write_request_timeout_in_ms":                          typeInt,
+}
+
+// Cassandra 4.0 refuses to start with the Thrift and request scheduler keys
+// that it removed
+var cassandraYaml40 = cassandraYaml311.withKeys(sectionSchema{
+	"allocate_tokens_for_local_replication_factor":                           typeInt,
+	"audit_logging_options":                                                  typeObject,
+	"auto_optimise_full_repair_streams":                                      typeBool,
+	"auto_optimise_inc_repair_streams":                                       typeBool,
+	"auto_optimise_preview_repair_streams":                                   typeBool,
+	"autocompaction_on_startup_enabled":                                      typeBool,
+	"block_for_peers_in_remote_dcs":                                          typeBool,
+	"block_for_peers_timeout_in_secs":                                        typeInt,
+	"check_for_duplicate_rows_during_compaction":                             typeBool,
+	"check_for_duplicate_rows_during_reads":                                  typeBool,
+	"commitlog_sync_group_window_in_ms":                                      typeNumber,
+	"concurrent_materialized_view_builders":                                  typeInt,
+	"concurrent_validations":                                                 typeInt,
+	"consecutive_message_errors_threshold":                                   typeInt,
+	"corrupted_tombstone_strategy":                                           typeString,
+	"diagnostic_events_enabled":                                              typeBool,
+	"enable_transient_replication":                                           typeBool,
+	"file_cache_enabled":                                                     typeBool,
+	"flush_compression":                                                      typeString,
+	"full_query_logging_options":                                             typeObject,
+	"initial_range_tombstone_list_allocation_size":                           typeInt,
+	"internode_application_receive_queue_capacity_in_bytes":                  typeInt,
+	"internode_application_receive_queue_reserve_endpoint_capacity_in_bytes": typeInt,
+	"internode_application_receive_queue_reserve_global_capacity_in_bytes":   typeInt,
+	"internode_application_send_queue_capacity_in_bytes":                     typeInt,
+	"internode_application_send_queue_reserve_endpoint_capacity_in_bytes":    typeInt,
+	"internode_application_send_queue_reserve_global_capacity_in_bytes":      typeInt,
+	"internode_max_message_size_in_bytes":                                    typeInt,
+	"internode_socket_receive_buffer_size_in_bytes":                          typeInt,
+	"internode_socket_send_buffer_size_in_bytes":                             typeInt,
+	"internode_tcp_connect_timeout_in_ms":                                    typeInt,
+	"internode_tcp_user_timeout_in_ms":                                       typeInt,
+	"key_cache_migrate_during_compaction":                                    typeBool,
+	"keyspace_count_warn_threshold":                                          typeInt,
+	"native_transport_allow_older_protocols":                                 typeBool,
+	"native_transport_idle_timeout_in_ms":                                    typeInt,
+	"native_transport_max_concurrent_requests_in_bytes":                      typeInt,
+	"native_transport_max_concurrent_requests_in_bytes_per_ip":               typeInt,
+	"native_transport_max_negotiable_protocol_version":                       typeInt,
+	"native_transport_receive_queue_capacity_in_bytes":                       typeInt,
+	"network_authorizer":                                                     typeString,
+	"networking_cache_size_in_mb":                                            typeInt,
+	"periodic_commitlog_sync_lag_block_in_ms":                                typeInt,
+	"range_tombstone_list_growth_factor":                                     typeNumber,
+	"repaired_data_tracking_for_partition_reads_enabled":                     typeBool,
+	"repaired_data_tracking_for_range_reads_enabled":                         typeBool,
+	"report_unconfirmed_repaired_data_mismatches":                            typeBool,
+	"snapshot_on_duplicate_row_detection":                                    typeBool,
+	"snapshot_on_repaired_data_mismatch":                                     typeBool,
+	"stream_entire_sstables":                                                 typeBool,
+	"streaming_connections_per_host":                                         typeInt,
+	"table_count_warn_threshold":                                             typeInt,
+	"use_offheap_merkle_trees":                                               typeBool,
+	"validation_preview_purge_head_start_in_sec":                             typeInt,
+},
+	"internode_recv_buff_size_in_bytes",
+	"internode_send_buff_size_in_bytes",
+	"otc_backlog_expiration_interval_ms",
+	"request_scheduler",
+	"request_scheduler_id",
+	"request_scheduler_options",
+	"rpc_listen_backlog",
+	"rpc_max_threads",
+	"rpc_min_threads",
+	"rpc_port",
+	"rpc_recv_buff_size_in_bytes",
+	"rpc_send_buff_size_in_bytes",
+	"rpc_server_type",
+	"start_rpc",
+	"streaming_socket_timeout_in_ms",
+	"thrift_framed_transport_size_in_mb",
+	"thrift_prepared_statements_cache_size_mb",
+)
+
+// The keys of jvm-options, see docs/user/jvm_server_configuration.md
+var jvmOptions311 = sectionSchema{
+	"additional-jvm-opts":                                typeAny,
+	"agent_lib_jdwp":                                     typeBool,
+	"always_pre_touch":                                   typeBool,
+	"cassandra_available_processors":                     typeInt,
+	"cassandra_config_directory":                         typeString,
+	"cassandra_disable_auth_caches_remote_configuration": typeBool,
+	"cassandra_force_3_0_protocol_version":               typeBool,
+	"cassandra_force_default_indexing_page_size":         typeBool,
+	"cassandra_initial_token":                            typeString,
+	"cassandra_join_ring":                                typeBool,
+	"cassandra_load_ring_state":                          typeBool,
+	"cassandra_metrics_reporter_config_file":             typeString,
+	"cassandra_replace_address":                          typeString,
+	"cassandra_replay_list":                              typeString,
+	"cassandra_ring_delay_ms":                            typeInt,
+	"cassandra_triggers_dir":                             typeString,
+	"cassandra_write_survey":                             typeBool,
+	"cms_initiating_occupancy_fraction":                  typeInt,
+	"cms_wait_duration":                                  typeInt,
+	"conc_gc_threads":                                    typeInt,
+	"enable_assertions":                                  typeBool,
+	"flight_recorder":                                    typeBool,
+	"g1r_set_updating_pause_time_percent":                typeInt,
+	"garbage_collector":                                  typeString,
+	"gc_log_file_size":                                   typeString,
+	"heap_dump_on_out_of_memory_error":                   typeBool,
+	"heap_size_young_generation":                         typeString,
+	"initial_heap_size":                                  typeString,
+	"initiating_heap_occupancy_percent":                  typeInt,
+	"java_net_prefer_ipv4_stack":                         typeBool,
+	"jmx-connection-type":                                typeString,
+	"jmx-remote-ssl-opts":                                typeString,
+	"log_gc":                                             typeBool,
+	"max_gc_pause_millis":                                typeInt,
+	"max_heap_size":                                      typeString,
+	"max_tenuring_threshold":                             typeInt,
+	"number_of_gc_log_files":                             typeInt,
+	"parallel_gc_threads":                                typeInt,
+	"per_thread_stack_size":                              typeString,
+	"perf_disable_shared_mem":                            typeBool,
+	"print_flss_statistics":                              typeBool,
+	"print_gc_application_stopped_time":                  typeBool,
+	"print_gc_details":                                   typeBool,
+	"print_heap_at_gc":                                   typeBool,
+	"print_promotion_failure":                            typeBool,
+	"print_tenuring_distribution":                        typeBool,
+	"resize_tlb":                                         typeBool,
+	"string_table_size":                                  typeString,
+	"survivor_ratio":                                     typeInt,
+	"thread_priority_policy_42":                          typeBool,
+	"unlock_commerical_features":                         typeBool,
+	"use_biased_locking":                                 typeBool,
+	"use_gc_log_file_rotation":                           typeBool,
+	"use_thread_priorities":                              typeBool,
+	"use_tlb":                                            typeBool,
+}
+
+// The keys of jvm-server-options, see docs/user/jvm_server_configuration.md
+var jvmServerOptions40 = sectionSchema{
+	"additional-jvm-opts":                                typeAny,
+	"agent_lib_jdwp":                                     typeBool,
+	"always_pre_touch":                                   typeBool,
+	"cassandra_available_processors":                     typeInt,
+	"cassandra_config_directory":                         typeString,
+	"cassandra_disable_auth_caches_remote_configuration": typeBool,
+	"cassandra_expiration_date_overflow_policy":          typeString,
+	"cassandra_force_default_indexing_page_size":         typeBool,
+	"cassandra_initial_token":                            typeString,
+	"cassandra_join_ring":                                typeBool,
+	"cassandra_load_ring_state":                          typeBool,
+	"cassandra_max_hint_ttl":                             typeString,
+	"cassandra_metrics_reporter_config_file":             typeString,
+	"cassandra_replace_address":                          typeString,
+	"cassandra_ring_delay_ms":                            typeInt,
+	"cassandra_triggers_dir":                             typeString,
+	"cassandra_write_survey":                             typeBool,
+	"crash_on_out_of_memory_error":                       typeBool,
+	"debug-non-safepoints":                               typeBool,
+	"enable_assertions":                                  typeBool,
+	"exit_on_out_of_memory_error":                        typeBool,
+	"flight_recorder":                                    typeBool,
+	"guaranteed-safepoint-interval":                      typeString,
+	"heap_dump_on_out_of_memory_error":                   typeBool,
+	"initial_heap_size":                                  typeString,
+	"io_netty_eventloop_maxpendingtasks":                 typeInt,
+	"java_net_prefer_ipv4_stack":                         typeBool,
+	"jdk_nio_maxcachedbuffersize":                        typeInt,
+	"jmx-connection-type":                                typeString,
+	"log_compilation":                                    typeBool,
+	"max_heap_size":                                      typeString,
+	"page-align-direct-memory":                           typeBool,
+	"per_thread_stack_size":                              typeString,
+	"perf_disable_shared_mem":                            typeBool,
+	"preserve-frame-pointer":                             typeBool,
+	"print_heap_histogram_on_out_of_memory_error":        typeBool,
+	"resize_tlb":                                         typeBool,
+	"restrict-contended":                                 typeBool,
+	"string_table_size":                                  typeString,
+	"unlock-diagnostic-vm-options":                       typeBool,
+	"unlock_commercial_features":                         typeBool,
+	"use-biased-locking":                                 typeBool,
+	"use_numa":                                           typeBool,
+	"use_thread_priorities":                              typeBool,
+	"use_tlb":                                            typeBool,
+}
+
+// The sections that are not checked key by key
+var cassandraUncheckedSections = map[string]sectionSchema{
+	"cassandra-env-sh":   nil,
+	"10-write-prom-conf": nil,
+}
+
+func cassandraSchema(sections map[string]sectionSchema) serverSchema {
+	schema := serverSchema{sections: map[string]sectionSchema{}, strict: true}
+	for _, s := range []map[string]sectionSchema{modelSections, cassandraUncheckedSections, sections} {
+		for name, section := range s {
+			schema.sections[name] = section
+		}
+	}
+	return schema
+}
+
+// The config schemas by server type and major.minor version. DSE accepts many
+// more keys than Cassandra, so only the types of the keys it shares with
+// Cassandra are checked.
+var serverSchemas = map[string]map[string]serverSchema{
+	"cassandra": {
+		"3.11": cassandraSchema(map[string]sectionSchema{
+			"cassandra-yaml": cassandraYaml311,
+			"jvm-options":    jvmOptions311,
+		}),
+		"4.0": cassandraSchema(map[string]sectionSchema{
+			"cassandra-yaml":       cassandraYaml40,
+			"jvm-server-options":   jvmServerOptions40,
+			"jvm8-server-options":  nil,
+			"jvm11-server-options": nil,
+		}),
+	},
+	"dse": {
+		"6.8": serverSchema{
+			sections: map[string]sectionSchema{
+				"jvm-options": jvmOptions311,
+			},
+			strict: false,
+		},
+	},
+}
@@ -0,0 +1,228 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package serverconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// The jvm options sections that can set the heap size
+var heapSections = []string{"jvm-options", "jvm-server-options"}
+
+// ValidateConfig checks the config JSON of a datacenter against the keys that
+// the config builder accepts for the server type and version. Heap sizes must
+// fit in memoryLimit, in bytes, unless it is zero. Server versions without a
+// known schema are only checked for well-formed JSON.
+func ValidateConfig(serverType string, serverVersion string, config []byte, memoryLimit int64, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(config) == 0 {
+		return allErrs
+	}
+
+	sections := map[string]interface{}{}
+	if err := json.Unmarshal(config, &sections); err != nil {
+		return append(allErrs, field.Invalid(fldPath, string(config), fmt.Sprintf("must be a JSON object: %s", err.Error())))
+	}
+
+	schema, found := getServerSchema(serverType, serverVersion)
+	if !found {
+		return allErrs
+	}
+
+	for _, name := range sortedKeys(sections) {
+		sectionPath := fldPath.Child(name)
+		keys, known := schema.sections[name]
+		if !known {
+			if schema.strict {
+				allErrs = append(allErrs, field.NotSupported(sectionPath, name, sortedSectionNames(schema)))
+			}
+			continue
+		}
+
+		section, ok := sections[name].(map[string]interface{})
+		if !ok {
+			allErrs = append(allErrs, field.Invalid(sectionPath, sections[name], "must be an object"))
+			continue
+		}
+		allErrs = append(allErrs, validateSection(keys, section, schema.strict, sectionPath)...)
+	}
+
+	for _, name := range heapSections {
+		if section, ok := sections[name].(map[string]interface{}); ok {
+			if _, known := schema.sections[name]; known {
+				allErrs = append(allErrs, validateHeapSize(section, memoryLimit, fldPath.Child(name))...)
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func getServerSchema(serverType string, serverVersion string) (serverSchema, bool) {
+	parts := strings.SplitN(serverVersion, ".", 3)
+	if len(parts) < 2 {
+		return serverSchema{}, false
+	}
+	schema, found := serverSchemas[serverType][parts[0]+"."+parts[1]]
+	return schema, found
+}
+
+func validateSection(keys sectionSchema, section map[string]interface{}, strict bool, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if keys == nil {
+		return allErrs
+	}
+
+	for _, key := range sortedKeys(section) {
+		keyPath := fldPath.Child(key)
+		value := section[key]
+		expected, known := keys[key]
+		if !known {
+			if strict {
+				allErrs = append(allErrs, field.Invalid(keyPath, value, "is not a known setting"))
+			}
+			continue
+		}
+		if !hasType(value, expected) {
+			allErrs = append(allErrs, field.Invalid(keyPath, value, fmt.Sprintf("must be of type %s", expected)))
+		}
+	}
+	return allErrs
+}
+
+// hasType tells whether the value can be rendered as the expected type. The
+// config builder writes numbers and booleans given as strings unchanged, so
+// they are accepted too. Null values unset the key.
+func hasType(value interface{}, expected valueType) bool {
+	if value == nil {
+		return true
+	}
+
+	switch expected {
+	case typeString:
+		switch value.(type) {
+		case string, float64:
+			return true
+		}
+		return false
+	case typeInt:
+		switch v := value.(type) {
+		case float64:
+			return v == math.Trunc(v)
+		case string:
+			_, err := strconv.ParseInt(v, 10, 64)
+			return err == nil
+		}
+		return false
+	case typeNumber:
+		switch v := value.(type) {
+		case float64:
+			return true
+		case string:
+			_, err := strconv.ParseFloat(v, 64)
+			return err == nil
+		}
+		return false
+	case typeBool:
+		switch v := value.(type) {
+		case bool:
+			return true
+		case string:
+			return v == "true" || v == "false"
+		}
+		return false
+	case typeList:
+		_, ok := value.([]interface{})
+		return ok
+	case typeObject:
+		_, ok := value.(map[string]interface{})
+		return ok
+	default:
+		return true
+	}
+}
+
+func validateHeapSize(section map[string]interface{}, memoryLimit int64, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for _, key := range []string{"initial_heap_size", "max_heap_size"} {
+		value, found := section[key]
+		if !found || value == nil {
+			continue
+		}
+
+		keyPath := fldPath.Child(key)
+		size, err := parseJvmSize(value)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(keyPath, value, err.Error()))
+			continue
+		}
+		if memoryLimit > 0 && size > memoryLimit {
+			allErrs = append(allErrs, field.Invalid(keyPath, value,
+				fmt.Sprintf("must not exceed the memory limit of %d bytes", memoryLimit)))
+		}
+	}
+	return allErrs
+}
+
+// parseJvmSize returns the number of bytes of a JVM memory size such as
+// 512000, 800m or 2G
+func parseJvmSize(value interface{}) (int64, error) {
+	var size string
+	switch v := value.(type) {
+	case float64:
+		size = strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		size = strings.TrimSpace(v)
+	default:
+		return 0, fmt.Errorf("must be a size such as 800M or 2G")
+	}
+
+	multiplier := int64(1)
+	if len(size) > 0 {
+		switch size[len(size)-1] {
+		case 'k', 'K':
+			multiplier = 1 << 10
+		case 'm', 'M':
+			multiplier = 1 << 20
+		case 'g', 'G':
+			multiplier = 1 << 30
+		case 't', 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			size = size[:len(size)-1]
+		}
+	}
+
+	number, err := strconv.ParseInt(size, 10, 64)
+	if err != nil || number <= 0 || number > math.MaxInt64/multiplier {
+		return 0, fmt.Errorf("must be a size such as 800M or 2G")
+	}
+	return number * multiplier, nil
+}
+
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedSectionNames(schema serverSchema) []string {
+	names := make([]string, 0, len(schema.sections))
+	for name := range schema.sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,207 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package serverconfig
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateConfig(t *testing.T) {
+	type args struct {
+		serverType    string
+		serverVersion string
+		config        string
+		memoryLimit   int64
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "No config",
+			args: args{
+				serverType:    "cassandra",
+				serverVersion: "3.11.6",
+			},
+			want: []string{},
+		},
+		{
+			name: "Valid 3.11 config",
+			args: args{
+				serverType:    "cassandra",
+				serverVersion: "3.11.6",
+				config: `{"cassandra-yaml":{"num_tokens":8,"authenticator":"PasswordAuthenticator","start_rpc":"false",` +
+					`"seed_provider":[{"class_name":"org.apache.cassandra.locator.SimpleSeedProvider"}]},` +
+					`"jvm-options":{"initial_heap_size":"800m","max_heap_size":"800m","cassandra_ring_delay_ms":"30000"}}`,
+				memoryLimit: 2 << 30,
+			},
+			want: []string{},
+		},
+		{
+			name: "Malformed JSON",
+			args: args{
+				serverType:    "cassandra",
+				serverVersion: "3.11.6",
+				config:        `{"cassandra-yaml":`,
+			},
+			want: []string{"spec.config"},
+		},
+		{
+			name: "Unknown section",
+			args: args{
+				serverType:    "cassandra",
+				serverVersion: "3.11.6",
+				config:        `{"cassandra-yml":{}}`,
+			},
+			want: []string{"spec.config.cassandra-yml"},
+		},
+		{
+			name: "Section that is not an object",
+			args: args{
+				serverType:    "cassandra",
+				serverVersion: "3.11.6",
+				config:        `{"jvm-options":"-Xmx800m"}`,
+			},
+			want: []string{"spec.config.jvm-options"},
+		},
+		{
+			name: "Unknown keys and wrong types",
+			args: args{
+				serverType:    "cassandra",
+				serverVersion: "3.11.6",
+				config:        `{"cassandra-yaml":{"num_tokens":8.5,"auto_snapshot":"yes","concurent_reads":32},"jvm-options":{"log_gc":1}}`,
+			},
+			want: []string{
+				"spec.config.cassandra-yaml.auto_snapshot",
+				"spec.config.cassandra-yaml.concurent_reads",
+				"spec.config.cassandra-yaml.num_tokens",
+				"spec.config.jvm-options.log_gc",
+			},
+		},
+		{
+			name: "Thrift settings removed in 4.0",
+			args: args{
+				serverType:    "cassandra",
+				serverVersion: "4.0.0",
+				config:        `{"cassandra-yaml":{"start_rpc":false,"rpc_port":9160}}`,
+			},
+			want: []string{
+				"spec.config.cassandra-yaml.rpc_port",
+				"spec.config.cassandra-yaml.start_rpc",
+			},
+		},
+		{
+			name: "jvm-options in 4.0",
+			args: args{
+				serverType:    "cassandra",
+				serverVersion: "4.0.0",
+				config:        `{"jvm-options":{"max_heap_size":"800m"}}`,
+			},
+			want: []string{"spec.config.jvm-options"},
+		},
+		{
+			name: "Heap bigger than the memory limit",
+			args: args{
+				serverType:    "cassandra",
+				serverVersion: "4.0.0",
+				config:        `{"jvm-server-options":{"initial_heap_size":"1G","max_heap_size":"4G"}}`,
+				memoryLimit:   2 << 30,
+			},
+			want: []string{"spec.config.jvm-server-options.max_heap_size"},
+		},
+		{
+			name: "Heap without a memory limit",
+			args: args{
+				serverType:    "cassandra",
+				serverVersion: "4.0.0",
+				config:        `{"jvm-server-options":{"max_heap_size":"64G"}}`,
+			},
+			want: []string{},
+		},
+		{
+			name: "Invalid heap size",
+			args: args{
+				serverType:    "cassandra",
+				serverVersion: "3.11.6",
+				config:        `{"jvm-options":{"max_heap_size":"lots"}}`,
+			},
+			want: []string{"spec.config.jvm-options.max_heap_size"},
+		},
+		{
+			name: "DSE settings",
+			args: args{
+				serverType:    "dse",
+				serverVersion: "6.8.1",
+				config:        `{"dse-yaml":{"authentication_options":{"enabled":true}},"cassandra-yaml":{"num_tokens":8}}`,
+			},
+			want: []string{},
+		},
+		{
+			name: "DSE wrong type and heap",
+			args: args{
+				serverType:    "dse",
+				serverVersion: "6.8.1",
+				config:        `{"jvm-options":{"log_gc":"sometimes","max_heap_size":"4G"}}`,
+				memoryLimit:   2 << 30,
+			},
+			want: []string{
+				"spec.config.jvm-options.log_gc",
+				"spec.config.jvm-options.max_heap_size",
+			},
+		},
+		{
+			name: "Unknown version",
+			args: args{
+				serverType:    "cassandra",
+				serverVersion: "5.0.0",
+				config:        `{"cassandra-yml":{}}`,
+			},
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateConfig(tt.args.serverType, tt.args.serverVersion, []byte(tt.args.config),
+				tt.args.memoryLimit, field.NewPath("spec", "config"))
+			got := []string{}
+			for _, err := range errs {
+				got = append(got, err.Field)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateConfig() = %v, want errors for %v", errs, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseJvmSize(t *testing.T) {
+	tests := []struct {
+		value   interface{}
+		want    int64
+		wantErr bool
+	}{
+		{value: "800m", want: 800 << 20},
+		{value: "2G", want: 2 << 30},
+		{value: "1024k", want: 1 << 20},
+		{value: float64(536870912), want: 536870912},
+		{value: "", wantErr: true},
+		{value: "-1G", wantErr: true},
+		{value: "2GB", wantErr: true},
+		{value: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseJvmSize(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseJvmSize(%v) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseJvmSize(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
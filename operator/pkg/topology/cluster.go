@@ -0,0 +1,350 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package topology
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/jsonmergepatch"
+	"k8s.io/kubernetes/pkg/util/hash"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/datastax/cass-operator/operator/internal/result"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/events"
+	"github.com/datastax/cass-operator/operator/pkg/httphelper"
+	"github.com/datastax/cass-operator/operator/pkg/schema"
+	"github.com/datastax/cass-operator/operator/pkg/utils"
+)
+
+// The annotation holding the hash of the spec a CassandraDatacenter was last
+// created or updated with by its cluster
+const specHashAnnotation = "cassandra.datastax.com/cluster-spec-hash"
+
+// The annotation holding the spec a CassandraDatacenter was last created or
+// updated with by its cluster, in JSON. Only the fields that changed since
+// are updated, leaving the ones written by the defaulting webhook or by a
+// restore alone.
+const lastAppliedSpecAnnotation = "cassandra.datastax.com/cluster-last-applied-spec"
+
+// ReconcileCluster creates the datacenters of the cluster in order, and has
+// each one join the cluster before the next one is created
+func (rc *ReconciliationContext) ReconcileCluster() result.ReconcileResult {
+	logger := rc.ReqLogger
+	logger.Info("topology::ReconcileCluster")
+
+	sourceDcName := rc.Cluster.Spec.Datacenters[0].Name
+	for idx := range rc.Cluster.Spec.Datacenters {
+		if recResult := rc.CheckDatacenter(&rc.Cluster.Spec.Datacenters[idx], sourceDcName); recResult.Completed() {
+			return recResult
+		}
+	}
+
+	logger.Info("All datacenters have joined the cluster")
+	return result.Done()
+}
+
+// CheckDatacenter creates or updates the CassandraDatacenter, and once it is
+// ready, has it join the cluster. It continues only once the datacenter has
+// joined.
+func (rc *ReconciliationContext) CheckDatacenter(template *api.CassandraClusterDatacenter, sourceDcName string) result.ReconcileResult {
+	logger := rc.ReqLogger.WithValues("datacenter", template.Name)
+	logger.Info("topology::CheckDatacenter")
+
+	cluster := rc.Cluster
+	desired, err := newDatacenterForCluster(cluster, template, rc.Scheme)
+	if err != nil {
+		logger.Error(err, "error building datacenter")
+		return result.Error(err)
+	}
+
+	dc := &api.CassandraDatacenter{}
+	err = rc.Client.Get(rc.Ctx, types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, dc)
+	if err != nil && errors.IsNotFound(err) {
+		// The status is recorded first, so that a datacenter that exists
+		// without one is known to have been adopted rather than created
+		if cluster.GetDatacenterStatus(template.Name) == nil {
+			if err := rc.setDatacenterPhase(template.Name, api.ClusterDatacenterCreating); err != nil {
+				logger.Error(err, "error patching cluster status")
+				return result.Error(err)
+			}
+		}
+
+		logger.Info("Creating datacenter")
+		if err := rc.Client.Create(rc.Ctx, desired); err != nil {
+			logger.Error(err, "error creating datacenter")
+			return result.Error(err)
+		}
+		rc.Recorder.Eventf(cluster, corev1.EventTypeNormal, events.CreatedResource,
+			"Created datacenter %s", desired.Name)
+
+		// The datacenter is watched, so the cluster is reconciled again as
+		// it comes up
+		return result.Done()
+	} else if err != nil {
+		logger.Error(err, "error getting datacenter")
+		return result.Error(err)
+	}
+
+	if dc.Annotations[specHashAnnotation] != desired.Annotations[specHashAnnotation] {
+		logger.Info("Updating datacenter")
+		if err := mergeDatacenterSpec(dc, desired); err != nil {
+			logger.Error(err, "error merging datacenter spec")
+			return result.Error(err)
+		}
+		if dc.Annotations == nil {
+			dc.Annotations = map[string]string{}
+		}
+		dc.Annotations[specHashAnnotation] = desired.Annotations[specHashAnnotation]
+		dc.Annotations[lastAppliedSpecAnnotation] = desired.Annotations[lastAppliedSpecAnnotation]
+		if err := controllerutil.SetControllerReference(cluster, dc, rc.Scheme); err != nil {
+			logger.Error(err, "error setting owner of datacenter")
+			return result.Error(err)
+		}
+		if err := rc.Client.Update(rc.Ctx, dc); err != nil {
+			logger.Error(err, "error updating datacenter")
+			return result.Error(err)
+		}
+	}
+
+	status := cluster.GetDatacenterStatus(template.Name)
+	if status != nil && status.Phase == api.ClusterDatacenterReady {
+		return result.Continue()
+	}
+
+	if dc.GetConditionStatus(api.DatacenterReady) != corev1.ConditionTrue {
+		logger.Info("Waiting for datacenter to be ready")
+		return result.Done()
+	}
+
+	// A datacenter that already existed is expected to have its data, as is
+	// the first datacenter
+	if status == nil || template.Name == sourceDcName {
+		if err := rc.setDatacenterPhase(template.Name, api.ClusterDatacenterReady); err != nil {
+			logger.Error(err, "error patching cluster status")
+			return result.Error(err)
+		}
+		return result.Continue()
+	}
+
+	if status.Phase == api.ClusterDatacenterCreating {
+		if err := rc.setDatacenterPhase(template.Name, api.ClusterDatacenterRebuilding); err != nil {
+			logger.Error(err, "error patching cluster status")
+			return result.Error(err)
+		}
+	}
+
+	return rc.CheckDatacenterRebuild(dc, sourceDcName)
+}
+
+// CheckDatacenterRebuild replicates the keyspaces of the source datacenter to
+// a new datacenter, then rebuilds its nodes from the source datacenter one at
+// a time
+func (rc *ReconciliationContext) CheckDatacenterRebuild(dc *api.CassandraDatacenter, sourceDcName string) result.ReconcileResult {
+	logger := rc.ReqLogger.WithValues("datacenter", dc.Name)
+	logger.Info("topology::CheckDatacenterRebuild")
+
+	schemaRc, err := createSchemaContext(dc.Namespace, dc.Name, rc.Client, rc.Scheme, rc.eventRecorder, logger)
+	if err != nil {
+		logger.Error(err, "error creating datacenter context")
+		return result.Error(err)
+	}
+
+	status := rc.Cluster.GetDatacenterStatus(dc.Name)
+	if status.RebuildJobID != "" {
+		return rc.checkRebuildJob(schemaRc, status, sourceDcName)
+	}
+
+	if len(status.RebuiltPods) == 0 {
+		if err := schemaRc.AddDatacenterToKeyspaces(sourceDcName); err != nil {
+			logger.Error(err, "error replicating keyspaces to datacenter")
+			return result.Error(err)
+		}
+	}
+
+	for _, pod := range schemaRc.StartedPods() {
+		if utils.IndexOfString(status.RebuiltPods, pod.Name) >= 0 {
+			continue
+		}
+
+		// Rebuilding a node streams all of its data and takes a while, so
+		// the rebuild is started in the background and its job followed
+		logger.Info("Rebuilding node", "pod", pod.Name)
+		jobID, err := schemaRc.NodeMgmtClient.CallStartRebuildEndpoint(pod, sourceDcName)
+		if err != nil {
+			logger.Error(err, "error starting rebuild of node", "pod", pod.Name)
+			return result.Error(err)
+		}
+
+		patch := client.MergeFrom(rc.Cluster.DeepCopy())
+		status.RebuildPodName = pod.Name
+		status.RebuildJobID = jobID
+		if err := rc.Client.Status().Patch(rc.Ctx, rc.Cluster, patch); err != nil {
+			logger.Error(err, "error patching cluster status")
+			return result.Error(err)
+		}
+
+		return result.RequeueSoon(10)
+	}
+
+	if err := rc.setDatacenterPhase(dc.Name, api.ClusterDatacenterReady); err != nil {
+		logger.Error(err, "error patching cluster status")
+		return result.Error(err)
+	}
+	rc.Recorder.Eventf(rc.Cluster, corev1.EventTypeNormal, events.DatacenterJoinedCluster,
+		"Datacenter %s joined the cluster", dc.Name)
+
+	return result.Continue()
+}
+
+// checkRebuildJob waits for the rebuild in progress to finish, and records
+// its pod as rebuilt once it has. A rebuild that failed or was lost, e.g.
+// because the node restarted, is started again after a while.
+func (rc *ReconciliationContext) checkRebuildJob(schemaRc *schema.ReconciliationContext, status *api.CassandraClusterDatacenterStatus, sourceDcName string) result.ReconcileResult {
+	logger := rc.ReqLogger.WithValues("datacenter", status.Name, "pod", status.RebuildPodName, "jobId", status.RebuildJobID)
+
+	var pod *corev1.Pod
+	for _, startedPod := range schemaRc.StartedPods() {
+		if startedPod.Name == status.RebuildPodName {
+			pod = startedPod
+		}
+	}
+	if pod == nil {
+		logger.Info("Waiting for the node being rebuilt to be started")
+		return result.RequeueSoon(10)
+	}
+
+	details, err := schemaRc.NodeMgmtClient.CallJobDetailsEndpoint(pod, status.RebuildJobID)
+	if err != nil {
+		logger.Error(err, "error getting rebuild job")
+		rc.Recorder.Eventf(rc.Cluster, corev1.EventTypeWarning, events.FailedRebuild,
+			"Lost track of the rebuild of node %s: %s", pod.Name, err.Error())
+		return rc.finishRebuild(status, false)
+	}
+
+	switch details.Status {
+	case httphelper.JobStatusCompleted:
+		rc.Recorder.Eventf(rc.Cluster, corev1.EventTypeNormal, events.RebuiltNode,
+			"Rebuilt node %s from datacenter %s", pod.Name, sourceDcName)
+		return rc.finishRebuild(status, true)
+	case httphelper.JobStatusError:
+		rc.Recorder.Eventf(rc.Cluster, corev1.EventTypeWarning, events.FailedRebuild,
+			"Failed to rebuild node %s: %s", pod.Name, details.Error)
+		return rc.finishRebuild(status, false)
+	}
+
+	logger.Info("Waiting for rebuild to finish")
+	return result.RequeueSoon(10)
+}
+
+// finishRebuild forgets the rebuild job in progress, recording its pod as
+// rebuilt when it succeeded
+func (rc *ReconciliationContext) finishRebuild(status *api.CassandraClusterDatacenterStatus, succeeded bool) result.ReconcileResult {
+	patch := client.MergeFrom(rc.Cluster.DeepCopy())
+	if succeeded {
+		status.RebuiltPods = append(status.RebuiltPods, status.RebuildPodName)
+	}
+	status.RebuildPodName = ""
+	status.RebuildJobID = ""
+	if err := rc.Client.Status().Patch(rc.Ctx, rc.Cluster, patch); err != nil {
+		rc.ReqLogger.Error(err, "error patching cluster status")
+		return result.Error(err)
+	}
+
+	if succeeded {
+		return result.RequeueSoon(0)
+	}
+	return result.RequeueSoon(60)
+}
+
+// setDatacenterPhase records the phase of the datacenter in the status of
+// the cluster
+func (rc *ReconciliationContext) setDatacenterPhase(dcName string, phase api.CassandraClusterDatacenterPhase) error {
+	cluster := rc.Cluster
+	patch := client.MergeFrom(cluster.DeepCopy())
+
+	if status := cluster.GetDatacenterStatus(dcName); status != nil {
+		status.Phase = phase
+	} else {
+		cluster.Status.Datacenters = append(cluster.Status.Datacenters, api.CassandraClusterDatacenterStatus{
+			Name:  dcName,
+			Phase: phase,
+		})
+	}
+
+	return rc.Client.Status().Patch(rc.Ctx, cluster, patch)
+}
+
+// newDatacenterForCluster returns the CassandraDatacenter for a datacenter
+// of the cluster, owned by the cluster
+func newDatacenterForCluster(cluster *api.CassandraCluster, template *api.CassandraClusterDatacenter, scheme *runtime.Scheme) (*api.CassandraDatacenter, error) {
+	dc := &api.CassandraDatacenter{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      template.Name,
+			Namespace: cluster.Namespace,
+		},
+		Spec: *template.Spec.DeepCopy(),
+	}
+	dc.Spec.ClusterName = cluster.GetClusterName()
+
+	lastApplied, err := json.Marshal(dc.Spec)
+	if err != nil {
+		return nil, err
+	}
+	dc.Annotations = map[string]string{
+		specHashAnnotation:        deepHashString(dc.Spec),
+		lastAppliedSpecAnnotation: string(lastApplied),
+	}
+
+	if err := controllerutil.SetControllerReference(cluster, dc, scheme); err != nil {
+		return nil, err
+	}
+	return dc, nil
+}
+
+// mergeDatacenterSpec updates the spec of the datacenter with the changes
+// between the spec it was last applied with and the desired one. The fields
+// the cluster does not set keep their values.
+func mergeDatacenterSpec(dc *api.CassandraDatacenter, desired *api.CassandraDatacenter) error {
+	original := []byte(dc.Annotations[lastAppliedSpecAnnotation])
+	if len(original) == 0 {
+		original = []byte("{}")
+	}
+	modified := []byte(desired.Annotations[lastAppliedSpecAnnotation])
+	current, err := json.Marshal(dc.Spec)
+	if err != nil {
+		return err
+	}
+
+	patch, err := jsonmergepatch.CreateThreeWayJSONMergePatch(original, modified, current)
+	if err != nil {
+		return err
+	}
+	merged, err := jsonpatch.MergePatch(current, patch)
+	if err != nil {
+		return err
+	}
+
+	spec := api.CassandraDatacenterSpec{}
+	if err := json.Unmarshal(merged, &spec); err != nil {
+		return err
+	}
+	dc.Spec = spec
+	return nil
+}
+
+func deepHashString(obj interface{}) string {
+	hasher := sha256.New()
+	hash.DeepHashObject(hasher, obj)
+	return base64.StdEncoding.EncodeToString(hasher.Sum([]byte{}))
+}
@@ -0,0 +1,330 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package topology
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/httphelper"
+	"github.com/datastax/cass-operator/operator/pkg/mocks"
+	"github.com/datastax/cass-operator/operator/pkg/schema"
+)
+
+func newTestCluster() *api.CassandraCluster {
+	dcSpec := api.CassandraDatacenterSpec{
+		Size:          2,
+		ServerType:    "cassandra",
+		ServerVersion: "3.11.6",
+		ManagementApiAuth: api.ManagementApiAuthConfig{
+			Insecure: &api.ManagementApiAuthInsecureConfig{},
+		},
+	}
+	return &api.CassandraCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster1",
+			Namespace: "default",
+		},
+		Spec: api.CassandraClusterSpec{
+			Datacenters: []api.CassandraClusterDatacenter{
+				{Name: "dc1", Spec: dcSpec},
+				{Name: "dc2", Spec: dcSpec},
+			},
+		},
+	}
+}
+
+func newTestScheme() *runtime.Scheme {
+	s := scheme.Scheme
+	s.AddKnownTypes(api.SchemeGroupVersion,
+		&api.CassandraCluster{},
+		&api.CassandraClusterList{},
+		&api.CassandraDatacenter{},
+		&api.CassandraDatacenterList{},
+		&api.CassandraKeyspace{},
+		&api.CassandraKeyspaceList{})
+	return s
+}
+
+func newReadyDatacenter(t *testing.T, cluster *api.CassandraCluster, idx int) *api.CassandraDatacenter {
+	dc, err := newDatacenterForCluster(cluster, &cluster.Spec.Datacenters[idx], newTestScheme())
+	assert.NoError(t, err)
+	dc.Status.Conditions = []api.DatacenterCondition{
+		*api.NewDatacenterCondition(api.DatacenterReady, corev1.ConditionTrue),
+	}
+	return dc
+}
+
+func newStartedPods(dc *api.CassandraDatacenter) []runtime.Object {
+	pods := []runtime.Object{}
+	for i := 0; i < int(dc.Spec.Size); i++ {
+		labels := dc.GetDatacenterLabels()
+		labels[api.CassNodeState] = "Started"
+		pods = append(pods, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%s-default-sts-%d", dc.Spec.ClusterName, dc.Name, i),
+				Namespace: dc.Namespace,
+				Labels:    labels,
+			},
+			Status: corev1.PodStatus{
+				PodIP: fmt.Sprintf("10.0.1.%d", i+1),
+			},
+		})
+	}
+	return pods
+}
+
+// setupTest creates a reconciliation context for the cluster whose
+// datacenters have a management API that reports a single keyspace with
+// the given replication and whose rebuilds complete, and returns that
+// management API
+func setupTest(cluster *api.CassandraCluster, trackObjects []runtime.Object, replication map[string]string) (*ReconciliationContext, *mocks.ManagementApi) {
+	s := newTestScheme()
+	replicationBody, _ := json.Marshal(replication)
+
+	logger := zap.Logger(true)
+	mgmtApi := mocks.NewManagementApi(map[string]string{
+		"/api/v0/ops/keyspace":             `["app_data"]`,
+		"/api/v0/ops/keyspace/replication": string(replicationBody),
+		"/api/v0/ops/node/rebuild":         "rebuild-1",
+		"/api/v0/ops/executor/job":         `{"id":"rebuild-1","type":"rebuild","status":"COMPLETED"}`,
+	})
+	mockHttpClient := mgmtApi.HttpClient()
+
+	createSchemaContext = func(namespace string, dcName string, cli runtimeClient.Client, scheme *runtime.Scheme, rec record.EventRecorder, reqLogger logr.Logger) (*schema.ReconciliationContext, error) {
+		schemaRc, err := schema.CreateReconciliationContext(namespace, dcName, cli, scheme, rec, reqLogger)
+		if err != nil {
+			return nil, err
+		}
+		schemaRc.NodeMgmtClient = httphelper.NodeMgmtClient{Client: mockHttpClient, Log: logger, Protocol: "http"}
+		return schemaRc, nil
+	}
+
+	trackObjects = append(trackObjects, cluster)
+	rc := CreateReconciliationContext(cluster, fake.NewFakeClientWithScheme(s, trackObjects...), s, record.NewFakeRecorder(100), logger)
+
+	return rc, mgmtApi
+}
+
+func getDatacenter(t *testing.T, rc *ReconciliationContext, name string) *api.CassandraDatacenter {
+	dc := &api.CassandraDatacenter{}
+	err := rc.Client.Get(rc.Ctx, types.NamespacedName{Namespace: "default", Name: name}, dc)
+	if err != nil {
+		return nil
+	}
+	return dc
+}
+
+func TestReconcileCluster_CreatesFirstDatacenter(t *testing.T) {
+	cluster := newTestCluster()
+	rc, _ := setupTest(cluster, []runtime.Object{}, nil)
+
+	recResult := rc.ReconcileCluster()
+	assert.True(t, recResult.Completed())
+
+	dc := getDatacenter(t, rc, "dc1")
+	if assert.NotNil(t, dc) {
+		assert.Equal(t, "cluster1", dc.Spec.ClusterName)
+		assert.Equal(t, int32(2), dc.Spec.Size)
+		if assert.Equal(t, 1, len(dc.OwnerReferences)) {
+			assert.Equal(t, "cluster1", dc.OwnerReferences[0].Name)
+		}
+	}
+	assert.Nil(t, getDatacenter(t, rc, "dc2"), "Should wait for the first datacenter to be ready")
+
+	assert.Equal(t, []api.CassandraClusterDatacenterStatus{
+		{Name: "dc1", Phase: api.ClusterDatacenterCreating},
+	}, cluster.Status.Datacenters)
+}
+
+func TestReconcileCluster_CreatesNextDatacenter(t *testing.T) {
+	cluster := newTestCluster()
+	cluster.Status.Datacenters = []api.CassandraClusterDatacenterStatus{
+		{Name: "dc1", Phase: api.ClusterDatacenterCreating},
+	}
+	rc, mgmtApi := setupTest(cluster, []runtime.Object{newReadyDatacenter(t, cluster, 0)}, nil)
+
+	rc.ReconcileCluster()
+
+	assert.Empty(t, mgmtApi.Posts, "Should not rebuild the first datacenter")
+	assert.NotNil(t, getDatacenter(t, rc, "dc2"))
+	assert.Equal(t, []api.CassandraClusterDatacenterStatus{
+		{Name: "dc1", Phase: api.ClusterDatacenterReady},
+		{Name: "dc2", Phase: api.ClusterDatacenterCreating},
+	}, cluster.Status.Datacenters)
+}
+
+func TestReconcileCluster_RebuildsNewDatacenter(t *testing.T) {
+	cluster := newTestCluster()
+	cluster.Status.Datacenters = []api.CassandraClusterDatacenterStatus{
+		{Name: "dc1", Phase: api.ClusterDatacenterReady},
+		{Name: "dc2", Phase: api.ClusterDatacenterCreating},
+	}
+	dc2 := newReadyDatacenter(t, cluster, 1)
+	trackObjects := append(newStartedPods(dc2), newReadyDatacenter(t, cluster, 0), dc2)
+	rc, mgmtApi := setupTest(cluster, trackObjects, map[string]string{
+		"class": "org.apache.cassandra.locator.NetworkTopologyStrategy",
+		"dc1":   "3",
+	})
+
+	recResult := rc.ReconcileCluster()
+	assert.True(t, recResult.Completed())
+
+	if assert.Equal(t, 2, len(mgmtApi.Posts)) {
+		assert.Equal(t, "/api/v0/ops/keyspace/alter", mgmtApi.Posts[0].URL.Path)
+		body, err := ioutil.ReadAll(mgmtApi.Posts[0].Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t,
+			`{"keyspace_name":"app_data","replication_settings":[{"dc_name":"dc1","replication_factor":3},{"dc_name":"dc2","replication_factor":2}]}`,
+			string(body))
+
+		assert.Equal(t, "/api/v0/ops/node/rebuild", mgmtApi.Posts[1].URL.Path)
+		assert.Equal(t, "src_dc=dc1", mgmtApi.Posts[1].URL.RawQuery)
+	}
+
+	status := cluster.GetDatacenterStatus("dc2")
+	assert.Equal(t, api.ClusterDatacenterRebuilding, status.Phase)
+	assert.Equal(t, "cluster1-dc2-default-sts-0", status.RebuildPodName)
+	assert.Equal(t, "rebuild-1", status.RebuildJobID)
+	assert.Empty(t, status.RebuiltPods)
+
+	// The next reconcile finds the rebuild finished, without altering the
+	// keyspaces again
+	mgmtApi.Posts = nil
+	rc.ReconcileCluster()
+	assert.Empty(t, mgmtApi.Posts)
+	status = cluster.GetDatacenterStatus("dc2")
+	assert.Equal(t, []string{"cluster1-dc2-default-sts-0"}, status.RebuiltPods)
+	assert.Equal(t, "", status.RebuildJobID)
+
+	// And then rebuilds the other node
+	rc.ReconcileCluster()
+	if assert.Equal(t, 1, len(mgmtApi.Posts)) {
+		assert.Equal(t, "/api/v0/ops/node/rebuild", mgmtApi.Posts[0].URL.Path)
+	}
+	rc.ReconcileCluster()
+
+	mgmtApi.Posts = nil
+	recResult = rc.ReconcileCluster()
+	assert.Empty(t, mgmtApi.Posts)
+	assert.Equal(t, api.ClusterDatacenterReady, cluster.GetDatacenterStatus("dc2").Phase)
+	assert.Equal(t, 2, len(cluster.GetDatacenterStatus("dc2").RebuiltPods))
+	res, err := recResult.Output()
+	assert.NoError(t, err)
+	assert.False(t, res.Requeue)
+}
+
+func TestReconcileCluster_RetriesFailedRebuild(t *testing.T) {
+	cluster := newTestCluster()
+	cluster.Status.Datacenters = []api.CassandraClusterDatacenterStatus{
+		{Name: "dc1", Phase: api.ClusterDatacenterReady},
+		{
+			Name:           "dc2",
+			Phase:          api.ClusterDatacenterRebuilding,
+			RebuildPodName: "cluster1-dc2-default-sts-0",
+			RebuildJobID:   "rebuild-1",
+		},
+	}
+	dc2 := newReadyDatacenter(t, cluster, 1)
+	trackObjects := append(newStartedPods(dc2), newReadyDatacenter(t, cluster, 0), dc2)
+	rc, mgmtApi := setupTest(cluster, trackObjects, nil)
+	mgmtApi.Responses["/api/v0/ops/executor/job"] = `{"id":"rebuild-1","type":"rebuild","status":"ERROR","error":"stream failed"}`
+
+	recResult := rc.ReconcileCluster()
+	res, err := recResult.Output()
+	assert.NoError(t, err)
+	assert.True(t, res.RequeueAfter > 0, "Should wait before retrying the rebuild")
+
+	assert.Empty(t, mgmtApi.Posts)
+	status := cluster.GetDatacenterStatus("dc2")
+	assert.Empty(t, status.RebuiltPods)
+	assert.Equal(t, "", status.RebuildJobID)
+
+	// The rebuild is started again on the next reconcile
+	rc.ReconcileCluster()
+	if assert.Equal(t, 1, len(mgmtApi.Posts)) {
+		assert.Equal(t, "/api/v0/ops/node/rebuild", mgmtApi.Posts[0].URL.Path)
+	}
+	assert.Equal(t, "cluster1-dc2-default-sts-0", cluster.GetDatacenterStatus("dc2").RebuildPodName)
+}
+
+func TestReconcileCluster_UpdatesDatacenter(t *testing.T) {
+	cluster := newTestCluster()
+	cluster.Status.Datacenters = []api.CassandraClusterDatacenterStatus{
+		{Name: "dc1", Phase: api.ClusterDatacenterReady},
+	}
+	rc, _ := setupTest(cluster, []runtime.Object{newReadyDatacenter(t, cluster, 0)}, nil)
+	cluster.Spec.Datacenters[0].Spec.Size = 3
+	cluster.Spec.Datacenters = cluster.Spec.Datacenters[:1]
+
+	rc.ReconcileCluster()
+
+	dc := getDatacenter(t, rc, "dc1")
+	if assert.NotNil(t, dc) {
+		assert.Equal(t, int32(3), dc.Spec.Size)
+	}
+}
+
+func TestReconcileCluster_UpdatesDatacenterKeepsFieldsItDoesNotSet(t *testing.T) {
+	cluster := newTestCluster()
+	cluster.Status.Datacenters = []api.CassandraClusterDatacenterStatus{
+		{Name: "dc1", Phase: api.ClusterDatacenterReady},
+	}
+	dc1 := newReadyDatacenter(t, cluster, 0)
+	// As written by the defaulting webhook and by a restore
+	dc1.Spec.Racks = []api.Rack{{Name: "default"}}
+	dc1.Spec.Stopped = true
+	rc, _ := setupTest(cluster, []runtime.Object{dc1}, nil)
+	cluster.Spec.Datacenters[0].Spec.Size = 3
+	cluster.Spec.Datacenters = cluster.Spec.Datacenters[:1]
+
+	rc.ReconcileCluster()
+
+	dc := getDatacenter(t, rc, "dc1")
+	if assert.NotNil(t, dc) {
+		assert.Equal(t, int32(3), dc.Spec.Size)
+		assert.Equal(t, "cluster1", dc.Spec.ClusterName)
+		assert.Equal(t, []api.Rack{{Name: "default"}}, dc.Spec.Racks)
+		assert.True(t, dc.Spec.Stopped)
+	}
+
+	// Fields the cluster stops setting are removed
+	cluster.Spec.Datacenters[0].Spec.ServerVersion = ""
+	rc.ReconcileCluster()
+
+	dc = getDatacenter(t, rc, "dc1")
+	if assert.NotNil(t, dc) {
+		assert.Equal(t, "", dc.Spec.ServerVersion)
+		assert.True(t, dc.Spec.Stopped)
+	}
+}
+
+func TestReconcileCluster_AdoptsExistingDatacenter(t *testing.T) {
+	cluster := newTestCluster()
+	rc, mgmtApi := setupTest(cluster, []runtime.Object{newReadyDatacenter(t, cluster, 0)}, nil)
+	cluster.Spec.Datacenters = cluster.Spec.Datacenters[:1]
+
+	recResult := rc.ReconcileCluster()
+	assert.True(t, recResult.Completed())
+
+	assert.Empty(t, mgmtApi.Posts)
+	assert.Equal(t, []api.CassandraClusterDatacenterStatus{
+		{Name: "dc1", Phase: api.ClusterDatacenterReady},
+	}, cluster.Status.Datacenters)
+}
@@ -0,0 +1,60 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package topology
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	runtimeClient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+	"github.com/datastax/cass-operator/operator/pkg/events"
+	"github.com/datastax/cass-operator/operator/pkg/schema"
+)
+
+// Creates the context used to alter keyspaces and rebuild nodes through the
+// management API of a datacenter. Tests replace it to mock the management API.
+var createSchemaContext = schema.CreateReconciliationContext
+
+// ReconciliationContext contains everything needed to reconcile the
+// datacenters of a CassandraCluster
+type ReconciliationContext struct {
+	Client    runtimeClient.Client
+	Scheme    *runtime.Scheme
+	Cluster   *api.CassandraCluster
+	Recorder  record.EventRecorder
+	ReqLogger logr.Logger
+	Ctx       context.Context
+
+	// The recorder that is handed to the contexts of datacenters, which log
+	// events themselves
+	eventRecorder record.EventRecorder
+}
+
+// CreateReconciliationContext returns a context for reconciling the given
+// cluster
+func CreateReconciliationContext(
+	cluster *api.CassandraCluster,
+	cli runtimeClient.Client,
+	scheme *runtime.Scheme,
+	rec record.EventRecorder,
+	reqLogger logr.Logger) *ReconciliationContext {
+
+	rc := &ReconciliationContext{}
+	rc.Client = cli
+	rc.Scheme = scheme
+	rc.Cluster = cluster
+	rc.Recorder = &events.LoggingEventRecorder{EventRecorder: rec, ReqLogger: reqLogger}
+	rc.ReqLogger = reqLogger.
+		WithValues("clusterName", cluster.GetClusterName())
+	rc.Ctx = context.Background()
+	rc.eventRecorder = rec
+
+	rc.ReqLogger.Info("topology::CreateReconciliationContext")
+
+	return rc
+}
@@ -0,0 +1,76 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package topology
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/datastax/cass-operator/operator/internal/result"
+	api "github.com/datastax/cass-operator/operator/pkg/apis/cassandra/v1beta1"
+)
+
+var log = logf.Log.WithName("topology_handler")
+
+// ReconcileCassandraCluster reconciles a CassandraCluster object
+type ReconcileCassandraCluster struct {
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+}
+
+// Reconcile reads the state of a CassandraCluster and creates its
+// datacenters one after the other
+func (r *ReconcileCassandraCluster) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	startReconcile := time.Now()
+	logger := log.
+		WithValues("requestNamespace", request.Namespace).
+		WithValues("requestName", request.Name).
+		// loopID is used to tie all events together that are spawned by the same reconciliation loop
+		WithValues("loopID", uuid.New().String())
+
+	defer func() {
+		logger.Info("Reconcile loop completed",
+			"duration", time.Since(startReconcile).Seconds())
+	}()
+
+	logger.Info("======== cluster handler::Reconcile has been called")
+
+	cluster := &api.CassandraCluster{}
+	if err := r.client.Get(context.Background(), request.NamespacedName, cluster); err != nil {
+		if errors.IsNotFound(err) {
+			logger.Info("CassandraCluster resource not found. Ignoring since object must be deleted.")
+			return result.Done().Output()
+		}
+		logger.Error(err, "Failed to get CassandraCluster.")
+		return result.Error(err).Output()
+	}
+
+	if len(cluster.Spec.Datacenters) == 0 {
+		logger.Info("CassandraCluster has no datacenters")
+		return result.Done().Output()
+	}
+
+	rc := CreateReconciliationContext(cluster, r.client, r.scheme, r.recorder, logger)
+	return rc.ReconcileCluster().Output()
+}
+
+// NewClusterReconciler returns a new reconcile.Reconciler for
+// CassandraClusters
+func NewClusterReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileCassandraCluster{
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: mgr.GetEventRecorderFor("cass-operator"),
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package utils
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+)
+
+// GenerateCertificate creates a new RSA key of the given size and a
+// certificate for it from the template, signed by the parent certificate and
+// key, or self-signed when there is no parent. The serial number of the
+// template is set to a random one. Both are returned PEM encoded, with the
+// key in PKCS#8 format.
+func GenerateCertificate(template, parent *x509.Certificate, parentKey crypto.Signer, keySize int) (certPem, keyPem []byte, err error) {
+	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
+	template.SerialNumber, err = rand.Int(rand.Reader, serialNumberLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, keySize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if parent == nil {
+		parent = template
+		parentKey = key
+	}
+
+	derBytes, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certPem = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
+	keyPem = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes})
+	return certPem, keyPem, nil
+}
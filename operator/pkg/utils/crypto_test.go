@@ -0,0 +1,59 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package utils
+
+import (
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseTestCertificate(t *testing.T, certPem []byte) *x509.Certificate {
+	block, _ := pem.Decode(certPem)
+	if !assert.NotNil(t, block) {
+		t.FailNow()
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	assert.NoError(t, err)
+	return cert
+}
+
+func TestGenerateCertificate(t *testing.T) {
+	now := time.Now()
+	caTemplate := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             now,
+		NotAfter:              now.Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caPem, caKeyPem, err := GenerateCertificate(caTemplate, nil, nil, 2048)
+	assert.NoError(t, err)
+	caCert := parseTestCertificate(t, caPem)
+	assert.NoError(t, caCert.CheckSignatureFrom(caCert), "Should be self-signed without a parent")
+
+	block, _ := pem.Decode(caKeyPem)
+	if !assert.NotNil(t, block) || !assert.Equal(t, "PRIVATE KEY", block.Type) {
+		return
+	}
+	caKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		Subject:   pkix.Name{CommonName: "server"},
+		NotBefore: now,
+		NotAfter:  now.Add(time.Hour),
+	}
+	certPem, _, err := GenerateCertificate(template, caCert, caKey.(crypto.Signer), 2048)
+	assert.NoError(t, err)
+	cert := parseTestCertificate(t, certPem)
+	assert.NoError(t, cert.CheckSignatureFrom(caCert), "Should be signed by the parent")
+	assert.NotEqual(t, caCert.SerialNumber, cert.SerialNumber)
+}
@@ -0,0 +1,136 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package backup_restore
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	ginkgo_util "github.com/datastax/cass-operator/mage/ginkgo"
+	"github.com/datastax/cass-operator/mage/kubectl"
+)
+
+var (
+	testName        = "Backup and restore"
+	namespace       = "test-backup-restore"
+	dcName          = "dc2"
+	dcYaml          = "../testdata/default-single-rack-2-node-dc-with-superuser-secret.yaml"
+	secretYaml      = "../testdata/bob-secret.yaml"
+	minioYaml       = "../testdata/minio.yaml"
+	backupYaml      = "../testdata/cassandrabackup.yaml"
+	restoreYaml     = "../testdata/cassandrarestore.yaml"
+	superuserName   = "bob"
+	superuserPass   = "bobber"
+	backupResource  = "CassandraBackup/dc2-backup"
+	restoreResource = "CassandraRestore/dc2-restore"
+	dcLabel         = fmt.Sprintf("cassandra.datastax.com/datacenter=%s", dcName)
+	ns              = ginkgo_util.NewWrapper(testName, namespace)
+)
+
+func TestLifecycle(t *testing.T) {
+	AfterSuite(func() {
+		logPath := fmt.Sprintf("%s/aftersuite", ns.LogDir)
+		kubectl.DumpAllLogs(logPath).ExecV()
+		fmt.Printf("\n\tPost-run logs dumped at: %s\n\n", logPath)
+		ns.Terminate()
+	})
+
+	RegisterFailHandler(Fail)
+	RunSpecs(t, testName)
+}
+
+func cql(podName string, statement string) kubectl.KCmd {
+	return kubectl.ExecOnPod(
+		podName, "--", "cqlsh",
+		"--user", superuserName,
+		"--password", superuserPass,
+		"-e", statement).
+		WithFlag("container", "cassandra")
+}
+
+var _ = Describe(testName, func() {
+	Context("when in a new cluster", func() {
+		Specify("the operator can back up and restore a datacenter", func() {
+			By("creating a namespace")
+			err := kubectl.CreateNamespace(namespace).ExecV()
+			Expect(err).ToNot(HaveOccurred())
+
+			step := "setting up cass-operator resources via helm chart"
+			ns.HelmInstall("../../charts/cass-operator-chart")
+
+			ns.WaitForOperatorReady()
+
+			step = "creating the object store"
+			k := kubectl.ApplyFiles(minioYaml)
+			ns.ExecAndLog(step, k)
+
+			step = "creating the superuser secret"
+			k = kubectl.ApplyFiles(secretYaml)
+			ns.ExecAndLog(step, k)
+
+			step = "create user secret"
+			k = kubectl.CreateSecretLiteral("bobby-secret", "bobby", "littlebobbydroptables")
+			ns.ExecAndLog(step, k)
+
+			step = "creating a datacenter resource with 1 rack/2 nodes"
+			k = kubectl.ApplyFiles(dcYaml)
+			ns.ExecAndLog(step, k)
+
+			ns.WaitForDatacenterReady(dcName)
+
+			podNames := ns.GetDatacenterPodNames(dcName)
+
+			step = "writing data to back up"
+			k = cql(podNames[0],
+				"CREATE KEYSPACE backup_test WITH replication = {'class': 'NetworkTopologyStrategy', 'dc2': 2}; "+
+					"CREATE TABLE backup_test.items (id text PRIMARY KEY); "+
+					"INSERT INTO backup_test.items (id) VALUES ('before-backup');")
+			ns.ExecAndLog(step, k)
+
+			step = "creating a backup"
+			k = kubectl.ApplyFiles(backupYaml)
+			ns.ExecAndLog(step, k)
+
+			step = "waiting for the backup to complete"
+			json := "jsonpath={.status.conditions[?(@.type=='Complete')].status}"
+			k = kubectl.Get(backupResource).FormatOutput(json)
+			ns.WaitForOutputAndLog(step, k, "True", 600)
+
+			step = "writing data after the backup"
+			k = cql(podNames[0], "INSERT INTO backup_test.items (id) VALUES ('after-backup');")
+			ns.ExecAndLog(step, k)
+
+			step = "restoring the backup"
+			k = kubectl.ApplyFiles(restoreYaml)
+			ns.ExecAndLog(step, k)
+
+			step = "waiting for the restore to complete"
+			json = "jsonpath={.status.conditions[?(@.type=='Complete')].status}"
+			k = kubectl.Get(restoreResource).FormatOutput(json)
+			ns.WaitForOutputAndLog(step, k, "True", 1200)
+
+			ns.WaitForDatacenterReady(dcName)
+
+			step = "checking that only the data from before the backup is left"
+			k = cql(podNames[0], "CONSISTENCY ALL; SELECT id FROM backup_test.items;")
+			output := ns.OutputAndLog(step, k)
+			Expect(output).To(ContainSubstring("before-backup"))
+			Expect(output).ToNot(ContainSubstring("after-backup"))
+
+			step = "deleting the dc"
+			k = kubectl.DeleteFromFiles(dcYaml)
+			ns.ExecAndLog(step, k)
+
+			step = "checking that the dc no longer exists"
+			json = "jsonpath={.items}"
+			k = kubectl.Get("CassandraDatacenter").
+				WithLabel(dcLabel).
+				FormatOutput(json)
+			ns.WaitForOutputAndLog(step, k, "[]", 300)
+		})
+	})
+})
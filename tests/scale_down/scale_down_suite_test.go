@@ -0,0 +1,102 @@
+// Copyright DataStax, Inc.
+// Please see the included license file for details.
+
+package scale_down
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+
+	ginkgo_util "github.com/datastax/cass-operator/mage/ginkgo"
+	"github.com/datastax/cass-operator/mage/kubectl"
+)
+
+var (
+	testName     = "Scale down"
+	namespace    = "test-scale-down"
+	dcName       = "dc2"
+	dcYaml       = "../testdata/default-single-rack-2-node-dc.yaml"
+	operatorYaml = "../testdata/operator.yaml"
+	dcResource   = fmt.Sprintf("CassandraDatacenter/%s", dcName)
+	dcLabel      = fmt.Sprintf("cassandra.datastax.com/datacenter=%s", dcName)
+	ns           = ginkgo_util.NewWrapper(testName, namespace)
+)
+
+func TestLifecycle(t *testing.T) {
+	AfterSuite(func() {
+		logPath := fmt.Sprintf("%s/aftersuite", ns.LogDir)
+		kubectl.DumpAllLogs(logPath).ExecV()
+		fmt.Printf("\n\tPost-run logs dumped at: %s\n\n", logPath)
+		ns.Terminate()
+	})
+
+	RegisterFailHandler(Fail)
+	RunSpecs(t, testName)
+}
+
+var _ = Describe(testName, func() {
+	Context("when in a new cluster", func() {
+		Specify("the operator can scale down a datacenter", func() {
+			By("creating a namespace")
+			err := kubectl.CreateNamespace(namespace).ExecV()
+			Expect(err).ToNot(HaveOccurred())
+
+			step := "setting up cass-operator resources via helm chart"
+			ns.HelmInstall("../../charts/cass-operator-chart")
+
+			ns.WaitForOperatorReady()
+
+			step = "creating a datacenter resource with 1 rack/2 nodes"
+			k := kubectl.ApplyFiles(dcYaml)
+			ns.ExecAndLog(step, k)
+
+			ns.WaitForDatacenterReady(dcName)
+
+			step = "scale down to 1 node"
+			json := "{\"spec\": {\"size\": 1}}"
+			k = kubectl.PatchMerge(dcResource, json)
+			ns.ExecAndLog(step, k)
+
+			ns.WaitForDatacenterCondition(dcName, "ScalingDown", string(corev1.ConditionTrue))
+			ns.WaitForDatacenterOperatorProgress(dcName, "Updating", 30)
+
+			step = "check that the highest ordinal pod is decommissioning"
+			json = "jsonpath={.metadata.labels['cassandra\\.datastax\\.com/node-state']}"
+			k = kubectl.Get("pod", "cluster2-dc2-default-sts-1").FormatOutput(json)
+			ns.WaitForOutputAndLog(step, k, "Decommissioning", 60)
+
+			ns.WaitForDatacenterCondition(dcName, "ScalingDown", string(corev1.ConditionFalse))
+			ns.WaitForDatacenterReady(dcName)
+
+			Expect(ns.GetDatacenterReadyPodNames(dcName)).To(Equal([]string{"cluster2-dc2-default-sts-0"}))
+
+			step = "check that the decommissioned node's PVC was deleted"
+			json = "jsonpath={.items[*].metadata.name}"
+			k = kubectl.Get("pvc").
+				WithLabel(dcLabel).
+				FormatOutput(json)
+			ns.WaitForOutputAndLog(step, k, "server-data-cluster2-dc2-default-sts-0", 120)
+
+			step = "check that the decommissioned node's status was removed"
+			json = "jsonpath={.status.nodeStatuses['cluster2-dc2-default-sts-1']}"
+			k = kubectl.Get(dcResource).FormatOutput(json)
+			ns.WaitForOutputAndLog(step, k, "", 30)
+
+			step = "deleting the dc"
+			k = kubectl.DeleteFromFiles(dcYaml)
+			ns.ExecAndLog(step, k)
+
+			step = "checking that the dc no longer exists"
+			json = "jsonpath={.items}"
+			k = kubectl.Get("CassandraDatacenter").
+				WithLabel(dcLabel).
+				FormatOutput(json)
+			ns.WaitForOutputAndLog(step, k, "[]", 300)
+		})
+	})
+})